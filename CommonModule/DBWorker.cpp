@@ -50,6 +50,13 @@ namespace Database
 			return std::unexpected(parse_result.error());
 		}
 
+		// Edit and recall events are already applied to the row by the REST API; storing them would add a duplicate message
+		if (command_ == "edit_message" || command_ == "recall")
+		{
+			Logger::handle().write(LogTypes::Sequence, "DBWorker: Skipping " + command_ + " event (message_id: " + message_id_ + ")");
+			return {};
+		}
+
 		// Step 2: Encrypt message if encryption is enabled
 		std::string stored_content = message_content_;
 		bool is_encrypted = false;
@@ -117,6 +124,33 @@ namespace Database
 			id_ = std::string(message_object.at("id").as_string());
 			sub_id_ = std::string(message_object.at("sub_id").as_string());
 
+			// Extract room_id (optional, present only for room messages)
+			room_id_.clear();
+			if (message_object.contains("room_id") && message_object.at("room_id").is_string())
+			{
+				room_id_ = std::string(message_object.at("room_id").as_string());
+			}
+
+			// Extract recipient_id (optional, present only for direct messages)
+			recipient_id_.clear();
+			if (message_object.contains("recipient_id") && message_object.at("recipient_id").is_string())
+			{
+				recipient_id_ = std::string(message_object.at("recipient_id").as_string());
+			}
+
+			// Extract parent_message_id and thread_root_id (optional, present only for replies)
+			parent_message_id_.clear();
+			if (message_object.contains("parent_message_id") && message_object.at("parent_message_id").is_string())
+			{
+				parent_message_id_ = std::string(message_object.at("parent_message_id").as_string());
+			}
+
+			thread_root_id_.clear();
+			if (message_object.contains("thread_root_id") && message_object.at("thread_root_id").is_string())
+			{
+				thread_root_id_ = std::string(message_object.at("thread_root_id").as_string());
+			}
+
 			// Extract publisher_information (optional, default to empty JSON object)
 			message_id_.clear();
 			if (message_object.contains("publisher_information"))
@@ -257,6 +291,23 @@ namespace Database
 				query << ", message_id";
 			}
 
+			if (!room_id_.empty())
+			{
+				query << ", room_id";
+			}
+
+			if (!recipient_id_.empty())
+			{
+				query << ", recipient_id";
+			}
+
+			// The thread columns must be set together (chk_messages_thread)
+			const bool is_reply = !parent_message_id_.empty() && !thread_root_id_.empty();
+			if (is_reply)
+			{
+				query << ", parent_message_id, thread_root_id";
+			}
+
 			query << ") VALUES ("
 				  << "'" << escaped_user_id << "', "
 				  << "'" << escaped_sub_id << "', "
@@ -273,6 +324,22 @@ namespace Database
 				query << ", '" << db_client_->escape_string(message_id_) << "'";
 			}
 
+			if (!room_id_.empty())
+			{
+				query << ", '" << db_client_->escape_string(room_id_) << "'";
+			}
+
+			if (!recipient_id_.empty())
+			{
+				query << ", '" << db_client_->escape_string(recipient_id_) << "'";
+			}
+
+			if (is_reply)
+			{
+				query << ", '" << db_client_->escape_string(parent_message_id_) << "'"
+					  << ", '" << db_client_->escape_string(thread_root_id_) << "'";
+			}
+
 			query << ")";
 
 			// Execute query
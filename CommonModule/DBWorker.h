@@ -63,6 +63,11 @@ namespace Database
 		 * {
 		 *   "id": "user_id",
 		 *   "sub_id": "session_id",
+		 *   "room_id": "room UUID (optional, room messages only)",
+		 *   "recipient_id": "user_id (optional, direct messages only)",
+		 *   "parent_message_id": "message UUID (optional, replies only)",
+		 *   "thread_root_id": "message UUID (optional, replies only)",
+		 *   "attachments": [...] (optional; not stored here - the REST API links attachments to the message),
 		 *   "publisher_information": {...},
 		 *   "message": {
 		 *     "server_name": "MainServer",
@@ -88,6 +93,9 @@ namespace Database
 		 * Inserts into 'messages' table with columns:
 		 * - id: user identifier
 		 * - sub_id: session identifier
+		 * - room_id: room UUID, only for room messages
+		 * - recipient_id: direct message recipient, only for direct messages
+		 * - parent_message_id, thread_root_id: replied message and thread root, only for replies
 		 * - publisher_info: JSON string of publisher information
 		 * - server_name: target server name
 		 * - message_content: encrypted or plain message content
@@ -116,6 +124,10 @@ namespace Database
 		std::string sub_id_;
 		std::string command_;
 		std::string message_id_;
+		std::string room_id_;
+		std::string recipient_id_;
+		std::string parent_message_id_;
+		std::string thread_root_id_;
 		std::string publisher_info_;
 		std::string server_name_;
 		std::string message_content_;
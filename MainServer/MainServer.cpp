@@ -435,13 +435,75 @@ auto MainServer::consume_message_queue() -> std::expected<void, std::string>
 			{ "data", inner_message.at("content").as_string() }
 		};
 
+		// Direct messages go only to the recipient's and the sender's sessions, never to everyone
+		std::string recipient_id;
+		if (received_message.contains("recipient_id") && received_message.at("recipient_id").is_string())
+		{
+			recipient_id = std::string(received_message.at("recipient_id").as_string());
+			message_object["recipient_id"] = recipient_id;
+		}
+
+		// Clients need message_id to match replies, edits and recalls with the message they refer to
+		if (received_message.contains("publisher_information") && received_message.at("publisher_information").is_object())
+		{
+			const auto& publisher_information = received_message.at("publisher_information").as_object();
+			if (publisher_information.contains("message_id") && publisher_information.at("message_id").is_string())
+			{
+				message_object["message_id"] = publisher_information.at("message_id").as_string();
+			}
+		}
+
+		// Replies carry the message they answer and the first message of their thread
+		for (const auto* field : { "parent_message_id", "thread_root_id" })
+		{
+			if (received_message.contains(field) && received_message.at(field).is_string())
+			{
+				message_object[field] = received_message.at(field).as_string();
+			}
+		}
+
+		// Attachment metadata (id, file_name, content_type, size_bytes, sha256); clients download the content from the REST API
+		if (received_message.contains("attachments") && received_message.at("attachments").is_array())
+		{
+			message_object["attachments"] = received_message.at("attachments").as_array();
+		}
+
+		// Edit and recall events act on an earlier message instead of adding one
+		std::string command = "send_broadcast_message";
+		if (inner_message.contains("command") && inner_message.at("command").is_string()
+			&& (inner_message.at("command").as_string() == "edit_message" || inner_message.at("command").as_string() == "recall"))
+		{
+			command = std::string(inner_message.at("command").as_string());
+
+			if (inner_message.contains("timestamp") && inner_message.at("timestamp").is_string())
+			{
+				message_object[command == "recall" ? "recalled_at" : "edited_at"] = inner_message.at("timestamp").as_string();
+			}
+		}
+
 		boost::json::object broadcast_message =
 		{
-			{ "command", "send_broadcast_message" },
+			{ "command", command },
 
 			{ "message", message_object }
 		};
 
+		if (!recipient_id.empty())
+		{
+			auto serialized = boost::json::serialize(broadcast_message);
+			for (const auto& target : { recipient_id, std::string(received_message.at("id").as_string()) })
+			{
+				auto send_result = send_message(serialized, target, "");
+				if (!send_result)
+				{
+					// The peer may be offline; the message is still stored and shows up in the thread history
+					Logger::handle().write(LogTypes::Information,
+						std::format("Direct message not delivered to {}: {}", target, send_result.error()));
+				}
+			}
+			continue;
+		}
+
 		auto send_result = send_message(boost::json::serialize(broadcast_message), "", "");
 		if (!send_result)
 		{
@@ -3,6 +3,9 @@
 // @description REST API for the RealTimeMessageChat system.
 // @BasePath /
 // @schemes http https
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 package main
 
 import (
@@ -23,7 +26,11 @@ import (
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/blobstore"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/encryption"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -82,6 +89,15 @@ func main() {
 		}
 	}()
 
+	// SIGHUP 은 재시작 없이 모더레이션 규칙을 다시 읽는다.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			app.reloadModeration(*configPath)
+		}
+	}()
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -110,10 +126,52 @@ type App struct {
 	db             *services.DatabaseService
 	userService    *service.UserService
 	messageService *service.MessageService
+	authService    *service.AuthService
+	apiKeyService  *service.APIKeyService
+	auditService   *service.AuditService
+	erasureService *service.ErasureService
+	exportService  *service.ExportService
+	roomService    *service.RoomService
+	conversations  *service.ConversationService
+	receipts       *service.ReceiptService
+	reactions      *service.ReactionService
+	attachments    *service.AttachmentService
+	edits          *service.EditService
+	threads        *service.ThreadService
+	presence       *service.PresenceService
+	moderation     *service.ModerationService
+	floodCounter   moderation.Counter
+	webhooks       *service.WebhookService
+	keys           *middleware.KeySet
+	// shutdownTracing 은 종료 시 남은 span 을 내보낸다. tracing 이 꺼져 있으면 nil 이다.
+	shutdownTracing func(context.Context) error
 }
 
 // cleanup closes all services
 func (a *App) cleanup() {
+	// 남은 API 키 사용량은 DB 를 닫기 전에 기록해야 한다.
+	if a.apiKeyService != nil {
+		a.apiKeyService.Close()
+	}
+	if a.erasureService != nil {
+		a.erasureService.Close()
+	}
+	if a.attachments != nil {
+		a.attachments.Close()
+	}
+	if a.exportService != nil {
+		a.exportService.Close()
+	}
+	if a.webhooks != nil {
+		a.webhooks.Close()
+	}
+	// 큐에 남은 감사 기록도 DB 를 닫기 전에 쓴다.
+	if a.auditService != nil {
+		a.auditService.Close()
+	}
+	if a.presence != nil {
+		a.presence.Close()
+	}
 	if a.rabbitMQ != nil {
 		a.rabbitMQ.Close()
 	}
@@ -123,12 +181,80 @@ func (a *App) cleanup() {
 	if a.db != nil {
 		a.db.Close()
 	}
+	// 다른 서비스를 닫으며 끝난 span 까지 내보내도록 마지막에 닫는다.
+	if a.shutdownTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.shutdownTracing(ctx); err != nil {
+			logger.Warnf("Failed to flush traces: %v", err)
+		}
+	}
+}
+
+// revocationChecker returns the token revocation lookup, or nil when token issuance is disabled.
+// *AuthService 가 nil 인 채로 인터페이스에 담기면 nil 비교가 깨지므로 명시적으로 nil 을 돌려준다.
+func (a *App) revocationChecker() middleware.RevocationChecker {
+	if a.authService == nil {
+		return nil
+	}
+	return a.authService
+}
+
+// apiKeyAuthenticator returns the API key lookup, or nil when API keys are disabled
+func (a *App) apiKeyAuthenticator() middleware.APIKeyAuthenticator {
+	if a.apiKeyService == nil {
+		return nil
+	}
+	return a.apiKeyService
+}
+
+// audit returns the audit middleware for an action, or a pass-through when there is no database
+func (a *App) audit(action, targetType, targetParam string) gin.HandlerFunc {
+	if a.auditService == nil {
+		return allowAll
+	}
+	return middleware.Audit(a.auditService, action, targetType, targetParam)
+}
+
+// reloadModeration rebuilds the moderation filters from the configuration file.
+// 설정을 읽지 못하거나 규칙이 잘못되면 기존 규칙을 그대로 둔다.
+func (a *App) reloadModeration(path string) {
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		logger.Errorf("Moderation reload failed, keeping the current rules: %v", err)
+		return
+	}
+
+	pipeline, err := newModerationPipeline(cfg, a.floodCounter)
+	if err != nil {
+		logger.Errorf("Moderation reload failed, keeping the current rules: %v", err)
+		return
+	}
+
+	a.moderation.SetPipeline(pipeline)
+	logger.Infof("Moderation reloaded: filters %v", pipeline.Filters())
 }
 
 // initializeApp initializes all services and dependencies
 func initializeApp(cfg *config.Config) *App {
 	app := &App{}
 
+	// DB 드라이버와 Redis 훅이 전역 tracer provider 를 쓰므로 다른 서비스보다 먼저 설정한다.
+	if cfg.Tracing.Enabled {
+		shutdown, err := tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:  cfg.Tracing.ServiceName,
+			Exporter:     cfg.Tracing.Exporter,
+			OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+			SampleRatio:  cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			logger.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		app.shutdownTracing = shutdown
+		logger.Infof("Tracing: %s exporter, sample ratio %.2f, service %q",
+			cfg.Tracing.Exporter, cfg.Tracing.SampleRatio, cfg.Tracing.ServiceName)
+	}
+
 	// Initialize RabbitMQ
 	rabbitMQ, err := services.NewRabbitMQService(&cfg.RabbitMQ)
 	if err != nil {
@@ -171,23 +297,269 @@ func initializeApp(cfg *config.Config) *App {
 	var userRepo repository.UserRepository
 	var messageRepo repository.MessageRepository
 
+	var blobs blobstore.Store
+
 	if dbService != nil {
 		userRepo = repository.NewUserRepository(dbService.GetDB())
 		messageRepo = repository.NewMessageRepository(dbService.GetDB())
+		blobs = newBlobStore(cfg)
+		app.auditService = service.NewAuditService(repository.NewAuditRepository(dbService.GetDB()))
+		app.auditService.Start()
+
+		// 이벤트는 DB 에 먼저 쌓고 워커가 보내므로, 웹훅은 DB 가 있을 때만 켠다.
+		w := cfg.Webhooks
+		app.webhooks = service.NewWebhookService(repository.NewWebhookRepository(dbService.GetDB()), service.WebhookOptions{
+			Timeout:     time.Duration(w.TimeoutSeconds) * time.Second,
+			MaxAttempts: w.MaxAttempts,
+			BackoffBase: time.Duration(w.BackoffBaseSeconds) * time.Second,
+			BackoffMax:  time.Duration(w.BackoffMaxSeconds) * time.Second,
+			BatchSize:   w.BatchSize,
+			Lease:       time.Duration(w.LeaseSeconds) * time.Second,
+			Retention:   time.Duration(w.RetentionDays) * 24 * time.Hour,
+		})
+		app.webhooks.Start(time.Duration(w.PollIntervalSeconds) * time.Second)
+		logger.Infof("Webhooks: %d attempts, backoff %ds to %ds, timeout %ds",
+			w.MaxAttempts, w.BackoffBaseSeconds, w.BackoffMaxSeconds, w.TimeoutSeconds)
 	}
 
 	// Initialize services
 	if userRepo != nil {
 		app.userService = service.NewUserService(userRepo, redisService)
+		app.userService.SetEvents(app.webhooks)
+
+		// 하트비트 기반 접속 상태는 Redis 가 있어야 한다. 없으면 users.status 로 동작한다.
+		if redisService != nil {
+			app.presence = service.NewPresenceService(
+				redisService, userRepo,
+				time.Duration(cfg.Presence.TTLSeconds)*time.Second, cfg.Presence.Channel, cfg.Presence.PersistStatus,
+			)
+			app.presence.StartSweeper(time.Duration(cfg.Presence.SweepIntervalSeconds) * time.Second)
+			app.userService.SetPresence(app.presence)
+
+			logger.Infof("Presence: heartbeat TTL %ds, sweep every %ds, events on %q, persist status %v",
+				cfg.Presence.TTLSeconds, cfg.Presence.SweepIntervalSeconds, cfg.Presence.Channel, cfg.Presence.PersistStatus)
+		} else {
+			logger.Warn("Presence: Redis unavailable, online users are read from users.status")
+		}
+
+		app.erasureService = service.NewErasureService(
+			repository.NewErasureRepository(dbService.GetDB()), app.userService, redisService,
+			cfg.Erasure.BatchSize, time.Duration(cfg.Erasure.LeaseSeconds)*time.Second,
+		)
+
+		app.exportService = service.NewExportService(
+			repository.NewExportRepository(dbService.GetDB()),
+			service.ExportSources{
+				Users:       userRepo,
+				Messages:    messageRepo,
+				APIKeys:     repository.NewAPIKeyRepository(dbService.GetDB()),
+				Audit:       repository.NewAuditRepository(dbService.GetDB()),
+				Rooms:       repository.NewRoomRepository(dbService.GetDB()),
+				Receipts:    repository.NewReceiptRepository(dbService.GetDB()),
+				Reactions:   repository.NewReactionRepository(dbService.GetDB()),
+				Attachments: repository.NewAttachmentRepository(dbService.GetDB()),
+				Blobs:       blobs,
+				Moderation:  repository.NewModerationRepository(dbService.GetDB()),
+			},
+			cfg.Export.Directory, time.Duration(cfg.Export.TTLHours)*time.Hour,
+			time.Duration(cfg.Export.LeaseSeconds)*time.Second,
+		)
+		if cfg.Export.DatabaseEncryptionKey != "" {
+			cipher, err := encryption.NewMessageCipher(cfg.Export.DatabaseEncryptionKey, cfg.Export.DatabaseEncryptionIV)
+			if err != nil {
+				logger.Fatalf("Invalid export encryption key: %v", err)
+			}
+			app.exportService.SetCipher(cipher)
+		} else {
+			logger.Info("Export: no message encryption key configured, encrypted messages are exported as ciphertext")
+		}
+		app.exportService.Start(time.Duration(cfg.Export.PollIntervalSeconds) * time.Second)
 	}
 
 	if messageRepo != nil {
 		app.messageService = service.NewMessageService(messageRepo, redisService)
+		app.messageService.SetEvents(app.webhooks)
+		roomRepo := repository.NewRoomRepository(dbService.GetDB())
+		app.roomService = service.NewRoomService(roomRepo, messageRepo)
+		app.threads = service.NewThreadService(messageRepo, roomRepo)
+		app.receipts = service.NewReceiptService(
+			repository.NewReceiptRepository(dbService.GetDB()), messageRepo, roomRepo, redisService, cfg.Receipts.Channel,
+		)
+		app.reactions = service.NewReactionService(
+			repository.NewReactionRepository(dbService.GetDB()), messageRepo, roomRepo, redisService, cfg.Reactions.Channel,
+		)
+		app.attachments = service.NewAttachmentService(
+			repository.NewAttachmentRepository(dbService.GetDB()), messageRepo, roomRepo, blobs,
+			cfg.Attachments.MaxSizeBytes, cfg.Attachments.AllowedTypes,
+		)
+		app.attachments.StartSweeper(
+			time.Duration(cfg.Attachments.SweepIntervalSeconds)*time.Second,
+			time.Duration(cfg.Attachments.UnclaimedTTLHours)*time.Hour,
+		)
+		if app.erasureService != nil {
+			app.erasureService.SetBlobEraser(app.attachments)
+		}
+		if app.userService != nil {
+			app.conversations = service.NewConversationService(messageRepo, app.userService)
+		}
+
+		app.edits = service.NewEditService(messageRepo, roomRepo, rabbitMQ, time.Duration(cfg.Messages.EditWindowSeconds)*time.Second)
+		if cfg.Export.DatabaseEncryptionKey != "" {
+			cipher, err := encryption.NewMessageCipher(cfg.Export.DatabaseEncryptionKey, cfg.Export.DatabaseEncryptionIV)
+			if err != nil {
+				logger.Fatalf("Invalid message encryption key: %v", err)
+			}
+			app.edits.SetCipher(cipher)
+		}
+	}
+
+	// 첨부 blob 삭제를 연결한 뒤에 워커를 시작한다.
+	if app.erasureService != nil {
+		app.erasureService.Start(time.Duration(cfg.Erasure.PollIntervalSeconds) * time.Second)
+	}
+
+	// 필터는 DB 없이도 동작한다. 검토 대기열만 DB 가 있어야 남고, 없으면 플래그는 로그로만 남는다.
+	app.floodCounter = moderation.NewMemoryCounter()
+	if redisService != nil {
+		app.floodCounter = moderation.NewRedisCounter(redisService.GetClient())
+	}
+	pipeline, err := newModerationPipeline(cfg, app.floodCounter)
+	if err != nil {
+		logger.Fatalf("Invalid moderation rules: %v", err)
+	}
+	var flags repository.ModerationRepository
+	if dbService != nil {
+		flags = repository.NewModerationRepository(dbService.GetDB())
+	}
+	app.moderation = service.NewModerationService(pipeline, flags)
+	if app.edits != nil {
+		app.edits.SetModeration(app.moderation)
+		app.moderation.SetRecaller(app.edits)
+	}
+	if cfg.Moderation.Enabled {
+		logger.Infof("Moderation: filters %v", pipeline.Filters())
+	} else {
+		logger.Info("Moderation disabled: messages are published without content checks")
+	}
+
+	// refresh token 과 폐기 목록이 Redis 에 있으므로, Redis 없이 로그인을 열면 로그아웃이 동작하지 않는다.
+	if cfg.Auth.Enabled {
+		app.keys = newKeySet(cfg)
+
+		switch {
+		case !app.keys.CanSign():
+			logger.Info("Auth endpoints disabled: no signing key configured, verifying tokens only")
+		case userRepo != nil && redisService != nil:
+			app.authService = service.NewAuthService(
+				userRepo, redisService, app.keys,
+				cfg.Auth.JWTExpirationHours, cfg.Auth.RefreshExpirationHours,
+			)
+			app.userService.SetTokenRevoker(app.authService)
+		default:
+			logger.Warn("Auth endpoints disabled: database and Redis are both required for token issuance")
+		}
+
+		if userRepo != nil {
+			app.apiKeyService = service.NewAPIKeyService(repository.NewAPIKeyRepository(dbService.GetDB()), userRepo)
+			app.apiKeyService.StartUsageFlusher(service.APIKeyUsageFlushInterval)
+		}
+	} else {
+		logger.Warn("Auth disabled: role and ownership checks are not enforced")
 	}
 
 	return app
 }
 
+// newKeySet loads the JWT signing and verification keys
+func newKeySet(cfg *config.Config) *middleware.KeySet {
+	keyFiles := make([]middleware.KeyFile, 0, len(cfg.Auth.Keys))
+	for _, key := range cfg.Auth.Keys {
+		keyFiles = append(keyFiles, middleware.KeyFile{
+			KeyID:          key.KeyID,
+			Algorithm:      key.Algorithm,
+			PrivateKeyFile: key.PrivateKeyFile,
+			PublicKeyFile:  key.PublicKeyFile,
+		})
+	}
+
+	keys, err := middleware.NewKeySet(middleware.KeySetOptions{
+		Algorithm:    cfg.Auth.Algorithm,
+		HMACSecret:   cfg.Auth.JWTSecret,
+		Keys:         keyFiles,
+		SigningKeyID: cfg.Auth.SigningKeyID,
+		JWKSURL:      cfg.Auth.JWKSURL,
+		JWKSRefresh:  time.Duration(cfg.Auth.JWKSRefreshMinutes) * time.Minute,
+		Issuer:       cfg.Auth.Issuer,
+		Audience:     cfg.Auth.Audience,
+	})
+	if err != nil {
+		logger.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
+	logger.Infof("JWT keys loaded: algorithm %s, signing key %q, %d local key(s), JWKS %q",
+		cfg.Auth.Algorithm, cfg.Auth.SigningKeyID, len(keyFiles), cfg.Auth.JWKSURL)
+
+	return keys
+}
+
+// newBlobStore opens the attachment blob store of the configured backend
+func newBlobStore(cfg *config.Config) blobstore.Store {
+	if cfg.Attachments.Backend == "s3" {
+		store, err := blobstore.NewS3Store(blobstore.S3Config{
+			Endpoint:        cfg.Attachments.S3.Endpoint,
+			Region:          cfg.Attachments.S3.Region,
+			Bucket:          cfg.Attachments.S3.Bucket,
+			AccessKeyID:     cfg.Attachments.S3.AccessKeyID,
+			SecretAccessKey: cfg.Attachments.S3.SecretAccessKey,
+		})
+		if err != nil {
+			logger.Fatalf("Failed to configure S3 attachment store: %v", err)
+		}
+		logger.Infof("Attachments: S3 bucket %q at %s", cfg.Attachments.S3.Bucket, cfg.Attachments.S3.Endpoint)
+		return store
+	}
+
+	store, err := blobstore.NewLocalStore(cfg.Attachments.Directory)
+	if err != nil {
+		logger.Fatalf("Failed to open attachment directory: %v", err)
+	}
+	logger.Infof("Attachments: local directory %s", cfg.Attachments.Directory)
+	return store
+}
+
+// newModerationPipeline builds the configured content filters; with moderation disabled the pipeline is empty
+func newModerationPipeline(cfg *config.Config, counter moderation.Counter) (*moderation.Pipeline, error) {
+	m := cfg.Moderation
+	if !m.Enabled {
+		return moderation.NewPipeline(), nil
+	}
+
+	rules := make([]moderation.RuleConfig, 0, len(m.Rules))
+	for _, rule := range m.Rules {
+		rules = append(rules, moderation.RuleConfig{
+			Name:      rule.Name,
+			Pattern:   rule.Pattern,
+			Action:    moderation.Action(rule.Action),
+			Reason:    rule.Reason,
+			Normalize: rule.Normalize,
+		})
+	}
+
+	return moderation.Build(moderation.Config{
+		MaxLength:          m.MaxLength,
+		BlockedWords:       m.BlockedWords.Words,
+		BlockedWordsFile:   m.BlockedWords.File,
+		BlockedWordsAction: moderation.Action(m.BlockedWords.Action),
+		Rules:              rules,
+		AllowDomains:       m.Links.AllowDomains,
+		DenyDomains:        m.Links.DenyDomains,
+		LinksAction:        moderation.Action(m.Links.Action),
+		FloodMaxRepeats:    m.Flood.MaxRepeats,
+		FloodWindow:        time.Duration(m.Flood.WindowSeconds) * time.Second,
+		FloodAction:        moderation.Action(m.Flood.Action),
+	}, counter)
+}
+
 // setupRouter configures all routes and middleware
 func setupRouter(cfg *config.Config, app *App) *gin.Engine {
 	router := gin.New()
@@ -201,6 +573,9 @@ func setupRouter(cfg *config.Config, app *App) *gin.Engine {
 	// Global middleware
 	router.Use(middleware.Recovery())
 	router.Use(middleware.RequestID())
+	if cfg.Tracing.Enabled {
+		router.Use(middleware.Tracing())
+	}
 	router.Use(middleware.Logger())
 	router.Use(middleware.CORS())
 
@@ -209,10 +584,22 @@ func setupRouter(cfg *config.Config, app *App) *gin.Engine {
 		router.Use(middleware.PrometheusMetrics())
 	}
 
+	// 사용자 단위 rate limit 정책이 검증된 user_id 를 쓰려면 토큰 검증이 limiter 보다 먼저 돌아야 한다.
+	// 여기서는 식별만 하고 거부하지 않는다 — 인증 강제는 auth.route_access 에 따라 라우트 그룹이 맡는다.
+	// public 그룹은 자격 증명을 보지 않으므로 검증(및 Redis 폐기 조회)도 건너뛴다 — 권한 검사가 붙은 라우트만 직접 식별한다.
+	publicPrefixes := publicRoutePrefixes(cfg)
+	if cfg.Auth.Enabled {
+		router.Use(middleware.SkipPaths(publicPrefixes, middleware.OptionalAuth(app.keys, app.revocationChecker())))
+	}
+	if app.apiKeyService != nil {
+		router.Use(middleware.SkipPaths(publicPrefixes, middleware.OptionalAPIKey(app.apiKeyService)))
+	}
+	logRouteAccess(cfg)
+
 	// Rate limiting middleware.
 	// Gin 은 라우트 등록 시점에 핸들러 체인을 확정하므로, 모든 라우트에 적용되도록
 	// 첫 라우트 등록보다 앞에서 Use 해야 한다.
-	router.Use(middleware.RateLimitByIP(cfg.Server.RateLimitPerSecond, cfg.Server.RateLimitBurst))
+	router.Use(newRateLimitPolicies(cfg, app).Middleware())
 
 	systemHandler := handlers.NewSystemHandler(
 		app.rabbitMQ, app.redis, app.db,
@@ -229,6 +616,11 @@ func setupRouter(cfg *config.Config, app *App) *gin.Engine {
 	// Root endpoint
 	router.GET("/", systemHandler.Root)
 
+	// 다른 서비스가 비밀 없이 토큰을 검증할 수 있도록 공개키를 내보낸다. HS256 이면 빈 목록이다.
+	if app.keys != nil {
+		router.GET("/.well-known/jwks.json", handlers.JWKSHandler(app.keys))
+	}
+
 	// Swagger UI
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -238,58 +630,393 @@ func setupRouter(cfg *config.Config, app *App) *gin.Engine {
 	return router
 }
 
+// newLimiter builds a limiter for the configured rate limit backend
+func newLimiter(cfg *config.Config, app *App, requestsPerSecond float64, burst int) middleware.Limiter {
+	if cfg.RateLimit.Backend == "redis" {
+		if app.redis != nil {
+			return middleware.NewRedisRateLimiter(app.redis.GetClient(), requestsPerSecond, burst)
+		}
+		// Redis 초기화가 실패해도 기동은 계속하므로, 한도가 레플리카 수만큼 늘어난다는 점을 남긴다.
+		logger.Warnf("Rate limit backend is redis but Redis is unavailable, using per-process limiter (%.2f req/s, burst %d)",
+			requestsPerSecond, burst)
+	}
+
+	return middleware.NewRateLimiter(requestsPerSecond, burst)
+}
+
+// newRateLimitPolicies builds the policy table from configuration and logs the effective policies
+func newRateLimitPolicies(cfg *config.Config, app *App) *middleware.PolicyRateLimiter {
+	policies := make([]middleware.RateLimitPolicy, 0, len(cfg.RateLimit.Policies))
+	for _, policy := range cfg.RateLimit.Policies {
+		policies = append(policies, middleware.RateLimitPolicy{
+			Name:              policy.Name,
+			RoutePrefix:       policy.RoutePrefix,
+			Methods:           policy.Methods,
+			Identity:          policy.Identity,
+			RequestsPerSecond: policy.RequestsPerSecond,
+			Burst:             policy.Burst,
+		})
+	}
+
+	defaultPolicy := middleware.RateLimitPolicy{
+		Name:              "default",
+		RoutePrefix:       "/",
+		Identity:          middleware.IdentityIP,
+		RequestsPerSecond: cfg.Server.RateLimitPerSecond,
+		Burst:             cfg.Server.RateLimitBurst,
+	}
+
+	exempt := middleware.RateLimitExemptions{
+		Routes:    cfg.RateLimit.Exempt.Routes,
+		IPs:       cfg.RateLimit.Exempt.IPs,
+		UserIDs:   cfg.RateLimit.Exempt.UserIDs,
+		APIKeyIDs: cfg.RateLimit.Exempt.APIKeys,
+	}
+
+	limiter, err := middleware.NewPolicyRateLimiter(defaultPolicy, policies, exempt, func(r float64, b int) middleware.Limiter {
+		return newLimiter(cfg, app, r, b)
+	})
+	if err != nil {
+		logger.Fatalf("Failed to configure rate limiting: %v", err)
+	}
+
+	for _, policy := range limiter.Policies() {
+		logger.Infof("Rate limit policy %q: %s %v per %s, %.2f req/s, burst %d",
+			policy.Name, policy.RoutePrefix, policy.Methods, policy.Identity, policy.RequestsPerSecond, policy.Burst)
+	}
+
+	return limiter
+}
+
+// allowAll is the authorization stand-in used when authentication is disabled
+func allowAll(c *gin.Context) {
+	c.Next()
+}
+
+// routeGroupPrefixes maps each configurable route group to its path prefix
+var routeGroupPrefixes = map[string]string{
+	config.RouteGroupMessages: "/api/v1/messages",
+	config.RouteGroupUsers:    "/api/v1/users",
+	config.RouteGroupRooms:    "/api/v1/rooms",
+}
+
+// publicRoutePrefixes returns the path prefixes of route groups configured as public
+func publicRoutePrefixes(cfg *config.Config) []string {
+	var prefixes []string
+	for _, group := range config.RouteGroups {
+		if cfg.Auth.RouteAccessFor(group) == config.RouteAccessPublic {
+			prefixes = append(prefixes, routeGroupPrefixes[group])
+		}
+	}
+	return prefixes
+}
+
+// routeAccess returns the group middleware for the configured access mode.
+// optional 은 전역 OptionalAuth/OptionalAPIKey 가 이미 식별했으므로 그룹에서 할 일이 없다.
+func routeAccess(cfg *config.Config, group string) gin.HandlerFunc {
+	if cfg.Auth.RouteAccessFor(group) == config.RouteAccessRequired {
+		return middleware.RequireAuthenticated()
+	}
+	return allowAll
+}
+
+// logRouteAccess prints the effective authentication policy of every v1 route group
+func logRouteAccess(cfg *config.Config) {
+	if !cfg.Auth.Enabled {
+		logger.Warn("Route access: auth disabled, every route group is public")
+		return
+	}
+
+	for _, group := range config.RouteGroups {
+		logger.Infof("Route access %s: %s", routeGroupPrefixes[group], cfg.Auth.RouteAccessFor(group))
+	}
+	logger.Info("Route access /api/v1/auth: public (logout and revoke require a token)")
+	logger.Info("Route access /api/v1/api-keys: required (admin)")
+	logger.Info("Route access /api/v1/audit-logs: required (admin)")
+	logger.Info("Route access /api/v1/moderation: required (admin)")
+	logger.Info("Route access /api/v1/webhooks: required (admin)")
+}
+
 // setupV1Routes sets up API v1 routes
 func setupV1Routes(router *gin.Engine, cfg *config.Config, app *App) {
 	v1 := router.Group("/api/v1")
 
 	// Create handlers
 	messageHandler := handlers.NewMessageHandler(app.rabbitMQ)
+	if app.roomService != nil {
+		messageHandler.SetRoomAccess(app.roomService)
+	}
+	if app.conversations != nil {
+		messageHandler.SetDirectMessages(app.conversations)
+	}
+	if app.threads != nil {
+		messageHandler.SetReplies(app.threads)
+	}
+	if app.attachments != nil {
+		messageHandler.SetAttachments(app.attachments)
+	}
+	messageHandler.SetModeration(app.moderation)
+	if app.webhooks != nil {
+		messageHandler.SetEvents(app.webhooks)
+	}
+
+	// 권한 검사는 전역 OptionalAuth 가 채운 신원을 본다. 인증이 꺼져 있으면 호출자를 식별할 수 없으므로 통과시킨다.
+	// public 그룹은 전역 식별을 건너뛰므로, 권한 검사가 붙은 라우트는 그룹 모드와 관계없이 자격 증명을 직접 본다.
+	adminOnly, selfOrAdmin, identified := allowAll, allowAll, allowAll
+	readUsers, readMessages, writeMessages := allowAll, allowAll, allowAll
+	if cfg.Auth.Enabled {
+		guard := func(check gin.HandlerFunc) gin.HandlerFunc { return check }
+		if len(publicRoutePrefixes(cfg)) > 0 {
+			identify := middleware.Identify(app.keys, app.revocationChecker(), app.apiKeyAuthenticator())
+			guard = func(check gin.HandlerFunc) gin.HandlerFunc { return middleware.Identified(identify, check) }
+		}
+
+		adminOnly = guard(middleware.RequireRole(middleware.RoleAdmin))
+		selfOrAdmin = guard(middleware.RequireSelfOrRole("userID", middleware.RoleAdmin))
+		identified = guard(middleware.RequireAuthenticated())
+		readUsers = guard(middleware.RequireScope(middleware.ScopeUsersRead))
+		readMessages = guard(middleware.RequireScope(middleware.ScopeMessagesRead))
+		writeMessages = guard(middleware.RequireScope(middleware.ScopeMessagesWrite))
+	}
 
 	// Message routes (basic)
-	messages := v1.Group("/messages")
+	// 발행도 messages:write 가 필요하다 — users:read 만 받은 API 키로 메시지를 보낼 수 없다.
+	messages := v1.Group("/messages", routeAccess(cfg, config.RouteGroupMessages))
 	{
-		messages.POST("/send", messageHandler.SendMessage)
+		messages.POST("/send", writeMessages, messageHandler.SendMessage)
 	}
 
 	// Extended message routes (with database)
 	if app.messageService != nil {
 		extMessageHandler := handlers.NewMessageHandlerExtended(app.messageService)
+		if app.threads != nil {
+			extMessageHandler.SetReadAccess(app.threads)
+		}
+		if app.receipts != nil {
+			extMessageHandler.SetReceipts(app.receipts)
+		}
+		if app.reactions != nil {
+			extMessageHandler.SetReactions(app.reactions)
+		}
+		if app.attachments != nil {
+			extMessageHandler.SetAttachments(app.attachments)
+		}
+
+		// 전체 메시지를 훑는 조회와 상태 변경·삭제는 관리자 전용이다.
+		// 감사 기록은 권한 검사보다 앞에 둬서 거부된 시도도 남긴다.
+		messages.GET("/recent", adminOnly, extMessageHandler.GetRecentMessages)
+		messages.GET("/stats", adminOnly, extMessageHandler.GetMessageStats)
+		messages.GET("/lookup/:identifier", adminOnly, extMessageHandler.LookupMessage)
+		messages.GET("/:messageID", readMessages, extMessageHandler.GetMessage)
+		messages.PATCH("/:messageID/status", app.audit("message.status.update", "message", "messageID"), adminOnly,
+			extMessageHandler.UpdateMessageStatus)
+		messages.DELETE("/:messageID", app.audit("message.delete", "message", "messageID"), adminOnly,
+			extMessageHandler.DeleteMessage)
+		messages.GET("/status/:status", adminOnly, extMessageHandler.GetMessagesByStatus)
+	}
 
-		messages.GET("/recent", extMessageHandler.GetRecentMessages)
-		messages.GET("/stats", extMessageHandler.GetMessageStats)
-		messages.GET("/:messageID", extMessageHandler.GetMessage)
-		messages.PATCH("/:messageID/status", extMessageHandler.UpdateMessageStatus)
-		messages.DELETE("/:messageID", extMessageHandler.DeleteMessage)
-		messages.GET("/status/:status", extMessageHandler.GetMessagesByStatus)
+	// Edits (the author only, within messages.edit_window_seconds) and recalls (the author or an admin)
+	if app.edits != nil {
+		editHandler := handlers.NewEditHandler(app.edits)
+		messages.PUT("/:messageID/content", app.audit("message.edit", "message", "messageID"), writeMessages,
+			editHandler.UpdateMessageContent)
+		messages.POST("/:messageID/recall", app.audit("message.recall", "message", "messageID"), writeMessages,
+			editHandler.RecallMessage)
+		messages.GET("/:messageID/revisions", readMessages, editHandler.ListRevisions)
+	}
+
+	// Threads (replies are visible to whoever can read the message they reply to)
+	if app.threads != nil {
+		threadHandler := handlers.NewThreadHandler(app.threads)
+		if app.reactions != nil {
+			threadHandler.SetReactions(app.reactions)
+		}
+		if app.attachments != nil {
+			threadHandler.SetAttachments(app.attachments)
+		}
+		messages.GET("/:messageID/thread", readMessages, threadHandler.ListReplies)
+	}
+
+	// Receipts (the sender or an admin can list who received and read a message)
+	if app.receipts != nil {
+		receiptHandler := handlers.NewReceiptHandler(app.receipts)
+		messages.GET("/:messageID/receipts", readMessages, receiptHandler.ListReceipts)
+	}
+
+	// Attachments (metadata and content are visible to the uploader and whoever can read the message they were sent with)
+	if app.attachments != nil {
+		attachmentHandler := handlers.NewAttachmentHandler(app.attachments)
+		attachments := v1.Group("/attachments", routeAccess(cfg, config.RouteGroupMessages))
+		{
+			attachments.GET("/:attachmentID", readMessages, attachmentHandler.GetAttachment)
+			attachments.GET("/:attachmentID/content", readMessages, attachmentHandler.DownloadAttachment)
+		}
 	}
 
 	// User routes (with database)
 	if app.userService != nil {
 		userHandler := handlers.NewUserHandler(app.userService)
 
-		users := v1.Group("/users")
+		users := v1.Group("/users", routeAccess(cfg, config.RouteGroupUsers))
 		{
-			users.POST("", userHandler.CreateUser)
-			users.GET("", userHandler.ListUsers)
-			users.GET("/online", userHandler.GetOnlineUsers)
-			users.GET("/:userID", userHandler.GetUser)
-			users.PUT("/:userID/status", userHandler.UpdateStatus)
-			users.DELETE("/:userID", userHandler.DeleteUser)
+			users.POST("", app.audit("user.create", "user", ""), userHandler.CreateUser)
+			// 이메일은 관리자와 본인에게만 보인다 (핸들러에서 가린다).
+			users.GET("", readUsers, userHandler.ListUsers)
+			users.GET("/online", readUsers, userHandler.GetOnlineUsers)
+			users.GET("/:userID", readUsers, userHandler.GetUser)
+			users.PATCH("/:userID", app.audit("user.profile.update", "user", "userID"), selfOrAdmin,
+				userHandler.UpdateProfile)
+			users.PUT("/:userID/status", app.audit("user.status.update", "user", "userID"), selfOrAdmin,
+				userHandler.UpdateStatus)
+			if app.presence != nil {
+				users.POST("/:userID/heartbeat", selfOrAdmin, userHandler.Heartbeat)
+			}
+			users.PUT("/:userID/role", app.audit("user.role.update", "user", "userID"), adminOnly, userHandler.UpdateRole)
+			users.DELETE("/:userID", app.audit("user.delete", "user", "userID"), adminOnly, userHandler.DeleteUser)
+
+			// Erasure (admin only)
+			erasureHandler := handlers.NewErasureHandler(app.erasureService)
+			users.POST("/:userID/erasure", app.audit("user.erase", "user", "userID"), adminOnly,
+				erasureHandler.RequestErasure)
+			users.GET("/:userID/erasure", adminOnly, erasureHandler.GetErasure)
+
+			// Data export (the user or an admin)
+			exportHandler := handlers.NewExportHandler(app.exportService)
+			users.POST("/:userID/export", app.audit("user.export", "user", "userID"), selfOrAdmin,
+				exportHandler.RequestExport)
+			users.GET("/:userID/exports/:exportID", selfOrAdmin, exportHandler.GetExport)
+			users.GET("/:userID/exports/:exportID/download", selfOrAdmin, exportHandler.DownloadExport)
 
 			// User messages
 			if app.messageService != nil {
 				extMessageHandler := handlers.NewMessageHandlerExtended(app.messageService)
-				users.GET("/:userID/messages", extMessageHandler.GetUserMessages)
+				users.GET("/:userID/messages", selfOrAdmin, extMessageHandler.GetUserMessages)
+			}
+
+			// Receipts (recorded by the recipient named in the path, or an admin; not audited like heartbeats)
+			if app.receipts != nil {
+				receiptHandler := handlers.NewReceiptHandler(app.receipts)
+				users.POST("/:userID/messages/read", selfOrAdmin, receiptHandler.MarkReadUpTo)
+				users.POST("/:userID/messages/:messageID/delivered", selfOrAdmin, receiptHandler.MarkDelivered)
+				users.POST("/:userID/messages/:messageID/read", selfOrAdmin, receiptHandler.MarkRead)
+			}
+
+			// Reactions (by the user named in the path, or an admin; the service checks the user can read the message)
+			if app.reactions != nil {
+				reactionHandler := handlers.NewReactionHandler(app.reactions)
+				users.PUT("/:userID/messages/:messageID/reactions/:emoji", selfOrAdmin, writeMessages, reactionHandler.AddReaction)
+				users.DELETE("/:userID/messages/:messageID/reactions/:emoji", selfOrAdmin, writeMessages, reactionHandler.RemoveReaction)
+			}
+
+			// Attachments (uploaded by the user named in the path, or an admin; sent with attachment_ids)
+			if app.attachments != nil {
+				attachmentHandler := handlers.NewAttachmentHandler(app.attachments)
+				users.POST("/:userID/attachments", selfOrAdmin, writeMessages, attachmentHandler.Upload)
+			}
+
+			// Direct messages (only the participant named in the path, or an admin)
+			if app.conversations != nil {
+				conversationHandler := handlers.NewConversationHandler(app.conversations)
+				if app.reactions != nil {
+					conversationHandler.SetReactions(app.reactions)
+				}
+				if app.attachments != nil {
+					conversationHandler.SetAttachments(app.attachments)
+				}
+				users.GET("/:userID/conversations", selfOrAdmin, readMessages, conversationHandler.ListConversations)
+				users.GET("/:userID/conversations/:peerID/messages", selfOrAdmin, readMessages, conversationHandler.GetThread)
 			}
 		}
 	}
 
-	// Protected routes (with auth)
-	if cfg.Auth.Enabled {
-		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg.Auth.JWTSecret))
+	// Room routes (with database). 방 안의 권한(owner/moderator/member)은 서비스가 확인한다.
+	if app.roomService != nil {
+		roomHandler := handlers.NewRoomHandler(app.roomService)
+		if app.reactions != nil {
+			roomHandler.SetReactions(app.reactions)
+		}
+		if app.attachments != nil {
+			roomHandler.SetAttachments(app.attachments)
+		}
+
+		roomHandler.SetAuthEnabled(cfg.Auth.Enabled)
+
+		// 방을 바꾸는 요청은 그룹 모드와 관계없이 식별된 호출자만 한다. 조회는 익명이면 공개 방만 보인다.
+		rooms := v1.Group("/rooms", routeAccess(cfg, config.RouteGroupRooms))
+		{
+			rooms.POST("", app.audit("room.create", "room", ""), identified, roomHandler.CreateRoom)
+			rooms.GET("", roomHandler.ListRooms)
+			rooms.GET("/:roomID", roomHandler.GetRoom)
+			rooms.PATCH("/:roomID", app.audit("room.update", "room", "roomID"), identified, roomHandler.UpdateRoom)
+			rooms.DELETE("/:roomID", app.audit("room.delete", "room", "roomID"), identified, roomHandler.DeleteRoom)
+			rooms.POST("/:roomID/join", app.audit("room.join", "room", "roomID"), identified, roomHandler.JoinRoom)
+			rooms.POST("/:roomID/leave", app.audit("room.leave", "room", "roomID"), identified, roomHandler.LeaveRoom)
+			rooms.GET("/:roomID/members", roomHandler.ListMembers)
+			rooms.PUT("/:roomID/members/:userID", app.audit("room.member.update", "room", "roomID"), identified,
+				roomHandler.SetMember)
+			rooms.DELETE("/:roomID/members/:userID", app.audit("room.member.remove", "room", "roomID"), identified,
+				roomHandler.RemoveMember)
+			rooms.GET("/:roomID/messages", readMessages, roomHandler.ListMessages)
+		}
+	}
+
+	// Auth routes
+	if app.authService != nil {
+		authHandler := handlers.NewAuthHandler(app.authService)
+		requireAuth := middleware.AuthMiddleware(app.keys, app.revocationChecker())
+
+		auth := v1.Group("/auth")
+		{
+			auth.POST("/token", authHandler.Token)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/logout", requireAuth, authHandler.Logout)
+			auth.POST("/revoke", app.audit("auth.revoke", "user", ""), requireAuth, authHandler.Revoke)
+		}
+	}
+
+	// API key management (admin only)
+	if app.apiKeyService != nil {
+		apiKeyHandler := handlers.NewAPIKeyHandler(app.apiKeyService)
+
+		apiKeys := v1.Group("/api-keys")
+		{
+			apiKeys.POST("", app.audit("api_key.issue", "api_key", ""), adminOnly, apiKeyHandler.IssueKey)
+			apiKeys.GET("", adminOnly, apiKeyHandler.ListKeys)
+			apiKeys.DELETE("/:keyID", app.audit("api_key.revoke", "api_key", "keyID"), adminOnly, apiKeyHandler.RevokeKey)
+		}
+	}
+
+	// Audit log (admin only)
+	if app.auditService != nil {
+		auditHandler := handlers.NewAuditHandler(app.auditService)
+		v1.GET("/audit-logs", adminOnly, auditHandler.ListAuditLogs)
+	}
+
+	// Moderation review queue (admin only)
+	if app.db != nil {
+		moderationHandler := handlers.NewModerationHandler(app.moderation)
+
+		moderationGroup := v1.Group("/moderation")
+		{
+			moderationGroup.GET("/flags", adminOnly, moderationHandler.ListFlags)
+			moderationGroup.POST("/flags/:flagID/review", app.audit("moderation.review", "moderation_flag", "flagID"), adminOnly, moderationHandler.ReviewFlag)
+		}
+	}
+
+	// Webhook subscriptions (admin only)
+	if app.webhooks != nil {
+		webhookHandler := handlers.NewWebhookHandler(app.webhooks)
+
+		webhooks := v1.Group("/webhooks")
 		{
-			// Add protected routes here
+			webhooks.POST("", app.audit("webhook.create", "webhook_subscription", ""), adminOnly, webhookHandler.CreateWebhook)
+			webhooks.GET("", adminOnly, webhookHandler.ListWebhooks)
+			webhooks.GET("/:webhookID", adminOnly, webhookHandler.GetWebhook)
+			webhooks.PATCH("/:webhookID", app.audit("webhook.update", "webhook_subscription", "webhookID"), adminOnly, webhookHandler.UpdateWebhook)
+			webhooks.DELETE("/:webhookID", app.audit("webhook.delete", "webhook_subscription", "webhookID"), adminOnly, webhookHandler.DeleteWebhook)
+			webhooks.GET("/:webhookID/deliveries", adminOnly, webhookHandler.ListDeliveries)
+			webhooks.POST("/:webhookID/deliveries/:deliveryID/redeliver", app.audit("webhook.redeliver", "webhook_subscription", "webhookID"),
+				adminOnly, webhookHandler.RedeliverDelivery)
 		}
 	}
 }
@@ -5,7 +5,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/encryption"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
 )
 
 // Config holds the application configuration
@@ -17,6 +22,18 @@ type Config struct {
 	Logging  LoggingConfig  `json:"logging"`
 	Auth     AuthConfig     `json:"auth"`
 	Metrics  MetricsConfig  `json:"metrics"`
+	Tracing  TracingConfig  `json:"tracing"`
+	// RateLimit 의 한도 값은 server.rate_limit_per_second / rate_limit_burst 를 그대로 쓴다.
+	RateLimit   RateLimitConfig   `json:"rate_limit"`
+	Presence    PresenceConfig    `json:"presence"`
+	Receipts    ReceiptsConfig    `json:"receipts"`
+	Reactions   ReactionsConfig   `json:"reactions"`
+	Messages    MessagesConfig    `json:"messages"`
+	Erasure     ErasureConfig     `json:"erasure"`
+	Export      ExportConfig      `json:"export"`
+	Attachments AttachmentsConfig `json:"attachments"`
+	Moderation  ModerationConfig  `json:"moderation"`
+	Webhooks    WebhooksConfig    `json:"webhooks"`
 }
 
 // ServerConfig holds HTTP server configuration
@@ -58,6 +75,136 @@ type LoggingConfig struct {
 	OutputPath string `json:"output_path"`
 }
 
+// PresenceConfig holds heartbeat-based presence configuration.
+// ttl_seconds 동안 하트비트가 없으면 오프라인이다 — 클라이언트는 그 절반 이하 간격으로 보내야 한다.
+// 오프라인 전환은 sweep_interval_seconds 마다 감지되므로 실제 전환은 최대 그만큼 늦다.
+type PresenceConfig struct {
+	TTLSeconds           int    `json:"ttl_seconds"`
+	SweepIntervalSeconds int    `json:"sweep_interval_seconds"`
+	Channel              string `json:"channel"`
+	PersistStatus        bool   `json:"persist_status"`
+}
+
+// ReceiptsConfig holds message receipt configuration.
+// 전달/읽음 확인 이벤트는 Redis 가 있을 때만 channel 로 발행된다. 기록 자체는 Redis 없이도 된다.
+type ReceiptsConfig struct {
+	Channel string `json:"channel"`
+}
+
+// ReactionsConfig holds message reaction configuration.
+// 반응 변경 이벤트는 Redis 가 있을 때만 channel 로 발행된다.
+type ReactionsConfig struct {
+	Channel string `json:"channel"`
+}
+
+// MessagesConfig holds message editing configuration.
+// 작성자는 보낸 뒤 edit_window_seconds 동안만 내용을 고칠 수 있다.
+type MessagesConfig struct {
+	EditWindowSeconds int `json:"edit_window_seconds"`
+}
+
+// ErasureConfig holds the user erasure worker configuration.
+// 작업은 batch_size 건씩 커밋하며 진행 위치를 남기므로, 중단되면 lease_seconds 뒤에 다른 레플리카가 이어받는다.
+type ErasureConfig struct {
+	BatchSize           int `json:"batch_size"`
+	PollIntervalSeconds int `json:"poll_interval_seconds"`
+	LeaseSeconds        int `json:"lease_seconds"`
+}
+
+// ExportConfig holds the per-user data export worker configuration.
+// 압축 파일은 directory 에 쓰이고 ttl_hours 가 지나면 지워진다. 레플리카가 여럿이면 directory 는 공유 볼륨이어야
+// 다운로드 요청이 어느 레플리카로 가도 파일을 찾는다.
+// database_encryption_key / database_encryption_iv 는 MainServerConsumer 와 같은 값이다 — 비워 두면 암호화된 메시지는 암호문 그대로 내보낸다.
+type ExportConfig struct {
+	Directory             string `json:"directory"`
+	TTLHours              int    `json:"ttl_hours"`
+	PollIntervalSeconds   int    `json:"poll_interval_seconds"`
+	LeaseSeconds          int    `json:"lease_seconds"`
+	DatabaseEncryptionKey string `json:"database_encryption_key"`
+	DatabaseEncryptionIV  string `json:"database_encryption_iv"`
+}
+
+// WebhooksConfig holds the outbound webhook delivery worker configuration.
+// 실패한 전송은 backoff_base_seconds 부터 두 배씩, 최대 backoff_max_seconds 간격으로 재시도하고,
+// max_attempts 번 실패하면 dead 로 남겨 관리자가 다시 보내게 한다.
+type WebhooksConfig struct {
+	TimeoutSeconds      int `json:"timeout_seconds"`
+	MaxAttempts         int `json:"max_attempts"`
+	BackoffBaseSeconds  int `json:"backoff_base_seconds"`
+	BackoffMaxSeconds   int `json:"backoff_max_seconds"`
+	BatchSize           int `json:"batch_size"`
+	PollIntervalSeconds int `json:"poll_interval_seconds"`
+	LeaseSeconds        int `json:"lease_seconds"`
+	RetentionDays       int `json:"retention_days"`
+}
+
+// AttachmentsConfig holds file attachment configuration.
+// backend 는 "local"(directory 아래 파일) 또는 "s3"(S3 호환 버킷)이다. local 이고 레플리카가 여럿이면
+// directory 는 공유 볼륨이어야 한다. 형식은 내용으로 판별하며 allowed_types 에 없으면 받지 않는다.
+// 올린 뒤 unclaimed_ttl_hours 안에 보내지 않은 첨부는 sweep_interval_seconds 마다 도는 정리 워커가 지운다.
+type AttachmentsConfig struct {
+	Backend              string              `json:"backend"`
+	Directory            string              `json:"directory"`
+	MaxSizeBytes         int64               `json:"max_size_bytes"`
+	AllowedTypes         []string            `json:"allowed_types"`
+	UnclaimedTTLHours    int                 `json:"unclaimed_ttl_hours"`
+	SweepIntervalSeconds int                 `json:"sweep_interval_seconds"`
+	S3                   AttachmentsS3Config `json:"s3"`
+}
+
+// ModerationConfig holds the content filters run before a message is published.
+// 각 필터의 action 은 "flag"(발행하되 검토 대기열에 올림) 또는 "reject"(발행하지 않음)다.
+// SIGHUP 을 받으면 설정 파일을 다시 읽어 이 구간만 재시작 없이 바꾼다.
+type ModerationConfig struct {
+	Enabled      bool                   `json:"enabled"`
+	MaxLength    int                    `json:"max_length"`
+	BlockedWords ModerationWordsConfig  `json:"blocked_words"`
+	Rules        []ModerationRuleConfig `json:"rules"`
+	Links        ModerationLinksConfig  `json:"links"`
+	Flood        ModerationFloodConfig  `json:"flood"`
+}
+
+// ModerationWordsConfig holds the blocked word list; file has one word or phrase per line
+type ModerationWordsConfig struct {
+	Words  []string `json:"words"`
+	File   string   `json:"file"`
+	Action string   `json:"action"`
+}
+
+// ModerationRuleConfig is a regular expression rule. With normalize the pattern sees normalized content.
+type ModerationRuleConfig struct {
+	Name      string `json:"name"`
+	Pattern   string `json:"pattern"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason"`
+	Normalize bool   `json:"normalize"`
+}
+
+// ModerationLinksConfig holds link domain lists. Denied domains are always rejected;
+// action applies to other domains when allow_domains is not empty.
+type ModerationLinksConfig struct {
+	AllowDomains []string `json:"allow_domains"`
+	DenyDomains  []string `json:"deny_domains"`
+	Action       string   `json:"action"`
+}
+
+// ModerationFloodConfig holds repeated content detection; max_repeats 0 turns it off
+type ModerationFloodConfig struct {
+	MaxRepeats    int    `json:"max_repeats"`
+	WindowSeconds int    `json:"window_seconds"`
+	Action        string `json:"action"`
+}
+
+// AttachmentsS3Config holds the S3-compatible bucket of the "s3" attachment backend.
+// 접근 키는 설정 파일 대신 ATTACHMENTS_S3_ACCESS_KEY_ID / ATTACHMENTS_S3_SECRET_ACCESS_KEY 로 주는 편이 낫다.
+type AttachmentsS3Config struct {
+	Endpoint        string `json:"endpoint"`
+	Region          string `json:"region"`
+	Bucket          string `json:"bucket"`
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+}
+
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
 	Host         string `json:"host"`
@@ -86,12 +233,60 @@ type DatabaseConfig struct {
 	Enabled         bool   `json:"enabled"`
 }
 
-// AuthConfig holds authentication configuration
+// AuthConfig holds authentication configuration.
+// algorithm 이 HS256 이면 jwt_secret 하나로 서명·검증하고, RS256/ES256 이면 keys 와 jwks_url 의 공개키로 검증한다.
+// 비대칭 모드에서 signing_key_id 를 비워 두면 검증만 하는 인스턴스가 된다(토큰 발급 라우트 비활성).
+// RouteAccess 에서 빠진 라우트 그룹은 인증이 켜져 있으면 required, 꺼져 있으면 public 이다.
 type AuthConfig struct {
-	JWTSecret              string `json:"jwt_secret"`
-	JWTExpirationHours     int    `json:"jwt_expiration_hours"`
-	RefreshExpirationHours int    `json:"refresh_expiration_hours"`
-	Enabled                bool   `json:"enabled"`
+	JWTSecret              string            `json:"jwt_secret"`
+	JWTExpirationHours     int               `json:"jwt_expiration_hours"`
+	RefreshExpirationHours int               `json:"refresh_expiration_hours"`
+	Enabled                bool              `json:"enabled"`
+	Algorithm              string            `json:"algorithm"` // HS256, RS256, ES256
+	SigningKeyID           string            `json:"signing_key_id"`
+	Keys                   []JWTKeyConfig    `json:"keys"`
+	JWKSURL                string            `json:"jwks_url"`
+	JWKSRefreshMinutes     int               `json:"jwks_refresh_minutes"`
+	Issuer                 string            `json:"issuer"`
+	Audience               string            `json:"audience"`
+	RouteAccess            map[string]string `json:"route_access"`
+}
+
+// Route access modes
+const (
+	RouteAccessPublic   = "public"   // credentials are not looked at
+	RouteAccessOptional = "optional" // callers are identified when they send valid credentials
+	RouteAccessRequired = "required" // unauthenticated requests are rejected with 401
+)
+
+// Route groups whose access mode is configurable
+const (
+	RouteGroupMessages = "messages"
+	RouteGroupUsers    = "users"
+	RouteGroupRooms    = "rooms"
+)
+
+// RouteGroups lists the configurable route groups in display order
+var RouteGroups = []string{RouteGroupMessages, RouteGroupUsers, RouteGroupRooms}
+
+// RouteAccessFor returns the effective access mode of a route group
+func (a *AuthConfig) RouteAccessFor(group string) string {
+	if mode, ok := a.RouteAccess[group]; ok {
+		return mode
+	}
+	if a.Enabled {
+		return RouteAccessRequired
+	}
+	return RouteAccessPublic
+}
+
+// JWTKeyConfig describes one asymmetric signing key.
+// 회전할 때는 새 키를 추가해 signing_key_id 로 지정하고, 이전 키는 public_key_file 만 남겨 발급된 토큰이 만료될 때까지 둔다.
+type JWTKeyConfig struct {
+	KeyID          string `json:"kid"`
+	Algorithm      string `json:"algorithm"` // defaults to auth.algorithm
+	PrivateKeyFile string `json:"private_key_file"`
+	PublicKeyFile  string `json:"public_key_file"`
 }
 
 // MetricsConfig holds metrics configuration
@@ -100,6 +295,47 @@ type MetricsConfig struct {
 	Path    string `json:"path"`
 }
 
+// TracingConfig holds OpenTelemetry tracing configuration.
+// exporter 는 "stdout"(표준 출력) 또는 "otlp"(OTLP/HTTP 수집기)이다. otlp_endpoint 가 비어 있으면
+// OTEL_EXPORTER_OTLP_ENDPOINT 환경 변수, 그마저 없으면 localhost:4318 로 보낸다.
+// sample_ratio(기본 1)는 새로 시작하는 trace 에만 적용되고, traceparent 로 이어받은 trace 는 호출측 결정을 따른다.
+type TracingConfig struct {
+	Enabled      bool    `json:"enabled"`
+	ServiceName  string  `json:"service_name"`
+	Exporter     string  `json:"exporter"`
+	OTLPEndpoint string  `json:"otlp_endpoint"`
+	SampleRatio  float64 `json:"sample_ratio"`
+}
+
+// RateLimitConfig holds rate limiter backend and policy configuration
+type RateLimitConfig struct {
+	// Backend 가 "local" 이면 레플리카마다 따로 버킷을 가지므로 N 개를 띄우면 한도도 N 배가 된다.
+	// "redis" 는 버킷을 Redis 에 두어 모든 레플리카가 하나의 한도를 공유한다.
+	Backend string `json:"backend"` // local, redis
+	// Policies 중 경로 접두사가 가장 긴 것이 적용되고, 어디에도 맞지 않으면
+	// server.rate_limit_per_second / rate_limit_burst 의 IP 단위 기본 정책이 적용된다.
+	Policies []RateLimitPolicy   `json:"policies"`
+	Exempt   RateLimitExemptions `json:"exempt"`
+}
+
+// RateLimitPolicy sets the limit for one route group and identity type
+type RateLimitPolicy struct {
+	Name              string   `json:"name"`
+	RoutePrefix       string   `json:"route_prefix"`
+	Methods           []string `json:"methods,omitempty"`
+	Identity          string   `json:"identity"` // ip, user, api_key
+	RequestsPerSecond float64  `json:"requests_per_second"`
+	Burst             int      `json:"burst"`
+}
+
+// RateLimitExemptions lists routes and identities that are never rate limited
+type RateLimitExemptions struct {
+	Routes  []string `json:"routes"`
+	IPs     []string `json:"ips"` // IP or CIDR
+	UserIDs []string `json:"user_ids"`
+	APIKeys []string `json:"api_keys"` // API key IDs
+}
+
 // LoadConfig loads configuration from a JSON file
 func LoadConfig(path string) (*Config, error) {
 	file, err := os.Open(path)
@@ -172,6 +408,22 @@ func (c *Config) loadFromEnv() {
 		c.RabbitMQ.Username = rabbitUser
 	}
 
+	// Message encryption key (same as MainServerConsumer)
+	if key := getEnvString("DATABASE_ENCRYPTION_KEY"); key != "" {
+		c.Export.DatabaseEncryptionKey = key
+	}
+	if iv := getEnvString("DATABASE_ENCRYPTION_IV"); iv != "" {
+		c.Export.DatabaseEncryptionIV = iv
+	}
+
+	// Attachment bucket credentials
+	if accessKeyID := getEnvString("ATTACHMENTS_S3_ACCESS_KEY_ID"); accessKeyID != "" {
+		c.Attachments.S3.AccessKeyID = accessKeyID
+	}
+	if secretAccessKey := getEnvString("ATTACHMENTS_S3_SECRET_ACCESS_KEY"); secretAccessKey != "" {
+		c.Attachments.S3.SecretAccessKey = secretAccessKey
+	}
+
 	// Redis password
 	if redisPassword := getEnvString("REDIS_PASSWORD"); redisPassword != "" {
 		c.Redis.Password = redisPassword
@@ -224,9 +476,179 @@ func (c *Config) applyDefaults() {
 		c.Server.RateLimitBurst = 20
 	}
 
+	if c.RateLimit.Backend == "" {
+		c.RateLimit.Backend = "local"
+	}
+
+	// access token 은 짧게, refresh token 은 길게 — 폐기는 Redis 목록으로 즉시 반영된다.
+	if c.Auth.Algorithm == "" {
+		c.Auth.Algorithm = "HS256"
+	}
+
+	if c.Auth.JWKSRefreshMinutes <= 0 {
+		c.Auth.JWKSRefreshMinutes = 60
+	}
+
+	if c.Auth.JWTExpirationHours <= 0 {
+		c.Auth.JWTExpirationHours = 1
+	}
+
+	if c.Auth.RefreshExpirationHours <= 0 {
+		c.Auth.RefreshExpirationHours = 168
+	}
+
+	if c.Presence.TTLSeconds <= 0 {
+		c.Presence.TTLSeconds = 60
+	}
+
+	if c.Presence.SweepIntervalSeconds <= 0 {
+		c.Presence.SweepIntervalSeconds = 10
+	}
+
+	if c.Presence.Channel == "" {
+		c.Presence.Channel = "presence"
+	}
+
+	if c.Receipts.Channel == "" {
+		c.Receipts.Channel = "message_receipts"
+	}
+
+	if c.Reactions.Channel == "" {
+		c.Reactions.Channel = "message_reactions"
+	}
+
+	if c.Messages.EditWindowSeconds <= 0 {
+		c.Messages.EditWindowSeconds = 900
+	}
+
+	if c.Erasure.BatchSize <= 0 {
+		c.Erasure.BatchSize = 500
+	}
+
+	if c.Erasure.PollIntervalSeconds <= 0 {
+		c.Erasure.PollIntervalSeconds = 30
+	}
+
+	if c.Erasure.LeaseSeconds <= 0 {
+		c.Erasure.LeaseSeconds = 300
+	}
+
+	if c.Export.Directory == "" {
+		c.Export.Directory = "data/exports"
+	}
+
+	if c.Export.TTLHours <= 0 {
+		c.Export.TTLHours = 24
+	}
+
+	if c.Export.PollIntervalSeconds <= 0 {
+		c.Export.PollIntervalSeconds = 30
+	}
+
+	if c.Export.LeaseSeconds <= 0 {
+		c.Export.LeaseSeconds = 600
+	}
+
+	if c.Attachments.Backend == "" {
+		c.Attachments.Backend = "local"
+	}
+
+	if c.Attachments.Directory == "" {
+		c.Attachments.Directory = "data/attachments"
+	}
+
+	if c.Attachments.MaxSizeBytes <= 0 {
+		c.Attachments.MaxSizeBytes = 10 << 20
+	}
+
+	if len(c.Attachments.AllowedTypes) == 0 {
+		c.Attachments.AllowedTypes = []string{
+			"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain",
+		}
+	}
+
+	if c.Attachments.UnclaimedTTLHours <= 0 {
+		c.Attachments.UnclaimedTTLHours = 24
+	}
+
+	if c.Attachments.SweepIntervalSeconds <= 0 {
+		c.Attachments.SweepIntervalSeconds = 600
+	}
+
+	if c.Moderation.MaxLength <= 0 {
+		c.Moderation.MaxLength = 4000
+	}
+
+	if c.Moderation.BlockedWords.Action == "" {
+		c.Moderation.BlockedWords.Action = "reject"
+	}
+
+	for i := range c.Moderation.Rules {
+		if c.Moderation.Rules[i].Action == "" {
+			c.Moderation.Rules[i].Action = "flag"
+		}
+	}
+
+	if c.Moderation.Links.Action == "" {
+		c.Moderation.Links.Action = "flag"
+	}
+
+	if c.Moderation.Flood.WindowSeconds <= 0 {
+		c.Moderation.Flood.WindowSeconds = 60
+	}
+
+	if c.Moderation.Flood.Action == "" {
+		c.Moderation.Flood.Action = "reject"
+	}
+
+	if c.Webhooks.TimeoutSeconds <= 0 {
+		c.Webhooks.TimeoutSeconds = 10
+	}
+
+	if c.Webhooks.MaxAttempts <= 0 {
+		c.Webhooks.MaxAttempts = 8
+	}
+
+	if c.Webhooks.BackoffBaseSeconds <= 0 {
+		c.Webhooks.BackoffBaseSeconds = 10
+	}
+
+	if c.Webhooks.BackoffMaxSeconds <= 0 {
+		c.Webhooks.BackoffMaxSeconds = 3600
+	}
+
+	if c.Webhooks.BatchSize <= 0 {
+		c.Webhooks.BatchSize = 20
+	}
+
+	if c.Webhooks.PollIntervalSeconds <= 0 {
+		c.Webhooks.PollIntervalSeconds = 5
+	}
+
+	if c.Webhooks.LeaseSeconds <= 0 {
+		c.Webhooks.LeaseSeconds = 60
+	}
+
+	if c.Webhooks.RetentionDays <= 0 {
+		c.Webhooks.RetentionDays = 7
+	}
+
 	if c.Metrics.Enabled && c.Metrics.Path == "" {
 		c.Metrics.Path = "/metrics"
 	}
+
+	if c.Tracing.ServiceName == "" {
+		c.Tracing.ServiceName = "rtmc-api-server"
+	}
+
+	if c.Tracing.Exporter == "" {
+		c.Tracing.Exporter = "stdout"
+	}
+
+	// 0 이면 새 trace 를 하나도 남기지 않으므로 생략과 같이 모두 기록하는 것으로 본다.
+	if c.Tracing.SampleRatio == 0 {
+		c.Tracing.SampleRatio = 1
+	}
 }
 
 // Validate validates the configuration
@@ -280,14 +702,217 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid logging level: %s", c.Logging.Level)
 	}
 
+	validBackends := map[string]bool{"local": true, "redis": true}
+	if !validBackends[c.RateLimit.Backend] {
+		return fmt.Errorf("invalid rate_limit backend: %s", c.RateLimit.Backend)
+	}
+
+	if c.RateLimit.Backend == "redis" && !c.Redis.Enabled {
+		return fmt.Errorf("rate_limit backend \"redis\" requires redis to be enabled")
+	}
+
+	if c.Presence.SweepIntervalSeconds > c.Presence.TTLSeconds {
+		return fmt.Errorf("presence sweep_interval_seconds (%d) must not exceed ttl_seconds (%d)",
+			c.Presence.SweepIntervalSeconds, c.Presence.TTLSeconds)
+	}
+
+	if c.Erasure.BatchSize > 10000 {
+		return fmt.Errorf("erasure batch_size must not exceed 10000")
+	}
+
+	if (c.Export.DatabaseEncryptionKey == "") != (c.Export.DatabaseEncryptionIV == "") {
+		return fmt.Errorf("export database_encryption_key and database_encryption_iv must be set together")
+	}
+
+	if c.Export.DatabaseEncryptionKey != "" {
+		if _, err := encryption.NewMessageCipher(c.Export.DatabaseEncryptionKey, c.Export.DatabaseEncryptionIV); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+	}
+
+	validAttachmentBackends := map[string]bool{"local": true, "s3": true}
+	if !validAttachmentBackends[c.Attachments.Backend] {
+		return fmt.Errorf("invalid attachments backend: %s", c.Attachments.Backend)
+	}
+
+	if c.Attachments.Backend == "s3" && (c.Attachments.S3.Endpoint == "" || c.Attachments.S3.Bucket == "") {
+		return fmt.Errorf("attachments backend \"s3\" requires s3.endpoint and s3.bucket")
+	}
+
+	if err := c.Moderation.validate(); err != nil {
+		return err
+	}
+
+	// 전송 중에 임대가 끝나면 다른 레플리카가 같은 이벤트를 또 보낸다.
+	if c.Webhooks.LeaseSeconds <= c.Webhooks.TimeoutSeconds {
+		return fmt.Errorf("webhooks lease_seconds (%d) must exceed timeout_seconds (%d)",
+			c.Webhooks.LeaseSeconds, c.Webhooks.TimeoutSeconds)
+	}
+
+	if c.Webhooks.BackoffMaxSeconds < c.Webhooks.BackoffBaseSeconds {
+		return fmt.Errorf("webhooks backoff_max_seconds must not be less than backoff_base_seconds")
+	}
+
+	if c.Tracing.Enabled {
+		validExporters := map[string]bool{"stdout": true, "otlp": true}
+		if !validExporters[c.Tracing.Exporter] {
+			return fmt.Errorf("invalid tracing exporter: %s", c.Tracing.Exporter)
+		}
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample_ratio must be between 0 and 1")
+	}
+
+	if err := c.RateLimit.validatePolicies(); err != nil {
+		return err
+	}
+
+	if err := c.Auth.validateRouteAccess(); err != nil {
+		return err
+	}
+
 	// Validate JWT secret if authentication is enabled
 	if c.Auth.Enabled {
-		if c.Auth.JWTSecret == "" {
+		if err := c.Auth.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate checks the filter actions and compiles the rules
+func (m *ModerationConfig) validate() error {
+	for name, action := range map[string]string{
+		"blocked_words": m.BlockedWords.Action, "links": m.Links.Action, "flood": m.Flood.Action,
+	} {
+		if _, err := moderation.ParseAction(action); err != nil {
+			return fmt.Errorf("moderation %s: %w", name, err)
+		}
+	}
+
+	if m.Flood.MaxRepeats < 0 {
+		return fmt.Errorf("moderation flood max_repeats must not be negative")
+	}
+
+	names := make(map[string]bool, len(m.Rules))
+	for _, rule := range m.Rules {
+		if rule.Name == "" || names[rule.Name] {
+			return fmt.Errorf("moderation rules need unique names, got %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		action, err := moderation.ParseAction(rule.Action)
+		if err != nil {
+			return fmt.Errorf("moderation rule %q: %w", rule.Name, err)
+		}
+		if _, err := moderation.NewRegexRule(rule.Name, rule.Pattern, action, rule.Reason, rule.Normalize); err != nil {
+			return fmt.Errorf("moderation %w", err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the signing algorithm and key material
+func (a *AuthConfig) validate() error {
+	switch a.Algorithm {
+	case "HS256":
+		if a.JWTSecret == "" {
 			return fmt.Errorf("jwt_secret is required when authentication is enabled")
 		}
 		// Minimum 32 bytes (256 bits) for HMAC-SHA256
-		if len(c.Auth.JWTSecret) < 32 {
-			return fmt.Errorf("jwt_secret must be at least 32 characters for security (current: %d)", len(c.Auth.JWTSecret))
+		if len(a.JWTSecret) < 32 {
+			return fmt.Errorf("jwt_secret must be at least 32 characters for security (current: %d)", len(a.JWTSecret))
+		}
+		return nil
+	case "RS256", "ES256":
+	default:
+		return fmt.Errorf("invalid auth algorithm: %s", a.Algorithm)
+	}
+
+	if len(a.Keys) == 0 && a.JWKSURL == "" {
+		return fmt.Errorf("auth algorithm %s requires keys or jwks_url", a.Algorithm)
+	}
+
+	kids := make(map[string]bool, len(a.Keys))
+	signingFound := a.SigningKeyID == ""
+
+	for i, key := range a.Keys {
+		if key.KeyID == "" {
+			return fmt.Errorf("auth key #%d: kid is required", i)
+		}
+		if kids[key.KeyID] {
+			return fmt.Errorf("auth key %q: duplicate kid", key.KeyID)
+		}
+		kids[key.KeyID] = true
+
+		if key.Algorithm != "" && key.Algorithm != "RS256" && key.Algorithm != "ES256" {
+			return fmt.Errorf("auth key %q: invalid algorithm %s", key.KeyID, key.Algorithm)
+		}
+		if key.PrivateKeyFile == "" && key.PublicKeyFile == "" {
+			return fmt.Errorf("auth key %q: private_key_file or public_key_file is required", key.KeyID)
+		}
+
+		if key.KeyID == a.SigningKeyID {
+			if key.PrivateKeyFile == "" {
+				return fmt.Errorf("auth signing key %q requires private_key_file", key.KeyID)
+			}
+			signingFound = true
+		}
+	}
+
+	if !signingFound {
+		return fmt.Errorf("auth signing_key_id %q does not match any key", a.SigningKeyID)
+	}
+
+	return nil
+}
+
+// validateRouteAccess checks route group names and access modes
+func (a *AuthConfig) validateRouteAccess() error {
+	validModes := map[string]bool{RouteAccessPublic: true, RouteAccessOptional: true, RouteAccessRequired: true}
+
+	for group, mode := range a.RouteAccess {
+		if !slices.Contains(RouteGroups, group) {
+			return fmt.Errorf("auth route_access: unknown route group %q", group)
+		}
+		if !validModes[mode] {
+			return fmt.Errorf("auth route_access %q: invalid mode %q", group, mode)
+		}
+		// 인증이 꺼져 있으면 호출자를 식별할 수 없어 optional/required 가 조용히 무시된다 — 설정 실수로 보고 막는다.
+		if !a.Enabled && mode != RouteAccessPublic {
+			return fmt.Errorf("auth route_access %q: mode %q requires auth to be enabled", group, mode)
+		}
+	}
+
+	return nil
+}
+
+// validatePolicies checks rate limit policies for missing or conflicting fields
+func (r *RateLimitConfig) validatePolicies() error {
+	validIdentities := map[string]bool{"ip": true, "user": true, "api_key": true}
+	names := make(map[string]bool, len(r.Policies))
+
+	for i, policy := range r.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("rate_limit policy #%d: name is required", i)
+		}
+		// 이름은 버킷 키 접두사로 쓰이므로 겹치면 서로 다른 정책이 한 버킷을 나눠 쓴다.
+		if names[policy.Name] || policy.Name == "default" {
+			return fmt.Errorf("rate_limit policy %q: duplicate or reserved name", policy.Name)
+		}
+		names[policy.Name] = true
+
+		if !strings.HasPrefix(policy.RoutePrefix, "/") {
+			return fmt.Errorf("rate_limit policy %q: route_prefix must start with /", policy.Name)
+		}
+		if !validIdentities[policy.Identity] {
+			return fmt.Errorf("rate_limit policy %q: invalid identity %q", policy.Name, policy.Identity)
+		}
+		if policy.RequestsPerSecond <= 0 || policy.Burst <= 0 {
+			return fmt.Errorf("rate_limit policy %q: requests_per_second and burst must be greater than 0", policy.Name)
 		}
 	}
 
@@ -1,8 +1,10 @@
 package config
 
 import (
+	"encoding/base64"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -338,6 +340,128 @@ func TestValidate_JWTSecret(t *testing.T) {
 	})
 }
 
+func TestValidate_AsymmetricAuth(t *testing.T) {
+	newAuthConfig := func() *Config {
+		cfg := createValidConfig()
+		cfg.Auth.Enabled = true
+		cfg.Auth.JWTSecret = ""
+		cfg.Auth.Algorithm = "RS256"
+		cfg.Auth.SigningKeyID = "2024-06"
+		cfg.Auth.Keys = []JWTKeyConfig{
+			{KeyID: "2024-06", PrivateKeyFile: "keys/2024-06.pem"},
+			{KeyID: "2024-01", PublicKeyFile: "keys/2024-01.pub.pem"},
+		}
+		return cfg
+	}
+
+	t.Run("valid rotation set", func(t *testing.T) {
+		assert.NoError(t, newAuthConfig().Validate())
+	})
+
+	t.Run("verify only via JWKS", func(t *testing.T) {
+		cfg := newAuthConfig()
+		cfg.Auth.SigningKeyID = ""
+		cfg.Auth.Keys = nil
+		cfg.Auth.JWKSURL = "https://auth.example.com/.well-known/jwks.json"
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	t.Run("no key material", func(t *testing.T) {
+		cfg := newAuthConfig()
+		cfg.Auth.Keys = nil
+		cfg.Auth.SigningKeyID = ""
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires keys or jwks_url")
+	})
+
+	t.Run("signing key without private key", func(t *testing.T) {
+		cfg := newAuthConfig()
+		cfg.Auth.SigningKeyID = "2024-01"
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires private_key_file")
+	})
+
+	t.Run("unknown signing key", func(t *testing.T) {
+		cfg := newAuthConfig()
+		cfg.Auth.SigningKeyID = "missing"
+
+		assert.Error(t, cfg.Validate())
+	})
+
+	t.Run("duplicate kid", func(t *testing.T) {
+		cfg := newAuthConfig()
+		cfg.Auth.Keys[1].KeyID = "2024-06"
+
+		assert.Error(t, cfg.Validate())
+	})
+
+	t.Run("invalid algorithm", func(t *testing.T) {
+		cfg := newAuthConfig()
+		cfg.Auth.Algorithm = "none"
+
+		assert.Error(t, cfg.Validate())
+	})
+}
+
+func TestRouteAccess(t *testing.T) {
+	t.Run("required by default when auth is enabled", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Auth.Enabled = true
+
+		assert.Equal(t, RouteAccessRequired, cfg.Auth.RouteAccessFor(RouteGroupMessages))
+		assert.Equal(t, RouteAccessRequired, cfg.Auth.RouteAccessFor(RouteGroupUsers))
+	})
+
+	t.Run("public by default when auth is disabled", func(t *testing.T) {
+		cfg := createValidConfig()
+
+		assert.Equal(t, RouteAccessPublic, cfg.Auth.RouteAccessFor(RouteGroupMessages))
+	})
+
+	t.Run("configured mode wins", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Auth.Enabled = true
+		cfg.Auth.JWTSecret = "test-secret-key-that-is-at-least-32-chars"
+		cfg.Auth.RouteAccess = map[string]string{RouteGroupUsers: RouteAccessOptional}
+
+		assert.NoError(t, cfg.Validate())
+		assert.Equal(t, RouteAccessOptional, cfg.Auth.RouteAccessFor(RouteGroupUsers))
+		assert.Equal(t, RouteAccessRequired, cfg.Auth.RouteAccessFor(RouteGroupMessages))
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Auth.RouteAccess = map[string]string{"admin": RouteAccessPublic}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown route group")
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Auth.RouteAccess = map[string]string{RouteGroupMessages: "sometimes"}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid mode")
+	})
+
+	t.Run("required needs auth enabled", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Auth.RouteAccess = map[string]string{RouteGroupMessages: RouteAccessRequired}
+
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires auth to be enabled")
+	})
+}
+
 func TestValidate_RabbitMQ(t *testing.T) {
 	t.Run("missing host", func(t *testing.T) {
 		cfg := createValidConfig()
@@ -370,6 +494,387 @@ func TestValidate_RabbitMQ(t *testing.T) {
 	})
 }
 
+func TestValidate_RateLimitBackend(t *testing.T) {
+	t.Run("defaults to local", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, "local", cfg.RateLimit.Backend)
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.RateLimit.Backend = "memcached"
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid rate_limit backend")
+	})
+
+	t.Run("redis backend requires redis", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.RateLimit.Backend = "redis"
+		cfg.Redis.Enabled = false
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "requires redis to be enabled")
+	})
+
+	t.Run("redis backend with redis enabled", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.RateLimit.Backend = "redis"
+		cfg.Redis.Enabled = true
+
+		assert.NoError(t, cfg.Validate())
+	})
+}
+
+func TestValidate_Presence(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, 60, cfg.Presence.TTLSeconds)
+		assert.Equal(t, 10, cfg.Presence.SweepIntervalSeconds)
+		assert.Equal(t, "presence", cfg.Presence.Channel)
+		assert.Equal(t, "message_receipts", cfg.Receipts.Channel)
+		assert.Equal(t, "message_reactions", cfg.Reactions.Channel)
+		assert.Equal(t, 900, cfg.Messages.EditWindowSeconds)
+	})
+
+	t.Run("sweep slower than ttl", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Presence.TTLSeconds = 30
+		cfg.Presence.SweepIntervalSeconds = 60
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must not exceed ttl_seconds")
+	})
+}
+
+func TestValidate_Erasure(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, 500, cfg.Erasure.BatchSize)
+		assert.Equal(t, 30, cfg.Erasure.PollIntervalSeconds)
+		assert.Equal(t, 300, cfg.Erasure.LeaseSeconds)
+	})
+
+	t.Run("batch too large", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Erasure.BatchSize = 50000
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "batch_size")
+	})
+}
+
+func TestValidate_Export(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	iv := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("v", 16)))
+
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, "data/exports", cfg.Export.Directory)
+		assert.Equal(t, 24, cfg.Export.TTLHours)
+		assert.Equal(t, 30, cfg.Export.PollIntervalSeconds)
+		assert.Equal(t, 600, cfg.Export.LeaseSeconds)
+	})
+
+	t.Run("encryption key from environment", func(t *testing.T) {
+		t.Setenv("DATABASE_ENCRYPTION_KEY", key)
+		t.Setenv("DATABASE_ENCRYPTION_IV", iv)
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, key, cfg.Export.DatabaseEncryptionKey)
+		assert.Equal(t, iv, cfg.Export.DatabaseEncryptionIV)
+	})
+
+	t.Run("key without iv", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Export.DatabaseEncryptionKey = key
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be set together")
+	})
+
+	t.Run("short key", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Export.DatabaseEncryptionKey = base64.StdEncoding.EncodeToString([]byte("short"))
+		cfg.Export.DatabaseEncryptionIV = iv
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "must be 32 bytes")
+	})
+}
+
+func TestValidate_Attachments(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, "local", cfg.Attachments.Backend)
+		assert.Equal(t, "data/attachments", cfg.Attachments.Directory)
+		assert.Equal(t, int64(10<<20), cfg.Attachments.MaxSizeBytes)
+		assert.Contains(t, cfg.Attachments.AllowedTypes, "image/png")
+		assert.Equal(t, 24, cfg.Attachments.UnclaimedTTLHours)
+		assert.Equal(t, 600, cfg.Attachments.SweepIntervalSeconds)
+	})
+
+	t.Run("s3 credentials from environment", func(t *testing.T) {
+		t.Setenv("ATTACHMENTS_S3_ACCESS_KEY_ID", "key-id")
+		t.Setenv("ATTACHMENTS_S3_SECRET_ACCESS_KEY", "secret")
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, "key-id", cfg.Attachments.S3.AccessKeyID)
+		assert.Equal(t, "secret", cfg.Attachments.S3.SecretAccessKey)
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Attachments.Backend = "ftp"
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "attachments backend")
+	})
+
+	t.Run("s3 without bucket", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Attachments.Backend = "s3"
+		cfg.Attachments.S3.Endpoint = "http://minio:9000"
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "s3.bucket")
+	})
+}
+
+func TestValidate_Moderation(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4000, cfg.Moderation.MaxLength)
+		assert.Equal(t, "reject", cfg.Moderation.BlockedWords.Action)
+		assert.Equal(t, "flag", cfg.Moderation.Links.Action)
+		assert.Equal(t, 60, cfg.Moderation.Flood.WindowSeconds)
+	})
+
+	t.Run("valid rule", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Moderation.Rules = []ModerationRuleConfig{{Name: "card", Pattern: `\d{4}-\d{4}`, Action: "flag"}}
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	tests := map[string]struct {
+		modify func(*ModerationConfig)
+		want   string
+	}{
+		"unknown action": {
+			modify: func(m *ModerationConfig) { m.Links.Action = "block" },
+			want:   "moderation links",
+		},
+		"allow is not an action": {
+			modify: func(m *ModerationConfig) { m.Flood.Action = "allow" },
+			want:   "moderation flood",
+		},
+		"negative max_repeats": {
+			modify: func(m *ModerationConfig) { m.Flood.MaxRepeats = -1 },
+			want:   "max_repeats",
+		},
+		"unnamed rule": {
+			modify: func(m *ModerationConfig) {
+				m.Rules = []ModerationRuleConfig{{Pattern: "x", Action: "flag"}}
+			},
+			want: "unique names",
+		},
+		"duplicate rule": {
+			modify: func(m *ModerationConfig) {
+				m.Rules = []ModerationRuleConfig{{Name: "a", Pattern: "x", Action: "flag"}, {Name: "a", Pattern: "y", Action: "flag"}}
+			},
+			want: "unique names",
+		},
+		"invalid pattern": {
+			modify: func(m *ModerationConfig) {
+				m.Rules = []ModerationRuleConfig{{Name: "broken", Pattern: "(", Action: "flag"}}
+			},
+			want: "broken",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := createValidConfig()
+			tt.modify(&cfg.Moderation)
+
+			err := cfg.Validate()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestValidate_Webhooks(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.Equal(t, 10, cfg.Webhooks.TimeoutSeconds)
+		assert.Equal(t, 8, cfg.Webhooks.MaxAttempts)
+		assert.Equal(t, 60, cfg.Webhooks.LeaseSeconds)
+		assert.Equal(t, 7, cfg.Webhooks.RetentionDays)
+	})
+
+	t.Run("lease shorter than timeout", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Webhooks.TimeoutSeconds = 60
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "lease_seconds")
+	})
+
+	t.Run("backoff max below base", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Webhooks.BackoffMaxSeconds = 5
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "backoff_max_seconds")
+	})
+}
+
+func TestValidate_Tracing(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		configPath := createTempConfigFile(t, validConfigJSON)
+
+		cfg, err := LoadConfig(configPath)
+
+		require.NoError(t, err)
+		assert.False(t, cfg.Tracing.Enabled)
+		assert.Equal(t, "rtmc-api-server", cfg.Tracing.ServiceName)
+		assert.Equal(t, "stdout", cfg.Tracing.Exporter)
+		assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
+	})
+
+	t.Run("unknown exporter", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Tracing = TracingConfig{Enabled: true, Exporter: "jaeger", SampleRatio: 1}
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "tracing exporter")
+	})
+
+	t.Run("sample ratio out of range", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.Tracing = TracingConfig{Enabled: true, Exporter: "otlp", SampleRatio: 1.5}
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "sample_ratio")
+	})
+}
+
+func TestValidate_RateLimitPolicies(t *testing.T) {
+	validPolicy := func() RateLimitPolicy {
+		return RateLimitPolicy{
+			Name:              "message_send",
+			RoutePrefix:       "/api/v1/messages/send",
+			Identity:          "user",
+			RequestsPerSecond: 5,
+			Burst:             10,
+		}
+	}
+
+	t.Run("valid policy", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.RateLimit.Policies = []RateLimitPolicy{validPolicy()}
+
+		assert.NoError(t, cfg.Validate())
+	})
+
+	tests := []struct {
+		name     string
+		mutate   func(p *RateLimitPolicy)
+		expected string
+	}{
+		{"missing name", func(p *RateLimitPolicy) { p.Name = "" }, "name is required"},
+		{"reserved name", func(p *RateLimitPolicy) { p.Name = "default" }, "duplicate or reserved name"},
+		{"relative route", func(p *RateLimitPolicy) { p.RoutePrefix = "api/v1" }, "route_prefix must start with /"},
+		{"unknown identity", func(p *RateLimitPolicy) { p.Identity = "session" }, "invalid identity"},
+		{"zero burst", func(p *RateLimitPolicy) { p.Burst = 0 }, "must be greater than 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := validPolicy()
+			tt.mutate(&policy)
+
+			cfg := createValidConfig()
+			cfg.RateLimit.Policies = []RateLimitPolicy{policy}
+
+			err := cfg.Validate()
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+
+	t.Run("duplicate name", func(t *testing.T) {
+		cfg := createValidConfig()
+		cfg.RateLimit.Policies = []RateLimitPolicy{validPolicy(), validPolicy()}
+
+		err := cfg.Validate()
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate or reserved name")
+	})
+}
+
 func TestGetRabbitMQURL(t *testing.T) {
 	cfg := createValidConfig()
 	cfg.RabbitMQ.Username = "user"
@@ -465,10 +970,28 @@ func createValidConfig() *Config {
 		Auth: AuthConfig{
 			JWTSecret: "test-secret-key-for-testing-purposes",
 			Enabled:   false,
+			Algorithm: "HS256",
 		},
 		Metrics: MetricsConfig{
 			Enabled: false,
 			Path:    "/metrics",
 		},
+		RateLimit: RateLimitConfig{
+			Backend: "local",
+		},
+		Attachments: AttachmentsConfig{
+			Backend: "local",
+		},
+		Moderation: ModerationConfig{
+			BlockedWords: ModerationWordsConfig{Action: "reject"},
+			Links:        ModerationLinksConfig{Action: "flag"},
+			Flood:        ModerationFloodConfig{Action: "reject"},
+		},
+		Webhooks: WebhooksConfig{
+			TimeoutSeconds:     10,
+			BackoffBaseSeconds: 10,
+			BackoffMaxSeconds:  3600,
+			LeaseSeconds:       60,
+		},
 	}
 }
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// APIKeyHandler handles API key management requests
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// IssueAPIKeyRequest represents the request to issue an API key
+type IssueAPIKeyRequest struct {
+	Name        string     `json:"name" binding:"required,max=100"`
+	OwnerUserID string     `json:"owner_user_id" binding:"required"`
+	Scopes      []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// IssueKey handles POST /api-keys
+// @Summary Issue API key
+// @Description Issue an API key for a service account. The key is returned only in this response; send it in the X-API-Key header.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param key body IssueAPIKeyRequest true "API key to issue"
+// @Success 201 {object} response.Response{data=service.IssuedAPIKey}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) IssueKey(c *gin.Context) {
+	var req IssueAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	issued, err := h.apiKeyService.IssueKey(c.Request.Context(), service.IssueAPIKeyInput{
+		Name:        req.Name,
+		OwnerUserID: req.OwnerUserID,
+		Scopes:      req.Scopes,
+		ExpiresAt:   req.ExpiresAt,
+		CreatedBy:   c.GetString("user_id"),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// 평문 키가 감사 로그에 남지 않도록 메타데이터만 기록한다.
+	middleware.SetAuditTarget(c, issued.KeyID)
+	middleware.SetAuditChange(c, nil, issued.APIKey)
+
+	response.Created(c, "API key issued - store it now, it cannot be shown again", issued)
+}
+
+// ListKeys handles GET /api-keys
+// @Summary List API keys
+// @Description List API keys with usage counters. Secrets are never returned.
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param owner_user_id query string false "Only keys of this owner"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	keys, total, err := h.apiKeyService.ListKeys(c.Request.Context(), c.Query("owner_user_id"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, keys, total, params.Limit, params.Offset)
+}
+
+// RevokeKey handles DELETE /api-keys/:keyID
+// @Summary Revoke API key
+// @Description Revoke an API key. Requests using it are rejected immediately.
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Param keyID path string true "API key ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/api-keys/{keyID} [delete]
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	if err := h.apiKeyService.RevokeKey(c.Request.Context(), c.Param("keyID")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "API key revoked", nil)
+}
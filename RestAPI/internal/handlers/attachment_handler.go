@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// multipartOverhead is how much a multipart upload may exceed the file size limit: boundaries, part headers and other fields
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles file attachment requests
+type AttachmentHandler struct {
+	attachmentService *service.AttachmentService
+}
+
+// NewAttachmentHandler creates a new attachment handler
+func NewAttachmentHandler(attachmentService *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+	}
+}
+
+// Upload handles POST /users/:userID/attachments
+// @Summary Upload attachment
+// @Description Upload a file in the "file" field of a multipart/form-data body. The type is sniffed from the content and must be in attachments.allowed_types. Send the returned id in attachment_ids of one message.
+// @Tags messages
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "Uploader user ID"
+// @Param file formData file true "File to upload"
+// @Success 201 {object} response.Response{data=repository.Attachment}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	// 파일을 메모리나 gin 의 임시 파일에 모으지 않고 파트를 그대로 서비스에 흘려보낸다.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.attachmentService.MaxSize()+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		response.ValidationError(c, "Expected a multipart/form-data body")
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			response.ValidationError(c, "The file field is required")
+			return
+		}
+		if err != nil {
+			response.ValidationError(c, "Invalid multipart body: "+err.Error())
+			return
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.attachmentService.Upload(c.Request.Context(), c.Param("userID"), part.FileName(), part)
+		part.Close()
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		response.Created(c, "Attachment uploaded", attachment)
+		return
+	}
+}
+
+// GetAttachment handles GET /attachments/:attachmentID
+// @Summary Get attachment
+// @Description Get an attachment's metadata. Visible to the uploader, admins, and whoever can read the message it was sent with.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param attachmentID path string true "Attachment ID"
+// @Success 200 {object} response.Response{data=repository.Attachment}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/attachments/{attachmentID} [get]
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	attachment, err := h.attachmentService.GetAttachment(c.Request.Context(), c.GetString("user_id"), middleware.IsAdmin(c),
+		c.Param("attachmentID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, attachment)
+}
+
+// DownloadAttachment handles GET /attachments/:attachmentID/content
+// @Summary Download attachment
+// @Description Download an attachment's content, with the same visibility as its metadata.
+// @Tags messages
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param attachmentID path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/attachments/{attachmentID}/content [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachment, content, err := h.attachmentService.OpenAttachment(c.Request.Context(), c.GetString("user_id"), middleware.IsAdmin(c),
+		c.Param("attachmentID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	defer content.Close()
+
+	// 저장된 형식을 그대로 쓰고 브라우저가 다시 추측하지 못하게 한다. 인라인으로 열지 않고 내려받게 한다.
+	c.DataFromReader(http.StatusOK, attachment.SizeBytes, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"ETag":                   strconv.Quote(attachment.SHA256),
+		"Cache-Control":          "private, max-age=3600",
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// AuditHandler handles audit log queries
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs handles GET /audit-logs
+// @Summary List audit log
+// @Description List recorded mutating operations, newest first. Denied attempts are included.
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query string false "Only operations by this user (or API key owner)"
+// @Param target_type query string false "Target type (user, message, api_key)"
+// @Param target_id query string false "Target ID"
+// @Param action query string false "Action, e.g. user.role.update"
+// @Param since query string false "Start of the time range (RFC 3339, inclusive)"
+// @Param until query string false "End of the time range (RFC 3339, exclusive)"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	filter := repository.AuditFilter{
+		ActorID:    c.Query("actor_id"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		Action:     c.Query("action"),
+	}
+
+	var ok bool
+	if filter.Since, ok = parseTimeQuery(c, "since"); !ok {
+		return
+	}
+	if filter.Until, ok = parseTimeQuery(c, "until"); !ok {
+		return
+	}
+
+	entries, total, err := h.auditService.ListAuditLogs(c.Request.Context(), filter, params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, entries, total, params.Limit, params.Offset)
+}
+
+// parseTimeQuery parses an optional RFC 3339 query parameter, writing a validation error on failure
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		response.ValidationError(c, name+" must be an RFC 3339 timestamp")
+		return nil, false
+	}
+	return &parsed, true
+}
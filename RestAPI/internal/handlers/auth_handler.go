@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+	"github.com/sirupsen/logrus"
+)
+
+// AuthHandler handles token issuance and revocation requests
+type AuthHandler struct {
+	authService *service.AuthService
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// LoginRequest represents the request to issue a token pair
+type LoginRequest struct {
+	UserID   string `json:"user_id" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshRequest represents the request to rotate a refresh token
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest represents the request to end a session
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RevokeRequest represents the request to revoke every token of a user
+type RevokeRequest struct {
+	// UserID 를 생략하면 호출자 본인의 토큰을 폐기한다. 다른 사용자는 관리자만 지정할 수 있다.
+	UserID string `json:"user_id"`
+}
+
+// Token handles POST /auth/token
+// @Summary Issue tokens
+// @Description Exchange user credentials for an access token and a refresh token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body LoginRequest true "User credentials"
+// @Success 200 {object} response.Response{data=service.TokenPair}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/token [post]
+func (h *AuthHandler) Token(c *gin.Context) {
+	var req LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	pair, err := h.authService.Login(c.Request.Context(), req.UserID, req.Password)
+	if err != nil {
+		logger.WithFields(logrus.Fields{
+			"user_id":   req.UserID,
+			"client_ip": c.ClientIP(),
+		}).Warn("Login failed")
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, pair)
+}
+
+// Refresh handles POST /auth/refresh
+// @Summary Refresh tokens
+// @Description Rotate a refresh token. The presented refresh token is invalidated and a new pair is returned.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body RefreshRequest true "Refresh token"
+// @Success 200 {object} response.Response{data=service.TokenPair}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	pair, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, pair)
+}
+
+// Logout handles POST /auth/logout
+// @Summary Log out
+// @Description Revoke the presented access token and, when given, the refresh token.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token body LogoutRequest false "Refresh token to revoke"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	// 본문 없이 access token 만 폐기하는 호출도 허용한다.
+	var req LogoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		response.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), claims, req.RefreshToken); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Logged out successfully", nil)
+}
+
+// Revoke handles POST /auth/revoke
+// @Summary Revoke all tokens
+// @Description Revoke every access and refresh token issued to the caller so far. Admins may target another user.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param target body RevokeRequest false "User whose tokens are revoked"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/auth/revoke [post]
+func (h *AuthHandler) Revoke(c *gin.Context) {
+	var req RevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	claims, ok := middleware.GetClaims(c)
+	if !ok {
+		response.Unauthorized(c, "Authentication required")
+		return
+	}
+
+	target := claims.UserID
+	if req.UserID != "" && req.UserID != claims.UserID {
+		middleware.SetAuditTarget(c, req.UserID)
+		if !middleware.IsAdmin(c) {
+			response.Forbidden(c, "Only admins can revoke another user's tokens")
+			return
+		}
+		target = req.UserID
+	}
+	middleware.SetAuditTarget(c, target)
+
+	if err := h.authService.RevokeUser(c.Request.Context(), target); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "All tokens revoked", nil)
+}
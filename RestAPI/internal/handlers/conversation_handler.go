@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ConversationHandler handles direct message conversation requests
+type ConversationHandler struct {
+	conversationService *service.ConversationService
+	reactions           ReactionCounter
+	attachments         AttachmentLoader
+}
+
+// NewConversationHandler creates a new conversation handler
+func NewConversationHandler(conversationService *service.ConversationService) *ConversationHandler {
+	return &ConversationHandler{
+		conversationService: conversationService,
+	}
+}
+
+// SetReactions enables reaction counts in direct message threads
+func (h *ConversationHandler) SetReactions(reactions ReactionCounter) {
+	h.reactions = reactions
+}
+
+// SetAttachments enables attachment metadata in direct message threads
+func (h *ConversationHandler) SetAttachments(attachments AttachmentLoader) {
+	h.attachments = attachments
+}
+
+// ListConversations handles GET /users/:userID/conversations
+// @Summary List direct message conversations
+// @Description List the users the user has exchanged direct messages with, each with the latest message, most recent first.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/conversations [get]
+func (h *ConversationHandler) ListConversations(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	conversations, total, err := h.conversationService.ListConversations(c.Request.Context(), c.Param("userID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, conversations, total, params.Limit, params.Offset)
+}
+
+// GetThread handles GET /users/:userID/conversations/:peerID/messages
+// @Summary Get direct message thread
+// @Description Retrieve the direct messages exchanged between the user and peerID, newest first, with pagination.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param peerID path string true "Other participant's user ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/conversations/{peerID}/messages [get]
+func (h *ConversationHandler) GetThread(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	messages, total, err := h.conversationService.GetThread(c.Request.Context(), c.Param("userID"), c.Param("peerID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if !attachReactions(c, h.reactions, messages) || !loadAttachments(c, h.attachments, messages) {
+		return
+	}
+
+	response.Paginated(c, messages, total, params.Limit, params.Offset)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// EditHandler handles message edit, recall and revision history requests
+type EditHandler struct {
+	editService *service.EditService
+}
+
+// NewEditHandler creates a new edit handler
+func NewEditHandler(editService *service.EditService) *EditHandler {
+	return &EditHandler{
+		editService: editService,
+	}
+}
+
+// UpdateMessageContentRequest represents the new content of an edited message
+type UpdateMessageContentRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// UpdateMessageContent handles PUT /messages/:messageID/content
+// @Summary Edit message content
+// @Description Replace the content of the caller's own message within the edit window. The previous content is kept as a revision and connected clients are notified.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param messageID path string true "Message ID"
+// @Param content body UpdateMessageContentRequest true "New content"
+// @Success 200 {object} response.Response{data=repository.Message}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/messages/{messageID}/content [put]
+func (h *EditHandler) UpdateMessageContent(c *gin.Context) {
+	var req UpdateMessageContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	message, err := h.editService.EditMessage(c.Request.Context(), c.GetString("user_id"), c.Param("messageID"), req.Content)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// 이전 내용은 message_revisions 에 남으므로 감사 기록에는 수정 시각만 싣는다.
+	middleware.SetAuditChange(c, nil, gin.H{"edited_at": message.EditedAt})
+
+	response.OKWithMessage(c, "Message updated successfully", message)
+}
+
+// RecallMessage handles POST /messages/:messageID/recall
+// @Summary Recall message
+// @Description Tombstone a message so every read path hides its content, and notify connected clients. The row is kept for audit. Only the author or an admin can recall; repeating it is a no-op.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param messageID path string true "Message ID"
+// @Success 200 {object} response.Response{data=repository.Message}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/messages/{messageID}/recall [post]
+func (h *EditHandler) RecallMessage(c *gin.Context) {
+	message, err := h.editService.RecallMessage(c.Request.Context(), c.GetString("user_id"), middleware.IsAdmin(c), c.Param("messageID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	middleware.SetAuditChange(c, nil, gin.H{"recalled_at": message.RecalledAt, "recalled_by": message.RecalledBy})
+
+	response.OKWithMessage(c, "Message recalled successfully", message)
+}
+
+// ListRevisions handles GET /messages/:messageID/revisions
+// @Summary List message revisions
+// @Description List the prior versions of a message, most recently replaced first. Visible to whoever can read the message: the sender, the direct message recipient, room members, anyone for a broadcast, and admins.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param messageID path string true "Message ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/messages/{messageID}/revisions [get]
+func (h *EditHandler) ListRevisions(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	revisions, total, err := h.editService.ListRevisions(c.Request.Context(), c.GetString("user_id"), middleware.IsAdmin(c),
+		c.Param("messageID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, revisions, total, params.Limit, params.Offset)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ErasureHandler handles user erasure requests
+type ErasureHandler struct {
+	erasureService *service.ErasureService
+}
+
+// NewErasureHandler creates a new erasure handler
+func NewErasureHandler(erasureService *service.ErasureService) *ErasureHandler {
+	return &ErasureHandler{
+		erasureService: erasureService,
+	}
+}
+
+// EraseUserRequest represents the body of an erasure request
+type EraseUserRequest struct {
+	Messages string `json:"messages" example:"anonymize"`
+}
+
+// RequestErasure handles POST /users/:userID/erasure
+// @Summary Request user erasure
+// @Description Soft-delete the user and erase the user's data in the background: messages are anonymized (default) or deleted in batches, Redis keys are purged and the users row is removed. Poll GET /users/{userID}/erasure for completion.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param request body EraseUserRequest false "What to do with the user's messages (anonymize or delete)"
+// @Success 202 {object} response.Response{data=repository.UserErasure}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/erasure [post]
+func (h *ErasureHandler) RequestErasure(c *gin.Context) {
+	// 본문은 선택이다 — 비어 있으면 기본값(anonymize)으로 처리한다.
+	var req EraseUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if middleware.AuditEnabled(c) {
+		middleware.SetAuditChange(c, nil, gin.H{"messages": req.Messages})
+	}
+
+	erasure, err := h.erasureService.RequestErasure(c.Request.Context(), c.Param("userID"), req.Messages, c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Accepted(c, "Erasure requested", erasure)
+}
+
+// GetErasure handles GET /users/:userID/erasure
+// @Summary Get user erasure
+// @Description Get the progress of a user's erasure. A completed erasure is the record that the user's data was erased.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {object} response.Response{data=repository.UserErasure}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/erasure [get]
+func (h *ErasureHandler) GetErasure(c *gin.Context) {
+	erasure, err := h.erasureService.GetErasure(c.Request.Context(), c.Param("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, erasure)
+}
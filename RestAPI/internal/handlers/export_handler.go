@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ExportHandler handles per-user data export requests
+type ExportHandler struct {
+	exportService *service.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService *service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// RequestExport handles POST /users/:userID/export
+// @Summary Request user data export
+// @Description Build a zip archive of the user's data in the background: profile, messages (decrypted when the server holds the message key), API keys and audit log entries. Poll GET /users/{userID}/exports/{exportID} until it is completed, then download it before expires_at.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 202 {object} response.Response{data=repository.UserExport}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/export [post]
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	export, err := h.exportService.RequestExport(c.Request.Context(), c.Param("userID"), c.GetString("user_id"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Accepted(c, "Export requested", export)
+}
+
+// GetExport handles GET /users/:userID/exports/:exportID
+// @Summary Get user data export
+// @Description Get the status of a data export. download_url is set while the archive can be downloaded.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param exportID path string true "Export ID"
+// @Success 200 {object} response.Response{data=repository.UserExport}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/exports/{exportID} [get]
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	export, err := h.exportService.GetExport(c.Request.Context(), c.Param("userID"), c.Param("exportID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if export.Downloadable(time.Now()) {
+		export.DownloadURL = c.Request.URL.Path + "/download"
+	}
+	response.OK(c, export)
+}
+
+// DownloadExport handles GET /users/:userID/exports/:exportID/download
+// @Summary Download user data export
+// @Description Download the zip archive of a completed export. The archive is removed once expires_at has passed.
+// @Tags users
+// @Produce application/zip
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param exportID path string true "Export ID"
+// @Success 200 {file} file
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 410 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/exports/{exportID}/download [get]
+func (h *ExportHandler) DownloadExport(c *gin.Context) {
+	export, path, err := h.exportService.OpenExport(c.Request.Context(), c.Param("userID"), c.Param("exportID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// 개인정보가 담긴 파일이므로 중간 캐시에 남기지 않는다.
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(path, "export-"+export.ID+".zip")
+}
@@ -7,15 +7,57 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
 	"github.com/sirupsen/logrus"
 )
 
+// RoomAccessChecker decides whether a user may post to a room
+type RoomAccessChecker interface {
+	CheckCanPost(ctx context.Context, roomID, userID string) error
+}
+
+// DirectMessageChecker decides whether a user may send a direct message to another
+type DirectMessageChecker interface {
+	CheckCanMessage(ctx context.Context, senderID, recipientID string) error
+}
+
+// ReplyResolver resolves the message a reply answers, filling in the reply's destination and thread
+type ReplyResolver interface {
+	PrepareReply(ctx context.Context, req *models.MessageRequest) error
+}
+
+// AttachmentClaimer links uploaded attachments to a message before it is published
+type AttachmentClaimer interface {
+	ClaimAttachments(ctx context.Context, userID, messageID string, ids []string) ([]models.QueueAttachment, error)
+	ReleaseAttachments(ctx context.Context, messageID string)
+}
+
+// ContentModerator checks message content before it is published and queues flagged messages for review
+type ContentModerator interface {
+	Moderate(ctx context.Context, msg *moderation.Message) (*moderation.Decision, error)
+	FlagMessage(ctx context.Context, messageID string, msg *moderation.Message, decision *moderation.Decision)
+}
+
+// EventNotifier is told about sent and failed messages
+type EventNotifier interface {
+	Notify(ctx context.Context, eventType, userID string, data interface{})
+}
+
 // MessageHandler handles message-related HTTP requests
 type MessageHandler struct {
-	rabbitMQ *services.RabbitMQService
+	rabbitMQ       *services.RabbitMQService
+	rooms          RoomAccessChecker
+	directMessages DirectMessageChecker
+	replies        ReplyResolver
+	attachments    AttachmentClaimer
+	moderation     ContentModerator
+	events         EventNotifier
 }
 
 // NewMessageHandler creates a new message handler
@@ -25,16 +67,51 @@ func NewMessageHandler(rabbitMQ *services.RabbitMQService) *MessageHandler {
 	}
 }
 
+// SetRoomAccess enables room messages; without it requests with a room_id are refused
+func (h *MessageHandler) SetRoomAccess(rooms RoomAccessChecker) {
+	h.rooms = rooms
+}
+
+// SetDirectMessages enables direct messages; without it requests with a recipient_id are refused
+func (h *MessageHandler) SetDirectMessages(directMessages DirectMessageChecker) {
+	h.directMessages = directMessages
+}
+
+// SetReplies enables replies; without it requests with a reply_to are refused
+func (h *MessageHandler) SetReplies(replies ReplyResolver) {
+	h.replies = replies
+}
+
+// SetAttachments enables attachments; without it requests with attachment_ids are refused
+func (h *MessageHandler) SetAttachments(attachments AttachmentClaimer) {
+	h.attachments = attachments
+}
+
+// SetModeration enables the content filters; without it every message is published as sent
+func (h *MessageHandler) SetModeration(moderation ContentModerator) {
+	h.moderation = moderation
+}
+
+// SetEvents reports message.sent and message.failed events, e.g. to webhook subscriptions
+func (h *MessageHandler) SetEvents(events EventNotifier) {
+	h.events = events
+}
+
 // SendMessage handles the POST /api/v1/messages/send endpoint
 // @Summary Send a message to RabbitMQ
-// @Description Publishes a message to RabbitMQ queue for processing
+// @Description Publishes a message to RabbitMQ queue for processing. With authentication enabled user_id must be the authenticated user. With room_id the sender must be a member of the room; with recipient_id the message is a direct message to that user. With reply_to the message is a reply and goes where the replied message went. attachment_ids are the sender's unsent uploads; their metadata travels with the queued message. Content passes the moderation filters first: rejected messages get 422, flagged ones are published and queued for review.
 // @Tags messages
 // @Accept json
 // @Produce json
 // @Param message body models.MessageRequest true "Message to send"
 // @Success 200 {object} models.MessageResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
 // @Failure 500 {object} models.ErrorResponse
+// @Failure 503 {object} models.ErrorResponse
 // @Router /api/v1/messages/send [post]
 func (h *MessageHandler) SendMessage(c *gin.Context) {
 	var req models.MessageRequest
@@ -67,11 +144,42 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	// 이후의 답글·목적지·첨부·모더레이션 확인은 모두 user_id 를 보낸 사람으로 믿으므로 가장 먼저 본다.
+	if !h.checkSender(c, &req) {
+		return
+	}
+
+	// 답글은 원글에서 목적지를 정하므로 목적지 확인보다 먼저 푼다.
+	if req.ReplyTo != "" && !h.prepareReply(c, &req) {
+		return
+	}
+
+	// 방 메시지는 멤버십을, 1:1 메시지는 수신자를 발행 전에 확인한다. 큐에 들어간 뒤에는 소비측이 거를 방법이 없다.
+	if (req.RoomID != "" || req.RecipientID != "") && !h.checkDestination(c, &req) {
+		return
+	}
+
+	// 내용 검사는 목적지 확인 뒤에 한다 — 보낼 수 없는 메시지가 반복 감지 횟수에 들어가지 않는다.
+	// 반복·속도 필터는 보낸 사람별로 센다 — 본문의 user_id 를 바꿔 가며 보내도 같은 사람으로 묶이도록 인증된 ID 를 쓴다.
+	checked := &moderation.Message{UserID: senderID(c, &req), Content: req.Content}
+	var decision *moderation.Decision
+	if h.moderation != nil {
+		var ok bool
+		if decision, ok = h.moderate(c, checked); !ok {
+			return
+		}
+	}
+
 	// Generate unique message ID
 	messageID := uuid.New().String()
 
+	// 첨부는 메시지 ID 에 묶어야 하므로 ID 를 만든 뒤, 발행 직전에 가져온다.
+	if len(req.AttachmentIDs) > 0 && !h.claimAttachments(c, &req, messageID) {
+		return
+	}
+
 	// Convert to queue message
-	queueMsg := req.ToQueueMessage(messageID)
+	queueMsg := req.ToQueueMessage(messageID).WithRequestID(middleware.GetRequestID(c))
 
 	// Serialize to JSON
 	msgBytes, err := queueMsg.ToJSON()
@@ -81,6 +189,8 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 			"message_id": messageID,
 			"user_id":    req.UserID,
 		}).Error("Failed to serialize message")
+		h.releaseAttachments(c, &req, messageID)
+		h.notify(c, service.EventMessageFailed, &req, messageID, "serialization failed")
 
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to process message",
@@ -94,13 +204,15 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 	defer cancel()
 
 	// Publish to RabbitMQ
-	if err := h.rabbitMQ.Publish(ctx, msgBytes); err != nil {
+	if err := h.rabbitMQ.PublishMessage(ctx, messageID, msgBytes); err != nil {
 		logger.WithFields(logrus.Fields{
 			"error":      err.Error(),
 			"message_id": messageID,
 			"user_id":    req.UserID,
 			"command":    req.Command,
 		}).Error("Failed to publish message to RabbitMQ")
+		h.releaseAttachments(c, &req, messageID)
+		h.notify(c, service.EventMessageFailed, &req, messageID, "publish failed")
 
 		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(
 			"Failed to send message",
@@ -109,12 +221,21 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		return
 	}
 
+	if h.moderation != nil {
+		h.moderation.FlagMessage(c.Request.Context(), messageID, checked, decision)
+	}
+	h.notify(c, service.EventMessageSent, &req, messageID, "")
+
 	// Log success
 	logger.WithFields(logrus.Fields{
-		"message_id": messageID,
-		"user_id":    req.UserID,
-		"command":    req.Command,
-		"priority":   req.Priority,
+		"message_id":   messageID,
+		"user_id":      req.UserID,
+		"room_id":      req.RoomID,
+		"recipient_id": req.RecipientID,
+		"reply_to":     req.ReplyTo,
+		"attachments":  len(req.Attachments),
+		"command":      req.Command,
+		"priority":     req.Priority,
 	}).Info("Message published successfully")
 
 	// Return success response
@@ -127,3 +248,161 @@ func (h *MessageHandler) SendMessage(c *gin.Context) {
 		},
 	))
 }
+
+// notify reports a message event when events are enabled.
+// 발행에 실패한 메시지도 ID 가 정해진 뒤라 수신측이 사용자에게 알릴 수 있도록 함께 보낸다.
+func (h *MessageHandler) notify(c *gin.Context, eventType string, req *models.MessageRequest, messageID, failure string) {
+	if h.events == nil {
+		return
+	}
+	h.events.Notify(c.Request.Context(), eventType, req.UserID, service.MessageEventData{
+		MessageID:   messageID,
+		UserID:      req.UserID,
+		RoomID:      req.RoomID,
+		RecipientID: req.RecipientID,
+		ReplyTo:     req.ReplyTo,
+		Command:     req.Command,
+		Error:       failure,
+	})
+}
+
+// checkSender writes an error response and returns false unless user_id is the authenticated caller.
+// 본문의 user_id 는 클라이언트가 정하는 값이라, 인증이 켜져 있으면 토큰·API 키의 사용자와 같아야 한다.
+// 관리자라도 다른 사용자로 보낼 수 없다. 인증이 꺼져 있으면(user_id 가 설정되지 않음) 본문을 그대로 믿는다.
+func (h *MessageHandler) checkSender(c *gin.Context, req *models.MessageRequest) bool {
+	callerID := c.GetString("user_id")
+	if callerID == "" || callerID == req.UserID {
+		return true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"user_id":   req.UserID,
+		"caller_id": callerID,
+	}).Warn("Message sender does not match the authenticated user")
+
+	c.JSON(http.StatusForbidden, models.NewErrorResponse(
+		"user_id must be the authenticated user",
+		apperrors.ErrCodeForbidden,
+	))
+	return false
+}
+
+// senderID returns the user a message is sent as: the authenticated caller, or user_id when auth is disabled.
+// checkSender 가 둘이 같음을 이미 확인했지만, 권한 확인에는 클라이언트가 보낸 값 대신 인증된 ID 를 직접 넘긴다.
+func senderID(c *gin.Context, req *models.MessageRequest) string {
+	if callerID := c.GetString("user_id"); callerID != "" {
+		return callerID
+	}
+	return req.UserID
+}
+
+// checkDestination writes an error response and returns false unless the sender may post to the room or recipient.
+// 방·1:1 메시지는 DB 가 있어야 확인할 수 있으므로, 확인 수단이 없으면 통과시키지 않고 503 으로 거절한다.
+func (h *MessageHandler) checkDestination(c *gin.Context, req *models.MessageRequest) bool {
+	var err error
+	switch {
+	case req.RoomID != "" && h.rooms != nil:
+		err = h.rooms.CheckCanPost(c.Request.Context(), req.RoomID, senderID(c, req))
+	case req.RecipientID != "" && h.directMessages != nil:
+		err = h.directMessages.CheckCanMessage(c.Request.Context(), senderID(c, req), req.RecipientID)
+	default:
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse(
+			"Room and direct messages require the database",
+			apperrors.ErrCodeServiceUnavail,
+		))
+		return false
+	}
+	if err == nil {
+		return true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"error":        err.Error(),
+		"user_id":      req.UserID,
+		"room_id":      req.RoomID,
+		"recipient_id": req.RecipientID,
+	}).Warn("Message destination refused")
+
+	writeCheckError(c, err, "Failed to check message destination")
+	return false
+}
+
+// moderate writes an error response and returns false when the filters reject the message
+func (h *MessageHandler) moderate(c *gin.Context, msg *moderation.Message) (*moderation.Decision, bool) {
+	decision, err := h.moderation.Moderate(c.Request.Context(), msg)
+	if err == nil {
+		return decision, true
+	}
+
+	writeCheckError(c, err, "Failed to check message content")
+	return nil, false
+}
+
+// prepareReply writes an error response and returns false unless the replied message resolves.
+// 답글도 원글을 DB 에서 찾아야 하므로 확인 수단이 없으면 503 으로 거절한다.
+func (h *MessageHandler) prepareReply(c *gin.Context, req *models.MessageRequest) bool {
+	if h.replies == nil {
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse(
+			"Replies require the database",
+			apperrors.ErrCodeServiceUnavail,
+		))
+		return false
+	}
+
+	err := h.replies.PrepareReply(c.Request.Context(), req)
+	if err == nil {
+		return true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"error":    err.Error(),
+		"user_id":  req.UserID,
+		"reply_to": req.ReplyTo,
+	}).Warn("Reply refused")
+
+	writeCheckError(c, err, "Failed to resolve reply")
+	return false
+}
+
+// claimAttachments writes an error response and returns false unless the sender's attachments were linked to the message.
+// 첨부도 DB 에 기록된 업로드에서 찾아야 하므로 확인 수단이 없으면 503 으로 거절한다.
+func (h *MessageHandler) claimAttachments(c *gin.Context, req *models.MessageRequest, messageID string) bool {
+	if h.attachments == nil {
+		c.JSON(http.StatusServiceUnavailable, models.NewErrorResponse(
+			"Attachments require the database",
+			apperrors.ErrCodeServiceUnavail,
+		))
+		return false
+	}
+
+	attachments, err := h.attachments.ClaimAttachments(c.Request.Context(), senderID(c, req), messageID, req.AttachmentIDs)
+	if err == nil {
+		req.Attachments = attachments
+		return true
+	}
+
+	logger.WithFields(logrus.Fields{
+		"error":          err.Error(),
+		"user_id":        req.UserID,
+		"attachment_ids": req.AttachmentIDs,
+	}).Warn("Attachments refused")
+
+	writeCheckError(c, err, "Failed to attach files")
+	return false
+}
+
+// releaseAttachments gives back the attachments of a message that was not published, so they can be sent again
+func (h *MessageHandler) releaseAttachments(c *gin.Context, req *models.MessageRequest, messageID string) {
+	if len(req.Attachments) > 0 {
+		h.attachments.ReleaseAttachments(c.Request.Context(), messageID)
+	}
+}
+
+// writeCheckError writes the response of a failed pre-publish check
+func writeCheckError(c *gin.Context, err error, fallback string) {
+	if appErr := apperrors.GetAppError(err); appErr != nil {
+		c.JSON(appErr.StatusCode, models.NewErrorResponse(appErr.Message, appErr.Code))
+	} else {
+		c.JSON(http.StatusInternalServerError, models.NewErrorResponse(fallback, apperrors.ErrCodeInternal))
+	}
+}
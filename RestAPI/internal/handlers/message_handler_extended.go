@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
@@ -10,6 +14,36 @@ import (
 // MessageHandlerExtended handles extended message-related HTTP requests
 type MessageHandlerExtended struct {
 	messageService *service.MessageService
+	receipts       ReceiptSummarizer
+	reactions      ReactionCounter
+	attachments    AttachmentLoader
+	access         ReadChecker
+}
+
+// ReceiptSummarizer counts the recipients that received and read a message
+type ReceiptSummarizer interface {
+	Summary(ctx context.Context, messageID string) (*repository.ReceiptSummary, error)
+}
+
+// ReactionCounter fills the aggregated reaction counts of messages
+type ReactionCounter interface {
+	AttachReactions(ctx context.Context, messages []*repository.Message) error
+}
+
+// AttachmentLoader fills the attachment metadata of messages
+type AttachmentLoader interface {
+	LoadAttachments(ctx context.Context, messages []*repository.Message) error
+}
+
+// ReadChecker decides whether a user can read a message
+type ReadChecker interface {
+	CheckCanRead(ctx context.Context, message *repository.Message, userID string) error
+}
+
+// MessageDetail is a message with its receipt summary
+type MessageDetail struct {
+	*repository.Message
+	Receipts *repository.ReceiptSummary `json:"receipts,omitempty"`
 }
 
 type UpdateMessageStatusRequest struct {
@@ -23,13 +57,37 @@ func NewMessageHandlerExtended(messageService *service.MessageService) *MessageH
 	}
 }
 
+// SetReceipts enables the receipt summary in message detail
+func (h *MessageHandlerExtended) SetReceipts(receipts ReceiptSummarizer) {
+	h.receipts = receipts
+}
+
+// SetReactions enables reaction counts in message detail
+func (h *MessageHandlerExtended) SetReactions(reactions ReactionCounter) {
+	h.reactions = reactions
+}
+
+// SetAttachments enables attachment metadata in message detail
+func (h *MessageHandlerExtended) SetAttachments(attachments AttachmentLoader) {
+	h.attachments = attachments
+}
+
+// SetReadAccess lets room members read room messages in message detail.
+// 설정하지 않으면 보낸 사람과 1:1 상대만 읽을 수 있다.
+func (h *MessageHandlerExtended) SetReadAccess(access ReadChecker) {
+	h.access = access
+}
+
 // GetMessage handles GET /messages/:messageID
 // @Summary Get message by ID
-// @Description Retrieve a single message by message ID.
+// @Description Retrieve a single message by message ID, with how many recipients received and read it, its reaction counts and its attachments. Non-admin callers can read messages they sent, direct messages sent to them, messages of rooms they belong to and broadcasts.
 // @Tags messages
 // @Produce json
+// @Security BearerAuth
 // @Param messageID path string true "Message ID"
-// @Success 200 {object} response.Response
+// @Success 200 {object} response.Response{data=MessageDetail}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/messages/{messageID} [get]
@@ -42,18 +100,47 @@ func (h *MessageHandlerExtended) GetMessage(c *gin.Context) {
 		return
 	}
 
-	response.OK(c, message)
+	// 남의 메시지는 403 대신 404 로 돌려 존재 여부를 드러내지 않는다. 1:1 메시지는 받은 사람도 읽을 수 있다.
+	if userID := c.GetString("user_id"); userID != "" && !middleware.IsAdmin(c) {
+		if h.access != nil {
+			if err := h.access.CheckCanRead(c.Request.Context(), message, userID); err != nil {
+				response.Error(c, err)
+				return
+			}
+		} else if !isParticipant(message, userID) {
+			response.NotFound(c, "Message not found")
+			return
+		}
+	}
+
+	if !attachReactions(c, h.reactions, []*repository.Message{message}) ||
+		!loadAttachments(c, h.attachments, []*repository.Message{message}) {
+		return
+	}
+
+	detail := MessageDetail{Message: message}
+	if h.receipts != nil {
+		if detail.Receipts, err = h.receipts.Summary(c.Request.Context(), messageID); err != nil {
+			response.Error(c, err)
+			return
+		}
+	}
+
+	response.OK(c, detail)
 }
 
 // GetUserMessages handles GET /users/:userID/messages
 // @Summary Get messages by user
-// @Description Retrieve messages for a specific user with pagination.
+// @Description Retrieve messages for a specific user with pagination. Non-admin callers can only read their own messages.
 // @Tags messages
 // @Produce json
+// @Security BearerAuth
 // @Param userID path string true "User ID"
 // @Param limit query int false "Pagination limit"
 // @Param offset query int false "Pagination offset"
 // @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/users/{userID}/messages [get]
 func (h *MessageHandlerExtended) GetUserMessages(c *gin.Context) {
@@ -74,9 +161,12 @@ func (h *MessageHandlerExtended) GetUserMessages(c *gin.Context) {
 // @Description Retrieve recent messages with pagination.
 // @Tags messages
 // @Produce json
+// @Security BearerAuth
 // @Param limit query int false "Pagination limit"
 // @Param offset query int false "Pagination offset"
 // @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/messages/recent [get]
 func (h *MessageHandlerExtended) GetRecentMessages(c *gin.Context) {
@@ -96,10 +186,13 @@ func (h *MessageHandlerExtended) GetRecentMessages(c *gin.Context) {
 // @Description Retrieve messages filtered by status with pagination.
 // @Tags messages
 // @Produce json
+// @Security BearerAuth
 // @Param status path string true "Message status"
 // @Param limit query int false "Pagination limit"
 // @Param offset query int false "Pagination offset"
 // @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/messages/status/{status} [get]
 func (h *MessageHandlerExtended) GetMessagesByStatus(c *gin.Context) {
@@ -121,10 +214,14 @@ func (h *MessageHandlerExtended) GetMessagesByStatus(c *gin.Context) {
 // @Tags messages
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param messageID path string true "Message ID"
 // @Param status body UpdateMessageStatusRequest true "Status update"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/messages/{messageID}/status [patch]
 func (h *MessageHandlerExtended) UpdateMessageStatus(c *gin.Context) {
@@ -137,6 +234,9 @@ func (h *MessageHandlerExtended) UpdateMessageStatus(c *gin.Context) {
 		return
 	}
 
+	// 메시지는 캐시되지 않아 변경 전 상태를 읽으려면 조회가 한 번 더 필요하므로 변경 후 값만 남긴다.
+	middleware.SetAuditChange(c, nil, gin.H{"status": req.Status})
+
 	if err := h.messageService.UpdateMessageStatus(c.Request.Context(), messageID, req.Status); err != nil {
 		response.Error(c, err)
 		return
@@ -150,8 +250,11 @@ func (h *MessageHandlerExtended) UpdateMessageStatus(c *gin.Context) {
 // @Description Delete a message by ID.
 // @Tags messages
 // @Produce json
+// @Security BearerAuth
 // @Param messageID path string true "Message ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/messages/{messageID} [delete]
 func (h *MessageHandlerExtended) DeleteMessage(c *gin.Context) {
@@ -170,7 +273,10 @@ func (h *MessageHandlerExtended) DeleteMessage(c *gin.Context) {
 // @Description Retrieve message counts by status.
 // @Tags messages
 // @Produce json
+// @Security BearerAuth
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/messages/stats [get]
 func (h *MessageHandlerExtended) GetMessageStats(c *gin.Context) {
@@ -182,3 +288,55 @@ func (h *MessageHandlerExtended) GetMessageStats(c *gin.Context) {
 
 	response.OK(c, stats)
 }
+
+// LookupMessage handles GET /messages/lookup/:identifier
+// @Summary Trace a message by any of its identifiers
+// @Description Admin only. Resolves a messages row ID, a message_id or an X-Request-ID to the stored messages, each with the request_id and message_id it was published under. A request ID can match several messages. Messages that were published but not yet stored by DBWorker are not found.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param identifier path string true "Row ID, message_id or request ID"
+// @Success 200 {object} response.Response{data=service.MessageLookup}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/messages/lookup/{identifier} [get]
+func (h *MessageHandlerExtended) LookupMessage(c *gin.Context) {
+	lookup, err := h.messageService.LookupMessage(c.Request.Context(), c.Param("identifier"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, lookup)
+}
+
+// isParticipant reports whether userID sent the message or is its direct message recipient
+func isParticipant(message *repository.Message, userID string) bool {
+	return message.UserID == userID || (message.RecipientID != nil && *message.RecipientID == userID)
+}
+
+// attachReactions fills reaction counts when reactions are enabled, writing an error response and returning false on failure
+func attachReactions(c *gin.Context, reactions ReactionCounter, messages []*repository.Message) bool {
+	if reactions == nil || len(messages) == 0 {
+		return true
+	}
+	if err := reactions.AttachReactions(c.Request.Context(), messages); err != nil {
+		response.Error(c, err)
+		return false
+	}
+	return true
+}
+
+// loadAttachments fills attachment metadata when attachments are enabled, writing an error response and returning false on failure
+func loadAttachments(c *gin.Context, attachments AttachmentLoader, messages []*repository.Message) bool {
+	if attachments == nil || len(messages) == 0 {
+		return true
+	}
+	if err := attachments.LoadAttachments(c.Request.Context(), messages); err != nil {
+		response.Error(c, err)
+		return false
+	}
+	return true
+}
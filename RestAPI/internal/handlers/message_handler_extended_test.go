@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedMessages serves one message from every lookup.
+// 테스트에서 쓰지 않는 메서드는 임베드한 nil 인터페이스로 남겨 둔다.
+type fixedMessages struct {
+	repository.MessageRepository
+	message *repository.Message
+}
+
+func (r *fixedMessages) GetByMessageID(ctx context.Context, messageID string) (*repository.Message, error) {
+	return r.message, nil
+}
+
+func (r *fixedMessages) ThreadSummaries(ctx context.Context, rootIDs []string) (map[string]*repository.ThreadSummary, error) {
+	return nil, nil
+}
+
+// memberRooms reports the listed users as members of every room
+type memberRooms struct {
+	repository.RoomRepository
+	members []string
+}
+
+func (r *memberRooms) GetMember(ctx context.Context, roomID, userID string) (*repository.RoomMember, error) {
+	for _, member := range r.members {
+		if member == userID {
+			return &repository.RoomMember{}, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func TestGetMessage_RoomMembers(t *testing.T) {
+	roomID := testRoomID
+	messages := &fixedMessages{message: &repository.Message{MessageID: "msg-1", UserID: "alice", RoomID: &roomID}}
+
+	h := NewMessageHandlerExtended(service.NewMessageService(messages, nil))
+	h.SetReadAccess(service.NewThreadService(messages, &memberRooms{members: []string{"alice", "bob"}}))
+
+	tests := []struct {
+		name     string
+		callerID string
+		want     int
+	}{
+		{"sender", "alice", http.StatusOK},
+		{"room member", "bob", http.StatusOK},
+		{"outsider", "mallory", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/messages/:messageID", func(c *gin.Context) {
+				c.Set("user_id", tt.callerID)
+			}, h.GetMessage)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/messages/msg-1", nil))
+
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
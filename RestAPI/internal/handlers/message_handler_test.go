@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRoomID = "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10"
+
+// refusingRooms records membership checks and refuses every one of them
+type refusingRooms struct {
+	checked []string
+}
+
+func (r *refusingRooms) CheckCanPost(ctx context.Context, roomID, userID string) error {
+	r.checked = append(r.checked, userID)
+	return apperrors.New(apperrors.ErrCodeForbidden, "Not a member of the room", http.StatusForbidden)
+}
+
+// refusingDirectMessages records direct message checks and refuses every one of them
+type refusingDirectMessages struct {
+	senders []string
+}
+
+func (d *refusingDirectMessages) CheckCanMessage(ctx context.Context, senderID, recipientID string) error {
+	d.senders = append(d.senders, senderID)
+	return apperrors.New(apperrors.ErrCodeNotFound, "User not found", http.StatusNotFound)
+}
+
+// refusingAttachments records attachment claims and refuses every one of them
+type refusingAttachments struct {
+	owners []string
+}
+
+func (a *refusingAttachments) ClaimAttachments(ctx context.Context, userID, messageID string, ids []string) ([]models.QueueAttachment, error) {
+	a.owners = append(a.owners, userID)
+	return nil, apperrors.New(apperrors.ErrCodeNotFound, "Attachment not found", http.StatusNotFound)
+}
+
+func (a *refusingAttachments) ReleaseAttachments(ctx context.Context, messageID string) {}
+
+// rejectingModerator records whose content was checked and rejects all of it
+type rejectingModerator struct {
+	senders []string
+}
+
+func (m *rejectingModerator) Moderate(ctx context.Context, msg *moderation.Message) (*moderation.Decision, error) {
+	m.senders = append(m.senders, msg.UserID)
+	return nil, apperrors.New(apperrors.ErrCodeContentRejected, "Message rejected", http.StatusUnprocessableEntity)
+}
+
+func (m *rejectingModerator) FlagMessage(ctx context.Context, messageID string, msg *moderation.Message, decision *moderation.Decision) {
+}
+
+// sendAs posts body to SendMessage with callerID as the authenticated user; an empty callerID means auth is off
+func sendAs(h *MessageHandler, callerID string, body map[string]interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/send", func(c *gin.Context) {
+		if callerID != "" {
+			c.Set("user_id", callerID)
+		}
+	}, h.SendMessage)
+
+	raw, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/send", bytes.NewReader(raw)))
+	return w
+}
+
+func TestSendMessage_SpoofedSender(t *testing.T) {
+	rooms := &refusingRooms{}
+	h := NewMessageHandler(nil)
+	h.SetRoomAccess(rooms)
+
+	t.Run("another user's id", func(t *testing.T) {
+		w := sendAs(h, "mallory", map[string]interface{}{
+			"user_id": "alice", "command": "chat", "content": "hi", "room_id": testRoomID,
+		})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), apperrors.ErrCodeForbidden)
+		assert.Empty(t, rooms.checked, "위조된 발신자로는 방 권한을 확인하지도 않는다")
+	})
+
+	t.Run("own id", func(t *testing.T) {
+		rooms.checked = nil
+		w := sendAs(h, "mallory", map[string]interface{}{
+			"user_id": "mallory", "command": "chat", "content": "hi", "room_id": testRoomID,
+		})
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, []string{"mallory"}, rooms.checked)
+	})
+
+	t.Run("auth disabled", func(t *testing.T) {
+		rooms.checked = nil
+		sendAs(h, "", map[string]interface{}{
+			"user_id": "alice", "command": "chat", "content": "hi", "room_id": testRoomID,
+		})
+
+		assert.Equal(t, []string{"alice"}, rooms.checked)
+	})
+}
+
+func TestSendMessage_SpoofedDirectMessageSender(t *testing.T) {
+	directMessages := &refusingDirectMessages{}
+	h := NewMessageHandler(nil)
+	h.SetDirectMessages(directMessages)
+
+	w := sendAs(h, "mallory", map[string]interface{}{
+		"user_id": "alice", "command": "chat", "content": "hi", "recipient_id": "bob",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code, "다른 사용자 이름으로 1:1 메시지를 보낼 수 없다")
+	assert.Empty(t, directMessages.senders)
+
+	sendAs(h, "mallory", map[string]interface{}{
+		"user_id": "mallory", "command": "chat", "content": "hi", "recipient_id": "bob",
+	})
+	assert.Equal(t, []string{"mallory"}, directMessages.senders)
+}
+
+func TestSendMessage_SpoofedAttachmentOwner(t *testing.T) {
+	attachments := &refusingAttachments{}
+	h := NewMessageHandler(nil)
+	h.SetAttachments(attachments)
+	attachmentIDs := []string{"5d8c1a2b-3e4f-4a5b-8c6d-7e8f9a0b1c2d"}
+
+	w := sendAs(h, "mallory", map[string]interface{}{
+		"user_id": "alice", "command": "chat", "content": "hi", "attachment_ids": attachmentIDs,
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code, "다른 사용자의 업로드를 가져갈 수 없다")
+	assert.Empty(t, attachments.owners)
+
+	w = sendAs(h, "mallory", map[string]interface{}{
+		"user_id": "mallory", "command": "chat", "content": "hi", "attachment_ids": attachmentIDs,
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, []string{"mallory"}, attachments.owners)
+}
+
+func TestSendMessage_ModerationKeyedOnCaller(t *testing.T) {
+	moderator := &rejectingModerator{}
+	h := NewMessageHandler(nil)
+	h.SetModeration(moderator)
+
+	w := sendAs(h, "mallory", map[string]interface{}{"user_id": "alice", "command": "chat", "content": "spam"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = sendAs(h, "mallory", map[string]interface{}{"user_id": "mallory", "command": "chat", "content": "spam"})
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, []string{"mallory"}, moderator.senders, "반복 감지는 인증된 사용자 기준으로 센다")
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ModerationHandler handles the review queue of flagged messages
+type ModerationHandler struct {
+	moderationService *service.ModerationService
+}
+
+// NewModerationHandler creates a new moderation handler
+func NewModerationHandler(moderationService *service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+	}
+}
+
+// ReviewFlagRequest represents a reviewer's decision on a flagged message
+type ReviewFlagRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve remove"`
+}
+
+// ListFlags handles GET /moderation/flags
+// @Summary List flagged messages
+// @Description List messages the moderation filters flagged, oldest first, with the verdict of each filter.
+// @Tags moderation
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending, approved or removed; all when omitted"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/moderation/flags [get]
+func (h *ModerationHandler) ListFlags(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	flags, total, err := h.moderationService.ListFlags(c.Request.Context(), c.Query("status"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, flags, total, params.Limit, params.Offset)
+}
+
+// ReviewFlag handles POST /moderation/flags/:flagID/review
+// @Summary Review a flagged message
+// @Description Approve a flagged message to keep it, or remove it, which recalls the message for everyone. A flag is reviewed once.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param flagID path string true "Flag ID"
+// @Param review body ReviewFlagRequest true "Review decision"
+// @Success 200 {object} response.Response{data=repository.ModerationFlag}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/moderation/flags/{flagID}/review [post]
+func (h *ModerationHandler) ReviewFlag(c *gin.Context) {
+	var req ReviewFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	flag, err := h.moderationService.ReviewFlag(c.Request.Context(), c.GetString("user_id"), c.Param("flagID"), req.Decision)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Flag reviewed", flag)
+}
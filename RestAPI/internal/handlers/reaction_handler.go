@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ReactionHandler handles emoji reaction requests
+type ReactionHandler struct {
+	reactionService *service.ReactionService
+}
+
+// NewReactionHandler creates a new reaction handler
+func NewReactionHandler(reactionService *service.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+	}
+}
+
+// AddReaction handles PUT /users/:userID/messages/:messageID/reactions/:emoji
+// @Summary Add reaction
+// @Description Add the user's emoji reaction to a message they can read and return the message's reaction counts. Repeating it is a no-op.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "Reacting user ID"
+// @Param messageID path string true "Message ID"
+// @Param emoji path string true "Emoji (URL-encoded), up to 64 characters"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/messages/{messageID}/reactions/{emoji} [put]
+func (h *ReactionHandler) AddReaction(c *gin.Context) {
+	counts, err := h.reactionService.AddReaction(c.Request.Context(), c.Param("userID"), c.Param("messageID"), c.Param("emoji"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"message_id": c.Param("messageID"), "reactions": counts})
+}
+
+// RemoveReaction handles DELETE /users/:userID/messages/:messageID/reactions/:emoji
+// @Summary Remove reaction
+// @Description Remove the user's emoji reaction from a message and return the message's reaction counts. Removing a reaction that is not there is a no-op.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "Reacting user ID"
+// @Param messageID path string true "Message ID"
+// @Param emoji path string true "Emoji (URL-encoded), up to 64 characters"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/messages/{messageID}/reactions/{emoji} [delete]
+func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
+	counts, err := h.reactionService.RemoveReaction(c.Request.Context(), c.Param("userID"), c.Param("messageID"), c.Param("emoji"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"message_id": c.Param("messageID"), "reactions": counts})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ReceiptHandler handles message delivery and read receipt requests
+type ReceiptHandler struct {
+	receiptService *service.ReceiptService
+}
+
+// NewReceiptHandler creates a new receipt handler
+func NewReceiptHandler(receiptService *service.ReceiptService) *ReceiptHandler {
+	return &ReceiptHandler{
+		receiptService: receiptService,
+	}
+}
+
+// MarkReadUpToRequest represents a read watermark: every message of the room or
+// direct message thread up to and including up_to_message_id is marked read
+type MarkReadUpToRequest struct {
+	RoomID        string `json:"room_id"`
+	PeerID        string `json:"peer_id"`
+	UpToMessageID string `json:"up_to_message_id" binding:"required"`
+}
+
+// MarkDelivered handles POST /users/:userID/messages/:messageID/delivered
+// @Summary Mark message delivered
+// @Description Record that the message reached the user. Repeating it keeps the first delivery time.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "Recipient user ID"
+// @Param messageID path string true "Message ID"
+// @Success 200 {object} response.Response{data=repository.MessageReceipt}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/messages/{messageID}/delivered [post]
+func (h *ReceiptHandler) MarkDelivered(c *gin.Context) {
+	receipt, err := h.receiptService.MarkDelivered(c.Request.Context(), c.Param("userID"), c.Param("messageID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, receipt)
+}
+
+// MarkRead handles POST /users/:userID/messages/:messageID/read
+// @Summary Mark message read
+// @Description Record that the user read the message. Repeating it keeps the first read time.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "Recipient user ID"
+// @Param messageID path string true "Message ID"
+// @Success 200 {object} response.Response{data=repository.MessageReceipt}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/messages/{messageID}/read [post]
+func (h *ReceiptHandler) MarkRead(c *gin.Context) {
+	receipt, err := h.receiptService.MarkRead(c.Request.Context(), c.Param("userID"), c.Param("messageID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, receipt)
+}
+
+// MarkReadUpTo handles POST /users/:userID/messages/read
+// @Summary Mark messages read up to a watermark
+// @Description Mark every message of a room (room_id) or direct message thread (peer_id) up to and including up_to_message_id as read. At most the latest 1000 messages are marked per request.
+// @Tags messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "Reader user ID"
+// @Param watermark body MarkReadUpToRequest true "Read watermark"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/messages/read [post]
+func (h *ReceiptHandler) MarkReadUpTo(c *gin.Context) {
+	var req MarkReadUpToRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	scope := repository.ReceiptScope{RoomID: req.RoomID, PeerID: req.PeerID}
+	messageIDs, err := h.receiptService.MarkReadUpTo(c.Request.Context(), c.Param("userID"), scope, req.UpToMessageID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, gin.H{"marked": len(messageIDs), "message_ids": messageIDs})
+}
+
+// ListReceipts handles GET /messages/:messageID/receipts
+// @Summary List message receipts
+// @Description List who received and read a message, earliest delivery first. Only the sender or an admin can see them.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param messageID path string true "Message ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/messages/{messageID}/receipts [get]
+func (h *ReceiptHandler) ListReceipts(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	receipts, total, err := h.receiptService.ListReceipts(c.Request.Context(), c.GetString("user_id"), middleware.IsAdmin(c),
+		c.Param("messageID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, receipts, total, params.Limit, params.Offset)
+}
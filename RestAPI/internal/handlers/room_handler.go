@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// RoomHandler handles room and room membership requests
+type RoomHandler struct {
+	roomService *service.RoomService
+	reactions   ReactionCounter
+	attachments AttachmentLoader
+	authEnabled bool
+}
+
+// NewRoomHandler creates a new room handler
+func NewRoomHandler(roomService *service.RoomService) *RoomHandler {
+	return &RoomHandler{
+		roomService: roomService,
+	}
+}
+
+// SetAuthEnabled restricts unidentified callers to public rooms and read-only access.
+// 설정하지 않으면 호출자가 비어 있는 요청은 인증이 꺼진 것으로 보고 방 역할을 검사하지 않는다.
+func (h *RoomHandler) SetAuthEnabled(enabled bool) {
+	h.authEnabled = enabled
+}
+
+// SetReactions enables reaction counts in room message lists
+func (h *RoomHandler) SetReactions(reactions ReactionCounter) {
+	h.reactions = reactions
+}
+
+// SetAttachments enables attachment metadata in room message lists
+func (h *RoomHandler) SetAttachments(attachments AttachmentLoader) {
+	h.attachments = attachments
+}
+
+// CreateRoomRequest represents the request to create a room.
+// OwnerID 는 인증이 꺼져 있거나 관리자가 다른 사용자 대신 만들 때만 쓴다. 그 외에는 호출자가 owner 가 된다.
+type CreateRoomRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	OwnerID     string `json:"owner_id"`
+}
+
+// UpdateRoomRequest represents a partial room update; omitted fields are kept
+type UpdateRoomRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	IsPrivate   *bool   `json:"is_private"`
+}
+
+// SetRoomMemberRequest represents the request to add a member or change a member's role
+type SetRoomMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// roomActor builds the room actor from the identity set by the auth middleware
+func (h *RoomHandler) roomActor(c *gin.Context) service.RoomActor {
+	return service.RoomActor{
+		UserID:      c.GetString("user_id"),
+		Admin:       middleware.IsAdmin(c),
+		AuthEnabled: h.authEnabled,
+	}
+}
+
+// CreateRoom handles POST /rooms
+// @Summary Create room
+// @Description Create a room. The caller becomes its owner.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param room body CreateRoomRequest true "Room to create"
+// @Success 201 {object} response.Response{data=repository.Room}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms [post]
+func (h *RoomHandler) CreateRoom(c *gin.Context) {
+	var req CreateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	room, err := h.roomService.CreateRoom(c.Request.Context(), h.roomActor(c), service.CreateRoomInput{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPrivate:   req.IsPrivate,
+		OwnerID:     req.OwnerID,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	middleware.SetAuditTarget(c, room.RoomID)
+	middleware.SetAuditChange(c, nil, room)
+
+	response.Created(c, "Room created successfully", room)
+}
+
+// ListRooms handles GET /rooms
+// @Summary List rooms
+// @Description List public rooms and the private rooms the caller belongs to. Admins see every room.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms [get]
+func (h *RoomHandler) ListRooms(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	rooms, total, err := h.roomService.ListRooms(c.Request.Context(), h.roomActor(c), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, rooms, total, params.Limit, params.Offset)
+}
+
+// GetRoom handles GET /rooms/:roomID
+// @Summary Get room
+// @Description Get a room. Private rooms are only visible to their members.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Success 200 {object} response.Response{data=repository.Room}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID} [get]
+func (h *RoomHandler) GetRoom(c *gin.Context) {
+	room, err := h.roomService.GetRoom(c.Request.Context(), h.roomActor(c), c.Param("roomID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, room)
+}
+
+// UpdateRoom handles PATCH /rooms/:roomID
+// @Summary Update room
+// @Description Change a room's name, description or visibility. Owners only.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Param room body UpdateRoomRequest true "Fields to change"
+// @Success 200 {object} response.Response{data=repository.Room}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID} [patch]
+func (h *RoomHandler) UpdateRoom(c *gin.Context) {
+	var req UpdateRoomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	middleware.SetAuditChange(c, nil, req)
+
+	room, err := h.roomService.UpdateRoom(c.Request.Context(), h.roomActor(c), c.Param("roomID"), repository.RoomUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		IsPrivate:   req.IsPrivate,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Room updated successfully", room)
+}
+
+// DeleteRoom handles DELETE /rooms/:roomID
+// @Summary Delete room
+// @Description Delete a room and its memberships. Messages sent to the room are kept. Owners only.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID} [delete]
+func (h *RoomHandler) DeleteRoom(c *gin.Context) {
+	if err := h.roomService.DeleteRoom(c.Request.Context(), h.roomActor(c), c.Param("roomID")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Room deleted successfully", nil)
+}
+
+// JoinRoom handles POST /rooms/:roomID/join
+// @Summary Join room
+// @Description Join a public room as a member. Private rooms are joined by being added by a member.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Success 200 {object} response.Response{data=repository.RoomMember}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID}/join [post]
+func (h *RoomHandler) JoinRoom(c *gin.Context) {
+	actor := h.roomActor(c)
+	if actor.UserID == "" {
+		response.Unauthorized(c, "Joining a room requires an authenticated user")
+		return
+	}
+
+	member, err := h.roomService.Join(c.Request.Context(), actor, c.Param("roomID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Joined room", member)
+}
+
+// LeaveRoom handles POST /rooms/:roomID/leave
+// @Summary Leave room
+// @Description Leave a room. The last owner must promote another member first.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID}/leave [post]
+func (h *RoomHandler) LeaveRoom(c *gin.Context) {
+	actor := h.roomActor(c)
+	if actor.UserID == "" {
+		response.Unauthorized(c, "Leaving a room requires an authenticated user")
+		return
+	}
+
+	if err := h.roomService.RemoveMember(c.Request.Context(), actor, c.Param("roomID"), actor.UserID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Left room", nil)
+}
+
+// ListMembers handles GET /rooms/:roomID/members
+// @Summary List room members
+// @Description List the members of a room, owners first.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID}/members [get]
+func (h *RoomHandler) ListMembers(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	members, total, err := h.roomService.ListMembers(c.Request.Context(), h.roomActor(c), c.Param("roomID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, members, total, params.Limit, params.Offset)
+}
+
+// SetMember handles PUT /rooms/:roomID/members/:userID
+// @Summary Add room member or change role
+// @Description Add a user to a room or change a member's role (owner, moderator, member; default member). Owners may set any role, moderators may add members.
+// @Tags rooms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Param userID path string true "User ID"
+// @Param member body SetRoomMemberRequest false "Member role"
+// @Success 200 {object} response.Response{data=repository.RoomMember}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID}/members/{userID} [put]
+func (h *RoomHandler) SetMember(c *gin.Context) {
+	var req SetRoomMemberRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.ValidationError(c, "Invalid request payload: "+err.Error())
+			return
+		}
+	}
+
+	userID := c.Param("userID")
+	member, err := h.roomService.SetMember(c.Request.Context(), h.roomActor(c), c.Param("roomID"), userID, req.Role)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	middleware.SetAuditChange(c, nil, gin.H{"user_id": userID, "role": member.Role})
+
+	response.OKWithMessage(c, "Room member updated successfully", member)
+}
+
+// RemoveMember handles DELETE /rooms/:roomID/members/:userID
+// @Summary Remove room member
+// @Description Remove a user from a room. Owners may remove anyone, moderators may remove members.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Param userID path string true "User ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID}/members/{userID} [delete]
+func (h *RoomHandler) RemoveMember(c *gin.Context) {
+	userID := c.Param("userID")
+	middleware.SetAuditChange(c, gin.H{"user_id": userID}, nil)
+
+	if err := h.roomService.RemoveMember(c.Request.Context(), h.roomActor(c), c.Param("roomID"), userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Room member removed successfully", nil)
+}
+
+// ListMessages handles GET /rooms/:roomID/messages
+// @Summary Get room history
+// @Description Retrieve the messages of a room, newest first, with pagination.
+// @Tags rooms
+// @Produce json
+// @Security BearerAuth
+// @Param roomID path string true "Room ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/rooms/{roomID}/messages [get]
+func (h *RoomHandler) ListMessages(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	messages, total, err := h.roomService.ListMessages(c.Request.Context(), h.roomActor(c), c.Param("roomID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if !attachReactions(c, h.reactions, messages) || !loadAttachments(c, h.attachments, messages) {
+		return
+	}
+
+	response.Paginated(c, messages, total, params.Limit, params.Offset)
+}
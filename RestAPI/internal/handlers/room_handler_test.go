@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret-key-that-is-at-least-32-chars"
+
+// privateRoom serves one private room owned by alice and records changes to it.
+// 테스트에서 쓰지 않는 메서드는 임베드한 nil 인터페이스로 남겨 둔다.
+type privateRoom struct {
+	repository.RoomRepository
+	deleted        []string
+	includePrivate []bool
+}
+
+func (r *privateRoom) GetByRoomID(ctx context.Context, roomID string) (*repository.Room, error) {
+	return &repository.Room{RoomID: roomID, Name: "staff", IsPrivate: true}, nil
+}
+
+func (r *privateRoom) GetMember(ctx context.Context, roomID, userID string) (*repository.RoomMember, error) {
+	if userID == "alice" {
+		return &repository.RoomMember{RoomID: roomID, UserID: userID, Role: repository.RoomRoleOwner}, nil
+	}
+	return nil, fmt.Errorf("%s: %w", userID, repository.ErrRoomMemberNotFound)
+}
+
+func (r *privateRoom) Delete(ctx context.Context, roomID string) error {
+	r.deleted = append(r.deleted, roomID)
+	return nil
+}
+
+func (r *privateRoom) List(ctx context.Context, viewerID string, includePrivate bool, limit, offset int) ([]*repository.Room, error) {
+	r.includePrivate = append(r.includePrivate, includePrivate)
+	return []*repository.Room{}, nil
+}
+
+func (r *privateRoom) Count(ctx context.Context, viewerID string, includePrivate bool) (int64, error) {
+	return 0, nil
+}
+
+// TestRoomRoutes_PublicGroupWithAuth wires the rooms group the way main does with auth enabled and
+// auth.route_access.rooms set to public: no global identification, identified guard on changes.
+func TestRoomRoutes_PublicGroupWithAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middleware.NewHMACKeySet(testJWTSecret)
+	identified := middleware.Identified(middleware.Identify(keys, nil, nil), middleware.RequireAuthenticated())
+
+	rooms := &privateRoom{}
+	h := NewRoomHandler(service.NewRoomService(rooms, nil))
+	h.SetAuthEnabled(true)
+
+	router := gin.New()
+	router.Use(middleware.SkipPaths([]string{"/rooms"}, middleware.OptionalAuth(keys, nil)))
+	router.GET("/rooms", h.ListRooms)
+	router.GET("/rooms/:roomID", h.GetRoom)
+	router.DELETE("/rooms/:roomID", identified, h.DeleteRoom)
+	router.PUT("/rooms/:roomID/members/:userID", identified, h.SetMember)
+
+	owner, err := keys.IssueToken(middleware.JWTClaims{UserID: "alice", Roles: []string{middleware.RoleUser}}, 1)
+	require.NoError(t, err)
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("anonymous cannot delete", func(t *testing.T) {
+		w := do(http.MethodDelete, "/rooms/"+testRoomID, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, rooms.deleted)
+	})
+
+	t.Run("anonymous cannot promote", func(t *testing.T) {
+		w := do(http.MethodPut, "/rooms/"+testRoomID+"/members/mallory", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("anonymous does not see private rooms", func(t *testing.T) {
+		w := do(http.MethodGet, "/rooms/"+testRoomID, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = do(http.MethodGet, "/rooms", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []bool{false}, rooms.includePrivate)
+	})
+
+	t.Run("owner deletes", func(t *testing.T) {
+		w := do(http.MethodDelete, "/rooms/"+testRoomID, owner)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{testRoomID}, rooms.deleted)
+	})
+}
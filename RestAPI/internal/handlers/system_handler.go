@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
@@ -103,3 +104,18 @@ func (h *SystemHandler) Root(c *gin.Context) {
 func MetricsHandler() gin.HandlerFunc {
 	return gin.WrapH(promhttp.Handler())
 }
+
+// JWKSHandler serves the public signing keys
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying access tokens issued by this server.
+// @Tags system
+// @Produce json
+// @Success 200 {object} middleware.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(keys *middleware.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 검증측이 캐시하도록 허용하되, 회전 후 새 키가 너무 늦게 퍼지지 않게 짧게 둔다.
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keys.JWKS())
+	}
+}
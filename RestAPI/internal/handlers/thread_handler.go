@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// ThreadHandler handles reply thread requests
+type ThreadHandler struct {
+	threadService *service.ThreadService
+	reactions     ReactionCounter
+	attachments   AttachmentLoader
+}
+
+// NewThreadHandler creates a new thread handler
+func NewThreadHandler(threadService *service.ThreadService) *ThreadHandler {
+	return &ThreadHandler{
+		threadService: threadService,
+	}
+}
+
+// SetReactions enables reaction counts in thread replies
+func (h *ThreadHandler) SetReactions(reactions ReactionCounter) {
+	h.reactions = reactions
+}
+
+// SetAttachments enables attachment metadata in thread replies
+func (h *ThreadHandler) SetAttachments(attachments AttachmentLoader) {
+	h.attachments = attachments
+}
+
+// ListReplies handles GET /messages/:messageID/thread
+// @Summary List thread replies
+// @Description List the replies of the thread a message belongs to, oldest first. Replies to replies belong to the same thread. Visible to whoever can read the message: the sender, the direct message recipient, room members, anyone for broadcasts, and admins.
+// @Tags messages
+// @Produce json
+// @Security BearerAuth
+// @Param messageID path string true "Message ID"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/messages/{messageID}/thread [get]
+func (h *ThreadHandler) ListReplies(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	replies, total, err := h.threadService.ListReplies(c.Request.Context(), c.GetString("user_id"), middleware.IsAdmin(c),
+		c.Param("messageID"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if !attachReactions(c, h.reactions, replies) || !loadAttachments(c, h.attachments, replies) {
+		return
+	}
+
+	response.Paginated(c, replies, total, params.Limit, params.Offset)
+}
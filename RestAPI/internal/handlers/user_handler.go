@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"database/sql"
+	"strings"
+
 	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
@@ -26,6 +31,15 @@ type CreateUserRequest struct {
 	UserID   string `json:"user_id" binding:"required"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	// Password 를 생략하면 /api/v1/auth/token 으로 로그인할 수 없는 사용자가 된다.
+	Password string `json:"password" binding:"omitempty,min=8,max=72"`
+}
+
+// UpdateProfileRequest represents a partial profile update.
+// 생략한 필드는 그대로 두고, 빈 문자열은 값을 지운다.
+type UpdateProfileRequest struct {
+	Username *string `json:"username"`
+	Email    *string `json:"email"`
 }
 
 // UpdateStatusRequest represents the request to update user status
@@ -33,6 +47,11 @@ type UpdateStatusRequest struct {
 	Status string `json:"status" binding:"required"`
 }
 
+// UpdateRoleRequest represents the request to change a user's role
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
 // CreateUser handles POST /users
 // @Summary Create user
 // @Description Create a new user.
@@ -56,18 +75,27 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.CreateUser(c.Request.Context(), req.UserID, req.Username, req.Email)
+	var user *repository.User
+	var err error
+	if req.Password != "" {
+		user, err = h.userService.CreateUserWithPassword(c.Request.Context(), req.UserID, req.Username, req.Email, req.Password)
+	} else {
+		user, err = h.userService.CreateUser(c.Request.Context(), req.UserID, req.Username, req.Email)
+	}
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
+	middleware.SetAuditTarget(c, user.UserID)
+	middleware.SetAuditChange(c, nil, user)
+
 	response.Created(c, "User created successfully", user)
 }
 
 // GetUser handles GET /users/:userID
 // @Summary Get user
-// @Description Retrieve a user by ID.
+// @Description Retrieve a user by ID. The email is only included for the user themselves and for administrators.
 // @Tags users
 // @Produce json
 // @Param userID path string true "User ID"
@@ -84,19 +112,64 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	response.OK(c, user)
+	response.OK(c, redactEmail(c, user))
+}
+
+// UpdateProfile handles PATCH /users/:userID
+// @Summary Update user profile
+// @Description Change the username and/or email. Omitted fields are left unchanged and an empty string clears the field. Usernames and emails are unique regardless of case. Non-admin callers can only update their own profile.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param profile body UpdateProfileRequest true "Profile fields to change"
+// @Success 200 {object} response.Response{data=repository.User}
+// @Failure 400 {object} response.Response "Validation failed; fields holds the reason per field"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response "Username or email already in use; fields names the field"
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID} [patch]
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	userID := c.Param("userID")
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if middleware.AuditEnabled(c) {
+		middleware.SetAuditChange(c, h.auditUserField(c, userID, "profile"), req)
+	}
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), userID, service.UpdateProfileInput{
+		Username: req.Username,
+		Email:    req.Email,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "User profile updated successfully", user)
 }
 
 // UpdateStatus handles PUT /users/:userID/status
 // @Summary Update user status
-// @Description Update a user's status.
+// @Description Update a user's status. Non-admin callers can only update their own status.
 // @Tags users
 // @Accept json
 // @Produce json
+// @Security BearerAuth
 // @Param userID path string true "User ID"
 // @Param status body UpdateStatusRequest true "Status update"
 // @Success 200 {object} response.Response
 // @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/users/{userID}/status [put]
 func (h *UserHandler) UpdateStatus(c *gin.Context) {
@@ -108,6 +181,10 @@ func (h *UserHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
+	if middleware.AuditEnabled(c) {
+		middleware.SetAuditChange(c, h.auditUserField(c, userID, "status"), gin.H{"status": req.Status})
+	}
+
 	if err := h.userService.UpdateUserStatus(c.Request.Context(), userID, req.Status); err != nil {
 		response.Error(c, err)
 		return
@@ -116,67 +193,192 @@ func (h *UserHandler) UpdateStatus(c *gin.Context) {
 	response.OKWithMessage(c, "User status updated successfully", nil)
 }
 
+// UpdateRole handles PUT /users/:userID/role
+// @Summary Update user role
+// @Description Change a user's role (user/admin). The user's existing tokens are revoked, so they sign in again with the new role.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Param role body UpdateRoleRequest true "Role update"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/users/{userID}/role [put]
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	userID := c.Param("userID")
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	if middleware.AuditEnabled(c) {
+		middleware.SetAuditChange(c, h.auditUserField(c, userID, "role"), gin.H{"role": req.Role})
+	}
+
+	if err := h.userService.UpdateUserRole(c.Request.Context(), userID, req.Role); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"user_id":    userID,
+		"role":       req.Role,
+		"changed_by": c.GetString("user_id"),
+	}).Info("User role changed")
+
+	response.OKWithMessage(c, "User role updated successfully", nil)
+}
+
 // ListUsers handles GET /users
 // @Summary List users
-// @Description Retrieve users with pagination.
+// @Description Search users with pagination. Text filters match anywhere in the value, case-insensitively; time ranges include the start and exclude the end. Only administrators can search or sort by email; other callers see emails of their own account only.
 // @Tags users
 // @Produce json
+// @Param q query string false "Partial match on username, or on username or email for administrators"
+// @Param username query string false "Partial match on username"
+// @Param email query string false "Partial match on email (administrators only)"
+// @Param status query string false "Status (online, offline, away)"
+// @Param last_seen_after query string false "Last seen at or after (RFC 3339)"
+// @Param last_seen_before query string false "Last seen before (RFC 3339)"
+// @Param created_after query string false "Created at or after (RFC 3339)"
+// @Param created_before query string false "Created before (RFC 3339)"
+// @Param sort query string false "Sort field (created_at, last_seen, username, email, user_id)" default(created_at)
+// @Param order query string false "Sort order (asc, desc)" default(desc)
 // @Param limit query int false "Pagination limit"
 // @Param offset query int false "Pagination offset"
 // @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 400 {object} response.Response
+// @Failure 403 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/users [get]
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	params := pagination.ParseFromQuery(c)
 
-	users, total, err := h.userService.ListUsers(c.Request.Context(), params.Limit, params.Offset)
+	filter := repository.UserFilter{
+		Query:    strings.TrimSpace(c.Query("q")),
+		Username: strings.TrimSpace(c.Query("username")),
+		Email:    strings.TrimSpace(c.Query("email")),
+		Status:   c.Query("status"),
+	}
+
+	var ok bool
+	if filter.LastSeenAfter, ok = parseTimeQuery(c, "last_seen_after"); !ok {
+		return
+	}
+	if filter.LastSeenBefore, ok = parseTimeQuery(c, "last_seen_before"); !ok {
+		return
+	}
+	if filter.CreatedAfter, ok = parseTimeQuery(c, "created_after"); !ok {
+		return
+	}
+	if filter.CreatedBefore, ok = parseTimeQuery(c, "created_before"); !ok {
+		return
+	}
+
+	sort := repository.UserSort{Field: c.DefaultQuery("sort", "created_at")}
+	switch c.DefaultQuery("order", "desc") {
+	case "desc":
+		sort.Desc = true
+	case "asc":
+	default:
+		response.ValidationError(c, "order must be asc or desc")
+		return
+	}
+
+	// 이메일로 검색·정렬할 수 있으면 응답에서 가려도 주소를 한 글자씩 알아낼 수 있다.
+	if !canSearchEmails(c) {
+		if filter.Email != "" || sort.Field == "email" {
+			response.Forbidden(c, "Only administrators can search or sort users by email")
+			return
+		}
+		filter.UsernameOnly = true
+	}
+
+	users, total, err := h.userService.ListUsers(c.Request.Context(), filter, sort, params.Limit, params.Offset)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.Paginated(c, users, total, params.Limit, params.Offset)
+	response.Paginated(c, redactEmails(c, users), total, params.Limit, params.Offset)
 }
 
 // GetOnlineUsers handles GET /users/online
 // @Summary List online users
-// @Description Retrieve online users with pagination.
+// @Description Retrieve online users with pagination, most recent heartbeat first. With Redis enabled, users are online while their heartbeat is fresh; otherwise users.status is used.
 // @Tags users
 // @Produce json
 // @Param limit query int false "Pagination limit"
 // @Param offset query int false "Pagination offset"
-// @Success 200 {object} response.Response
+// @Success 200 {object} response.Response{data=response.PaginatedData}
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
 // @Router /api/v1/users/online [get]
 func (h *UserHandler) GetOnlineUsers(c *gin.Context) {
 	params := pagination.ParseFromQuery(c)
 
-	users, err := h.userService.GetOnlineUsers(c.Request.Context(), params.Limit, params.Offset)
+	users, total, err := h.userService.GetOnlineUsers(c.Request.Context(), params.Limit, params.Offset)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	// Online users don't have a total count in current implementation
-	response.OK(c, map[string]interface{}{
-		"users":  users,
-		"limit":  params.Limit,
-		"offset": params.Offset,
-	})
+	response.Paginated(c, redactEmails(c, users), total, params.Limit, params.Offset)
+}
+
+// Heartbeat handles POST /users/:userID/heartbeat
+// @Summary Send presence heartbeat
+// @Description Keep the user online for another presence TTL. Without heartbeats the user goes offline once the TTL expires.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param userID path string true "User ID"
+// @Success 200 {object} response.Response{data=service.PresenceState}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /api/v1/users/{userID}/heartbeat [post]
+func (h *UserHandler) Heartbeat(c *gin.Context) {
+	state, err := h.userService.Heartbeat(c.Request.Context(), c.Param("userID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, state)
 }
 
 // DeleteUser handles DELETE /users/:userID
 // @Summary Delete user
-// @Description Delete a user by ID.
+// @Description Soft-delete a user. The user disappears from every endpoint, API keys and tokens are revoked, and messages are kept until an erasure is requested.
 // @Tags users
 // @Produce json
+// @Security BearerAuth
 // @Param userID path string true "User ID"
 // @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /api/v1/users/{userID} [delete]
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	userID := c.Param("userID")
 
+	// 삭제 후에는 누구였는지 되찾을 수 없으므로 변경 전 레코드 전체를 남긴다.
+	if middleware.AuditEnabled(c) {
+		if user, err := h.userService.GetUser(c.Request.Context(), userID); err == nil {
+			middleware.SetAuditChange(c, user, nil)
+		}
+	}
+
 	if err := h.userService.DeleteUser(c.Request.Context(), userID); err != nil {
 		response.Error(c, err)
 		return
@@ -184,3 +386,50 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 
 	response.OKWithMessage(c, "User deleted successfully", nil)
 }
+
+// auditUserField reads one field of the user before a change for the audit log.
+// GetUser 는 캐시를 먼저 보므로 싸다. 조회에 실패하면 변경 전 값 없이 기록한다.
+func (h *UserHandler) auditUserField(c *gin.Context, userID, field string) interface{} {
+	user, err := h.userService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		return nil
+	}
+
+	switch field {
+	case "status":
+		return gin.H{"status": user.Status}
+	case "role":
+		return gin.H{"role": user.Role}
+	case "profile":
+		return gin.H{"username": user.Username.String, "email": user.Email.String}
+	default:
+		return nil
+	}
+}
+
+// canSearchEmails reports whether the caller may search every user's email.
+// 인증이 꺼져 있으면 호출자를 알 수 없으므로 지금처럼 모두 허용한다.
+func canSearchEmails(c *gin.Context) bool {
+	return c.GetString("user_id") == "" || middleware.IsAdmin(c)
+}
+
+// redactEmail returns user without its email unless the caller is that user or may search emails.
+// 캐시에서 온 객체일 수 있으므로 원본 대신 복사본을 고친다.
+func redactEmail(c *gin.Context, user *repository.User) *repository.User {
+	if user == nil || !user.Email.Valid || user.UserID == c.GetString("user_id") || canSearchEmails(c) {
+		return user
+	}
+
+	redacted := *user
+	redacted.Email = sql.NullString{}
+	return &redacted
+}
+
+// redactEmails applies redactEmail to every user
+func redactEmails(c *gin.Context, users []*repository.User) []*repository.User {
+	redacted := make([]*repository.User, len(users))
+	for i, user := range users {
+		redacted[i] = redactEmail(c, user)
+	}
+	return redacted
+}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedUsers serves one user from every lookup and search and records the last search filter.
+// 테스트에서 쓰지 않는 메서드는 임베드한 nil 인터페이스로 남겨 둔다.
+type fixedUsers struct {
+	repository.UserRepository
+	user     *repository.User
+	searched *repository.UserFilter
+}
+
+func (r *fixedUsers) GetByUserID(ctx context.Context, userID string) (*repository.User, error) {
+	return r.user, nil
+}
+
+func (r *fixedUsers) Search(ctx context.Context, filter repository.UserFilter, sort repository.UserSort, limit, offset int) ([]*repository.User, error) {
+	r.searched = &filter
+	return []*repository.User{r.user}, nil
+}
+
+func (r *fixedUsers) CountSearch(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	return 1, nil
+}
+
+func newFixedUsers() *fixedUsers {
+	return &fixedUsers{user: &repository.User{
+		UserID: "alice",
+		Email:  sql.NullString{String: "alice@example.com", Valid: true},
+	}}
+}
+
+// getAs calls the user routes with callerID as the authenticated user; an empty callerID means auth is off
+func getAs(h *UserHandler, callerID string, roles []string, target string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if callerID != "" {
+			c.Set("user_id", callerID)
+			c.Set(middleware.RolesKey, roles)
+		}
+	})
+	router.GET("/users", h.ListUsers)
+	router.GET("/users/:userID", h.GetUser)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestGetUser_EmailVisibility(t *testing.T) {
+	tests := []struct {
+		name      string
+		callerID  string
+		roles     []string
+		wantEmail bool
+	}{
+		{"other user", "bob", []string{middleware.RoleUser}, false},
+		{"the user themselves", "alice", []string{middleware.RoleUser}, true},
+		{"admin", "root", []string{middleware.RoleAdmin}, true},
+		{"auth disabled", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := newFixedUsers()
+			w := getAs(NewUserHandler(service.NewUserService(users, nil)), tt.callerID, tt.roles, "/users/alice")
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantEmail, strings.Contains(w.Body.String(), "alice@example.com"))
+			assert.True(t, users.user.Email.Valid, "the cached user must not be modified")
+		})
+	}
+}
+
+func TestListUsers_EmailSearchAdminOnly(t *testing.T) {
+	t.Run("non-admin cannot filter by email", func(t *testing.T) {
+		users := newFixedUsers()
+		w := getAs(NewUserHandler(service.NewUserService(users, nil)), "bob", []string{middleware.RoleUser}, "/users?email=alice")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Nil(t, users.searched)
+	})
+
+	t.Run("non-admin cannot sort by email", func(t *testing.T) {
+		users := newFixedUsers()
+		w := getAs(NewUserHandler(service.NewUserService(users, nil)), "bob", []string{middleware.RoleUser}, "/users?sort=email")
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Nil(t, users.searched)
+	})
+
+	t.Run("non-admin query matches usernames only", func(t *testing.T) {
+		users := newFixedUsers()
+		w := getAs(NewUserHandler(service.NewUserService(users, nil)), "bob", []string{middleware.RoleUser}, "/users?q=example.com")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.NotNil(t, users.searched) {
+			assert.True(t, users.searched.UsernameOnly)
+		}
+		assert.NotContains(t, w.Body.String(), "alice@example.com")
+	})
+
+	t.Run("admin searches emails", func(t *testing.T) {
+		users := newFixedUsers()
+		w := getAs(NewUserHandler(service.NewUserService(users, nil)), "root", []string{middleware.RoleAdmin}, "/users?q=example.com&sort=email")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.NotNil(t, users.searched) {
+			assert.False(t, users.searched.UsernameOnly)
+		}
+		assert.Contains(t, w.Body.String(), "alice@example.com")
+	})
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/service"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/pagination"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/response"
+)
+
+// WebhookHandler handles webhook subscription management requests
+type WebhookHandler struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest represents the request to register a webhook endpoint
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required,max=2048"`
+	EventTypes  []string `json:"event_types"`
+	Secret      string   `json:"secret" binding:"max=255"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// UpdateWebhookRequest represents a partial webhook update; omitted fields are left as they are
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url" binding:"omitempty,max=2048"`
+	EventTypes  *[]string `json:"event_types"`
+	Secret      *string   `json:"secret" binding:"omitempty,max=255"`
+	Description *string   `json:"description" binding:"omitempty,max=255"`
+	Active      *bool     `json:"active"`
+}
+
+// CreateWebhook handles POST /webhooks
+// @Summary Register a webhook
+// @Description Register an endpoint that receives events as signed POST requests. event_types filters the events (message.sent, message.failed, user.created, user.deleted); empty means every event. Without a secret one is generated; it is returned only in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body CreateWebhookRequest true "Webhook to register"
+// @Success 201 {object} response.Response{data=service.IssuedWebhook}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	issued, err := h.webhookService.CreateSubscription(c.Request.Context(), service.WebhookInput{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		CreatedBy:   c.GetString("user_id"),
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// 서명 비밀값이 감사 로그에 남지 않도록 구독 정보만 기록한다.
+	middleware.SetAuditTarget(c, issued.ID)
+	middleware.SetAuditChange(c, nil, issued.WebhookSubscription)
+
+	response.Created(c, "Webhook registered - store the secret now, it cannot be shown again", issued)
+}
+
+// ListWebhooks handles GET /webhooks
+// @Summary List webhooks
+// @Description List webhook subscriptions, newest first. Secrets are not included.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	subs, total, err := h.webhookService.ListSubscriptions(c.Request.Context(), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, subs, total, params.Limit, params.Offset)
+}
+
+// GetWebhook handles GET /webhooks/:webhookID
+// @Summary Get a webhook
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookID path string true "Webhook ID"
+// @Success 200 {object} response.Response{data=repository.WebhookSubscription}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks/{webhookID} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	sub, err := h.webhookService.GetSubscription(c.Request.Context(), c.Param("webhookID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OK(c, sub)
+}
+
+// UpdateWebhook handles PATCH /webhooks/:webhookID
+// @Summary Update a webhook
+// @Description Change the URL, event types, secret, description or active flag. An inactive webhook gets no new events; deliveries already queued are still sent.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhookID path string true "Webhook ID"
+// @Param webhook body UpdateWebhookRequest true "Fields to change"
+// @Success 200 {object} response.Response{data=repository.WebhookSubscription}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks/{webhookID} [patch]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var req UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ValidationError(c, "Invalid request payload: "+err.Error())
+		return
+	}
+
+	sub, err := h.webhookService.UpdateSubscription(c.Request.Context(), c.Param("webhookID"), service.WebhookUpdate{
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Secret:      req.Secret,
+		Description: req.Description,
+		Active:      req.Active,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	middleware.SetAuditChange(c, nil, sub)
+	response.OKWithMessage(c, "Webhook updated", sub)
+}
+
+// DeleteWebhook handles DELETE /webhooks/:webhookID
+// @Summary Delete a webhook
+// @Description Delete a webhook subscription together with its delivery log; queued deliveries are dropped.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookID path string true "Webhook ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks/{webhookID} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), c.Param("webhookID")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.OKWithMessage(c, "Webhook deleted", nil)
+}
+
+// ListDeliveries handles GET /webhooks/:webhookID/deliveries
+// @Summary List webhook deliveries
+// @Description List the delivery log of a webhook, newest first: status, attempts, the last response code and error, and the next attempt time.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookID path string true "Webhook ID"
+// @Param status query string false "pending, succeeded or dead; all when omitted"
+// @Param limit query int false "Pagination limit"
+// @Param offset query int false "Pagination offset"
+// @Success 200 {object} response.Response{data=response.PaginatedData}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks/{webhookID}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	params := pagination.ParseFromQuery(c)
+
+	deliveries, total, err := h.webhookService.ListDeliveries(c.Request.Context(), c.Param("webhookID"), c.Query("status"), params.Limit, params.Offset)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, deliveries, total, params.Limit, params.Offset)
+}
+
+// RedeliverDelivery handles POST /webhooks/:webhookID/deliveries/:deliveryID/redeliver
+// @Summary Redeliver a dead delivery
+// @Description Queue a delivery that ran out of attempts again, with a fresh set of attempts. The payload is sent as it was recorded.
+// @Tags webhooks
+// @Produce json
+// @Security BearerAuth
+// @Param webhookID path string true "Webhook ID"
+// @Param deliveryID path string true "Delivery ID"
+// @Success 200 {object} response.Response{data=repository.WebhookDelivery}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api/v1/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver [post]
+func (h *WebhookHandler) RedeliverDelivery(c *gin.Context) {
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), c.Param("webhookID"), c.Param("deliveryID"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	middleware.SetAuditChange(c, nil, gin.H{"delivery_id": delivery.ID, "event_type": delivery.EventType})
+	response.OKWithMessage(c, "Delivery queued", delivery)
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyIDKey is the context key for the public identifier of the authenticating API key
+const APIKeyIDKey = "api_key_id"
+
+// APIKeyPrincipal is the identity behind a valid API key
+type APIKeyPrincipal struct {
+	KeyID       string
+	OwnerUserID string
+	Scopes      []string
+}
+
+// APIKeyAuthenticator resolves an API key.
+// 키가 없거나 만료·폐기됐으면 (nil, nil), 조회 자체가 실패하면 error 를 돌려준다.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*APIKeyPrincipal, error)
+}
+
+// APIKeyMiddleware requires a valid X-API-Key header
+func APIKeyMiddleware(authenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success":   false,
+				"error":     "Missing API key",
+				"code":      "UNAUTHORIZED",
+				"timestamp": time.Now().Unix(),
+			})
+			c.Abort()
+			return
+		}
+
+		if !authenticateAPIKey(c, authenticator, key) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// OptionalAPIKey identifies callers that send X-API-Key and passes requests without one.
+// OptionalAuth 와 달리 잘못된 키는 거부한다 — 키를 보낸 데몬이 익명으로 처리되면 원인을 찾기 어렵다.
+// bearer token 으로 이미 식별된 요청에서는 키를 보지 않는다.
+func OptionalAPIKey(authenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" || authenticated(c) {
+			c.Next()
+			return
+		}
+
+		if !authenticateAPIKey(c, authenticator, key) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey stores the key's identity or aborts the request
+func authenticateAPIKey(c *gin.Context, authenticator APIKeyAuthenticator, key string) bool {
+	principal, err := authenticator.AuthenticateAPIKey(c.Request.Context(), key)
+	if err != nil {
+		logger.Errorf("API key lookup failed: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success":   false,
+			"error":     "Unable to verify API key",
+			"code":      "SERVICE_UNAVAILABLE",
+			"timestamp": time.Now().Unix(),
+		})
+		c.Abort()
+		return false
+	}
+
+	if principal == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success":   false,
+			"error":     "Invalid or expired API key",
+			"code":      "UNAUTHORIZED",
+			"timestamp": time.Now().Unix(),
+		})
+		c.Abort()
+		return false
+	}
+
+	// 키는 소유자 이름으로 동작하지만 역할은 물려받지 않는다 — 권한은 발급 시 지정한 scope 로만 정해진다.
+	c.Set("user_id", principal.OwnerUserID)
+	c.Set(RolesKey, []string(nil))
+	c.Set(ScopesKey, principal.Scopes)
+	c.Set(APIKeyIDKey, principal.KeyID)
+	return true
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubAPIKeys accepts the keys in the map or fails every lookup when err is set
+type stubAPIKeys struct {
+	keys map[string]*APIKeyPrincipal
+	err  error
+}
+
+func (s *stubAPIKeys) AuthenticateAPIKey(_ context.Context, key string) (*APIKeyPrincipal, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.keys[key], nil
+}
+
+func TestOptionalAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	authenticator := &stubAPIKeys{keys: map[string]*APIKeyPrincipal{
+		"good-key": {KeyID: "k1", OwnerUserID: "publisher", Scopes: []string{ScopeMessagesWrite}},
+	}}
+
+	router := gin.New()
+	router.Use(OptionalAuth(NewHMACKeySet(testJWTSecret), nil))
+	router.Use(OptionalAPIKey(authenticator))
+	router.POST("/send", RequireScope(ScopeMessagesWrite), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString("user_id")+"/"+c.GetString(APIKeyIDKey))
+	})
+	router.DELETE("/admin", RequireRole(RoleAdmin), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(method, path, key, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("valid key", func(t *testing.T) {
+		w := do(http.MethodPost, "/send", "good-key", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "publisher/k1", w.Body.String())
+	})
+
+	t.Run("invalid key is rejected", func(t *testing.T) {
+		w := do(http.MethodPost, "/send", "bad-key", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("no key", func(t *testing.T) {
+		w := do(http.MethodPost, "/send", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("key does not grant roles", func(t *testing.T) {
+		w := do(http.MethodDelete, "/admin", "good-key", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("bearer token takes precedence", func(t *testing.T) {
+		token := issueRoleToken(t, "alice", RoleUser)
+		w := do(http.MethodPost, "/send", "bad-key", token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "alice/", w.Body.String())
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		failing := gin.New()
+		failing.Use(OptionalAPIKey(&stubAPIKeys{err: errors.New("db down")}))
+		failing.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(APIKeyHeader, "any")
+		w := httptest.NewRecorder()
+		failing.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestAPIKeyMiddleware_RequiresKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/", APIKeyMiddleware(&stubAPIKeys{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
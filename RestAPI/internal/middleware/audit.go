@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// auditKey is the context key for the audit entry of the current request
+const auditKey = "audit_entry"
+
+// auditWriteTimeout bounds the audit write when the recorder cannot queue the entry and writes it in the request
+const auditWriteTimeout = 3 * time.Second
+
+// Actor types
+const (
+	ActorUser      = "user"
+	ActorAPIKey    = "api_key"
+	ActorAnonymous = "anonymous"
+)
+
+// Audit outcomes
+const (
+	AuditSuccess = "success"
+	AuditDenied  = "denied"
+	AuditFailure = "failure"
+)
+
+// AuditEntry describes one mutating operation
+type AuditEntry struct {
+	OccurredAt time.Time
+	ActorID    string
+	ActorType  string
+	APIKeyID   string
+	Action     string
+	TargetType string
+	TargetID   string
+	Before     interface{}
+	After      interface{}
+	RequestID  string
+	ClientIP   string
+	Outcome    string
+	StatusCode int
+}
+
+// AuditRecorder persists audit entries
+type AuditRecorder interface {
+	RecordAudit(ctx context.Context, entry *AuditEntry) error
+}
+
+// Audit records the route's outcome under action once the handler has finished.
+// 권한 검사보다 앞에 둬야 거부된 시도(denied)도 남는다. targetParam 이 비어 있으면 핸들러가 SetAuditTarget 으로 채운다.
+// 기록 실패는 요청 결과를 바꾸지 않는다 — 이미 응답이 나간 뒤이고, 로그로만 남긴다.
+// recorder 는 보통 큐에 넣고 바로 돌아오므로 요청이 DB 쓰기를 기다리지 않는다.
+func Audit(recorder AuditRecorder, action, targetType, targetParam string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		entry := &AuditEntry{
+			OccurredAt: time.Now(),
+			Action:     action,
+			TargetType: targetType,
+			RequestID:  GetRequestID(c),
+			ClientIP:   c.ClientIP(),
+		}
+		if targetParam != "" {
+			entry.TargetID = c.Param(targetParam)
+		}
+		c.Set(auditKey, entry)
+
+		c.Next()
+
+		// 신원은 Next 이후에 읽는다 — 그룹 미들웨어가 인증을 마친 뒤여야 한다.
+		entry.ActorID = c.GetString("user_id")
+		entry.APIKeyID = c.GetString(APIKeyIDKey)
+		switch {
+		case entry.APIKeyID != "":
+			entry.ActorType = ActorAPIKey
+		case entry.ActorID != "":
+			entry.ActorType = ActorUser
+		default:
+			entry.ActorType = ActorAnonymous
+		}
+
+		entry.StatusCode = c.Writer.Status()
+		entry.Outcome = auditOutcome(entry.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(c.Request.Context()), auditWriteTimeout)
+		defer cancel()
+
+		if err := recorder.RecordAudit(ctx, entry); err != nil {
+			logger.Errorf("Failed to record audit entry %s %s/%s: %v", action, entry.TargetType, entry.TargetID, err)
+		}
+	}
+}
+
+// AuditEnabled reports whether the request is being audited.
+// 변경 전 값을 읽는 데 조회가 필요하면 감사 대상일 때만 하도록 핸들러가 확인한다.
+func AuditEnabled(c *gin.Context) bool {
+	_, ok := c.Get(auditKey)
+	return ok
+}
+
+// SetAuditTarget sets the target ID when it is not a path parameter
+func SetAuditTarget(c *gin.Context, targetID string) {
+	if entry, ok := auditEntry(c); ok {
+		entry.TargetID = targetID
+	}
+}
+
+// SetAuditChange attaches the values before and after the change; either may be nil
+func SetAuditChange(c *gin.Context, before, after interface{}) {
+	if entry, ok := auditEntry(c); ok {
+		entry.Before = before
+		entry.After = after
+	}
+}
+
+func auditEntry(c *gin.Context) (*AuditEntry, bool) {
+	value, exists := c.Get(auditKey)
+	if !exists {
+		return nil, false
+	}
+	entry, ok := value.(*AuditEntry)
+	return entry, ok
+}
+
+// auditOutcome classifies a response status
+func auditOutcome(status int) string {
+	switch {
+	case status < 400:
+		return AuditSuccess
+	case status == 401 || status == 403:
+		return AuditDenied
+	default:
+		return AuditFailure
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAuditRecorder keeps recorded entries in memory
+type stubAuditRecorder struct {
+	entries []*AuditEntry
+	err     error
+}
+
+func (s *stubAuditRecorder) RecordAudit(_ context.Context, entry *AuditEntry) error {
+	s.entries = append(s.entries, entry)
+	return s.err
+}
+
+func TestAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := &stubAuditRecorder{}
+	apiKeys := &stubAPIKeys{keys: map[string]*APIKeyPrincipal{
+		"good-key": {KeyID: "k1", OwnerUserID: "publisher", Scopes: []string{ScopeMessagesWrite}},
+	}}
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(OptionalAuth(NewHMACKeySet(testJWTSecret), nil))
+	router.Use(OptionalAPIKey(apiKeys))
+	router.PUT("/users/:userID/role", Audit(recorder, "user.role.update", "user", "userID"), RequireRole(RoleAdmin),
+		func(c *gin.Context) {
+			SetAuditChange(c, map[string]string{"role": "user"}, map[string]string{"role": "admin"})
+			c.Status(http.StatusOK)
+		})
+	router.POST("/keys", Audit(recorder, "api_key.issue", "api_key", ""), func(c *gin.Context) {
+		SetAuditTarget(c, "new-key")
+		c.Status(http.StatusBadRequest)
+	})
+
+	do := func(method, path string, header http.Header) *AuditEntry {
+		t.Helper()
+		recorder.entries = nil
+
+		req := httptest.NewRequest(method, path, nil)
+		req.Header = header
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		require.Len(t, recorder.entries, 1)
+		return recorder.entries[0]
+	}
+
+	t.Run("admin change", func(t *testing.T) {
+		token := issueRoleToken(t, "root", RoleAdmin)
+		entry := do(http.MethodPut, "/users/alice/role", http.Header{"Authorization": {"Bearer " + token}})
+
+		assert.Equal(t, "root", entry.ActorID)
+		assert.Equal(t, ActorUser, entry.ActorType)
+		assert.Equal(t, "alice", entry.TargetID)
+		assert.Equal(t, AuditSuccess, entry.Outcome)
+		assert.Equal(t, map[string]string{"role": "admin"}, entry.After)
+		assert.NotEmpty(t, entry.RequestID)
+	})
+
+	t.Run("denied attempt is recorded", func(t *testing.T) {
+		token := issueRoleToken(t, "alice", RoleUser)
+		entry := do(http.MethodPut, "/users/alice/role", http.Header{"Authorization": {"Bearer " + token}})
+
+		assert.Equal(t, "alice", entry.ActorID)
+		assert.Equal(t, AuditDenied, entry.Outcome)
+		assert.Equal(t, http.StatusForbidden, entry.StatusCode)
+		assert.Nil(t, entry.After)
+	})
+
+	t.Run("anonymous caller", func(t *testing.T) {
+		entry := do(http.MethodPut, "/users/alice/role", http.Header{})
+
+		assert.Equal(t, ActorAnonymous, entry.ActorType)
+		assert.Equal(t, AuditDenied, entry.Outcome)
+	})
+
+	t.Run("API key caller and handler-set target", func(t *testing.T) {
+		header := http.Header{}
+		header.Set(APIKeyHeader, "good-key")
+		entry := do(http.MethodPost, "/keys", header)
+
+		assert.Equal(t, ActorAPIKey, entry.ActorType)
+		assert.Equal(t, "publisher", entry.ActorID)
+		assert.Equal(t, "k1", entry.APIKeyID)
+		assert.Equal(t, "new-key", entry.TargetID)
+		assert.Equal(t, AuditFailure, entry.Outcome)
+	})
+
+	t.Run("recorder failure does not change the response", func(t *testing.T) {
+		recorder.err = errors.New("connection refused")
+		defer func() { recorder.err = nil }()
+
+		req := httptest.NewRequest(http.MethodPost, "/keys", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
@@ -10,15 +11,28 @@ import (
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
 )
 
+// ClaimsKey is the context key for the validated JWT claims
+const ClaimsKey = "jwt_claims"
+
 // JWTClaims represents the JWT claims
 type JWTClaims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
+	UserID   string   `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"`
+	Scopes   []string `json:"scopes,omitempty"`
+	// IssuedAtMs is the issue time in unix milliseconds; iat only has second resolution
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// AuthMiddleware creates a JWT authentication middleware
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// RevocationChecker reports whether a validated token has been revoked
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, claims *JWTClaims) (bool, error)
+}
+
+// AuthMiddleware creates a JWT authentication middleware.
+// revocation 이 nil 이면 서명·만료만 검사한다. 조회가 실패하면 폐기된 토큰을 통과시키지 않도록 503 으로 거부한다.
+func AuthMiddleware(keys *KeySet, revocation RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -49,14 +63,7 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		tokenString := parts[1]
 
 		// Parse and validate token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
-
+		claims, err := keys.ValidateToken(tokenString)
 		if err != nil {
 			logger.Warnf("JWT validation failed: %v", err)
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -69,95 +76,103 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		// Extract claims
-		claims, ok := token.Claims.(*JWTClaims)
-		if !ok || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success":   false,
-				"error":     "Invalid token claims",
-				"code":      "UNAUTHORIZED",
-				"timestamp": time.Now().Unix(),
-			})
-			c.Abort()
-			return
+		if revocation != nil {
+			revoked, err := revocation.IsRevoked(c.Request.Context(), claims)
+			if err != nil {
+				logger.Errorf("Token revocation check failed: %v", err)
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"success":   false,
+					"error":     "Unable to verify token",
+					"code":      "SERVICE_UNAVAILABLE",
+					"timestamp": time.Now().Unix(),
+				})
+				c.Abort()
+				return
+			}
+
+			if revoked {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"success":   false,
+					"error":     "Token has been revoked",
+					"code":      "UNAUTHORIZED",
+					"timestamp": time.Now().Unix(),
+				})
+				c.Abort()
+				return
+			}
 		}
 
 		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
+		setClaims(c, claims)
 
 		c.Next()
 	}
 }
 
-// OptionalAuth is an authentication middleware that doesn't block if token is missing
-func OptionalAuth(jwtSecret string) gin.HandlerFunc {
+// OptionalAuth is an authentication middleware that doesn't block if token is missing.
+// 폐기됐거나 폐기 여부를 확인할 수 없는 토큰은 미인증 요청으로 취급한다.
+func OptionalAuth(keys *KeySet, revocation RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.Next()
-			return
-		}
-
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.Next()
-			return
-		}
-
-		tokenString := parts[1]
+		identifyBearer(c, keys, revocation)
+		c.Next()
+	}
+}
 
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
-		})
+// Identify identifies the caller like OptionalAuth followed by OptionalAPIKey, without continuing the chain.
+// apiKeys 가 nil 이면 API 키는 보지 않는다. 잘못된 API 키로 요청이 중단되면 false 를 돌려준다.
+func Identify(keys *KeySet, revocation RevocationChecker, apiKeys APIKeyAuthenticator) func(c *gin.Context) bool {
+	return func(c *gin.Context) bool {
+		identifyBearer(c, keys, revocation)
 
-		if err == nil {
-			if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-				c.Set("user_id", claims.UserID)
-				c.Set("username", claims.Username)
-			}
+		if key := c.GetHeader(APIKeyHeader); key != "" && apiKeys != nil && !authenticated(c) {
+			return authenticateAPIKey(c, apiKeys, key)
 		}
-
-		c.Next()
+		return true
 	}
 }
 
-// GenerateToken generates a new JWT token
-func GenerateToken(userID, username, jwtSecret string, expirationHours int) (string, error) {
-	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expirationHours) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+// identifyBearer stores the identity of a valid, unrevoked bearer token and leaves other requests anonymous
+func identifyBearer(c *gin.Context, keys *KeySet, revocation RevocationChecker) {
+	parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(jwtSecret))
+	if claims, err := keys.ValidateToken(parts[1]); err == nil && !isRevoked(c, revocation, claims) {
+		setClaims(c, claims)
+	}
 }
 
-// ValidateToken validates a JWT token and returns claims
-func ValidateToken(tokenString, jwtSecret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(jwtSecret), nil
-	})
+// isRevoked treats a failed revocation lookup as revoked
+func isRevoked(c *gin.Context, revocation RevocationChecker, claims *JWTClaims) bool {
+	if revocation == nil {
+		return false
+	}
 
+	revoked, err := revocation.IsRevoked(c.Request.Context(), claims)
 	if err != nil {
-		return nil, err
+		logger.Warnf("Token revocation check failed: %v", err)
+		return true
 	}
+	return revoked
+}
+
+// setClaims stores the authenticated identity in the request context
+func setClaims(c *gin.Context, claims *JWTClaims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set(RolesKey, claims.Roles)
+	c.Set(ScopesKey, claims.Scopes)
+	c.Set(ClaimsKey, claims)
+}
 
-	claims, ok := token.Claims.(*JWTClaims)
-	if !ok || !token.Valid {
-		return nil, jwt.ErrTokenInvalidClaims
+// GetClaims returns the claims stored by AuthMiddleware or OptionalAuth
+func GetClaims(c *gin.Context) (*JWTClaims, bool) {
+	value, exists := c.Get(ClaimsKey)
+	if !exists {
+		return nil, false
 	}
 
-	return claims, nil
+	claims, ok := value.(*JWTClaims)
+	return claims, ok
 }
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret-key-that-is-at-least-32-chars"
+
+// stubRevocation revokes the listed token IDs or fails every lookup when err is set
+type stubRevocation struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (s *stubRevocation) IsRevoked(_ context.Context, claims *JWTClaims) (bool, error) {
+	if s.err != nil {
+		return false, s.err
+	}
+	return s.revoked[claims.ID], nil
+}
+
+func setupAuthRouter(revocation RevocationChecker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/protected", AuthMiddleware(NewHMACKeySet(testJWTSecret), revocation), func(c *gin.Context) {
+		claims, ok := GetClaims(c)
+		if !ok {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.String(http.StatusOK, claims.UserID)
+	})
+
+	return router
+}
+
+func doAuthRequest(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuthMiddleware_Revocation(t *testing.T) {
+	token, err := GenerateToken("alice", "Alice", testJWTSecret, 1)
+	require.NoError(t, err)
+
+	claims, err := ValidateToken(token, testJWTSecret)
+	require.NoError(t, err)
+	require.NotEmpty(t, claims.ID)
+
+	t.Run("valid token", func(t *testing.T) {
+		w := doAuthRequest(setupAuthRouter(&stubRevocation{}), token)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "alice", w.Body.String())
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		revocation := &stubRevocation{revoked: map[string]bool{claims.ID: true}}
+		w := doAuthRequest(setupAuthRouter(revocation), token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("revocation lookup fails closed", func(t *testing.T) {
+		w := doAuthRequest(setupAuthRouter(&stubRevocation{err: errors.New("redis down")}), token)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("no revocation checker", func(t *testing.T) {
+		w := doAuthRequest(setupAuthRouter(nil), token)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
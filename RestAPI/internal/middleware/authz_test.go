@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuthzRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	router := gin.New()
+	router.Use(OptionalAuth(NewHMACKeySet(testJWTSecret), nil))
+	router.DELETE("/admin", RequireRole(RoleAdmin), ok)
+	router.GET("/scoped", RequireScope(ScopeMessagesRead, ScopeMessagesWrite), ok)
+	router.GET("/users/:userID/messages", RequireSelfOrRole("userID", RoleAdmin), ok)
+
+	return router
+}
+
+func issueRoleToken(t *testing.T, userID, role string) string {
+	t.Helper()
+
+	claims := JWTClaims{UserID: userID}
+	if role != "" {
+		claims.Roles = []string{role}
+		claims.Scopes = ScopesForRoles(role)
+	}
+
+	token, err := NewHMACKeySet(testJWTSecret).IssueToken(claims, 1)
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthorization(t *testing.T) {
+	router := setupAuthzRouter(t)
+
+	admin := issueRoleToken(t, "root", RoleAdmin)
+	alice := issueRoleToken(t, "alice", RoleUser)
+	legacy := issueRoleToken(t, "legacy", "")
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"anonymous admin route", http.MethodDelete, "/admin", "", http.StatusUnauthorized},
+		{"user on admin route", http.MethodDelete, "/admin", alice, http.StatusForbidden},
+		{"admin on admin route", http.MethodDelete, "/admin", admin, http.StatusOK},
+		{"user with scopes", http.MethodGet, "/scoped", alice, http.StatusOK},
+		{"token without scopes", http.MethodGet, "/scoped", legacy, http.StatusForbidden},
+		{"own messages", http.MethodGet, "/users/alice/messages", alice, http.StatusOK},
+		{"other user's messages", http.MethodGet, "/users/bob/messages", alice, http.StatusForbidden},
+		{"admin reads any messages", http.MethodGet, "/users/bob/messages", admin, http.StatusOK},
+		{"anonymous messages", http.MethodGet, "/users/alice/messages", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestScopesForRoles(t *testing.T) {
+	assert.NotContains(t, ScopesForRoles(RoleUser), ScopeAdmin)
+	assert.Contains(t, ScopesForRoles(RoleAdmin), ScopeAdmin)
+	assert.Len(t, ScopesForRoles(RoleUser, RoleAdmin), len(ScopesForRoles(RoleAdmin)))
+	assert.Empty(t, ScopesForRoles("unknown"))
+}
+
+func TestRequireAuthenticated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	identify := SkipPaths([]string{"/public"}, OptionalAuth(NewHMACKeySet(testJWTSecret), nil))
+
+	router := gin.New()
+	router.Use(identify)
+	router.GET("/private", RequireAuthenticated(), func(c *gin.Context) { c.String(http.StatusOK, c.GetString("user_id")) })
+	router.GET("/public/info", func(c *gin.Context) { c.String(http.StatusOK, c.GetString("user_id")) })
+
+	alice := issueRoleToken(t, "alice", RoleUser)
+
+	do := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("authenticated", func(t *testing.T) {
+		w := do("/private", alice)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "alice", w.Body.String())
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		w := do("/private", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Authentication required")
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		w := do("/private", "not-a-token")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Invalid or expired credentials")
+	})
+
+	t.Run("public path is not identified", func(t *testing.T) {
+		w := do("/public/info", alice)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestIdentified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	apiKeys := &stubAPIKeys{keys: map[string]*APIKeyPrincipal{
+		"good-key": {KeyID: "k1", OwnerUserID: "publisher", Scopes: []string{ScopeMessagesWrite}},
+	}}
+	identify := Identify(NewHMACKeySet(testJWTSecret), nil, apiKeys)
+	ok := func(c *gin.Context) { c.String(http.StatusOK, c.GetString("user_id")) }
+
+	// public 그룹처럼 전역 식별을 건너뛴 경로에서도 권한 검사가 붙은 라우트는 호출자를 알아본다.
+	router := gin.New()
+	router.Use(SkipPaths([]string{"/public"}, OptionalAuth(NewHMACKeySet(testJWTSecret), nil)))
+	router.DELETE("/public/admin", Identified(identify, RequireRole(RoleAdmin)), ok)
+	router.POST("/public/send", Identified(identify, RequireScope(ScopeMessagesWrite)), ok)
+	router.GET("/public/info", ok)
+
+	admin := issueRoleToken(t, "root", RoleAdmin)
+	alice := issueRoleToken(t, "alice", RoleUser)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		key      string
+		want     int
+		wantUser string
+	}{
+		{"admin on admin route", http.MethodDelete, "/public/admin", admin, "", http.StatusOK, "root"},
+		{"user on admin route", http.MethodDelete, "/public/admin", alice, "", http.StatusForbidden, ""},
+		{"anonymous admin route", http.MethodDelete, "/public/admin", "", "", http.StatusUnauthorized, ""},
+		{"api key with scope", http.MethodPost, "/public/send", "", "good-key", http.StatusOK, "publisher"},
+		{"invalid api key", http.MethodPost, "/public/send", "", "bad-key", http.StatusUnauthorized, ""},
+		{"unguarded route stays anonymous", http.MethodGet, "/public/info", admin, "", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			if tt.key != "" {
+				req.Header.Set(APIKeyHeader, tt.key)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Code)
+			if tt.want == http.StatusOK {
+				assert.Equal(t, tt.wantUser, w.Body.String())
+			}
+		})
+	}
+}
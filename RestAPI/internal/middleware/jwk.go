@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// maxJWKSBytes caps the JWKS response body
+const maxJWKSBytes = 1 << 20
+
+// JSONWebKey is a public key in RFC 7517 form
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// rsaJSONWebKey encodes an RSA public key
+func rsaJSONWebKey(kid string, key *rsa.PublicKey) JSONWebKey {
+	return JSONWebKey{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: AlgRS256,
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// ecJSONWebKey encodes a P-256 public key.
+// 좌표는 곡선 크기(32 바이트)로 0 을 채워야 한다 — big.Int.Bytes 는 앞자리 0 을 잘라낸다.
+func ecJSONWebKey(kid string, key *ecdsa.PublicKey) JSONWebKey {
+	size := (key.Curve.Params().BitSize + 7) / 8
+
+	return JSONWebKey{
+		KeyType:   "EC",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: AlgES256,
+		Curve:     key.Curve.Params().Name,
+		X:         base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+		Y:         base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+// verificationKey decodes the JWK into a public key bound to its algorithm
+func (j JSONWebKey) verificationKey() (verificationKey, error) {
+	if j.KeyID == "" {
+		return verificationKey{}, errors.New("missing kid")
+	}
+	if j.Use != "" && j.Use != "sig" {
+		return verificationKey{}, fmt.Errorf("unsupported use %q", j.Use)
+	}
+
+	switch j.KeyType {
+	case "RSA":
+		if j.Algorithm != "" && j.Algorithm != AlgRS256 {
+			return verificationKey{}, fmt.Errorf("unsupported algorithm %q", j.Algorithm)
+		}
+
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 {
+			return verificationKey{}, errors.New("invalid e")
+		}
+
+		return verificationKey{
+			method: jwt.SigningMethodRS256,
+			key:    &rsa.PublicKey{N: n, E: int(e.Int64())},
+		}, nil
+
+	case "EC":
+		if j.Curve != "P-256" || (j.Algorithm != "" && j.Algorithm != AlgES256) {
+			return verificationKey{}, fmt.Errorf("unsupported curve %q / algorithm %q", j.Curve, j.Algorithm)
+		}
+
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid x: %w", err)
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return verificationKey{}, fmt.Errorf("invalid y: %w", err)
+		}
+
+		curve := elliptic.P256()
+		if !curve.IsOnCurve(x, y) {
+			return verificationKey{}, errors.New("point is not on P-256")
+		}
+
+		return verificationKey{
+			method: jwt.SigningMethodES256,
+			key:    &ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		}, nil
+	}
+
+	return verificationKey{}, fmt.Errorf("unsupported key type %q", j.KeyType)
+}
+
+// decodeBigInt decodes a base64url (unpadded) big-endian integer
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// fetchJSONWebKeySet downloads and decodes a JWKS document
+func fetchJSONWebKeySet(ctx context.Context, client *http.Client, url string) (*JSONWebKeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var set JSONWebKeySet
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBytes)).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	return &set, nil
+}
@@ -0,0 +1,439 @@
+package middleware
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+// jwksMinRefetchInterval bounds how often an unknown kid may trigger a JWKS fetch.
+// 임의의 kid 를 넣은 토큰으로 JWKS 서버에 요청을 증폭시키지 못하게 한다.
+const jwksMinRefetchInterval = 30 * time.Second
+
+// KeyFile describes one asymmetric key loaded from PEM files
+type KeyFile struct {
+	KeyID          string
+	Algorithm      string
+	PrivateKeyFile string // optional - verify-only when empty
+	PublicKeyFile  string // optional when PrivateKeyFile is set
+}
+
+// KeySetOptions configures a KeySet
+type KeySetOptions struct {
+	Algorithm    string // HS256, RS256 or ES256
+	HMACSecret   string
+	Keys         []KeyFile
+	SigningKeyID string
+	JWKSURL      string
+	JWKSRefresh  time.Duration
+	Issuer       string
+	Audience     string
+	HTTPClient   *http.Client
+}
+
+// verificationKey is a public key bound to the only algorithm it may verify
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// signingKey is the private key used to mint new tokens
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// KeySet signs and verifies JWTs.
+// 비대칭 모드에서는 kid 로 검증 키를 고르고, 키마다 알고리즘을 고정해 alg 바꿔치기(HS256 ↔ RS256)를 막는다.
+// 회전 중에는 이전 키를 공개키만 남겨 두면 이미 발급된 토큰이 만료될 때까지 계속 검증된다.
+type KeySet struct {
+	hmacSecret   []byte
+	signing      *signingKey
+	issuer       string
+	audience     string
+	validMethods []string
+
+	mu         sync.RWMutex
+	localKeys  map[string]verificationKey
+	remoteKeys map[string]verificationKey
+	lastFetch  time.Time
+
+	fetchMu     sync.Mutex
+	jwksURL     string
+	jwksRefresh time.Duration
+	httpClient  *http.Client
+}
+
+// NewHMACKeySet creates a key set that signs and verifies with a shared HS256 secret
+func NewHMACKeySet(secret string) *KeySet {
+	return &KeySet{
+		hmacSecret:   []byte(secret),
+		signing:      &signingKey{method: jwt.SigningMethodHS256, key: []byte(secret)},
+		validMethods: []string{AlgHS256},
+		localKeys:    map[string]verificationKey{},
+		remoteKeys:   map[string]verificationKey{},
+	}
+}
+
+// NewKeySet creates a key set from PEM files and/or a JWKS URL
+func NewKeySet(opts KeySetOptions) (*KeySet, error) {
+	if opts.Algorithm == "" || opts.Algorithm == AlgHS256 {
+		if opts.HMACSecret == "" {
+			return nil, errors.New("HS256 requires a secret")
+		}
+		keys := NewHMACKeySet(opts.HMACSecret)
+		keys.issuer, keys.audience = opts.Issuer, opts.Audience
+		return keys, nil
+	}
+
+	k := &KeySet{
+		issuer:       opts.Issuer,
+		audience:     opts.Audience,
+		validMethods: []string{AlgRS256, AlgES256},
+		localKeys:    make(map[string]verificationKey, len(opts.Keys)),
+		remoteKeys:   map[string]verificationKey{},
+		jwksURL:      opts.JWKSURL,
+		jwksRefresh:  opts.JWKSRefresh,
+		httpClient:   opts.HTTPClient,
+	}
+
+	if k.httpClient == nil {
+		k.httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	for _, file := range opts.Keys {
+		if err := k.loadKeyFile(file, opts.Algorithm, file.KeyID == opts.SigningKeyID); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.SigningKeyID != "" && k.signing == nil {
+		return nil, fmt.Errorf("signing key %q has no private key", opts.SigningKeyID)
+	}
+
+	// 기동 시점에 JWKS 를 못 받으면 모든 토큰이 거부되므로 설정 오류로 바로 드러낸다.
+	if k.jwksURL != "" {
+		if err := k.fetchJWKS(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+// loadKeyFile parses the PEM files of one key and registers it
+func (k *KeySet) loadKeyFile(file KeyFile, defaultAlgorithm string, signing bool) error {
+	if file.KeyID == "" {
+		return errors.New("jwt key is missing kid")
+	}
+
+	algorithm := file.Algorithm
+	if algorithm == "" {
+		algorithm = defaultAlgorithm
+	}
+
+	method := jwt.GetSigningMethod(algorithm)
+	if method == nil || (algorithm != AlgRS256 && algorithm != AlgES256) {
+		return fmt.Errorf("jwt key %q: unsupported algorithm %q", file.KeyID, algorithm)
+	}
+
+	var private, public interface{}
+	if file.PrivateKeyFile != "" {
+		data, err := os.ReadFile(file.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("jwt key %q: %w", file.KeyID, err)
+		}
+		if private, public, err = parsePrivateKeyPEM(algorithm, data); err != nil {
+			return fmt.Errorf("jwt key %q: %w", file.KeyID, err)
+		}
+	}
+
+	if file.PublicKeyFile != "" {
+		data, err := os.ReadFile(file.PublicKeyFile)
+		if err != nil {
+			return fmt.Errorf("jwt key %q: %w", file.KeyID, err)
+		}
+		if public, err = parsePublicKeyPEM(algorithm, data); err != nil {
+			return fmt.Errorf("jwt key %q: %w", file.KeyID, err)
+		}
+	}
+
+	if public == nil {
+		return fmt.Errorf("jwt key %q: private_key_file or public_key_file is required", file.KeyID)
+	}
+
+	if _, exists := k.localKeys[file.KeyID]; exists {
+		return fmt.Errorf("duplicate jwt kid %q", file.KeyID)
+	}
+	k.localKeys[file.KeyID] = verificationKey{method: method, key: public}
+
+	if signing {
+		if private == nil {
+			return fmt.Errorf("signing key %q has no private key", file.KeyID)
+		}
+		k.signing = &signingKey{kid: file.KeyID, method: method, key: private}
+	}
+
+	return nil
+}
+
+// parsePrivateKeyPEM parses a private key and derives its public half
+func parsePrivateKeyPEM(algorithm string, data []byte) (interface{}, interface{}, error) {
+	if algorithm == AlgRS256 {
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, &key.PublicKey, nil
+}
+
+// parsePublicKeyPEM parses a public key for the algorithm
+func parsePublicKeyPEM(algorithm string, data []byte) (interface{}, error) {
+	if algorithm == AlgRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	}
+	return jwt.ParseECPublicKeyFromPEM(data)
+}
+
+// CanSign reports whether this key set holds a private key and may issue tokens
+func (k *KeySet) CanSign() bool {
+	return k.signing != nil
+}
+
+// Algorithm returns the signing algorithm, or an empty string for verify-only key sets
+func (k *KeySet) Algorithm() string {
+	if k.signing == nil {
+		return ""
+	}
+	return k.signing.method.Alg()
+}
+
+// GenerateToken signs a new token for the user without roles or scopes
+func (k *KeySet) GenerateToken(userID, username string, expirationHours int) (string, error) {
+	return k.IssueToken(JWTClaims{UserID: userID, Username: username}, expirationHours)
+}
+
+// IssueToken signs the identity, roles and scopes of claims; registered claims are filled in here.
+// jti 를 매번 새로 발급해야 로그아웃 시 토큰 하나만 골라 폐기할 수 있다.
+func (k *KeySet) IssueToken(claims JWTClaims, expirationHours int) (string, error) {
+	if k.signing == nil {
+		return "", errors.New("key set has no signing key")
+	}
+
+	now := time.Now()
+	claims.IssuedAtMs = now.UnixMilli()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    k.issuer,
+		ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(expirationHours) * time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	if k.audience != "" {
+		claims.Audience = jwt.ClaimStrings{k.audience}
+	}
+
+	token := jwt.NewWithClaims(k.signing.method, claims)
+	if k.signing.kid != "" {
+		token.Header["kid"] = k.signing.kid
+	}
+
+	return token.SignedString(k.signing.key)
+}
+
+// ValidateToken verifies the signature, expiry, issuer and audience and returns the claims
+func (k *KeySet) ValidateToken(tokenString string) (*JWTClaims, error) {
+	options := []jwt.ParserOption{jwt.WithValidMethods(k.validMethods)}
+	if k.issuer != "" {
+		options = append(options, jwt.WithIssuer(k.issuer))
+	}
+	if k.audience != "" {
+		options = append(options, jwt.WithAudience(k.audience))
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, k.keyfunc, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	return claims, nil
+}
+
+// keyfunc selects the verification key for a parsed token header
+func (k *KeySet) keyfunc(token *jwt.Token) (interface{}, error) {
+	if k.hmacSecret != nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return k.hmacSecret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token header has no kid")
+	}
+
+	key, ok := k.lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if key.method.Alg() != token.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+
+	return key.key, nil
+}
+
+// lookup finds a key by kid, refetching the JWKS when the kid is unknown or the cache is stale
+func (k *KeySet) lookup(kid string) (verificationKey, bool) {
+	k.mu.RLock()
+	key, ok := k.localKeys[kid]
+	if !ok {
+		key, ok = k.remoteKeys[kid]
+	}
+	lastFetch := k.lastFetch
+	k.mu.RUnlock()
+
+	if k.jwksURL == "" {
+		return key, ok
+	}
+
+	stale := k.jwksRefresh > 0 && time.Since(lastFetch) > k.jwksRefresh
+	if ok && !stale {
+		return key, true
+	}
+
+	if time.Since(lastFetch) < jwksMinRefetchInterval {
+		return key, ok
+	}
+
+	// 갱신이 실패해도 기존 키로 계속 검증한다 — JWKS 서버 장애가 곧 인증 장애가 되지 않게 한다.
+	if err := k.refreshJWKS(context.Background(), lastFetch); err != nil {
+		logger.Warnf("Failed to refresh JWKS from %s: %v", k.jwksURL, err)
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	if key, ok := k.localKeys[kid]; ok {
+		return key, true
+	}
+	key, ok = k.remoteKeys[kid]
+	return key, ok
+}
+
+// refreshJWKS fetches the JWKS unless another goroutine already did so since seen
+func (k *KeySet) refreshJWKS(ctx context.Context, seen time.Time) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	k.mu.RLock()
+	alreadyFetched := k.lastFetch.After(seen)
+	k.mu.RUnlock()
+
+	if alreadyFetched {
+		return nil
+	}
+
+	return k.fetchJWKS(ctx)
+}
+
+// fetchJWKS downloads the remote key set and replaces the cached remote keys
+func (k *KeySet) fetchJWKS(ctx context.Context) error {
+	// 실패해도 시각을 남겨야 장애 중에 요청마다 JWKS 를 다시 부르지 않는다.
+	defer func() {
+		k.mu.Lock()
+		k.lastFetch = time.Now()
+		k.mu.Unlock()
+	}()
+
+	set, err := fetchJSONWebKeySet(ctx, k.httpClient, k.jwksURL)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]verificationKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.verificationKey()
+		if err != nil {
+			logger.Warnf("Skipping JWKS key %q: %v", jwk.KeyID, err)
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	if len(keys) == 0 {
+		return fmt.Errorf("JWKS at %s contains no usable keys", k.jwksURL)
+	}
+
+	k.mu.Lock()
+	k.remoteKeys = keys
+	k.mu.Unlock()
+
+	return nil
+}
+
+// JWKS returns the public keys this instance signs or verifies with locally
+func (k *KeySet) JWKS() JSONWebKeySet {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(k.localKeys))}
+	for kid, key := range k.localKeys {
+		var jwk JSONWebKey
+		switch public := key.key.(type) {
+		case *rsa.PublicKey:
+			jwk = rsaJSONWebKey(kid, public)
+		case *ecdsa.PublicKey:
+			jwk = ecJSONWebKey(kid, public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].KeyID < set.Keys[j].KeyID })
+	return set
+}
+
+// GenerateToken generates a new HS256 JWT token
+func GenerateToken(userID, username, jwtSecret string, expirationHours int) (string, error) {
+	return NewHMACKeySet(jwtSecret).GenerateToken(userID, username, expirationHours)
+}
+
+// ValidateToken validates an HS256 JWT token and returns claims
+func ValidateToken(tokenString, jwtSecret string) (*JWTClaims, error) {
+	return NewHMACKeySet(jwtSecret).ValidateToken(tokenString)
+}
@@ -0,0 +1,268 @@
+package middleware
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRSAKey writes a fresh RSA key pair and returns the private and public PEM paths
+func writeRSAKey(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return writeKeyPair(t, dir, name, key, &key.PublicKey)
+}
+
+// writeECKey writes a fresh P-256 key pair and returns the private and public PEM paths
+func writeECKey(t *testing.T, dir, name string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return writeKeyPair(t, dir, name, key, &key.PublicKey)
+}
+
+func writeKeyPair(t *testing.T, dir, name string, private, public interface{}) (string, string) {
+	t.Helper()
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+
+	privatePath := filepath.Join(dir, name+".pem")
+	publicPath := filepath.Join(dir, name+".pub.pem")
+
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600))
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644))
+
+	return privatePath, publicPath
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	rsaPrivate, _ := writeRSAKey(t, dir, "rsa")
+	ecPrivate, _ := writeECKey(t, dir, "ec")
+
+	for _, tc := range []struct {
+		algorithm, path string
+	}{
+		{AlgRS256, rsaPrivate},
+		{AlgES256, ecPrivate},
+	} {
+		t.Run(tc.algorithm, func(t *testing.T) {
+			keys, err := NewKeySet(KeySetOptions{
+				Algorithm:    tc.algorithm,
+				Keys:         []KeyFile{{KeyID: "k1", PrivateKeyFile: tc.path}},
+				SigningKeyID: "k1",
+				Issuer:       "rtmc-api",
+				Audience:     "rtmc",
+			})
+			require.NoError(t, err)
+			require.True(t, keys.CanSign())
+
+			token, err := keys.GenerateToken("alice", "Alice", 1)
+			require.NoError(t, err)
+
+			claims, err := keys.ValidateToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", claims.UserID)
+			assert.Equal(t, "rtmc-api", claims.Issuer)
+			assert.NotEmpty(t, claims.ID)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	oldPrivate, oldPublic := writeRSAKey(t, dir, "old")
+	newPrivate, _ := writeECKey(t, dir, "new")
+
+	before, err := NewKeySet(KeySetOptions{
+		Algorithm:    AlgRS256,
+		Keys:         []KeyFile{{KeyID: "old", PrivateKeyFile: oldPrivate}},
+		SigningKeyID: "old",
+	})
+	require.NoError(t, err)
+
+	oldToken, err := before.GenerateToken("alice", "Alice", 1)
+	require.NoError(t, err)
+
+	// 회전 후: 새 키로 서명하고, 이전 키는 공개키만 남겨 기존 토큰을 계속 검증한다.
+	after, err := NewKeySet(KeySetOptions{
+		Algorithm: AlgRS256,
+		Keys: []KeyFile{
+			{KeyID: "new", Algorithm: AlgES256, PrivateKeyFile: newPrivate},
+			{KeyID: "old", PublicKeyFile: oldPublic},
+		},
+		SigningKeyID: "new",
+	})
+	require.NoError(t, err)
+
+	newToken, err := after.GenerateToken("bob", "Bob", 1)
+	require.NoError(t, err)
+
+	header, _, err := jwt.NewParser().ParseUnverified(newToken, &JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "new", header.Header["kid"])
+
+	_, err = after.ValidateToken(oldToken)
+	assert.NoError(t, err, "이전 키로 서명된 토큰도 검증돼야 한다")
+
+	_, err = after.ValidateToken(newToken)
+	assert.NoError(t, err)
+
+	_, err = before.ValidateToken(newToken)
+	assert.Error(t, err, "모르는 kid 는 거부해야 한다")
+
+	assert.Len(t, after.JWKS().Keys, 2)
+}
+
+func TestKeySet_RejectsForgedTokens(t *testing.T) {
+	dir := t.TempDir()
+	private, public := writeRSAKey(t, dir, "rsa")
+
+	keys, err := NewKeySet(KeySetOptions{
+		Algorithm:    AlgRS256,
+		Keys:         []KeyFile{{KeyID: "k1", PrivateKeyFile: private}},
+		SigningKeyID: "k1",
+		Audience:     "rtmc",
+	})
+	require.NoError(t, err)
+
+	t.Run("HS256 signed with the public key", func(t *testing.T) {
+		publicPEM, err := os.ReadFile(public)
+		require.NoError(t, err)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{UserID: "mallory"})
+		token.Header["kid"] = "k1"
+		forged, err := token.SignedString(publicPEM)
+		require.NoError(t, err)
+
+		_, err = keys.ValidateToken(forged)
+		assert.Error(t, err)
+	})
+
+	t.Run("HMAC token from shared secret", func(t *testing.T) {
+		token, err := GenerateToken("mallory", "Mallory", testJWTSecret, 1)
+		require.NoError(t, err)
+
+		_, err = keys.ValidateToken(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		other, err := NewKeySet(KeySetOptions{
+			Algorithm:    AlgRS256,
+			Keys:         []KeyFile{{KeyID: "k1", PrivateKeyFile: private}},
+			SigningKeyID: "k1",
+			Audience:     "another-service",
+		})
+		require.NoError(t, err)
+
+		token, err := other.GenerateToken("alice", "Alice", 1)
+		require.NoError(t, err)
+
+		_, err = keys.ValidateToken(token)
+		assert.Error(t, err)
+	})
+}
+
+// jwksServer serves the JWKS of whichever key set is current and counts requests
+type jwksServer struct {
+	current  atomic.Pointer[KeySet]
+	requests atomic.Int32
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.requests.Add(1)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.current.Load().JWKS())
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	firstPrivate, _ := writeRSAKey(t, dir, "first")
+	secondPrivate, _ := writeECKey(t, dir, "second")
+
+	issuer, err := NewKeySet(KeySetOptions{
+		Algorithm:    AlgRS256,
+		Keys:         []KeyFile{{KeyID: "first", PrivateKeyFile: firstPrivate}},
+		SigningKeyID: "first",
+	})
+	require.NoError(t, err)
+
+	stub := &jwksServer{}
+	stub.current.Store(issuer)
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	verifier, err := NewKeySet(KeySetOptions{
+		Algorithm:   AlgRS256,
+		JWKSURL:     server.URL,
+		JWKSRefresh: time.Hour,
+	})
+	require.NoError(t, err)
+	assert.False(t, verifier.CanSign())
+
+	token, err := issuer.GenerateToken("alice", "Alice", 1)
+	require.NoError(t, err)
+
+	_, err = verifier.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), stub.requests.Load())
+
+	// 발급측이 키를 바꾸면 모르는 kid 를 만난 검증측이 JWKS 를 다시 받아야 한다.
+	rotated, err := NewKeySet(KeySetOptions{
+		Algorithm:    AlgES256,
+		Keys:         []KeyFile{{KeyID: "second", PrivateKeyFile: secondPrivate}},
+		SigningKeyID: "second",
+	})
+	require.NoError(t, err)
+	stub.current.Store(rotated)
+
+	rotatedToken, err := rotated.GenerateToken("bob", "Bob", 1)
+	require.NoError(t, err)
+
+	verifier.mu.Lock()
+	verifier.lastFetch = time.Now().Add(-time.Minute)
+	verifier.mu.Unlock()
+
+	_, err = verifier.ValidateToken(rotatedToken)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), stub.requests.Load())
+
+	// 재조회 간격 안에서는 모르는 kid 가 와도 JWKS 를 다시 부르지 않는다.
+	_, err = verifier.ValidateToken(token)
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), stub.requests.Load())
+}
+
+func TestKeySet_JWKSUnavailableAtStartup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := NewKeySet(KeySetOptions{Algorithm: AlgRS256, JWKSURL: server.URL})
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Logger is a middleware that logs HTTP requests
@@ -44,6 +45,11 @@ func Logger() gin.HandlerFunc {
 			"body_size":   bodySize,
 		})
 
+		// Tracing 미들웨어가 요청 컨텍스트에 span 을 담았으면 로그와 trace 를 잇는다.
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			entry = entry.WithField("trace_id", spanContext.TraceID().String())
+		}
+
 		if errorMessage != "" {
 			entry = entry.WithField("error", errorMessage)
 		}
@@ -90,8 +96,10 @@ func Recovery() gin.HandlerFunc {
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept-Encoding, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept-Encoding, Cache-Control, X-Requested-With, X-API-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		// 브라우저 클라이언트가 rate limit 헤더를 읽어 스스로 속도를 줄일 수 있도록 노출한다.
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
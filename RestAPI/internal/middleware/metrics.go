@@ -85,6 +85,32 @@ var (
 		},
 		[]string{"operation"},
 	)
+
+	// Rate limit decisions served by the local fallback limiter
+	rateLimitFallbackTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "rate_limit_fallback_total",
+			Help: "Total number of rate limit decisions made locally because Redis was unavailable",
+		},
+	)
+
+	// Moderation decisions on sent and edited messages
+	moderationDecisionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "moderation_decisions_total",
+			Help: "Total number of moderation decisions by action",
+		},
+		[]string{"action"},
+	)
+
+	// Webhook delivery attempts
+	webhookDeliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "webhook_deliveries_total",
+			Help: "Total number of webhook delivery attempts by result (succeeded, retry, dead)",
+		},
+		[]string{"result"},
+	)
 )
 
 // PrometheusMetrics is a middleware that collects Prometheus metrics
@@ -168,3 +194,18 @@ func RecordRedisOperation(operation string, duration time.Duration, err error) {
 	redisOperationsTotal.WithLabelValues(operation, status).Inc()
 	redisOperationDuration.WithLabelValues(operation).Observe(duration.Seconds())
 }
+
+// RecordRateLimitFallback records a rate limit decision served by the local fallback limiter
+func RecordRateLimitFallback() {
+	rateLimitFallbackTotal.Inc()
+}
+
+// RecordModerationDecision records the moderation decision on a message
+func RecordModerationDecision(action string) {
+	moderationDecisionsTotal.WithLabelValues(action).Inc()
+}
+
+// RecordWebhookDelivery records the result of a webhook delivery attempt
+func RecordWebhookDelivery(result string) {
+	webhookDeliveriesTotal.WithLabelValues(result).Inc()
+}
@@ -0,0 +1,295 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Rate limit identity types
+const (
+	IdentityIP     = "ip"
+	IdentityUser   = "user"
+	IdentityAPIKey = "api_key"
+)
+
+// Standard rate limit response headers (draft-ietf-httpapi-ratelimit-headers)
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RetryAfterHeader         = "Retry-After"
+)
+
+// RateLimitPolicy describes the limit applied to one route group and identity type
+type RateLimitPolicy struct {
+	Name              string
+	RoutePrefix       string
+	Methods           []string
+	Identity          string
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// RateLimitExemptions lists routes and identities that bypass every policy
+type RateLimitExemptions struct {
+	Routes    []string
+	IPs       []string // IP or CIDR
+	UserIDs   []string
+	APIKeyIDs []string
+}
+
+// LimiterFactory creates a limiter for the given rate and burst
+type LimiterFactory func(requestsPerSecond float64, burst int) Limiter
+
+type compiledPolicy struct {
+	RateLimitPolicy
+	methods map[string]bool
+	limiter Limiter
+}
+
+// matches reports whether the policy covers the request method and path
+func (p *compiledPolicy) matches(method, path string) bool {
+	if len(p.methods) > 0 && !p.methods[method] {
+		return false
+	}
+	return hasPathPrefix(path, p.RoutePrefix)
+}
+
+// PolicyRateLimiter applies the most specific matching policy to each request
+type PolicyRateLimiter struct {
+	policies      []*compiledPolicy
+	defaultPolicy *compiledPolicy
+	exemptRoutes  []string
+	exemptNets    []*net.IPNet
+	exemptUsers   map[string]bool
+	exemptAPIKeys map[string]bool
+}
+
+// NewPolicyRateLimiter creates a policy-driven rate limiter.
+// defaultPolicy 는 어떤 정책에도 걸리지 않은 요청에 적용된다.
+func NewPolicyRateLimiter(
+	defaultPolicy RateLimitPolicy,
+	policies []RateLimitPolicy,
+	exempt RateLimitExemptions,
+	newLimiter LimiterFactory,
+) (*PolicyRateLimiter, error) {
+	p := &PolicyRateLimiter{
+		defaultPolicy: compilePolicy(defaultPolicy, newLimiter),
+		exemptRoutes:  exempt.Routes,
+		exemptUsers:   toSet(exempt.UserIDs),
+		exemptAPIKeys: toSet(exempt.APIKeyIDs),
+	}
+
+	for _, policy := range policies {
+		p.policies = append(p.policies, compilePolicy(policy, newLimiter))
+	}
+
+	// 가장 긴 접두사가 먼저 검사되도록 정렬한다. 같은 접두사면 메서드를 지정한 정책이 우선한다.
+	sort.SliceStable(p.policies, func(i, j int) bool {
+		if len(p.policies[i].RoutePrefix) != len(p.policies[j].RoutePrefix) {
+			return len(p.policies[i].RoutePrefix) > len(p.policies[j].RoutePrefix)
+		}
+		return len(p.policies[i].methods) > len(p.policies[j].methods)
+	})
+
+	for _, entry := range exempt.IPs {
+		ipNet, err := parseIPOrCIDR(entry)
+		if err != nil {
+			return nil, err
+		}
+		p.exemptNets = append(p.exemptNets, ipNet)
+	}
+
+	return p, nil
+}
+
+// compilePolicy prepares a policy for matching and attaches its limiter
+func compilePolicy(policy RateLimitPolicy, newLimiter LimiterFactory) *compiledPolicy {
+	compiled := &compiledPolicy{
+		RateLimitPolicy: policy,
+		limiter:         newLimiter(policy.RequestsPerSecond, policy.Burst),
+	}
+
+	if len(policy.Methods) > 0 {
+		compiled.methods = make(map[string]bool, len(policy.Methods))
+		for _, method := range policy.Methods {
+			compiled.methods[strings.ToUpper(method)] = true
+		}
+	}
+
+	return compiled
+}
+
+// Policies returns the configured policies in match order followed by the default policy
+func (p *PolicyRateLimiter) Policies() []RateLimitPolicy {
+	policies := make([]RateLimitPolicy, 0, len(p.policies)+1)
+	for _, policy := range p.policies {
+		policies = append(policies, policy.RateLimitPolicy)
+	}
+	return append(policies, p.defaultPolicy.RateLimitPolicy)
+}
+
+// Middleware returns a Gin middleware function.
+// user/api_key 정책은 컨텍스트의 인증 결과를 쓰므로 인증 미들웨어 뒤에 등록해야 한다.
+func (p *PolicyRateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if p.isExemptRoute(path) {
+			c.Next()
+			return
+		}
+
+		policy := p.match(c.Request.Method, path)
+
+		identity, exempt := p.resolveIdentity(c, policy.Identity)
+		if exempt {
+			c.Next()
+			return
+		}
+
+		result := policy.limiter.Allow(c.Request.Context(), policy.Name+":"+identity)
+		if !applyRateLimitResult(c, result) {
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// match returns the most specific policy for the request
+func (p *PolicyRateLimiter) match(method, path string) *compiledPolicy {
+	for _, policy := range p.policies {
+		if policy.matches(method, path) {
+			return policy
+		}
+	}
+	return p.defaultPolicy
+}
+
+// isExemptRoute reports whether the path is excluded from rate limiting
+func (p *PolicyRateLimiter) isExemptRoute(path string) bool {
+	for _, prefix := range p.exemptRoutes {
+		if hasPathPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveIdentity returns the bucket key for the requested identity type.
+// 요청한 식별자가 없으면(미인증 등) IP 로 내려간다 — 익명 요청이 한도를 벗어나지 않게 하기 위함이다.
+func (p *PolicyRateLimiter) resolveIdentity(c *gin.Context, identityType string) (string, bool) {
+	switch identityType {
+	case IdentityUser:
+		if userID := c.GetString("user_id"); userID != "" {
+			return IdentityUser + ":" + userID, p.exemptUsers[userID]
+		}
+	case IdentityAPIKey:
+		if keyID := c.GetString(APIKeyIDKey); keyID != "" {
+			return IdentityAPIKey + ":" + keyID, p.exemptAPIKeys[keyID]
+		}
+	}
+
+	clientIP := c.ClientIP()
+	return IdentityIP + ":" + clientIP, p.isExemptIP(clientIP)
+}
+
+// isExemptIP reports whether the client IP falls in an exempt network
+func (p *PolicyRateLimiter) isExemptIP(clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range p.exemptNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyRateLimitResult writes the standard rate limit headers and rejects the request when denied.
+// 허용된 요청에도 헤더를 붙여야 클라이언트가 429 를 맞기 전에 속도를 줄일 수 있다.
+func applyRateLimitResult(c *gin.Context, result RateLimitResult) bool {
+	c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.ResetAfter, 0)))
+
+	if result.Allowed {
+		return true
+	}
+
+	c.Header(RetryAfterHeader, strconv.Itoa(ceilSeconds(result.RetryAfter, 1)))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"success":   false,
+		"error":     "Rate limit exceeded",
+		"code":      "TOO_MANY_REQUESTS",
+		"timestamp": time.Now().Unix(),
+	})
+	c.Abort()
+	return false
+}
+
+// ceilSeconds rounds a duration up to whole seconds, never returning less than minimum
+func ceilSeconds(d time.Duration, minimum int) int {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < minimum {
+		return minimum
+	}
+	return seconds
+}
+
+// hasPathPrefix matches whole path segments so /api/v1/user does not cover /api/v1/users
+func hasPathPrefix(path, prefix string) bool {
+	if prefix == "" || prefix == "/" {
+		return true
+	}
+
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+
+	return len(path) == len(prefix) || path[len(prefix)] == '/'
+}
+
+// parseIPOrCIDR parses a single IP address or a CIDR block
+func parseIPOrCIDR(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exempt CIDR %q: %w", entry, err)
+		}
+		return ipNet, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid exempt IP %q", entry)
+	}
+
+	bits := 128
+	if v4 := ip.To4(); v4 != nil {
+		ip, bits = v4, 32
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// toSet converts a slice to a lookup set
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
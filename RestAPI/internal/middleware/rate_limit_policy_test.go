@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func localLimiterFactory(r float64, b int) Limiter {
+	return NewRateLimiter(r, b)
+}
+
+// setupPolicyRouter registers every path used by the tests behind the policy limiter.
+// userID 가 비어 있지 않으면 인증 미들웨어가 식별한 것처럼 컨텍스트에 심는다.
+func setupPolicyRouter(t *testing.T, policies []RateLimitPolicy, exempt RateLimitExemptions) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := NewPolicyRateLimiter(
+		RateLimitPolicy{Name: "default", RoutePrefix: "/", Identity: IdentityIP, RequestsPerSecond: 1, Burst: 1},
+		policies, exempt, localLimiterFactory,
+	)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("user_id", userID)
+		}
+		c.Next()
+	})
+	router.Use(limiter.Middleware())
+
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/health", ok)
+	router.GET("/api/v1/users", ok)
+	router.POST("/api/v1/messages/send", ok)
+	router.GET("/api/v1/messages/recent", ok)
+
+	return router
+}
+
+func doRequest(router *gin.Engine, method, path, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPolicyRateLimiter_Headers(t *testing.T) {
+	router := setupPolicyRouter(t, []RateLimitPolicy{
+		{Name: "api", RoutePrefix: "/api/v1", Identity: IdentityIP, RequestsPerSecond: 1, Burst: 2},
+	}, RateLimitExemptions{})
+
+	first := doRequest(router, http.MethodGet, "/api/v1/users", "")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", first.Header().Get(RateLimitRemainingHeader))
+	assert.NotEmpty(t, first.Header().Get(RateLimitResetHeader))
+	assert.Empty(t, first.Header().Get(RetryAfterHeader))
+
+	doRequest(router, http.MethodGet, "/api/v1/users", "")
+
+	denied := doRequest(router, http.MethodGet, "/api/v1/users", "")
+	assert.Equal(t, http.StatusTooManyRequests, denied.Code)
+	assert.Equal(t, "0", denied.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(t, "1", denied.Header().Get(RetryAfterHeader))
+}
+
+func TestPolicyRateLimiter_MostSpecificPolicyWins(t *testing.T) {
+	router := setupPolicyRouter(t, []RateLimitPolicy{
+		{Name: "api", RoutePrefix: "/api/v1", Identity: IdentityIP, RequestsPerSecond: 1, Burst: 5},
+		{Name: "send", RoutePrefix: "/api/v1/messages/send", Methods: []string{"post"}, Identity: IdentityIP, RequestsPerSecond: 1, Burst: 1},
+	}, RateLimitExemptions{})
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/api/v1/messages/send", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodPost, "/api/v1/messages/send", "").Code)
+
+	// 다른 정책은 별도 버킷이므로 send 가 막혀도 나머지 API 는 계속 허용된다.
+	recent := doRequest(router, http.MethodGet, "/api/v1/messages/recent", "")
+	assert.Equal(t, http.StatusOK, recent.Code)
+	assert.Equal(t, "5", recent.Header().Get(RateLimitLimitHeader))
+}
+
+func TestPolicyRateLimiter_UserIdentity(t *testing.T) {
+	router := setupPolicyRouter(t, []RateLimitPolicy{
+		{Name: "send", RoutePrefix: "/api/v1/messages/send", Identity: IdentityUser, RequestsPerSecond: 1, Burst: 1},
+	}, RateLimitExemptions{UserIDs: []string{"trusted"}})
+
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/api/v1/messages/send", "alice").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodPost, "/api/v1/messages/send", "alice").Code)
+
+	// 같은 IP 라도 사용자가 다르면 별도 버킷이다.
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/api/v1/messages/send", "bob").Code)
+
+	// 미인증 요청은 IP 버킷으로 내려간다.
+	assert.Equal(t, http.StatusOK, doRequest(router, http.MethodPost, "/api/v1/messages/send", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(router, http.MethodPost, "/api/v1/messages/send", "").Code)
+
+	for i := 0; i < 3; i++ {
+		w := doRequest(router, http.MethodPost, "/api/v1/messages/send", "trusted")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	}
+}
+
+func TestPolicyRateLimiter_Exemptions(t *testing.T) {
+	t.Run("exempt route", func(t *testing.T) {
+		router := setupPolicyRouter(t, nil, RateLimitExemptions{Routes: []string{"/health"}})
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/health", "").Code)
+		}
+	})
+
+	t.Run("exempt CIDR", func(t *testing.T) {
+		// httptest 요청의 원격 주소는 192.0.2.1 이다.
+		router := setupPolicyRouter(t, nil, RateLimitExemptions{IPs: []string{"192.0.2.0/24"}})
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, doRequest(router, http.MethodGet, "/api/v1/users", "").Code)
+		}
+	})
+
+	t.Run("invalid exempt IP", func(t *testing.T) {
+		_, err := NewPolicyRateLimiter(
+			RateLimitPolicy{Name: "default", RoutePrefix: "/", Identity: IdentityIP, RequestsPerSecond: 1, Burst: 1},
+			nil, RateLimitExemptions{IPs: []string{"not-an-ip"}}, localLimiterFactory,
+		)
+		assert.Error(t, err)
+	})
+}
+
+func TestHasPathPrefix(t *testing.T) {
+	assert.True(t, hasPathPrefix("/api/v1/users", "/api/v1"))
+	assert.True(t, hasPathPrefix("/api/v1/users", "/api/v1/"))
+	assert.True(t, hasPathPrefix("/api/v1", "/api/v1"))
+	assert.True(t, hasPathPrefix("/anything", "/"))
+	assert.False(t, hasPathPrefix("/api/v1/users", "/api/v1/user"))
+	assert.False(t, hasPathPrefix("/health", "/api"))
+}
@@ -1,7 +1,8 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"math"
 	"sync"
 	"time"
 
@@ -9,6 +10,25 @@ import (
 	"golang.org/x/time/rate"
 )
 
+// RateLimitResult describes the outcome of a single rate limit decision
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter 는 거부된 요청이 다시 허용되기까지 기다려야 하는 시간이다.
+	RetryAfter time.Duration
+	// ResetAfter 는 버킷이 가득 찬 상태로 돌아가기까지 걸리는 시간이다.
+	ResetAfter time.Duration
+}
+
+// Limiter decides whether a request identified by key may proceed
+type Limiter interface {
+	Allow(ctx context.Context, key string) RateLimitResult
+}
+
+// KeyFunc extracts the rate limit identity from a request
+type KeyFunc func(c *gin.Context) string
+
 // RateLimiter implements a token bucket rate limiter
 type RateLimiter struct {
 	limiters map[string]*rate.Limiter
@@ -66,22 +86,60 @@ func (rl *RateLimiter) cleanupStaleEntries() {
 	}
 }
 
+// Allow consumes one token for key from the in-process bucket
+func (rl *RateLimiter) Allow(_ context.Context, key string) RateLimitResult {
+	limiter := rl.getLimiter(key)
+	now := time.Now()
+
+	// Allow 대신 Reserve 를 써야 거부 시 다음 토큰까지 남은 시간을 알 수 있다.
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return RateLimitResult{Allowed: false, Limit: rl.burst}
+	}
+
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return RateLimitResult{
+			Allowed:    false,
+			Limit:      rl.burst,
+			RetryAfter: delay,
+			ResetAfter: rl.refillDuration(limiter.TokensAt(now)),
+		}
+	}
+
+	tokens := limiter.TokensAt(now)
+	return RateLimitResult{
+		Allowed:    true,
+		Limit:      rl.burst,
+		Remaining:  int(math.Max(0, math.Floor(tokens))),
+		ResetAfter: rl.refillDuration(tokens),
+	}
+}
+
+// refillDuration returns how long the bucket needs to refill from tokens to burst
+func (rl *RateLimiter) refillDuration(tokens float64) time.Duration {
+	if rl.rate <= 0 {
+		return 0
+	}
+
+	missing := float64(rl.burst) - tokens
+	if missing <= 0 {
+		return 0
+	}
+
+	return time.Duration(missing / float64(rl.rate) * float64(time.Second))
+}
+
 // Middleware returns a Gin middleware function
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return RateLimit(rl, KeyByIP)
+}
+
+// RateLimit creates a middleware that rejects requests once limiter denies the key returned by keyFunc
+func RateLimit(limiter Limiter, keyFunc KeyFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Use client IP as the key
-		key := c.ClientIP()
-
-		limiter := rl.getLimiter(key)
-
-		if !limiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"success":   false,
-				"error":     "Rate limit exceeded",
-				"code":      "TOO_MANY_REQUESTS",
-				"timestamp": time.Now().Unix(),
-			})
-			c.Abort()
+		result := limiter.Allow(c.Request.Context(), keyFunc(c))
+		if !applyRateLimitResult(c, result) {
 			return
 		}
 
@@ -89,34 +147,26 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	}
 }
 
-// RateLimitByUser creates a rate limiter that uses user ID from context
-func RateLimitByUser(r float64, b int) gin.HandlerFunc {
-	limiter := NewRateLimiter(r, b)
+// KeyByIP uses the client IP as the rate limit identity
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
 
-	return func(c *gin.Context) {
-		// Try to get user ID from context (set by auth middleware)
-		userID, exists := c.Get("user_id")
-		if !exists {
-			// Fallback to IP-based rate limiting
-			userID = c.ClientIP()
+// KeyByUser uses the authenticated user ID, falling back to the client IP
+func KeyByUser(c *gin.Context) string {
+	// Try to get user ID from context (set by auth middleware)
+	if userID, exists := c.Get("user_id"); exists {
+		if id, ok := userID.(string); ok && id != "" {
+			return "user:" + id
 		}
+	}
 
-		key := userID.(string)
-		rateLimiter := limiter.getLimiter(key)
-
-		if !rateLimiter.Allow() {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"success":   false,
-				"error":     "Rate limit exceeded",
-				"code":      "TOO_MANY_REQUESTS",
-				"timestamp": time.Now().Unix(),
-			})
-			c.Abort()
-			return
-		}
+	return KeyByIP(c)
+}
 
-		c.Next()
-	}
+// RateLimitByUser creates a rate limiter that uses user ID from context
+func RateLimitByUser(r float64, b int) gin.HandlerFunc {
+	return RateLimit(NewRateLimiter(r, b), KeyByUser)
 }
 
 // RateLimitByIP creates a simple IP-based rate limiter
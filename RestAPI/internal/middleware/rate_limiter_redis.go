@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript implements the generic cell rate algorithm atomically.
+// 키 하나에 TAT(theoretical arrival time)만 저장하므로 레플리카가 몇 개든 같은 예산을 나눠 쓴다.
+// 시계는 Redis TIME 을 쓴다 — 레플리카마다 시계가 어긋나도 판정이 흔들리지 않는다.
+//
+// KEYS[1] = bucket key
+// ARGV[1] = burst, ARGV[2] = rate (tokens per period), ARGV[3] = period (seconds)
+// returns {allowed, remaining, retry_after_seconds, reset_after_seconds}
+var gcraScript = redis.NewScript(`
+local key = KEYS[1]
+local burst = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local period = tonumber(ARGV[3])
+
+local emission_interval = period / rate
+local burst_offset = emission_interval * burst
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local tat = tonumber(redis.call("GET", key))
+if not tat or tat < now then
+  tat = now
+end
+
+local new_tat = tat + emission_interval
+local allow_at = new_tat - burst_offset
+local diff = now - allow_at
+
+if diff < 0 then
+  return {0, 0, tostring(-diff), tostring(tat - now)}
+end
+
+local reset_after = new_tat - now
+redis.call("SET", key, tostring(new_tat), "PX", math.ceil(reset_after * 1000))
+
+return {1, math.floor(diff / emission_interval), "0", tostring(reset_after)}
+`)
+
+// RedisRateLimiter shares token buckets across replicas through Redis.
+// Redis 호출이 실패하면 같은 한도의 로컬 limiter 로 판정을 넘겨 요청 경로가 Redis 장애에 묶이지 않게 한다.
+type RedisRateLimiter struct {
+	client   redis.Scripter
+	rate     float64
+	burst    int
+	fallback *RateLimiter
+	degraded atomic.Bool
+}
+
+// NewRedisRateLimiter creates a Redis-backed rate limiter
+// rate: tokens per second
+// burst: maximum burst size
+func NewRedisRateLimiter(client redis.Scripter, r float64, b int) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:   client,
+		rate:     r,
+		burst:    b,
+		fallback: NewRateLimiter(r, b),
+	}
+}
+
+// Allow consumes one token for key from the shared bucket
+func (rl *RedisRateLimiter) Allow(ctx context.Context, key string) RateLimitResult {
+	result, err := rl.allowRedis(ctx, key)
+	if err != nil {
+		RecordRateLimitFallback()
+		if rl.degraded.CompareAndSwap(false, true) {
+			logger.Warnf("Redis rate limiter unavailable, falling back to local limiter: %v", err)
+		}
+		return rl.fallback.Allow(ctx, key)
+	}
+
+	if rl.degraded.CompareAndSwap(true, false) {
+		logger.Info("Redis rate limiter recovered")
+	}
+
+	return result
+}
+
+// allowRedis runs the GCRA script and decodes its reply
+func (rl *RedisRateLimiter) allowRedis(ctx context.Context, key string) (RateLimitResult, error) {
+	if rl.rate <= 0 {
+		return RateLimitResult{Allowed: false, Limit: rl.burst}, nil
+	}
+
+	reply, err := gcraScript.Run(ctx, rl.client, []string{cache.RateLimitKey(key)}, rl.burst, rl.rate, 1).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if len(reply) != 4 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit script reply: %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	remaining, _ := reply[1].(int64)
+
+	retryAfter, err := parseSeconds(reply[2])
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	resetAfter, err := parseSeconds(reply[3])
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	return RateLimitResult{
+		Allowed:    allowed == 1,
+		Limit:      rl.burst,
+		Remaining:  int(remaining),
+		RetryAfter: retryAfter,
+		ResetAfter: resetAfter,
+	}, nil
+}
+
+// parseSeconds converts a fractional-seconds string returned by Lua into a duration
+func parseSeconds(value interface{}) (time.Duration, error) {
+	text, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected rate limit duration type %T", value)
+	}
+
+	seconds, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate limit duration %q: %w", text, err)
+	}
+
+	return time.Duration(math.Max(0, seconds) * float64(time.Second)), nil
+}
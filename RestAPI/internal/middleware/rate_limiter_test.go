@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return server, client
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	limiter := NewRateLimiter(1, 2)
+	ctx := context.Background()
+
+	first := limiter.Allow(ctx, "ip:1.2.3.4")
+	assert.True(t, first.Allowed)
+	assert.Equal(t, 2, first.Limit)
+	assert.Equal(t, 1, first.Remaining)
+
+	assert.True(t, limiter.Allow(ctx, "ip:1.2.3.4").Allowed)
+
+	denied := limiter.Allow(ctx, "ip:1.2.3.4")
+	assert.False(t, denied.Allowed)
+	assert.Greater(t, denied.RetryAfter.Seconds(), 0.0)
+
+	assert.True(t, limiter.Allow(ctx, "ip:5.6.7.8").Allowed, "다른 키는 별도 버킷을 써야 한다")
+}
+
+func TestRedisRateLimiter_SharedAcrossReplicas(t *testing.T) {
+	_, client := setupTestRedis(t)
+	ctx := context.Background()
+
+	// 같은 Redis 를 보는 두 인스턴스는 레플리카 두 개에 해당한다.
+	replicaA := NewRedisRateLimiter(client, 1, 3)
+	replicaB := NewRedisRateLimiter(client, 1, 3)
+
+	assert.True(t, replicaA.Allow(ctx, "ip:1.2.3.4").Allowed)
+	assert.True(t, replicaB.Allow(ctx, "ip:1.2.3.4").Allowed)
+	assert.True(t, replicaA.Allow(ctx, "ip:1.2.3.4").Allowed)
+
+	denied := replicaB.Allow(ctx, "ip:1.2.3.4")
+	assert.False(t, denied.Allowed, "버스트 3 을 두 레플리카가 나눠 써야 한다")
+	assert.Equal(t, 0, denied.Remaining)
+	assert.Greater(t, denied.RetryAfter.Seconds(), 0.0)
+
+	assert.True(t, replicaB.Allow(ctx, "ip:5.6.7.8").Allowed)
+}
+
+func TestRedisRateLimiter_Remaining(t *testing.T) {
+	_, client := setupTestRedis(t)
+	limiter := NewRedisRateLimiter(client, 1, 3)
+	ctx := context.Background()
+
+	first := limiter.Allow(ctx, "user:alice")
+	require.True(t, first.Allowed)
+	assert.Equal(t, 3, first.Limit)
+	assert.Equal(t, 2, first.Remaining)
+	assert.Greater(t, first.ResetAfter.Seconds(), 0.0)
+}
+
+func TestRedisRateLimiter_FallbackWhenUnavailable(t *testing.T) {
+	server, client := setupTestRedis(t)
+	limiter := NewRedisRateLimiter(client, 1, 1)
+	ctx := context.Background()
+
+	server.Close()
+
+	before := testutil.ToFloat64(rateLimitFallbackTotal)
+
+	assert.True(t, limiter.Allow(ctx, "ip:1.2.3.4").Allowed)
+	assert.False(t, limiter.Allow(ctx, "ip:1.2.3.4").Allowed, "로컬 limiter 도 같은 한도를 적용해야 한다")
+
+	assert.Equal(t, before+2, testutil.ToFloat64(rateLimitFallbackTotal))
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RateLimit(NewRateLimiter(1, 1), KeyByIP))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	first := httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusOK, first.Code)
+
+	second := httptest.NewRecorder()
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/ping", nil))
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+}
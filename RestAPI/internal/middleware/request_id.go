@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -27,6 +29,9 @@ func RequestID() gin.HandlerFunc {
 		c.Set(RequestIDKey, requestID)
 		c.Writer.Header().Set(RequestIDHeader, requestID)
 
+		// 요청 컨텍스트에도 담아 gin 컨텍스트가 없는 서비스·RabbitMQ 발행까지 ID 가 따라가게 한다.
+		c.Request = c.Request.WithContext(ContextWithRequestID(c.Request.Context(), requestID))
+
 		c.Next()
 	}
 }
@@ -40,3 +45,17 @@ func GetRequestID(c *gin.Context) string {
 	}
 	return ""
 }
+
+// requestIDContextKey is the context.Context key of the request ID
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx that carries requestID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "" outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
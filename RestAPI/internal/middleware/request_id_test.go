@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var fromGin, fromContext string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		fromGin = GetRequestID(c)
+		fromContext = RequestIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	t.Run("keeps the caller's ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "req-1", w.Header().Get(RequestIDHeader))
+		assert.Equal(t, "req-1", fromGin)
+		assert.Equal(t, "req-1", fromContext, "서비스 계층은 요청 컨텍스트로 같은 ID 를 받는다")
+	})
+
+	t.Run("generates one", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.NotEmpty(t, fromContext)
+		assert.Equal(t, w.Header().Get(RequestIDHeader), fromContext)
+	})
+}
+
+func TestRequestIDFromContext_Empty(t *testing.T) {
+	assert.Equal(t, "", RequestIDFromContext(context.Background()))
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := tracingtest.Setup(t)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(Tracing())
+	router.GET("/users/:userID", func(c *gin.Context) {
+		// 핸들러가 만드는 span 은 요청 span 의 자식이어야 한다.
+		_, child := tracing.Tracer().Start(c.Request.Context(), "redis get")
+		child.End()
+		c.Status(http.StatusOK)
+	})
+	router.GET("/boom", func(c *gin.Context) {
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	serve := func(path string, header http.Header) tracetest.SpanStub {
+		t.Helper()
+		exporter.Reset()
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		for _, span := range exporter.GetSpans() {
+			if span.SpanKind == trace.SpanKindServer {
+				return span
+			}
+		}
+		t.Fatalf("no server span for %s", path)
+		return tracetest.SpanStub{}
+	}
+
+	t.Run("names the span after the route", func(t *testing.T) {
+		span := serve("/users/alice", nil)
+
+		assert.Equal(t, "GET /users/:userID", span.Name)
+		assert.Contains(t, span.Attributes, attribute.String("http.route", "/users/:userID"))
+		assert.Contains(t, span.Attributes, attribute.String("url.path", "/users/alice"))
+		assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+		assert.Equal(t, codes.Unset, span.Status.Code)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "redis get", spans[0].Name)
+		assert.Equal(t, span.SpanContext.SpanID(), spans[0].Parent.SpanID())
+	})
+
+	t.Run("continues an incoming trace", func(t *testing.T) {
+		header := http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+		span := serve("/users/alice", header)
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+		assert.True(t, span.Parent.IsRemote())
+	})
+
+	t.Run("marks server errors", func(t *testing.T) {
+		span := serve("/boom", nil)
+
+		assert.Equal(t, codes.Error, span.Status.Code)
+	})
+
+	t.Run("unmatched route", func(t *testing.T) {
+		span := serve("/missing", nil)
+
+		assert.Equal(t, "GET", span.Name)
+		assert.Contains(t, span.Attributes, attribute.Int("http.response.status_code", http.StatusNotFound))
+	})
+}
@@ -4,17 +4,43 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-// MessageRequest represents the incoming message request from clients
+// CommandEditMessage is the queue command of a message edit event.
+// DBWorker 는 이 명령을 저장하지 않고(REST API 가 이미 반영했다) MainServer 는 클라이언트에 edit_message 로 전달한다.
+const CommandEditMessage = "edit_message"
+
+// CommandRecall is the queue command of a message recall event.
+// 내용 없이 message_id 만 실어 보내며, MainServer 는 클라이언트에 recall 로 전달해 화면에서 지우게 한다.
+const CommandRecall = "recall"
+
+// MaxAttachmentsPerMessage limits how many attachments one message can carry
+const MaxAttachmentsPerMessage = 10
+
+// MessageRequest represents the incoming message request from clients.
+// room_id 가 있으면 해당 방 메시지이며, 보내는 사용자가 방 멤버여야 한다.
+// recipient_id 가 있으면 그 사용자에게만 가는 1:1 메시지다. 둘은 함께 쓸 수 없다.
+// reply_to 가 있으면 그 메시지에 대한 답글이며, 목적지(방·1:1 상대)는 원글을 따른다.
+// attachment_ids 는 보내는 사용자가 미리 올린, 아직 보내지 않은 첨부의 ID 다.
 type MessageRequest struct {
-	UserID    string                 `json:"user_id" binding:"required"`
-	Command   string                 `json:"command" binding:"required"`
-	SubID     string                 `json:"sub_id,omitempty"`
-	Content   string                 `json:"content" binding:"required"`
-	Metadata  map[string]interface{} `json:"metadata,omitempty"`
-	Priority  int                    `json:"priority,omitempty"` // 1=high, 2=normal, 3=low
-	Timestamp int64                  `json:"timestamp,omitempty"`
+	UserID        string                 `json:"user_id" binding:"required"`
+	Command       string                 `json:"command" binding:"required"`
+	SubID         string                 `json:"sub_id,omitempty"`
+	RoomID        string                 `json:"room_id,omitempty"`
+	RecipientID   string                 `json:"recipient_id,omitempty"`
+	ReplyTo       string                 `json:"reply_to,omitempty"`
+	AttachmentIDs []string               `json:"attachment_ids,omitempty"`
+	Content       string                 `json:"content" binding:"required"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	Priority      int                    `json:"priority,omitempty"` // 1=high, 2=normal, 3=low
+	Timestamp     int64                  `json:"timestamp,omitempty"`
+
+	// ThreadRootID is resolved from reply_to by the server, never read from the request
+	ThreadRootID string `json:"-"`
+	// Attachments are resolved from attachment_ids by the server, never read from the request
+	Attachments []QueueAttachment `json:"-"`
 }
 
 // MessageResponse represents the API response
@@ -48,18 +74,36 @@ type QueueMessagePayload struct {
 // DBWorker 가 publisher_info TEXT 로 그대로 영속화하므로 message_id 추적성이 유지된다.
 type QueuePublisherInformation struct {
 	MessageID string                 `json:"message_id"`
+	RequestID string                 `json:"request_id,omitempty"`
 	Source    string                 `json:"source"`
 	Priority  int                    `json:"priority"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	CreatedAt int64                  `json:"created_at"`
 }
 
+// QueueAttachment is the metadata of a file sent with a message.
+// 내용은 큐로 보내지 않는다 — 클라이언트는 GET /api/v1/attachments/:id/content 로 받는다.
+type QueueAttachment struct {
+	ID          string `json:"id"`
+	FileName    string `json:"file_name"`
+	ContentType string `json:"content_type"`
+	SizeBytes   int64  `json:"size_bytes"`
+	SHA256      string `json:"sha256"`
+}
+
 // QueueMessage represents the message structure sent to RabbitMQ.
 // id 는 사용자 식별자다 — database/schema.sql 과 DBWorker 가 그렇게 정의한다.
 // sub_id 는 Consumer 가 필수 문자열로 요구하므로 omitempty 를 쓰지 않는다.
+// room_id·recipient_id 는 방 메시지·1:1 메시지에만, parent_message_id·thread_root_id 는 답글에만 있고
+// DBWorker 가 messages 의 같은 이름 컬럼으로 저장한다. attachments 는 첨부가 있을 때만 있고 MainServer 가 클라이언트에 전달한다.
 type QueueMessage struct {
 	ID                   string                    `json:"id"`
 	SubID                string                    `json:"sub_id"`
+	RoomID               string                    `json:"room_id,omitempty"`
+	RecipientID          string                    `json:"recipient_id,omitempty"`
+	ParentMessageID      string                    `json:"parent_message_id,omitempty"`
+	ThreadRootID         string                    `json:"thread_root_id,omitempty"`
+	Attachments          []QueueAttachment         `json:"attachments,omitempty"`
 	PublisherInformation QueuePublisherInformation `json:"publisher_information"`
 	Message              QueueMessagePayload       `json:"message"`
 }
@@ -78,6 +122,41 @@ func (m *MessageRequest) Validate() error {
 		return fmt.Errorf("content is required")
 	}
 
+	if m.RoomID != "" {
+		if _, err := uuid.Parse(m.RoomID); err != nil {
+			return fmt.Errorf("room_id must be a UUID")
+		}
+	}
+
+	if m.ReplyTo != "" {
+		if _, err := uuid.Parse(m.ReplyTo); err != nil {
+			return fmt.Errorf("reply_to must be a message UUID")
+		}
+	}
+
+	if len(m.AttachmentIDs) > MaxAttachmentsPerMessage {
+		return fmt.Errorf("at most %d attachment_ids are allowed", MaxAttachmentsPerMessage)
+	}
+	seen := make(map[string]bool, len(m.AttachmentIDs))
+	for _, id := range m.AttachmentIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			return fmt.Errorf("attachment_ids must be attachment UUIDs")
+		}
+		if seen[id] {
+			return fmt.Errorf("attachment_ids must not repeat")
+		}
+		seen[id] = true
+	}
+
+	if m.RecipientID != "" {
+		if m.RoomID != "" {
+			return fmt.Errorf("room_id and recipient_id cannot be used together")
+		}
+		if m.RecipientID == m.UserID {
+			return fmt.Errorf("recipient_id must differ from user_id")
+		}
+	}
+
 	// Validate priority range
 	if m.Priority != 0 && (m.Priority < 1 || m.Priority > 3) {
 		return fmt.Errorf("priority must be between 1 and 3 when provided")
@@ -101,8 +180,13 @@ func (m *MessageRequest) ToQueueMessage(messageID string) *QueueMessage {
 	}
 
 	return &QueueMessage{
-		ID:    m.UserID,
-		SubID: m.SubID,
+		ID:              m.UserID,
+		SubID:           m.SubID,
+		RoomID:          m.RoomID,
+		RecipientID:     m.RecipientID,
+		ParentMessageID: m.ReplyTo,
+		ThreadRootID:    m.ThreadRootID,
+		Attachments:     m.Attachments,
 		PublisherInformation: QueuePublisherInformation{
 			MessageID: messageID,
 			Source:    "restapi",
@@ -119,6 +203,44 @@ func (m *MessageRequest) ToQueueMessage(messageID string) *QueueMessage {
 	}
 }
 
+// NewEditQueueMessage builds the edit event of a message.
+// publisher_information.message_id 는 수정된 메시지의 ID 이고, 목적지(room_id·recipient_id)는 원래 메시지와 같다.
+func NewEditQueueMessage(messageID, userID, roomID, recipientID, content string, editedAt time.Time) *QueueMessage {
+	return newEventQueueMessage(CommandEditMessage, messageID, userID, roomID, recipientID, content, editedAt)
+}
+
+// NewRecallQueueMessage builds the recall event of a message. 회수된 내용은 다시 보내지 않는다.
+func NewRecallQueueMessage(messageID, userID, roomID, recipientID string, recalledAt time.Time) *QueueMessage {
+	return newEventQueueMessage(CommandRecall, messageID, userID, roomID, recipientID, "", recalledAt)
+}
+
+// newEventQueueMessage builds an event about an already stored message
+func newEventQueueMessage(command, messageID, userID, roomID, recipientID, content string, at time.Time) *QueueMessage {
+	return &QueueMessage{
+		ID:          userID,
+		RoomID:      roomID,
+		RecipientID: recipientID,
+		PublisherInformation: QueuePublisherInformation{
+			MessageID: messageID,
+			Source:    "restapi",
+			Priority:  2,
+			CreatedAt: time.Now().Unix(),
+		},
+		Message: QueueMessagePayload{
+			Command:   command,
+			Content:   content,
+			Timestamp: at.UTC().Format(time.RFC3339),
+		},
+	}
+}
+
+// WithRequestID records the HTTP request that produced the message in publisher_information.
+// DBWorker 가 publisher_information 을 그대로 publisher_info 에 저장하므로 저장된 행에서 요청을 거꾸로 찾을 수 있다.
+func (q *QueueMessage) WithRequestID(requestID string) *QueueMessage {
+	q.PublisherInformation.RequestID = requestID
+	return q
+}
+
 // ToJSON converts QueueMessage to JSON bytes
 func (q *QueueMessage) ToJSON() ([]byte, error) {
 	return json.Marshal(q)
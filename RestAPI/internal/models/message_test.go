@@ -3,6 +3,7 @@ package models
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -61,6 +62,66 @@ func TestToQueueMessage_SubIDAlwaysPresent(t *testing.T) {
 	assert.IsType(t, "", value)
 }
 
+// 방 메시지는 room_id, 1:1 메시지는 recipient_id 를 최상위에 싣고, 브로드캐스트는 두 키를 생략한다.
+func TestToQueueMessage_RoomAndRecipient(t *testing.T) {
+	room := &MessageRequest{UserID: "u", Command: "c", Content: "x", RoomID: "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10"}
+	raw, err := room.ToQueueMessage("mid").ToJSON()
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10", decoded["room_id"])
+
+	broadcast := &MessageRequest{UserID: "u", Command: "c", Content: "x"}
+	raw, err = broadcast.ToQueueMessage("mid").ToJSON()
+	require.NoError(t, err)
+
+	decoded = nil
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.NotContains(t, decoded, "room_id")
+	assert.NotContains(t, decoded, "recipient_id")
+
+	direct := &MessageRequest{UserID: "u", Command: "c", Content: "x", RecipientID: "v"}
+	raw, err = direct.ToQueueMessage("mid").ToJSON()
+	require.NoError(t, err)
+
+	decoded = nil
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "u", decoded["id"], "id 는 여전히 보낸 사람이다")
+	assert.Equal(t, "v", decoded["recipient_id"])
+	assert.NotContains(t, decoded, "parent_message_id")
+}
+
+func TestToQueueMessage_Reply(t *testing.T) {
+	reply := &MessageRequest{UserID: "u", Command: "c", Content: "x", ReplyTo: "parent", ThreadRootID: "root"}
+	raw, err := reply.ToQueueMessage("mid").ToJSON()
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "parent", decoded["parent_message_id"])
+	assert.Equal(t, "root", decoded["thread_root_id"])
+}
+
+func TestToQueueMessage_Attachments(t *testing.T) {
+	request := &MessageRequest{UserID: "u", Command: "c", Content: "x", Attachments: []QueueAttachment{
+		{ID: "a1", FileName: "cat.png", ContentType: "image/png", SizeBytes: 42, SHA256: "abc"},
+	}}
+	raw, err := request.ToQueueMessage("mid").ToJSON()
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	attachments := decoded["attachments"].([]interface{})
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "image/png", attachments[0].(map[string]interface{})["content_type"])
+	assert.NotContains(t, decoded, "attachment_ids")
+
+	plain, err := (&MessageRequest{UserID: "u", Command: "c", Content: "x"}).ToQueueMessage("mid").ToJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(plain), "attachments")
+}
+
 func TestMessageRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -73,6 +134,17 @@ func TestMessageRequest_Validate(t *testing.T) {
 		{"missing content", MessageRequest{UserID: "u", Command: "c"}, true},
 		{"priority too high", MessageRequest{UserID: "u", Command: "c", Content: "x", Priority: 4}, true},
 		{"priority too low", MessageRequest{UserID: "u", Command: "c", Content: "x", Priority: -1}, true},
+		{"room message", MessageRequest{UserID: "u", Command: "c", Content: "x", RoomID: "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10"}, false},
+		{"room_id not a UUID", MessageRequest{UserID: "u", Command: "c", Content: "x", RoomID: "general"}, true},
+		{"direct message", MessageRequest{UserID: "u", Command: "c", Content: "x", RecipientID: "v"}, false},
+		{"direct message to self", MessageRequest{UserID: "u", Command: "c", Content: "x", RecipientID: "u"}, true},
+		{"room and recipient", MessageRequest{UserID: "u", Command: "c", Content: "x", RecipientID: "v", RoomID: "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10"}, true},
+		{"reply", MessageRequest{UserID: "u", Command: "c", Content: "x", ReplyTo: "3c1d7c52-2f0e-4c8e-9a3b-5d6e7f809a1b"}, false},
+		{"reply_to not a UUID", MessageRequest{UserID: "u", Command: "c", Content: "x", ReplyTo: "m1"}, true},
+		{"attachments", MessageRequest{UserID: "u", Command: "c", Content: "x", AttachmentIDs: []string{"3c1d7c52-2f0e-4c8e-9a3b-5d6e7f809a1b"}}, false},
+		{"attachment id not a UUID", MessageRequest{UserID: "u", Command: "c", Content: "x", AttachmentIDs: []string{"a1"}}, true},
+		{"attachment id repeated", MessageRequest{UserID: "u", Command: "c", Content: "x", AttachmentIDs: []string{"3c1d7c52-2f0e-4c8e-9a3b-5d6e7f809a1b", "3c1d7c52-2f0e-4c8e-9a3b-5d6e7f809a1b"}}, true},
+		{"too many attachments", MessageRequest{UserID: "u", Command: "c", Content: "x", AttachmentIDs: make([]string, MaxAttachmentsPerMessage+1)}, true},
 	}
 
 	for _, tc := range tests {
@@ -88,3 +160,49 @@ func TestMessageRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+// 수정 이벤트는 edit_message 명령으로 원래 메시지의 ID 와 목적지를 싣는다.
+func TestNewEditQueueMessage(t *testing.T) {
+	editedAt := time.Date(2026, 1, 5, 9, 30, 0, 0, time.UTC)
+	raw, err := NewEditQueueMessage("mid", "u", "", "v", "fixed", editedAt).ToJSON()
+	require.NoError(t, err)
+
+	var decoded QueueMessage
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, CommandEditMessage, decoded.Message.Command)
+	assert.Equal(t, "fixed", decoded.Message.Content)
+	assert.Equal(t, "2026-01-05T09:30:00Z", decoded.Message.Timestamp)
+	assert.Equal(t, "mid", decoded.PublisherInformation.MessageID)
+	assert.Equal(t, "v", decoded.RecipientID)
+}
+
+func TestNewRecallQueueMessage(t *testing.T) {
+	recalledAt := time.Date(2026, 1, 5, 9, 45, 0, 0, time.UTC)
+	raw, err := NewRecallQueueMessage("mid", "u", "room", "", recalledAt).ToJSON()
+	require.NoError(t, err)
+
+	var decoded QueueMessage
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, CommandRecall, decoded.Message.Command)
+	assert.Empty(t, decoded.Message.Content)
+	assert.Equal(t, "2026-01-05T09:45:00Z", decoded.Message.Timestamp)
+	assert.Equal(t, "mid", decoded.PublisherInformation.MessageID)
+	assert.Equal(t, "room", decoded.RoomID)
+}
+
+func TestQueueMessage_WithRequestID(t *testing.T) {
+	raw, err := NewRecallQueueMessage("mid", "u", "room", "", time.Now()).WithRequestID("req-1").ToJSON()
+	require.NoError(t, err)
+
+	var decoded struct {
+		PublisherInformation map[string]interface{} `json:"publisher_information"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "req-1", decoded.PublisherInformation["request_id"])
+	assert.Equal(t, "mid", decoded.PublisherInformation["message_id"])
+
+	// 요청 밖에서 만든 메시지는 request_id 를 싣지 않는다.
+	raw, err = NewRecallQueueMessage("mid", "u", "room", "", time.Now()).ToJSON()
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "request_id")
+}
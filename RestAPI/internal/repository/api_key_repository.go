@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// APIKey represents a service-to-service credential in the database.
+// 키 원문은 저장하지 않는다 — SecretHash 로만 대조하고 발급 응답에서 한 번만 보여준다.
+type APIKey struct {
+	ID          int64          `db:"id" json:"-"`
+	KeyID       string         `db:"key_id" json:"key_id"`
+	Name        string         `db:"name" json:"name"`
+	SecretHash  string         `db:"secret_hash" json:"-"`
+	OwnerUserID string         `db:"owner_user_id" json:"owner_user_id"`
+	Scopes      pq.StringArray `db:"scopes" json:"scopes"`
+	ExpiresAt   sql.NullTime   `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt   sql.NullTime   `db:"revoked_at" json:"revoked_at,omitempty"`
+	LastUsedAt  sql.NullTime   `db:"last_used_at" json:"last_used_at,omitempty"`
+	UsageCount  int64          `db:"usage_count" json:"usage_count"`
+	CreatedBy   string         `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+}
+
+// APIKeyRepository defines API key data access methods
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByKeyID(ctx context.Context, keyID string) (*APIKey, error)
+	List(ctx context.Context, ownerUserID string, limit, offset int) ([]*APIKey, error)
+	Count(ctx context.Context, ownerUserID string) (int64, error)
+	Revoke(ctx context.Context, keyID string) error
+	RecordUsage(ctx context.Context, keyID string, count int64, lastUsedAt time.Time) error
+}
+
+// apiKeyRepository implements APIKeyRepository
+type apiKeyRepository struct {
+	db *sqlx.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sqlx.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create creates a new API key
+func (r *apiKeyRepository) Create(ctx context.Context, key *APIKey) error {
+	query := `
+		INSERT INTO api_keys (key_id, name, secret_hash, owner_user_id, scopes, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowxContext(
+		ctx, query,
+		key.KeyID, key.Name, key.SecretHash, key.OwnerUserID, key.Scopes, key.ExpiresAt, key.CreatedBy,
+	).Scan(&key.ID, &key.CreatedAt)
+}
+
+// GetByKeyID retrieves an API key by its public identifier
+func (r *apiKeyRepository) GetByKeyID(ctx context.Context, keyID string) (*APIKey, error) {
+	query := `
+		SELECT id, key_id, name, secret_hash, owner_user_id, scopes, expires_at,
+		       revoked_at, last_used_at, usage_count, created_by, created_at
+		FROM api_keys
+		WHERE key_id = $1
+	`
+
+	// 인증은 '없는 키' 와 DB 장애를 구분해야 하므로 sql.ErrNoRows 를 감싸서 돌려준다.
+	var key APIKey
+	err := r.db.GetContext(ctx, &key, query, keyID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("api key not found: %s: %w", keyID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List retrieves API keys, optionally only those of one owner
+func (r *apiKeyRepository) List(ctx context.Context, ownerUserID string, limit, offset int) ([]*APIKey, error) {
+	query := `
+		SELECT id, key_id, name, secret_hash, owner_user_id, scopes, expires_at,
+		       revoked_at, last_used_at, usage_count, created_by, created_at
+		FROM api_keys
+		WHERE ($1 = '' OR owner_user_id = $1)
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	var keys []*APIKey
+	err := r.db.SelectContext(ctx, &keys, query, ownerUserID, limit, offset)
+	return keys, err
+}
+
+// Count returns the number of API keys, optionally only those of one owner
+func (r *apiKeyRepository) Count(ctx context.Context, ownerUserID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM api_keys WHERE ($1 = '' OR owner_user_id = $1)`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, ownerUserID)
+	return count, err
+}
+
+// Revoke marks an API key as revoked
+func (r *apiKeyRepository) Revoke(ctx context.Context, keyID string) error {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE key_id = $1 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, keyID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("api key not found: %s: %w", keyID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RecordUsage adds count to the usage counter and advances last_used_at
+func (r *apiKeyRepository) RecordUsage(ctx context.Context, keyID string, count int64, lastUsedAt time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET usage_count = usage_count + $1,
+		    last_used_at = GREATEST(COALESCE(last_used_at, $2), $2)
+		WHERE key_id = $3
+	`
+
+	_, err := r.db.ExecContext(ctx, query, count, lastUsedAt, keyID)
+	return err
+}
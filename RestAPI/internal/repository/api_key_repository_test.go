@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyColumns = []string{
+	"id", "key_id", "name", "secret_hash", "owner_user_id", "scopes", "expires_at",
+	"revoked_at", "last_used_at", "usage_count", "created_by", "created_at",
+}
+
+func TestAPIKeyRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	key := &APIKey{
+		KeyID:       "0123456789abcdef",
+		Name:        "publisher",
+		SecretHash:  "hash",
+		OwnerUserID: "publisher",
+		Scopes:      pq.StringArray{"messages:write"},
+		CreatedBy:   "root",
+	}
+
+	now := time.Now()
+	mock.ExpectQuery(`INSERT INTO api_keys`).
+		WithArgs(key.KeyID, key.Name, key.SecretHash, key.OwnerUserID, key.Scopes, key.ExpiresAt, key.CreatedBy).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(int64(1), now))
+
+	err := repo.Create(ctx, key)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), key.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAPIKeyRepository_GetByKeyID(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	t.Run("key found", func(t *testing.T) {
+		rows := sqlmock.NewRows(apiKeyColumns).
+			AddRow(int64(1), "0123456789abcdef", "publisher", "hash", "publisher", "{messages:write,messages:read}",
+				nil, nil, nil, int64(42), "root", time.Now())
+
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE key_id`).
+			WithArgs("0123456789abcdef").
+			WillReturnRows(rows)
+
+		key, err := repo.GetByKeyID(ctx, "0123456789abcdef")
+
+		assert.NoError(t, err)
+		assert.Equal(t, pq.StringArray{"messages:write", "messages:read"}, key.Scopes)
+		assert.Equal(t, int64(42), key.UsageCount)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("key not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT (.+) FROM api_keys WHERE key_id`).
+			WithArgs("missing").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByKeyID(ctx, "missing")
+
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyRepository_Revoke(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful revoke", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+			WithArgs("0123456789abcdef").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Revoke(ctx, "0123456789abcdef"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already revoked or missing", func(t *testing.T) {
+		mock.ExpectExec(`UPDATE api_keys SET revoked_at`).
+			WithArgs("missing").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := repo.Revoke(ctx, "missing")
+
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAPIKeyRepository_RecordUsage(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	usedAt := time.Now()
+	mock.ExpectExec(`UPDATE api_keys SET usage_count = usage_count \+ \$1`).
+		WithArgs(int64(5), usedAt, "0123456789abcdef").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.RecordUsage(ctx, "0123456789abcdef", 5, usedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ErrAttachmentSent is returned when an attachment was already sent with another message
+var ErrAttachmentSent = errors.New("attachment already sent")
+
+// Attachment is an uploaded file. 내용은 blob 저장소에 SHA256 을 키로 있고, 같은 내용의 업로드는 blob 을 함께 쓴다.
+type Attachment struct {
+	ID          string    `db:"id" json:"id"`
+	UploaderID  string    `db:"uploader_id" json:"uploader_id"`
+	MessageID   *string   `db:"message_id" json:"message_id,omitempty"`
+	FileName    string    `db:"file_name" json:"file_name"`
+	ContentType string    `db:"content_type" json:"content_type"`
+	SizeBytes   int64     `db:"size_bytes" json:"size_bytes"`
+	SHA256      string    `db:"sha256" json:"sha256"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// AttachmentRepository defines attachment data access methods.
+// 첨부는 업로드 때 message_id 없이 만들어지고, 메시지를 보낼 때 Claim 으로 그 메시지에 묶인다.
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *Attachment) error
+	Get(ctx context.Context, id string) (*Attachment, error)
+	Claim(ctx context.Context, uploaderID, messageID string, ids []string) ([]*Attachment, error)
+	Release(ctx context.Context, messageID string) error
+	ListByMessages(ctx context.Context, messageIDs []string) ([]*Attachment, error)
+	ListByUploader(ctx context.Context, uploaderID string) ([]*Attachment, error)
+	ListUnclaimed(ctx context.Context, before time.Time, limit int) ([]*Attachment, error)
+	DeleteUnclaimed(ctx context.Context, ids []string) error
+	DeleteBlob(ctx context.Context, sha256 string, exceptIDs []string, deleteBlob func(ctx context.Context) error) (bool, error)
+}
+
+// attachmentRepository implements AttachmentRepository
+type attachmentRepository struct {
+	db *sqlx.DB
+}
+
+// NewAttachmentRepository creates a new attachment repository
+func NewAttachmentRepository(db *sqlx.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+const attachmentColumns = `id, uploader_id, message_id, file_name, content_type, size_bytes, sha256, created_at`
+
+// lockBlobQuery serializes Create and DeleteBlob on the same content until the transaction ends.
+// 참조 확인과 blob 삭제 사이에 같은 내용의 업로드가 끼어들어 새 첨부가 지워진 blob 을 가리키는 일을 막는다.
+const lockBlobQuery = `SELECT pg_advisory_xact_lock(hashtext('attachments:' || $1))`
+
+// Create records an uploaded file. 업로더가 없으면 sql.ErrNoRows 를 감싸서 돌려준다.
+// 행이 커밋된 뒤에는 DeleteBlob 이 이 첨부를 참조로 보므로, 호출측은 Create 다음에 blob 을 올린다.
+func (r *attachmentRepository) Create(ctx context.Context, attachment *Attachment) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, lockBlobQuery, attachment.SHA256); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO attachments (uploader_id, file_name, content_type, size_bytes, sha256)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowxContext(ctx, query, attachment.UploaderID, attachment.FileName, attachment.ContentType,
+		attachment.SizeBytes, attachment.SHA256).Scan(&attachment.ID, &attachment.CreatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return fmt.Errorf("user not found: %s: %w", attachment.UploaderID, sql.ErrNoRows)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Get retrieves an attachment by ID
+func (r *attachmentRepository) Get(ctx context.Context, id string) (*Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1`
+
+	var attachment Attachment
+	err := r.db.GetContext(ctx, &attachment, query, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found: %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// Claim links the uploader's unsent attachments to a message, all or none, in the order of ids.
+// 업로더의 첨부가 아니거나 없는 ID 가 있으면 sql.ErrNoRows 를, 이미 다른 메시지로 보낸 첨부가 있으면
+// ErrAttachmentSent 를 감싸서 돌려준다. 행을 잠그므로 같은 첨부를 동시에 보내도 한 메시지만 가져간다.
+func (r *attachmentRepository) Claim(ctx context.Context, uploaderID, messageID string, ids []string) ([]*Attachment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var found []*Attachment
+	err = tx.SelectContext(ctx, &found, `
+		SELECT `+attachmentColumns+`
+		FROM attachments
+		WHERE id = ANY($1) AND uploader_id = $2
+		FOR UPDATE
+	`, pq.Array(ids), uploaderID)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*Attachment, len(found))
+	for _, attachment := range found {
+		byID[attachment.ID] = attachment
+	}
+
+	claimed := make([]*Attachment, 0, len(ids))
+	for _, id := range ids {
+		attachment, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("attachment not found: %s: %w", id, sql.ErrNoRows)
+		}
+		if attachment.MessageID != nil {
+			return nil, fmt.Errorf("%s: %w", id, ErrAttachmentSent)
+		}
+		attachment.MessageID = &messageID
+		claimed = append(claimed, attachment)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE attachments SET message_id = $1 WHERE id = ANY($2)`, messageID, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// Release unlinks the attachments of a message that was never published, so they can be sent again
+func (r *attachmentRepository) Release(ctx context.Context, messageID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE attachments SET message_id = NULL WHERE message_id = $1`, messageID)
+	return err
+}
+
+// ListByMessages retrieves the attachments of the given messages in upload order
+func (r *attachmentRepository) ListByMessages(ctx context.Context, messageIDs []string) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE message_id = ANY($1)
+		ORDER BY created_at, id
+	`
+
+	var attachments []*Attachment
+	err := r.db.SelectContext(ctx, &attachments, query, pq.Array(messageIDs))
+	return attachments, err
+}
+
+// ListByUploader retrieves every attachment uploaded by a user, sent or not, in upload order
+func (r *attachmentRepository) ListByUploader(ctx context.Context, uploaderID string) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE uploader_id = $1
+		ORDER BY created_at, id
+	`
+
+	var attachments []*Attachment
+	err := r.db.SelectContext(ctx, &attachments, query, uploaderID)
+	return attachments, err
+}
+
+// ListUnclaimed retrieves up to limit attachments uploaded before the given time and never sent, oldest first
+func (r *attachmentRepository) ListUnclaimed(ctx context.Context, before time.Time, limit int) ([]*Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE message_id IS NULL AND created_at < $1
+		ORDER BY created_at, id
+		LIMIT $2
+	`
+
+	var attachments []*Attachment
+	err := r.db.SelectContext(ctx, &attachments, query, before, limit)
+	return attachments, err
+}
+
+// DeleteUnclaimed removes the given attachments unless they were sent in the meantime
+func (r *attachmentRepository) DeleteUnclaimed(ctx context.Context, ids []string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = ANY($1) AND message_id IS NULL`, pq.Array(ids))
+	return err
+}
+
+// DeleteBlob calls deleteBlob when no attachment other than exceptIDs uses the content, and reports whether it did.
+// 삭제할 첨부 행을 지우기 전에 부른다 — 행이 남아 있으므로 중간에 실패해도 다시 호출하면 같은 blob 을 찾는다.
+func (r *attachmentRepository) DeleteBlob(ctx context.Context, sha256 string, exceptIDs []string, deleteBlob func(ctx context.Context) error) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, lockBlobQuery, sha256); err != nil {
+		return false, err
+	}
+
+	var referenced bool
+	err = tx.GetContext(ctx, &referenced, `
+		SELECT EXISTS(SELECT 1 FROM attachments WHERE sha256 = $1 AND NOT (id = ANY($2)))
+	`, sha256, pq.Array(exceptIDs))
+	if err != nil {
+		return false, err
+	}
+	if referenced {
+		return false, nil
+	}
+
+	// 잠금을 쥔 채로 지워야 그 사이에 같은 내용이 다시 올라오지 않는다.
+	if err := deleteBlob(ctx); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
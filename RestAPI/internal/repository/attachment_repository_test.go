@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var attachmentTestColumns = []string{"id", "uploader_id", "message_id", "file_name", "content_type", "size_bytes", "sha256", "created_at"}
+
+const testSHA256 = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+func TestAttachmentRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAttachmentRepository(db)
+	ctx := context.Background()
+
+	t.Run("created", func(t *testing.T) {
+		attachment := &Attachment{UploaderID: "alice", FileName: "hello.txt", ContentType: "text/plain", SizeBytes: 5, SHA256: testSHA256}
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(testSHA256).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`INSERT INTO attachments \(uploader_id, file_name, content_type, size_bytes, sha256\)`).
+			WithArgs("alice", "hello.txt", "text/plain", int64(5), testSHA256).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow("a1", time.Now()))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.Create(ctx, attachment))
+		assert.Equal(t, "a1", attachment.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("uploader missing", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(testSHA256).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`INSERT INTO attachments`).
+			WillReturnError(&pq.Error{Code: "23503"})
+		mock.ExpectRollback()
+
+		err := repo.Create(ctx, &Attachment{UploaderID: "ghost", SHA256: testSHA256})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAttachmentRepository_Claim(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAttachmentRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+	sent := "earlier-message"
+
+	t.Run("claims in request order", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM attachments WHERE id = ANY\(\$1\) AND uploader_id = \$2 FOR UPDATE`).
+			WithArgs(pq.Array([]string{"a2", "a1"}), "alice").
+			WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+				AddRow("a1", "alice", nil, "one.png", "image/png", 10, testSHA256, now).
+				AddRow("a2", "alice", nil, "two.png", "image/png", 20, testSHA256, now))
+		mock.ExpectExec(`UPDATE attachments SET message_id = \$1 WHERE id = ANY\(\$2\)`).
+			WithArgs("m1", pq.Array([]string{"a2", "a1"})).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		claimed, err := repo.Claim(ctx, "alice", "m1", []string{"a2", "a1"})
+
+		require.NoError(t, err)
+		require.Len(t, claimed, 2)
+		assert.Equal(t, "a2", claimed[0].ID)
+		assert.Equal(t, "m1", *claimed[1].MessageID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("someone else's attachment", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM attachments`).
+			WithArgs(pq.Array([]string{"a1", "bobs"}), "alice").
+			WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+				AddRow("a1", "alice", nil, "one.png", "image/png", 10, testSHA256, now))
+		mock.ExpectRollback()
+
+		_, err := repo.Claim(ctx, "alice", "m1", []string{"a1", "bobs"})
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already sent", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT (.+) FROM attachments`).
+			WithArgs(pq.Array([]string{"a1"}), "alice").
+			WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+				AddRow("a1", "alice", sent, "one.png", "image/png", 10, testSHA256, now))
+		mock.ExpectRollback()
+
+		_, err := repo.Claim(ctx, "alice", "m1", []string{"a1"})
+
+		assert.ErrorIs(t, err, ErrAttachmentSent)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAttachmentRepository_ListByMessages(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAttachmentRepository(db)
+
+	mock.ExpectQuery(`SELECT (.+) FROM attachments WHERE message_id = ANY\(\$1\) ORDER BY created_at, id`).
+		WithArgs(pq.Array([]string{"m1", "m2"})).
+		WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+			AddRow("a1", "alice", "m1", "one.png", "image/png", 10, testSHA256, time.Now()))
+
+	attachments, err := repo.ListByMessages(context.Background(), []string{"m1", "m2"})
+
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "m1", *attachments[0].MessageID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_ListUnclaimed(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAttachmentRepository(db)
+	before := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(`SELECT (.+) FROM attachments WHERE message_id IS NULL AND created_at < \$1 ORDER BY created_at, id LIMIT \$2`).
+		WithArgs(before, 100).
+		WillReturnRows(sqlmock.NewRows(attachmentTestColumns).
+			AddRow("a1", "alice", nil, "one.png", "image/png", 10, testSHA256, before.Add(-time.Minute)))
+
+	attachments, err := repo.ListUnclaimed(context.Background(), before, 100)
+
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Nil(t, attachments[0].MessageID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_DeleteUnclaimed(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAttachmentRepository(db)
+
+	mock.ExpectExec(`DELETE FROM attachments WHERE id = ANY\(\$1\) AND message_id IS NULL`).
+		WithArgs(pq.Array([]string{"a1", "a2"})).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	require.NoError(t, repo.DeleteUnclaimed(context.Background(), []string{"a1", "a2"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAttachmentRepository_DeleteBlob(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAttachmentRepository(db)
+	ctx := context.Background()
+
+	expectCheck := func(referenced bool) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtext\('attachments:' \|\| \$1\)\)`).
+			WithArgs(testSHA256).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM attachments WHERE sha256 = \$1 AND NOT \(id = ANY\(\$2\)\)\)`).
+			WithArgs(testSHA256, pq.Array([]string{"a1"})).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(referenced))
+	}
+
+	t.Run("unreferenced", func(t *testing.T) {
+		expectCheck(false)
+		mock.ExpectCommit()
+
+		calls := 0
+		deleted, err := repo.DeleteBlob(ctx, testSHA256, []string{"a1"}, func(context.Context) error {
+			calls++
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		assert.Equal(t, 1, calls)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("shared with another upload", func(t *testing.T) {
+		expectCheck(true)
+		mock.ExpectRollback()
+
+		deleted, err := repo.DeleteBlob(ctx, testSHA256, []string{"a1"}, func(context.Context) error {
+			t.Fatal("a shared blob must not be deleted")
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
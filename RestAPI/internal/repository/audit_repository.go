@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// AuditLog represents one recorded mutating operation
+type AuditLog struct {
+	ID         int64            `db:"id" json:"id"`
+	OccurredAt time.Time        `db:"occurred_at" json:"occurred_at"`
+	ActorID    string           `db:"actor_id" json:"actor_id"`
+	ActorType  string           `db:"actor_type" json:"actor_type"`
+	APIKeyID   string           `db:"api_key_id" json:"api_key_id,omitempty"`
+	Action     string           `db:"action" json:"action"`
+	TargetType string           `db:"target_type" json:"target_type"`
+	TargetID   string           `db:"target_id" json:"target_id"`
+	Before     *json.RawMessage `db:"before_value" json:"before,omitempty"`
+	After      *json.RawMessage `db:"after_value" json:"after,omitempty"`
+	RequestID  string           `db:"request_id" json:"request_id"`
+	ClientIP   string           `db:"client_ip" json:"client_ip"`
+	Outcome    string           `db:"outcome" json:"outcome"`
+	StatusCode int              `db:"status_code" json:"status_code"`
+}
+
+// AuditFilter narrows an audit log query; zero fields are ignored
+type AuditFilter struct {
+	ActorID    string
+	TargetType string
+	TargetID   string
+	Action     string
+	Since      *time.Time
+	Until      *time.Time
+}
+
+// AuditRepository defines audit log data access methods
+type AuditRepository interface {
+	Create(ctx context.Context, entry *AuditLog) error
+	List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditLog, error)
+	Count(ctx context.Context, filter AuditFilter) (int64, error)
+}
+
+// auditRepository implements AuditRepository
+type auditRepository struct {
+	db *sqlx.DB
+}
+
+// NewAuditRepository creates a new audit repository
+func NewAuditRepository(db *sqlx.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create appends an audit entry
+func (r *auditRepository) Create(ctx context.Context, entry *AuditLog) error {
+	query := `
+		INSERT INTO audit_log (occurred_at, actor_id, actor_type, api_key_id, action, target_type, target_id,
+		                       before_value, after_value, request_id, client_ip, outcome, status_code)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`
+
+	return r.db.QueryRowxContext(
+		ctx, query,
+		entry.OccurredAt, entry.ActorID, entry.ActorType, entry.APIKeyID, entry.Action, entry.TargetType, entry.TargetID,
+		jsonbParam(entry.Before), jsonbParam(entry.After), entry.RequestID, entry.ClientIP, entry.Outcome, entry.StatusCode,
+	).Scan(&entry.ID)
+}
+
+// List retrieves audit entries matching the filter, newest first
+func (r *auditRepository) List(ctx context.Context, filter AuditFilter, limit, offset int) ([]*AuditLog, error) {
+	where, args := filter.whereClause()
+	query := fmt.Sprintf(`
+		SELECT id, occurred_at, actor_id, actor_type, api_key_id, action, target_type, target_id,
+		       before_value, after_value, request_id, client_ip, outcome, status_code
+		FROM audit_log
+		%s
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	var entries []*AuditLog
+	err := r.db.SelectContext(ctx, &entries, query, append(args, limit, offset)...)
+	return entries, err
+}
+
+// Count returns the number of audit entries matching the filter
+func (r *auditRepository) Count(ctx context.Context, filter AuditFilter) (int64, error) {
+	where, args := filter.whereClause()
+	query := "SELECT COUNT(*) FROM audit_log " + where
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, args...)
+	return count, err
+}
+
+// whereClause builds the WHERE clause and its arguments.
+// 값은 전부 플레이스홀더로 넘기고 컬럼 이름만 고정 문자열로 붙인다.
+func (f AuditFilter) whereClause() (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if f.ActorID != "" {
+		add("actor_id = $%d", f.ActorID)
+	}
+	if f.TargetType != "" {
+		add("target_type = $%d", f.TargetType)
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.Since != nil {
+		add("occurred_at >= $%d", *f.Since)
+	}
+	if f.Until != nil {
+		add("occurred_at < $%d", *f.Until)
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// jsonbParam converts a JSON value for a JSONB parameter.
+// lib/pq 는 []byte 를 bytea 로 인코딩하므로 문자열로 넘겨야 JSONB 로 해석된다.
+func jsonbParam(value *json.RawMessage) interface{} {
+	if value == nil {
+		return nil
+	}
+	return string(*value)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var auditColumns = []string{
+	"id", "occurred_at", "actor_id", "actor_type", "api_key_id", "action", "target_type", "target_id",
+	"before_value", "after_value", "request_id", "client_ip", "outcome", "status_code",
+}
+
+func TestAuditRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAuditRepository(db)
+	ctx := context.Background()
+
+	after := json.RawMessage(`{"role":"admin"}`)
+	entry := &AuditLog{
+		OccurredAt: time.Now(),
+		ActorID:    "root",
+		ActorType:  "user",
+		Action:     "user.role.update",
+		TargetType: "user",
+		TargetID:   "alice",
+		After:      &after,
+		RequestID:  "req-1",
+		ClientIP:   "10.0.0.1",
+		Outcome:    "success",
+		StatusCode: 200,
+	}
+
+	// JSONB 값은 문자열로, 비어 있는 before 는 NULL 로 넘어가야 한다.
+	mock.ExpectQuery(`INSERT INTO audit_log`).
+		WithArgs(entry.OccurredAt, "root", "user", "", "user.role.update", "user", "alice",
+			nil, `{"role":"admin"}`, "req-1", "10.0.0.1", "success", 200).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(int64(7)))
+
+	err := repo.Create(ctx, entry)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), entry.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAuditRepository_List(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAuditRepository(db)
+	ctx := context.Background()
+
+	t.Run("without filter", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT (.+) FROM audit_log ORDER BY occurred_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(20, 0).
+			WillReturnRows(sqlmock.NewRows(auditColumns))
+
+		_, err := repo.List(ctx, AuditFilter{}, 20, 0)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("with filter", func(t *testing.T) {
+		since := time.Now().Add(-time.Hour)
+		rows := sqlmock.NewRows(auditColumns).
+			AddRow(int64(1), time.Now(), "root", "user", "", "user.role.update", "user", "alice",
+				[]byte(`{"role":"user"}`), []byte(`{"role":"admin"}`), "req-1", "10.0.0.1", "success", 200)
+
+		mock.ExpectQuery(`FROM audit_log WHERE actor_id = \$1 AND target_type = \$2 AND occurred_at >= \$3 ORDER BY (.+) LIMIT \$4 OFFSET \$5`).
+			WithArgs("root", "user", since, 20, 0).
+			WillReturnRows(rows)
+
+		entries, err := repo.List(ctx, AuditFilter{ActorID: "root", TargetType: "user", Since: &since}, 20, 0)
+
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.NotNil(t, entries[0].Before)
+		assert.JSONEq(t, `{"role":"user"}`, string(*entries[0].Before))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAuditRepository_Count(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewAuditRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM audit_log WHERE target_type = \$1 AND target_id = \$2`).
+		WithArgs("message", "msg-1").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+
+	count, err := repo.Count(ctx, AuditFilter{TargetType: "message", TargetID: "msg-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Erasure message modes
+const (
+	ErasureAnonymize = "anonymize"
+	ErasureDelete    = "delete"
+)
+
+// Erasure statuses
+const (
+	ErasurePending   = "pending"
+	ErasureRunning   = "running"
+	ErasureCompleted = "completed"
+)
+
+// ErasedUserID replaces user_id on anonymized messages.
+// 사용자별 가명을 쓰면 erasure 기록으로 다시 연결할 수 있으므로 모든 사용자에게 같은 값을 쓴다.
+const ErasedUserID = "erased"
+
+// ErrErasureExists is returned when an erasure was already requested for the user
+var ErrErasureExists = errors.New("erasure already requested")
+
+// UserErasure is an erasure request for one user; once completed it is the record that the erasure finished
+type UserErasure struct {
+	ID                int64        `db:"id" json:"id"`
+	UserID            string       `db:"user_id" json:"user_id"`
+	MessageMode       string       `db:"message_mode" json:"message_mode"`
+	Status            string       `db:"status" json:"status"`
+	RequestedBy       string       `db:"requested_by" json:"requested_by"`
+	MessagesProcessed int64        `db:"messages_processed" json:"messages_processed"`
+	CachePurged       bool         `db:"cache_purged" json:"cache_purged"`
+	Attempts          int          `db:"attempts" json:"attempts"`
+	LastError         string       `db:"last_error" json:"last_error,omitempty"`
+	LeaseUntil        sql.NullTime `db:"lease_until" json:"-"`
+	RequestedAt       time.Time    `db:"requested_at" json:"requested_at"`
+	StartedAt         sql.NullTime `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt       sql.NullTime `db:"completed_at" json:"completed_at,omitempty"`
+}
+
+// ErasureRepository defines user erasure data access methods.
+// 작업은 임대(lease) 방식이다 — 작업을 쥔 워커가 배치마다 lease_until 을 연장하고, 중단돼 만료되면 다른 워커가 이어받는다.
+type ErasureRepository interface {
+	Create(ctx context.Context, erasure *UserErasure) error
+	GetByUserID(ctx context.Context, userID string) (*UserErasure, error)
+	ClaimNext(ctx context.Context, lease time.Duration) (*UserErasure, error)
+	ProcessMessages(ctx context.Context, erasure *UserErasure, batchSize int, lease time.Duration) ([]string, error)
+	MarkCachePurged(ctx context.Context, id int64) error
+	Complete(ctx context.Context, erasure *UserErasure) error
+	RecordFailure(ctx context.Context, id int64, message string) error
+	Release(ctx context.Context, id int64) error
+}
+
+// erasureRepository implements ErasureRepository
+type erasureRepository struct {
+	db *sqlx.DB
+}
+
+// NewErasureRepository creates a new erasure repository
+func NewErasureRepository(db *sqlx.DB) ErasureRepository {
+	return &erasureRepository{db: db}
+}
+
+// Create records an erasure request for a user, including a soft-deleted one.
+// 사용자가 없으면 sql.ErrNoRows 를, 이미 요청된 사용자면 ErrErasureExists 를 감싸서 돌려준다.
+func (r *erasureRepository) Create(ctx context.Context, erasure *UserErasure) error {
+	query := `
+		INSERT INTO user_erasures (user_id, message_mode, requested_by)
+		SELECT $1, $2, $3
+		WHERE EXISTS (SELECT 1 FROM users WHERE user_id = $1)
+		RETURNING id, status, requested_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, erasure.UserID, erasure.MessageMode, erasure.RequestedBy).
+		Scan(&erasure.ID, &erasure.Status, &erasure.RequestedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found: %s: %w", erasure.UserID, err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%s: %w", erasure.UserID, ErrErasureExists)
+	}
+	return err
+}
+
+// GetByUserID retrieves the erasure request of a user
+func (r *erasureRepository) GetByUserID(ctx context.Context, userID string) (*UserErasure, error) {
+	query := `
+		SELECT id, user_id, message_mode, status, requested_by, messages_processed, cache_purged,
+		       attempts, last_error, lease_until, requested_at, started_at, completed_at
+		FROM user_erasures
+		WHERE user_id = $1
+	`
+
+	var erasure UserErasure
+	err := r.db.GetContext(ctx, &erasure, query, userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("erasure not found: %s: %w", userID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &erasure, nil
+}
+
+// ClaimNext takes the oldest unfinished erasure whose lease is free and leases it; it returns nil when there is none.
+// SKIP LOCKED 로 여러 레플리카가 같은 작업을 동시에 집지 않는다.
+func (r *erasureRepository) ClaimNext(ctx context.Context, lease time.Duration) (*UserErasure, error) {
+	query := `
+		UPDATE user_erasures
+		SET status = 'running',
+		    attempts = attempts + 1,
+		    started_at = COALESCE(started_at, CURRENT_TIMESTAMP),
+		    lease_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+		WHERE id = (
+			SELECT id FROM user_erasures
+			WHERE status <> 'completed' AND (lease_until IS NULL OR lease_until < CURRENT_TIMESTAMP)
+			ORDER BY requested_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, message_mode, status, requested_by, messages_processed, cache_purged,
+		          attempts, last_error, lease_until, requested_at, started_at, completed_at
+	`
+
+	var erasure UserErasure
+	err := r.db.GetContext(ctx, &erasure, query, lease.Seconds())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &erasure, nil
+}
+
+// ProcessMessages anonymizes or deletes up to batchSize of the user's messages and returns their message IDs.
+// 메시지 변경과 진행 카운터·lease 연장을 한 트랜잭션으로 커밋하므로, 어느 시점에 끊겨도 남은 메시지부터 다시 시작하면 된다.
+func (r *erasureRepository) ProcessMessages(ctx context.Context, erasure *UserErasure, batchSize int, lease time.Duration) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var messageIDs []string
+	switch erasure.MessageMode {
+	case ErasureAnonymize:
+		err = tx.SelectContext(ctx, &messageIDs, `
+			UPDATE messages
+			SET user_id = $2, sub_id = '', publisher_info = '{}'
+			WHERE id IN (SELECT id FROM messages WHERE user_id = $1 ORDER BY id LIMIT $3)
+			RETURNING message_id
+		`, erasure.UserID, ErasedUserID, batchSize)
+	case ErasureDelete:
+		err = tx.SelectContext(ctx, &messageIDs, `
+			DELETE FROM messages
+			WHERE id IN (SELECT id FROM messages WHERE user_id = $1 ORDER BY id LIMIT $2)
+			RETURNING message_id
+		`, erasure.UserID, batchSize)
+	default:
+		return nil, fmt.Errorf("unknown erasure message mode: %s", erasure.MessageMode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_erasures
+		SET messages_processed = messages_processed + $1,
+		    lease_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id = $3
+	`, len(messageIDs), lease.Seconds(), erasure.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	erasure.MessagesProcessed += int64(len(messageIDs))
+	return messageIDs, nil
+}
+
+// MarkCachePurged records that the user's Redis keys were removed
+func (r *erasureRepository) MarkCachePurged(ctx context.Context, id int64) error {
+	query := `UPDATE user_erasures SET cache_purged = TRUE WHERE id = $1`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// Complete removes the users row, clears the user's profile from the audit log, expires the user's exports,
+// drops the user's moderation flags and webhook deliveries, and marks the erasure completed.
+// api_keys 는 ON DELETE CASCADE 로 함께 지워진다.
+func (r *erasureRepository) Complete(ctx context.Context, erasure *UserErasure) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE user_id = $1`, erasure.UserID); err != nil {
+		return err
+	}
+
+	// 감사 기록은 남기되 변경 전후 값에 들어 있는 프로필은 지운다.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE audit_log
+		SET before_value = NULL, after_value = NULL
+		WHERE target_type = 'user' AND target_id = $1
+	`, erasure.UserID)
+	if err != nil {
+		return err
+	}
+
+	// 내보낸 압축 파일에도 개인정보가 있다 — 만료시켜 export 워커가 다음 정리 때 지우게 한다.
+	_, err = tx.ExecContext(ctx, `
+		UPDATE user_exports
+		SET expires_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND status = 'completed'
+	`, erasure.UserID)
+	if err != nil {
+		return err
+	}
+
+	// 검토 대기열에는 보낸 내용이 평문으로 남아 있다.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM moderation_flags WHERE user_id = $1`, erasure.UserID); err != nil {
+		return err
+	}
+
+	// 웹훅 전송 기록의 payload 에도 사용자 정보가 담겨 있다.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE user_id = $1`, erasure.UserID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		UPDATE user_erasures
+		SET status = 'completed', completed_at = CURRENT_TIMESTAMP, lease_until = NULL, last_error = ''
+		WHERE id = $1
+		RETURNING completed_at
+	`, erasure.ID).Scan(&erasure.CompletedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	erasure.Status = ErasureCompleted
+	erasure.LastError = ""
+	return nil
+}
+
+// RecordFailure stores the last error; the job is retried once its lease expires
+func (r *erasureRepository) RecordFailure(ctx context.Context, id int64, message string) error {
+	query := `UPDATE user_erasures SET last_error = $1 WHERE id = $2`
+
+	_, err := r.db.ExecContext(ctx, query, message, id)
+	return err
+}
+
+// Release frees the lease so the job can be resumed right away, e.g. on shutdown
+func (r *erasureRepository) Release(ctx context.Context, id int64) error {
+	query := `UPDATE user_erasures SET lease_until = NULL WHERE id = $1 AND status <> 'completed'`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var erasureColumns = []string{
+	"id", "user_id", "message_mode", "status", "requested_by", "messages_processed", "cache_purged",
+	"attempts", "last_error", "lease_until", "requested_at", "started_at", "completed_at",
+}
+
+func TestErasureRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewErasureRepository(db)
+	ctx := context.Background()
+
+	t.Run("created", func(t *testing.T) {
+		erasure := &UserErasure{UserID: "alice", MessageMode: ErasureAnonymize, RequestedBy: "root"}
+
+		mock.ExpectQuery(`INSERT INTO user_erasures (.+) WHERE EXISTS \(SELECT 1 FROM users WHERE user_id = \$1\)`).
+			WithArgs("alice", ErasureAnonymize, "root").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "requested_at"}).AddRow(int64(3), ErasurePending, time.Now()))
+
+		err := repo.Create(ctx, erasure)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), erasure.ID)
+		assert.Equal(t, ErasurePending, erasure.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO user_erasures`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "requested_at"}))
+
+		err := repo.Create(ctx, &UserErasure{UserID: "ghost", MessageMode: ErasureDelete})
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already requested", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO user_erasures`).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "user_erasures_user_id_key"})
+
+		err := repo.Create(ctx, &UserErasure{UserID: "alice", MessageMode: ErasureDelete})
+
+		assert.ErrorIs(t, err, ErrErasureExists)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestErasureRepository_ClaimNext(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewErasureRepository(db)
+	ctx := context.Background()
+
+	t.Run("claims the oldest free job", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(erasureColumns).
+			AddRow(int64(3), "alice", ErasureAnonymize, ErasureRunning, "root", int64(500), false,
+				2, "connection reset", now.Add(5*time.Minute), now, now, nil)
+
+		mock.ExpectQuery(`UPDATE user_erasures SET status = 'running'(.+)FOR UPDATE SKIP LOCKED`).
+			WithArgs(float64(300)).
+			WillReturnRows(rows)
+
+		erasure, err := repo.ClaimNext(ctx, 5*time.Minute)
+
+		require.NoError(t, err)
+		require.NotNil(t, erasure)
+		assert.Equal(t, "alice", erasure.UserID)
+		assert.Equal(t, int64(500), erasure.MessagesProcessed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE user_erasures`).
+			WillReturnRows(sqlmock.NewRows(erasureColumns))
+
+		erasure, err := repo.ClaimNext(ctx, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Nil(t, erasure)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestErasureRepository_ProcessMessages(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewErasureRepository(db)
+	ctx := context.Background()
+
+	t.Run("anonymize commits messages and progress together", func(t *testing.T) {
+		erasure := &UserErasure{ID: 3, UserID: "alice", MessageMode: ErasureAnonymize, MessagesProcessed: 10}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`UPDATE messages SET user_id = \$2, sub_id = '', publisher_info = '\{\}'(.+)WHERE user_id = \$1 ORDER BY id LIMIT \$3`).
+			WithArgs("alice", ErasedUserID, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"message_id"}).AddRow("m-1").AddRow("m-2"))
+		mock.ExpectExec(`UPDATE user_erasures SET messages_processed = messages_processed \+ \$1`).
+			WithArgs(2, float64(60), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		messageIDs, err := repo.ProcessMessages(ctx, erasure, 2, time.Minute)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m-1", "m-2"}, messageIDs)
+		assert.Equal(t, int64(12), erasure.MessagesProcessed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("delete rolls back on error", func(t *testing.T) {
+		erasure := &UserErasure{ID: 4, UserID: "bob", MessageMode: ErasureDelete}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`DELETE FROM messages`).
+			WithArgs("bob", 100).
+			WillReturnError(sql.ErrConnDone)
+		mock.ExpectRollback()
+
+		_, err := repo.ProcessMessages(ctx, erasure, 100, time.Minute)
+
+		assert.Error(t, err)
+		assert.Zero(t, erasure.MessagesProcessed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestErasureRepository_Complete(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewErasureRepository(db)
+	ctx := context.Background()
+
+	erasure := &UserErasure{ID: 3, UserID: "alice", Status: ErasureRunning}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM users WHERE user_id = \$1`).
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE audit_log SET before_value = NULL, after_value = NULL`).
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`UPDATE user_exports SET expires_at = CURRENT_TIMESTAMP`).
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM moderation_flags WHERE user_id = \$1`).
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM webhook_deliveries WHERE user_id = \$1`).
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`UPDATE user_erasures SET status = 'completed'`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"completed_at"}).AddRow(time.Now()))
+	mock.ExpectCommit()
+
+	err := repo.Complete(ctx, erasure)
+
+	require.NoError(t, err)
+	assert.Equal(t, ErasureCompleted, erasure.Status)
+	assert.True(t, erasure.CompletedAt.Valid)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Export statuses
+const (
+	ExportPending   = "pending"
+	ExportRunning   = "running"
+	ExportCompleted = "completed"
+	ExportFailed    = "failed"
+	ExportExpired   = "expired"
+)
+
+// ErrExportInProgress is returned when the user already has a pending or running export
+var ErrExportInProgress = errors.New("export already in progress")
+
+// UserExport is a data export job for one user.
+// DownloadURL 은 DB 컬럼이 아니다 — 핸들러가 다운로드 가능한 작업에만 채운다.
+type UserExport struct {
+	ID          string       `db:"id" json:"id"`
+	UserID      string       `db:"user_id" json:"user_id"`
+	Status      string       `db:"status" json:"status"`
+	RequestedBy string       `db:"requested_by" json:"requested_by"`
+	FileName    string       `db:"file_name" json:"-"`
+	SizeBytes   int64        `db:"size_bytes" json:"size_bytes"`
+	Attempts    int          `db:"attempts" json:"attempts"`
+	LastError   string       `db:"last_error" json:"last_error,omitempty"`
+	LeaseUntil  sql.NullTime `db:"lease_until" json:"-"`
+	RequestedAt time.Time    `db:"requested_at" json:"requested_at"`
+	StartedAt   sql.NullTime `db:"started_at" json:"started_at,omitempty"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt   sql.NullTime `db:"expires_at" json:"expires_at,omitempty"`
+	DownloadURL string       `db:"-" json:"download_url,omitempty"`
+}
+
+// Downloadable reports whether the archive can be downloaded at now
+func (e *UserExport) Downloadable(now time.Time) bool {
+	return e.Status == ExportCompleted && e.ExpiresAt.Valid && now.Before(e.ExpiresAt.Time)
+}
+
+// ExportRepository defines user export data access methods.
+// 작업은 erasure 와 같은 임대(lease) 방식이다 — 다만 압축 파일은 이어 쓸 수 없으므로 재시도는 처음부터 다시 만든다.
+type ExportRepository interface {
+	Create(ctx context.Context, export *UserExport) error
+	Get(ctx context.Context, userID, exportID string) (*UserExport, error)
+	ClaimNext(ctx context.Context, lease time.Duration) (*UserExport, error)
+	ExtendLease(ctx context.Context, id string, lease time.Duration) error
+	Complete(ctx context.Context, export *UserExport, ttl time.Duration) error
+	RecordFailure(ctx context.Context, id, message string, final bool) error
+	Release(ctx context.Context, id string) error
+	ListExpired(ctx context.Context, limit int) ([]*UserExport, error)
+	MarkExpired(ctx context.Context, id string) error
+}
+
+// exportRepository implements ExportRepository
+type exportRepository struct {
+	db *sqlx.DB
+}
+
+// NewExportRepository creates a new export repository
+func NewExportRepository(db *sqlx.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+const exportColumns = `id, user_id, status, requested_by, file_name, size_bytes, attempts, last_error,
+	lease_until, requested_at, started_at, completed_at, expires_at`
+
+// Create records an export request for an active user.
+// 사용자가 없으면 sql.ErrNoRows 를, 진행 중인 작업이 있으면 ErrExportInProgress 를 감싸서 돌려준다.
+func (r *exportRepository) Create(ctx context.Context, export *UserExport) error {
+	query := `
+		INSERT INTO user_exports (user_id, requested_by)
+		SELECT $1, $2
+		WHERE EXISTS (SELECT 1 FROM users WHERE user_id = $1 AND deleted_at IS NULL)
+		RETURNING id, status, requested_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, export.UserID, export.RequestedBy).
+		Scan(&export.ID, &export.Status, &export.RequestedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found: %s: %w", export.UserID, err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return fmt.Errorf("%s: %w", export.UserID, ErrExportInProgress)
+	}
+	return err
+}
+
+// Get retrieves an export of a user
+func (r *exportRepository) Get(ctx context.Context, userID, exportID string) (*UserExport, error) {
+	query := `SELECT ` + exportColumns + ` FROM user_exports WHERE id = $1 AND user_id = $2`
+
+	var export UserExport
+	err := r.db.GetContext(ctx, &export, query, exportID, userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("export not found: %s: %w", exportID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ClaimNext takes the oldest unfinished export whose lease is free and leases it; it returns nil when there is none
+func (r *exportRepository) ClaimNext(ctx context.Context, lease time.Duration) (*UserExport, error) {
+	query := `
+		UPDATE user_exports
+		SET status = 'running',
+		    attempts = attempts + 1,
+		    started_at = COALESCE(started_at, CURRENT_TIMESTAMP),
+		    lease_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+		WHERE id = (
+			SELECT id FROM user_exports
+			WHERE status IN ('pending', 'running') AND (lease_until IS NULL OR lease_until < CURRENT_TIMESTAMP)
+			ORDER BY requested_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportColumns
+
+	var export UserExport
+	err := r.db.GetContext(ctx, &export, query, lease.Seconds())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// ExtendLease renews the lease of a running export
+func (r *exportRepository) ExtendLease(ctx context.Context, id string, lease time.Duration) error {
+	query := `
+		UPDATE user_exports
+		SET lease_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+		WHERE id = $2 AND status = 'running'
+	`
+
+	_, err := r.db.ExecContext(ctx, query, lease.Seconds(), id)
+	return err
+}
+
+// Complete records the written archive and makes it downloadable for ttl
+func (r *exportRepository) Complete(ctx context.Context, export *UserExport, ttl time.Duration) error {
+	query := `
+		UPDATE user_exports
+		SET status = 'completed', file_name = $1, size_bytes = $2, last_error = '', lease_until = NULL,
+		    completed_at = CURRENT_TIMESTAMP,
+		    expires_at = CURRENT_TIMESTAMP + make_interval(secs => $3)
+		WHERE id = $4
+		RETURNING completed_at, expires_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, export.FileName, export.SizeBytes, ttl.Seconds(), export.ID).
+		Scan(&export.CompletedAt, &export.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	export.Status = ExportCompleted
+	export.LastError = ""
+	return nil
+}
+
+// RecordFailure stores the last error; a final failure ends the job, otherwise it is retried once its lease expires
+func (r *exportRepository) RecordFailure(ctx context.Context, id, message string, final bool) error {
+	query := `
+		UPDATE user_exports
+		SET last_error = $1,
+		    status = CASE WHEN $2 THEN 'failed' ELSE status END,
+		    lease_until = CASE WHEN $2 THEN NULL ELSE lease_until END
+		WHERE id = $3
+	`
+
+	_, err := r.db.ExecContext(ctx, query, message, final, id)
+	return err
+}
+
+// Release frees the lease so the job can be retried right away, e.g. on shutdown
+func (r *exportRepository) Release(ctx context.Context, id string) error {
+	query := `UPDATE user_exports SET lease_until = NULL WHERE id = $1 AND status = 'running'`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// ListExpired returns completed exports whose download window has passed
+func (r *exportRepository) ListExpired(ctx context.Context, limit int) ([]*UserExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM user_exports
+		WHERE status = 'completed' AND expires_at < CURRENT_TIMESTAMP
+		ORDER BY expires_at
+		LIMIT $1
+	`
+
+	var exports []*UserExport
+	err := r.db.SelectContext(ctx, &exports, query, limit)
+	return exports, err
+}
+
+// MarkExpired records that the archive was removed
+func (r *exportRepository) MarkExpired(ctx context.Context, id string) error {
+	query := `UPDATE user_exports SET status = 'expired', file_name = '' WHERE id = $1 AND status = 'completed'`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exportTestColumns = []string{
+	"id", "user_id", "status", "requested_by", "file_name", "size_bytes", "attempts", "last_error",
+	"lease_until", "requested_at", "started_at", "completed_at", "expires_at",
+}
+
+const testExportID = "5b8f0f5e-3c1a-4d8e-9f7a-2f6b1c0d9e11"
+
+func TestExportRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewExportRepository(db)
+	ctx := context.Background()
+
+	t.Run("created", func(t *testing.T) {
+		export := &UserExport{UserID: "alice", RequestedBy: "alice"}
+
+		mock.ExpectQuery(`INSERT INTO user_exports (.+) WHERE EXISTS \(SELECT 1 FROM users WHERE user_id = \$1 AND deleted_at IS NULL\)`).
+			WithArgs("alice", "alice").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "requested_at"}).AddRow(testExportID, ExportPending, time.Now()))
+
+		err := repo.Create(ctx, export)
+
+		assert.NoError(t, err)
+		assert.Equal(t, testExportID, export.ID)
+		assert.Equal(t, ExportPending, export.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO user_exports`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status", "requested_at"}))
+
+		err := repo.Create(ctx, &UserExport{UserID: "ghost"})
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already in progress", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO user_exports`).
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "idx_user_exports_open"})
+
+		err := repo.Create(ctx, &UserExport{UserID: "alice"})
+
+		assert.ErrorIs(t, err, ErrExportInProgress)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestExportRepository_ClaimNext(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewExportRepository(db)
+	ctx := context.Background()
+
+	t.Run("claims the oldest free job", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows(exportTestColumns).
+			AddRow(testExportID, "alice", ExportRunning, "alice", "", int64(0), 1, "",
+				now.Add(10*time.Minute), now, now, nil, nil)
+
+		mock.ExpectQuery(`UPDATE user_exports SET status = 'running'(.+)WHERE status IN \('pending', 'running'\)(.+)FOR UPDATE SKIP LOCKED`).
+			WithArgs(float64(600)).
+			WillReturnRows(rows)
+
+		export, err := repo.ClaimNext(ctx, 10*time.Minute)
+
+		require.NoError(t, err)
+		require.NotNil(t, export)
+		assert.Equal(t, "alice", export.UserID)
+		assert.Equal(t, 1, export.Attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to do", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE user_exports`).
+			WillReturnRows(sqlmock.NewRows(exportTestColumns))
+
+		export, err := repo.ClaimNext(ctx, time.Minute)
+
+		assert.NoError(t, err)
+		assert.Nil(t, export)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestExportRepository_Complete(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewExportRepository(db)
+	ctx := context.Background()
+
+	now := time.Now()
+	export := &UserExport{ID: testExportID, UserID: "alice", Status: ExportRunning, FileName: testExportID + ".zip", SizeBytes: 2048}
+
+	mock.ExpectQuery(`UPDATE user_exports SET status = 'completed'(.+)expires_at = CURRENT_TIMESTAMP \+ make_interval\(secs => \$3\)`).
+		WithArgs(testExportID+".zip", int64(2048), float64(86400), testExportID).
+		WillReturnRows(sqlmock.NewRows([]string{"completed_at", "expires_at"}).AddRow(now, now.Add(24*time.Hour)))
+
+	err := repo.Complete(ctx, export, 24*time.Hour)
+
+	require.NoError(t, err)
+	assert.Equal(t, ExportCompleted, export.Status)
+	assert.True(t, export.Downloadable(now))
+	assert.False(t, export.Downloadable(now.Add(25*time.Hour)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestExportRepository_RecordFailure(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewExportRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectExec(`UPDATE user_exports SET last_error = \$1, status = CASE WHEN \$2 THEN 'failed' ELSE status END`).
+		WithArgs("disk full", true, testExportID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.RecordFailure(ctx, testExportID, "disk full", true)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -7,11 +7,13 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // Message represents a message in the database
 // Message mirrors the messages table defined in database/schema.sql.
 // 생산자는 두 곳이다 — CommonModule/DBWorker(C++) 가 INSERT 하고 이 리포지토리가 조회·전이한다.
+// RoomID 는 대화방 메시지에만, RecipientID 는 1:1 메시지에만 있고 전체 브로드캐스트는 둘 다 nil 이다.
 type Message struct {
 	ID            int64        `db:"id" json:"id"`
 	MessageID     string       `db:"message_id" json:"message_id"`
@@ -25,6 +27,62 @@ type Message struct {
 	Status        string       `db:"status" json:"status"`
 	CreatedAt     time.Time    `db:"created_at" json:"created_at"`
 	ProcessedAt   sql.NullTime `db:"processed_at" json:"processed_at,omitempty"`
+	RoomID        *string      `db:"room_id" json:"room_id,omitempty"`
+	RecipientID   *string      `db:"recipient_id" json:"recipient_id,omitempty"`
+	EditedAt      *time.Time   `db:"edited_at" json:"edited_at,omitempty"`
+	RecalledAt    *time.Time   `db:"recalled_at" json:"recalled_at,omitempty"`
+	RecalledBy    *string      `db:"recalled_by" json:"recalled_by,omitempty"`
+
+	// 답글은 parent_message_id 로 원글을, thread_root_id 로 스레드의 첫 메시지를 가리킨다.
+	// ReplyCount·LatestReply 는 스레드 첫 메시지에만 서비스가 채운다.
+	ParentMessageID *string  `db:"parent_message_id" json:"parent_message_id,omitempty"`
+	ThreadRootID    *string  `db:"thread_root_id" json:"thread_root_id,omitempty"`
+	ReplyCount      int64    `db:"-" json:"reply_count,omitempty"`
+	LatestReply     *Message `db:"-" json:"latest_reply,omitempty"`
+
+	// Reactions counts the users that reacted with each emoji, filled by the service on read
+	Reactions map[string]int64 `db:"-" json:"reactions,omitempty"`
+
+	// Attachments are the files sent with the message, filled by the service on read
+	Attachments []*Attachment `db:"-" json:"attachments,omitempty"`
+}
+
+// tombstone hides the content of a recalled message.
+// 내용은 감사용으로 DB 에 남지만 어떤 조회 경로로도 나가지 않도록 리포지토리가 읽는 즉시 지운다.
+func (m *Message) tombstone() {
+	if m.RecalledAt != nil {
+		m.Content = ""
+		m.IsEncrypted = false
+	}
+}
+
+// tombstoned applies tombstone to every message of a list
+func tombstoned(messages []*Message) []*Message {
+	for _, message := range messages {
+		message.tombstone()
+	}
+	return messages
+}
+
+// Conversation is a user's direct message exchange with one peer, summarized by its latest message
+type Conversation struct {
+	PeerID  string `db:"peer_id" json:"peer_id"`
+	Message `json:"last_message"`
+}
+
+// ThreadSummary is the reply count and the latest reply of a thread
+type ThreadSummary struct {
+	ReplyCount  int64
+	LatestReply *Message
+}
+
+// MessageRevision is a prior version of an edited message
+type MessageRevision struct {
+	ID          int64     `db:"id" json:"id"`
+	MessageID   string    `db:"message_id" json:"message_id"`
+	Content     string    `db:"content" json:"content"`
+	IsEncrypted bool      `db:"is_encrypted" json:"is_encrypted"`
+	ReplacedAt  time.Time `db:"replaced_at" json:"replaced_at"`
 }
 
 // MessageRepository defines message data access methods
@@ -32,15 +90,31 @@ type MessageRepository interface {
 	Create(ctx context.Context, message *Message) error
 	GetByMessageID(ctx context.Context, messageID string) (*Message, error)
 	GetByID(ctx context.Context, id int64) (*Message, error)
-	UpdateStatus(ctx context.Context, messageID string, status string) error
+	UpdateStatus(ctx context.Context, messageID string, status string) (previous string, err error)
 	MarkAsProcessed(ctx context.Context, messageID string) error
+	Recall(ctx context.Context, messageID, recalledBy string) (message *Message, changed bool, err error)
+	UpdateContent(ctx context.Context, messageID, content string, isEncrypted bool) (*Message, error)
+	ListRevisions(ctx context.Context, messageID string, limit, offset int) ([]*MessageRevision, error)
+	CountRevisions(ctx context.Context, messageID string) (int64, error)
 	ListByUser(ctx context.Context, userID string, limit, offset int) ([]*Message, error)
+	ListByUserAfter(ctx context.Context, userID string, afterID int64, limit int) ([]*Message, error)
+	ListByRoom(ctx context.Context, roomID string, limit, offset int) ([]*Message, error)
 	ListByStatus(ctx context.Context, status string, limit, offset int) ([]*Message, error)
 	ListRecent(ctx context.Context, limit, offset int) ([]*Message, error)
+	ListByRequestID(ctx context.Context, requestID string, limit int) ([]*Message, error)
 	Delete(ctx context.Context, messageID string) error
 	Count(ctx context.Context) (int64, error)
 	CountByUser(ctx context.Context, userID string) (int64, error)
+	CountByRoom(ctx context.Context, roomID string) (int64, error)
+	ListConversations(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error)
+	CountConversations(ctx context.Context, userID string) (int64, error)
+	ListThread(ctx context.Context, userID, peerID string, limit, offset int) ([]*Message, error)
+	CountThread(ctx context.Context, userID, peerID string) (int64, error)
 	CountByStatus(ctx context.Context, status string) (int64, error)
+	ListReplies(ctx context.Context, rootID string, limit, offset int) ([]*Message, error)
+	CountReplies(ctx context.Context, rootID string) (int64, error)
+	ThreadSummaries(ctx context.Context, rootIDs []string) (map[string]*ThreadSummary, error)
+	RecallReplies(ctx context.Context, rootID, recalledBy string) ([]*Message, error)
 }
 
 // messageRepository implements MessageRepository
@@ -56,8 +130,9 @@ func NewMessageRepository(db *sqlx.DB) MessageRepository {
 // Create creates a new message
 func (r *messageRepository) Create(ctx context.Context, message *Message) error {
 	query := `
-		INSERT INTO messages (message_id, user_id, sub_id, command, publisher_info, server_name, content, is_encrypted, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO messages (message_id, user_id, sub_id, command, publisher_info, server_name, content, is_encrypted, status, room_id, recipient_id,
+		                      parent_message_id, thread_root_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at
 	`
 
@@ -65,7 +140,8 @@ func (r *messageRepository) Create(ctx context.Context, message *Message) error
 		ctx, query,
 		message.MessageID, message.UserID, message.SubID, message.Command,
 		message.PublisherInfo, message.ServerName, message.Content,
-		message.IsEncrypted, message.Status,
+		message.IsEncrypted, message.Status, message.RoomID, message.RecipientID,
+		message.ParentMessageID, message.ThreadRootID,
 	).Scan(&message.ID, &message.CreatedAt)
 }
 
@@ -73,7 +149,8 @@ func (r *messageRepository) Create(ctx context.Context, message *Message) error
 func (r *messageRepository) GetByMessageID(ctx context.Context, messageID string) (*Message, error) {
 	query := `
 		SELECT id, message_id, user_id, sub_id, command, publisher_info,
-		       server_name, content, is_encrypted, status, created_at, processed_at
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
 		FROM messages
 		WHERE message_id = $1
 	`
@@ -83,6 +160,7 @@ func (r *messageRepository) GetByMessageID(ctx context.Context, messageID string
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("message not found: %s", messageID)
 	}
+	message.tombstone()
 	return &message, err
 }
 
@@ -90,7 +168,8 @@ func (r *messageRepository) GetByMessageID(ctx context.Context, messageID string
 func (r *messageRepository) GetByID(ctx context.Context, id int64) (*Message, error) {
 	query := `
 		SELECT id, message_id, user_id, sub_id, command, publisher_info,
-		       server_name, content, is_encrypted, status, created_at, processed_at
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
 		FROM messages
 		WHERE id = $1
 	`
@@ -100,32 +179,31 @@ func (r *messageRepository) GetByID(ctx context.Context, id int64) (*Message, er
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("message not found with ID: %d", id)
 	}
+	message.tombstone()
 	return &message, err
 }
 
-// UpdateStatus updates message status
-func (r *messageRepository) UpdateStatus(ctx context.Context, messageID string, status string) error {
+// UpdateStatus updates message status and returns the status it replaced
+func (r *messageRepository) UpdateStatus(ctx context.Context, messageID string, status string) (string, error) {
+	// 변경 전 상태를 같은 문장에서 돌려받아야 동시에 바꿔도 전이가 한 번만 보고된다.
 	query := `
-		UPDATE messages
+		UPDATE messages m
 		SET status = $1
-		WHERE message_id = $2
+		FROM (SELECT message_id, status FROM messages WHERE message_id = $2 FOR UPDATE) old
+		WHERE m.message_id = old.message_id
+		RETURNING old.status
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, messageID)
-	if err != nil {
-		return err
+	var previous string
+	err := r.db.QueryRowxContext(ctx, query, status, messageID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("message not found: %s: %w", messageID, err)
 	}
-
-	rows, err := result.RowsAffected()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("message not found: %s", messageID)
-	}
-
-	return nil
+	return previous, nil
 }
 
 // MarkAsProcessed marks a message as processed
@@ -153,11 +231,109 @@ func (r *messageRepository) MarkAsProcessed(ctx context.Context, messageID strin
 	return nil
 }
 
+// UpdateContent replaces a message's content and keeps the previous version in message_revisions.
+// 행을 잠근 뒤 이전 내용을 옮겨 적으므로, 동시에 수정해도 각 수정 직전의 내용이 하나씩 남는다.
+func (r *messageRepository) UpdateContent(ctx context.Context, messageID, content string, isEncrypted bool) (*Message, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO message_revisions (message_id, content, is_encrypted)
+		SELECT message_id, content, is_encrypted
+		FROM messages
+		WHERE message_id = $1 AND recalled_at IS NULL
+		FOR UPDATE
+	`, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("message not found: %s: %w", messageID, sql.ErrNoRows)
+	}
+
+	var message Message
+	err = tx.GetContext(ctx, &message, `
+		UPDATE messages
+		SET content = $2, is_encrypted = $3, edited_at = CURRENT_TIMESTAMP
+		WHERE message_id = $1
+		RETURNING id, message_id, user_id, sub_id, command, publisher_info,
+		          server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		          recalled_at, recalled_by, parent_message_id, thread_root_id
+	`, messageID, content, isEncrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// Recall tombstones a message. 이미 회수된 메시지는 처음 회수 기록을 유지하고 changed 가 false 다.
+func (r *messageRepository) Recall(ctx context.Context, messageID, recalledBy string) (*Message, bool, error) {
+	query := `
+		UPDATE messages
+		SET recalled_at = COALESCE(recalled_at, CURRENT_TIMESTAMP), recalled_by = COALESCE(recalled_by, $2)
+		WHERE message_id = $1
+		RETURNING id, message_id, user_id, sub_id, command, publisher_info,
+		          server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		          recalled_at, recalled_by, parent_message_id, thread_root_id, recalled_at = CURRENT_TIMESTAMP AS changed
+	`
+
+	var row struct {
+		Message
+		Changed bool `db:"changed"`
+	}
+	err := r.db.GetContext(ctx, &row, query, messageID, recalledBy)
+	if err == sql.ErrNoRows {
+		return nil, false, fmt.Errorf("message not found: %s: %w", messageID, err)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	row.tombstone()
+	return &row.Message, row.Changed, nil
+}
+
+// ListRevisions retrieves the prior versions of a message, most recently replaced first
+func (r *messageRepository) ListRevisions(ctx context.Context, messageID string, limit, offset int) ([]*MessageRevision, error) {
+	query := `
+		SELECT id, message_id, content, is_encrypted, replaced_at
+		FROM message_revisions
+		WHERE message_id = $1
+		ORDER BY id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	var revisions []*MessageRevision
+	err := r.db.SelectContext(ctx, &revisions, query, messageID, limit, offset)
+	return revisions, err
+}
+
+// CountRevisions returns the number of prior versions of a message
+func (r *messageRepository) CountRevisions(ctx context.Context, messageID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM message_revisions WHERE message_id = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, messageID)
+	return count, err
+}
+
 // ListByUser retrieves messages for a specific user
 func (r *messageRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*Message, error) {
 	query := `
 		SELECT id, message_id, user_id, sub_id, command, publisher_info,
-		       server_name, content, is_encrypted, status, created_at, processed_at
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
 		FROM messages
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -166,14 +342,118 @@ func (r *messageRepository) ListByUser(ctx context.Context, userID string, limit
 
 	var messages []*Message
 	err := r.db.SelectContext(ctx, &messages, query, userID, limit, offset)
-	return messages, err
+	return tombstoned(messages), err
+}
+
+// ListByUserAfter retrieves a user's messages with id greater than afterID in id order.
+// 전체를 훑는 용도(내보내기)라 OFFSET 대신 키셋으로 넘긴다 — 도중에 새 메시지가 들어와도 빠지거나 겹치지 않는다.
+func (r *messageRepository) ListByUserAfter(ctx context.Context, userID string, afterID int64, limit int) ([]*Message, error) {
+	query := `
+		SELECT id, message_id, user_id, sub_id, command, publisher_info,
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
+		FROM messages
+		WHERE user_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+	`
+
+	var messages []*Message
+	err := r.db.SelectContext(ctx, &messages, query, userID, afterID, limit)
+	return tombstoned(messages), err
+}
+
+// ListByRoom retrieves the messages of a room, newest first
+func (r *messageRepository) ListByRoom(ctx context.Context, roomID string, limit, offset int) ([]*Message, error) {
+	query := `
+		SELECT id, message_id, user_id, sub_id, command, publisher_info,
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
+		FROM messages
+		WHERE room_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	var messages []*Message
+	err := r.db.SelectContext(ctx, &messages, query, roomID, limit, offset)
+	return tombstoned(messages), err
+}
+
+// ListConversations retrieves the user's direct message peers with the latest message of each, most recent first
+func (r *messageRepository) ListConversations(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error) {
+	query := `
+		SELECT * FROM (
+			SELECT DISTINCT ON (peer_id)
+			       CASE WHEN user_id = $1 THEN recipient_id ELSE user_id END AS peer_id,
+			       id, message_id, user_id, sub_id, command, publisher_info,
+			       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+			       recalled_at, recalled_by, parent_message_id, thread_root_id
+			FROM messages
+			WHERE recipient_id IS NOT NULL AND (user_id = $1 OR recipient_id = $1)
+			ORDER BY peer_id, created_at DESC, id DESC
+		) latest
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	var conversations []*Conversation
+	err := r.db.SelectContext(ctx, &conversations, query, userID, limit, offset)
+	for _, conversation := range conversations {
+		conversation.tombstone()
+	}
+	return conversations, err
+}
+
+// CountConversations returns the number of distinct direct message peers of the user
+func (r *messageRepository) CountConversations(ctx context.Context, userID string) (int64, error) {
+	query := `
+		SELECT COUNT(DISTINCT CASE WHEN user_id = $1 THEN recipient_id ELSE user_id END)
+		FROM messages
+		WHERE recipient_id IS NOT NULL AND (user_id = $1 OR recipient_id = $1)
+	`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, userID)
+	return count, err
+}
+
+// ListThread retrieves the direct messages exchanged between two users, newest first
+func (r *messageRepository) ListThread(ctx context.Context, userID, peerID string, limit, offset int) ([]*Message, error) {
+	query := `
+		SELECT id, message_id, user_id, sub_id, command, publisher_info,
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
+		FROM messages
+		WHERE (user_id = $1 AND recipient_id = $2) OR (user_id = $2 AND recipient_id = $1)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	var messages []*Message
+	err := r.db.SelectContext(ctx, &messages, query, userID, peerID, limit, offset)
+	return tombstoned(messages), err
+}
+
+// CountThread returns the number of direct messages exchanged between two users
+func (r *messageRepository) CountThread(ctx context.Context, userID, peerID string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM messages
+		WHERE (user_id = $1 AND recipient_id = $2) OR (user_id = $2 AND recipient_id = $1)
+	`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, userID, peerID)
+	return count, err
 }
 
 // ListByStatus retrieves messages by status
 func (r *messageRepository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*Message, error) {
 	query := `
 		SELECT id, message_id, user_id, sub_id, command, publisher_info,
-		       server_name, content, is_encrypted, status, created_at, processed_at
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
 		FROM messages
 		WHERE status = $1
 		ORDER BY created_at DESC
@@ -182,14 +462,15 @@ func (r *messageRepository) ListByStatus(ctx context.Context, status string, lim
 
 	var messages []*Message
 	err := r.db.SelectContext(ctx, &messages, query, status, limit, offset)
-	return messages, err
+	return tombstoned(messages), err
 }
 
 // ListRecent retrieves recent messages
 func (r *messageRepository) ListRecent(ctx context.Context, limit, offset int) ([]*Message, error) {
 	query := `
 		SELECT id, message_id, user_id, sub_id, command, publisher_info,
-		       server_name, content, is_encrypted, status, created_at, processed_at
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
 		FROM messages
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -197,7 +478,26 @@ func (r *messageRepository) ListRecent(ctx context.Context, limit, offset int) (
 
 	var messages []*Message
 	err := r.db.SelectContext(ctx, &messages, query, limit, offset)
-	return messages, err
+	return tombstoned(messages), err
+}
+
+// ListByRequestID retrieves the messages published by an HTTP request, oldest first.
+// request_id 는 publisher_info JSON 안에 있다. DBWorker 밖에서 넣은 행은 JSON 이 아닐 수 있으므로
+// IS JSON OBJECT 로 거른 뒤 캐스팅한다 — 식은 idx_messages_request_id 와 같아야 인덱스를 탄다.
+func (r *messageRepository) ListByRequestID(ctx context.Context, requestID string, limit int) ([]*Message, error) {
+	query := `
+		SELECT id, message_id, user_id, sub_id, command, publisher_info,
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
+		FROM messages
+		WHERE (CASE WHEN publisher_info IS JSON OBJECT THEN publisher_info::jsonb ->> 'request_id' END) = $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	var messages []*Message
+	err := r.db.SelectContext(ctx, &messages, query, requestID, limit)
+	return tombstoned(messages), err
 }
 
 // Delete deletes a message
@@ -239,6 +539,15 @@ func (r *messageRepository) CountByUser(ctx context.Context, userID string) (int
 	return count, err
 }
 
+// CountByRoom returns the number of messages in a room
+func (r *messageRepository) CountByRoom(ctx context.Context, roomID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE room_id = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, roomID)
+	return count, err
+}
+
 // CountByStatus returns the number of messages by status
 func (r *messageRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
 	query := `SELECT COUNT(*) FROM messages WHERE status = $1`
@@ -247,3 +556,76 @@ func (r *messageRepository) CountByStatus(ctx context.Context, status string) (i
 	err := r.db.GetContext(ctx, &count, query, status)
 	return count, err
 }
+
+// ListReplies retrieves the replies of a thread, oldest first
+func (r *messageRepository) ListReplies(ctx context.Context, rootID string, limit, offset int) ([]*Message, error) {
+	query := `
+		SELECT id, message_id, user_id, sub_id, command, publisher_info,
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id
+		FROM messages
+		WHERE thread_root_id = $1
+		ORDER BY created_at ASC, id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	var messages []*Message
+	err := r.db.SelectContext(ctx, &messages, query, rootID, limit, offset)
+	return tombstoned(messages), err
+}
+
+// CountReplies counts the replies of a thread
+func (r *messageRepository) CountReplies(ctx context.Context, rootID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM messages WHERE thread_root_id = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, rootID)
+	return count, err
+}
+
+// ThreadSummaries retrieves the reply count and latest reply of each thread root that has replies.
+// 목록 한 페이지의 첫 메시지들을 한 번에 요약한다 — 답글이 없는 메시지는 결과에 없다.
+func (r *messageRepository) ThreadSummaries(ctx context.Context, rootIDs []string) (map[string]*ThreadSummary, error) {
+	query := `
+		SELECT DISTINCT ON (thread_root_id)
+		       id, message_id, user_id, sub_id, command, publisher_info,
+		       server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		       recalled_at, recalled_by, parent_message_id, thread_root_id,
+		       COUNT(*) OVER (PARTITION BY thread_root_id) AS reply_count
+		FROM messages
+		WHERE thread_root_id = ANY($1)
+		ORDER BY thread_root_id, created_at DESC, id DESC
+	`
+
+	var rows []struct {
+		Message
+		Count int64 `db:"reply_count"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, query, pq.Array(rootIDs)); err != nil {
+		return nil, err
+	}
+
+	summaries := make(map[string]*ThreadSummary, len(rows))
+	for i := range rows {
+		latest := rows[i].Message
+		latest.tombstone()
+		summaries[*latest.ThreadRootID] = &ThreadSummary{ReplyCount: rows[i].Count, LatestReply: &latest}
+	}
+	return summaries, nil
+}
+
+// RecallReplies tombstones the replies of a thread that are not recalled yet, returning them
+func (r *messageRepository) RecallReplies(ctx context.Context, rootID, recalledBy string) ([]*Message, error) {
+	query := `
+		UPDATE messages
+		SET recalled_at = CURRENT_TIMESTAMP, recalled_by = $2
+		WHERE thread_root_id = $1 AND recalled_at IS NULL
+		RETURNING id, message_id, user_id, sub_id, command, publisher_info,
+		          server_name, content, is_encrypted, status, created_at, processed_at, room_id, recipient_id, edited_at,
+		          recalled_at, recalled_by, parent_message_id, thread_root_id
+	`
+
+	var messages []*Message
+	err := r.db.SelectContext(ctx, &messages, query, rootID, recalledBy)
+	return tombstoned(messages), err
+}
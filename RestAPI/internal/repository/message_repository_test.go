@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var messageTestColumns = []string{
+	"id", "message_id", "user_id", "sub_id", "command", "publisher_info",
+	"server_name", "content", "is_encrypted", "status", "created_at", "processed_at", "room_id", "recipient_id",
+	"edited_at", "recalled_at", "recalled_by", "parent_message_id", "thread_root_id",
+}
+
+func TestMessageRepository_ListConversations(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+
+	now := time.Now()
+	rows := sqlmock.NewRows(append([]string{"peer_id"}, messageTestColumns...)).
+		AddRow("bob", 2, "m2", "bob", "", "chat", "{}", "MainServer", "hi alice", false, "processed", now, nil, nil, "alice", nil, nil, nil, nil, nil).
+		AddRow("carol", 1, "m1", "alice", "", "chat", "{}", "MainServer", "hi carol", false, "processed", now.Add(-time.Hour), nil, nil, "carol", nil, nil, nil, nil, nil)
+
+	mock.ExpectQuery(`SELECT DISTINCT ON \(peer_id\) CASE WHEN user_id = \$1 THEN recipient_id ELSE user_id END AS peer_id(.+)WHERE recipient_id IS NOT NULL AND \(user_id = \$1 OR recipient_id = \$1\)`).
+		WithArgs("alice", 20, 0).
+		WillReturnRows(rows)
+
+	conversations, err := repo.ListConversations(context.Background(), "alice", 20, 0)
+
+	require.NoError(t, err)
+	require.Len(t, conversations, 2)
+	assert.Equal(t, "bob", conversations[0].PeerID)
+	assert.Equal(t, "hi alice", conversations[0].Content)
+	assert.Equal(t, "carol", *conversations[1].RecipientID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// 응답은 peer_id 옆에 최신 메시지를 last_message 로 싣는다.
+	raw, err := json.Marshal(conversations[0])
+	require.NoError(t, err)
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+	assert.Equal(t, "bob", decoded["peer_id"])
+	assert.Contains(t, decoded["last_message"], "content")
+}
+
+func TestMessageRepository_ListThread(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+
+	mock.ExpectQuery(`WHERE \(user_id = \$1 AND recipient_id = \$2\) OR \(user_id = \$2 AND recipient_id = \$1\)`).
+		WithArgs("alice", "bob", 20, 0).
+		WillReturnRows(sqlmock.NewRows(messageTestColumns).
+			AddRow(2, "m2", "bob", "", "chat", "{}", "MainServer", "hi alice", false, "processed", time.Now(), nil, nil, "alice", nil, nil, nil, nil, nil))
+
+	messages, err := repo.ListThread(context.Background(), "alice", "bob", 20, 0)
+
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "bob", messages[0].UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_UpdateContent(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("keeps the previous version", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO message_revisions \(message_id, content, is_encrypted\) SELECT message_id, content, is_encrypted FROM messages WHERE message_id = \$1 AND recalled_at IS NULL FOR UPDATE`).
+			WithArgs("m1").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectQuery(`UPDATE messages SET content = \$2, is_encrypted = \$3, edited_at = CURRENT_TIMESTAMP WHERE message_id = \$1`).
+			WithArgs("m1", "fixed", false).
+			WillReturnRows(sqlmock.NewRows(messageTestColumns).
+				AddRow(1, "m1", "alice", "", "chat", "{}", "MainServer", "fixed", false, "processed", now, nil, nil, nil, now, nil, nil, nil, nil))
+		mock.ExpectCommit()
+
+		message, err := repo.UpdateContent(ctx, "m1", "fixed", false)
+
+		require.NoError(t, err)
+		assert.Equal(t, "fixed", message.Content)
+		require.NotNil(t, message.EditedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO message_revisions`).
+			WithArgs("gone").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		_, err := repo.UpdateContent(ctx, "gone", "fixed", false)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_Recall(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+	columns := append(append([]string{}, messageTestColumns...), "changed")
+
+	t.Run("tombstones the message", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(`UPDATE messages SET recalled_at = COALESCE\(recalled_at, CURRENT_TIMESTAMP\), recalled_by = COALESCE\(recalled_by, \$2\) WHERE message_id = \$1`).
+			WithArgs("m1", "alice").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "m1", "alice", "", "chat", "{}", "MainServer", "secret", true, "processed", now, nil, nil, nil, nil, now, "alice", nil, nil, true))
+
+		message, changed, err := repo.Recall(ctx, "m1", "alice")
+
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Empty(t, message.Content)
+		assert.False(t, message.IsEncrypted)
+		require.NotNil(t, message.RecalledBy)
+		assert.Equal(t, "alice", *message.RecalledBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE messages SET recalled_at`).
+			WithArgs("gone", "alice").
+			WillReturnError(sql.ErrNoRows)
+
+		_, _, err := repo.Recall(ctx, "gone", "alice")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestMessageRepository_GetByMessageID_Recalled(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT .* FROM messages WHERE message_id = \$1`).
+		WithArgs("m1").
+		WillReturnRows(sqlmock.NewRows(messageTestColumns).
+			AddRow(1, "m1", "alice", "", "chat", "{}", "MainServer", "hello", false, "processed", now, nil, nil, nil, nil, now, "admin", nil, nil))
+
+	message, err := repo.GetByMessageID(context.Background(), "m1")
+
+	require.NoError(t, err)
+	assert.Empty(t, message.Content)
+	require.NotNil(t, message.RecalledAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_ListReplies(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+	root := "11111111-1111-1111-1111-111111111111"
+
+	mock.ExpectQuery(`SELECT .* FROM messages WHERE thread_root_id = \$1 ORDER BY created_at ASC, id ASC LIMIT \$2 OFFSET \$3`).
+		WithArgs(root, 20, 0).
+		WillReturnRows(sqlmock.NewRows(messageTestColumns).
+			AddRow(2, "m2", "bob", "", "chat", "{}", "MainServer", "re", false, "processed", time.Now(), nil, nil, nil, nil, nil, nil, root, root))
+
+	replies, err := repo.ListReplies(context.Background(), root, 20, 0)
+
+	require.NoError(t, err)
+	require.Len(t, replies, 1)
+	require.NotNil(t, replies[0].ParentMessageID)
+	assert.Equal(t, root, *replies[0].ThreadRootID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_ThreadSummaries(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+	root := "11111111-1111-1111-1111-111111111111"
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT DISTINCT ON \(thread_root_id\) .* COUNT\(\*\) OVER \(PARTITION BY thread_root_id\) AS reply_count FROM messages WHERE thread_root_id = ANY\(\$1\)`).
+		WithArgs(pq.Array([]string{root, "m9"})).
+		WillReturnRows(sqlmock.NewRows(append(append([]string{}, messageTestColumns...), "reply_count")).
+			AddRow(5, "m5", "carol", "", "chat", "{}", "MainServer", "gone", false, "processed", now, nil, nil, nil, nil, now, "carol", "m4", root, 3))
+
+	summaries, err := repo.ThreadSummaries(context.Background(), []string{root, "m9"})
+
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, int64(3), summaries[root].ReplyCount)
+	assert.Equal(t, "m5", summaries[root].LatestReply.MessageID)
+	assert.Empty(t, summaries[root].LatestReply.Content, "회수된 답글은 내용을 가린다")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_ListByRequestID(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+
+	recalledAt := time.Now()
+	mock.ExpectQuery(`WHERE \(CASE WHEN publisher_info IS JSON OBJECT THEN publisher_info::jsonb ->> 'request_id' END\) = \$1\s+ORDER BY id`).
+		WithArgs("req-1", 50).
+		WillReturnRows(sqlmock.NewRows(messageTestColumns).
+			AddRow(7, "m1", "alice", "", "chat", `{"message_id":"m1","request_id":"req-1"}`, "MainServer", "secret", false, "processed", time.Now(), nil, nil, nil, nil, recalledAt, "alice", nil, nil))
+
+	messages, err := repo.ListByRequestID(context.Background(), "req-1", 50)
+
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, int64(7), messages[0].ID)
+	assert.Empty(t, messages[0].Content, "회수된 메시지는 조회 경로와 관계없이 내용을 숨긴다")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMessageRepository_UpdateStatus(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewMessageRepository(db)
+	ctx := context.Background()
+
+	t.Run("returns the replaced status", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE messages m SET status = \$1 FROM \(SELECT message_id, status FROM messages WHERE message_id = \$2 FOR UPDATE\) old WHERE m.message_id = old.message_id RETURNING old.status`).
+			WithArgs("failed", "m1").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("pending"))
+
+		previous, err := repo.UpdateStatus(ctx, "m1", "failed")
+
+		require.NoError(t, err)
+		assert.Equal(t, "pending", previous)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE messages m SET status`).
+			WithArgs("failed", "gone").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdateStatus(ctx, "gone", "failed")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
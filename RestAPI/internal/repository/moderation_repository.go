@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Moderation flag statuses
+const (
+	FlagPending  = "pending"
+	FlagApproved = "approved"
+	FlagRemoved  = "removed"
+)
+
+// ModerationFlag is a flagged message waiting for or done with review
+type ModerationFlag struct {
+	ID         string           `db:"id" json:"id"`
+	MessageID  string           `db:"message_id" json:"message_id"`
+	UserID     string           `db:"user_id" json:"user_id"`
+	Content    string           `db:"content" json:"content"`
+	IsEdit     bool             `db:"is_edit" json:"is_edit"`
+	Verdicts   *json.RawMessage `db:"verdicts" json:"verdicts"`
+	Status     string           `db:"status" json:"status"`
+	ReviewedBy *string          `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time       `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time        `db:"created_at" json:"created_at"`
+}
+
+// ModerationRepository defines moderation review queue data access methods
+type ModerationRepository interface {
+	Create(ctx context.Context, flag *ModerationFlag) error
+	Get(ctx context.Context, id string) (*ModerationFlag, error)
+	List(ctx context.Context, status string, limit, offset int) ([]*ModerationFlag, error)
+	ListByUser(ctx context.Context, userID string, limit, offset int) ([]*ModerationFlag, error)
+	Count(ctx context.Context, status string) (int64, error)
+	Review(ctx context.Context, id, status, reviewerID string) (*ModerationFlag, error)
+}
+
+// moderationRepository implements ModerationRepository
+type moderationRepository struct {
+	db *sqlx.DB
+}
+
+// NewModerationRepository creates a new moderation repository
+func NewModerationRepository(db *sqlx.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+const moderationFlagColumns = `id, message_id, user_id, content, is_edit, verdicts, status, reviewed_by, reviewed_at, created_at`
+
+// Create adds a pending flag
+func (r *moderationRepository) Create(ctx context.Context, flag *ModerationFlag) error {
+	query := `
+		INSERT INTO moderation_flags (message_id, user_id, content, is_edit, verdicts)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at
+	`
+
+	return r.db.QueryRowxContext(ctx, query, flag.MessageID, flag.UserID, flag.Content, flag.IsEdit, jsonbParam(flag.Verdicts)).
+		Scan(&flag.ID, &flag.Status, &flag.CreatedAt)
+}
+
+// Get retrieves a flag by ID
+func (r *moderationRepository) Get(ctx context.Context, id string) (*ModerationFlag, error) {
+	query := `SELECT ` + moderationFlagColumns + ` FROM moderation_flags WHERE id = $1`
+
+	var flag ModerationFlag
+	err := r.db.GetContext(ctx, &flag, query, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("moderation flag not found: %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &flag, nil
+}
+
+// List retrieves flags with the given status (all when empty), oldest first so the queue is worked in order
+func (r *moderationRepository) List(ctx context.Context, status string, limit, offset int) ([]*ModerationFlag, error) {
+	query := `
+		SELECT ` + moderationFlagColumns + `
+		FROM moderation_flags
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+	`
+
+	var flags []*ModerationFlag
+	err := r.db.SelectContext(ctx, &flags, query, status, limit, offset)
+	return flags, err
+}
+
+// ListByUser retrieves the flags raised on a user's messages, oldest first
+func (r *moderationRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*ModerationFlag, error) {
+	query := `
+		SELECT ` + moderationFlagColumns + `
+		FROM moderation_flags
+		WHERE user_id = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+	`
+
+	var flags []*ModerationFlag
+	err := r.db.SelectContext(ctx, &flags, query, userID, limit, offset)
+	return flags, err
+}
+
+// Count returns the number of flags with the given status (all when empty)
+func (r *moderationRepository) Count(ctx context.Context, status string) (int64, error) {
+	query := `SELECT COUNT(*) FROM moderation_flags WHERE $1 = '' OR status = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, status)
+	return count, err
+}
+
+// Review records the decision on a pending flag.
+// 이미 검토된 플래그는 바꾸지 않고 sql.ErrNoRows 를 감싸서 돌려준다 — 두 관리자가 동시에 검토해도 한 번만 반영된다.
+func (r *moderationRepository) Review(ctx context.Context, id, status, reviewerID string) (*ModerationFlag, error) {
+	query := `
+		UPDATE moderation_flags
+		SET status = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + moderationFlagColumns
+
+	var flag ModerationFlag
+	err := r.db.GetContext(ctx, &flag, query, id, status, reviewerID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("pending moderation flag not found: %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &flag, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var moderationFlagTestColumns = []string{
+	"id", "message_id", "user_id", "content", "is_edit", "verdicts", "status", "reviewed_by", "reviewed_at", "created_at",
+}
+
+func TestModerationRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewModerationRepository(db)
+
+	verdicts := json.RawMessage(`[{"filter":"links","action":"flag","reason":"links to domain other.net outside the allow list"}]`)
+	flag := &ModerationFlag{MessageID: "m1", UserID: "alice", Content: "see http://other.net", Verdicts: &verdicts}
+
+	mock.ExpectQuery(`INSERT INTO moderation_flags \(message_id, user_id, content, is_edit, verdicts\)`).
+		WithArgs("m1", "alice", "see http://other.net", false, string(verdicts)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "created_at"}).AddRow("f1", FlagPending, time.Now()))
+
+	require.NoError(t, repo.Create(context.Background(), flag))
+	assert.Equal(t, "f1", flag.ID)
+	assert.Equal(t, FlagPending, flag.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModerationRepository_List(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewModerationRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(`SELECT (.+) FROM moderation_flags WHERE \$1 = '' OR status = \$1 ORDER BY created_at, id LIMIT \$2 OFFSET \$3`).
+		WithArgs(FlagPending, 20, 0).
+		WillReturnRows(sqlmock.NewRows(moderationFlagTestColumns).
+			AddRow("f1", "m1", "alice", "hi", false, []byte(`[]`), FlagPending, nil, nil, time.Now()))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM moderation_flags WHERE \$1 = '' OR status = \$1`).
+		WithArgs(FlagPending).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	flags, err := repo.List(ctx, FlagPending, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.JSONEq(t, `[]`, string(*flags[0].Verdicts))
+
+	count, err := repo.Count(ctx, FlagPending)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestModerationRepository_Review(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewModerationRepository(db)
+	ctx := context.Background()
+
+	t.Run("pending", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE moderation_flags SET status = \$2, reviewed_by = \$3, reviewed_at = CURRENT_TIMESTAMP WHERE id = \$1 AND status = 'pending'`).
+			WithArgs("f1", FlagRemoved, "admin").
+			WillReturnRows(sqlmock.NewRows(moderationFlagTestColumns).
+				AddRow("f1", "m1", "alice", "hi", false, []byte(`[]`), FlagRemoved, "admin", time.Now(), time.Now()))
+
+		flag, err := repo.Review(ctx, "f1", FlagRemoved, "admin")
+
+		require.NoError(t, err)
+		assert.Equal(t, FlagRemoved, flag.Status)
+		assert.Equal(t, "admin", *flag.ReviewedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already reviewed", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE moderation_flags`).
+			WithArgs("f1", FlagApproved, "admin").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.Review(ctx, "f1", FlagApproved, "admin")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestModerationRepository_ListByUser(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewModerationRepository(db)
+
+	mock.ExpectQuery(`SELECT (.+) FROM moderation_flags WHERE user_id = \$1 ORDER BY created_at, id LIMIT \$2 OFFSET \$3`).
+		WithArgs("alice", 500, 0).
+		WillReturnRows(sqlmock.NewRows(moderationFlagTestColumns).
+			AddRow("f1", "m1", "alice", "hi", false, []byte(`[]`), FlagApproved, "root", time.Now(), time.Now()))
+
+	flags, err := repo.ListByUser(context.Background(), "alice", 500, 0)
+
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, "alice", flags[0].UserID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
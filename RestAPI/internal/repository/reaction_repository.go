@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// ReactionCount is how many users reacted to a message with one emoji
+type ReactionCount struct {
+	MessageID string `db:"message_id"`
+	Emoji     string `db:"emoji"`
+	Count     int64  `db:"count"`
+}
+
+// MessageReaction is one user's reaction to a message
+type MessageReaction struct {
+	MessageID string    `db:"message_id" json:"message_id"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Emoji     string    `db:"emoji" json:"emoji"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ReactionRepository defines message reaction data access methods.
+// Add·Remove 는 실제로 행이 바뀌었을 때만 true 다 — 같은 요청을 반복해도 이벤트가 중복되지 않는다.
+type ReactionRepository interface {
+	Add(ctx context.Context, messageID, userID, emoji string) (added bool, err error)
+	Remove(ctx context.Context, messageID, userID, emoji string) (removed bool, err error)
+	Counts(ctx context.Context, messageIDs []string) ([]*ReactionCount, error)
+	ListByUser(ctx context.Context, userID string, limit, offset int) ([]*MessageReaction, error)
+}
+
+// reactionRepository implements ReactionRepository
+type reactionRepository struct {
+	db *sqlx.DB
+}
+
+// NewReactionRepository creates a new reaction repository
+func NewReactionRepository(db *sqlx.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// Add records userID's reaction to a message
+func (r *reactionRepository) Add(ctx context.Context, messageID, userID, emoji string) (bool, error) {
+	query := `
+		INSERT INTO message_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (message_id, user_id, emoji) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		// 메시지나 사용자가 그사이 지워졌다.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return false, fmt.Errorf("message or user not found: %s, %s: %w", messageID, userID, sql.ErrNoRows)
+		}
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// Remove deletes userID's reaction to a message
+func (r *reactionRepository) Remove(ctx context.Context, messageID, userID, emoji string) (bool, error) {
+	query := `DELETE FROM message_reactions WHERE message_id = $1 AND user_id = $2 AND emoji = $3`
+
+	result, err := r.db.ExecContext(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// Counts aggregates the reactions of the given messages by emoji. 반응이 없는 메시지는 결과에 없다.
+func (r *reactionRepository) Counts(ctx context.Context, messageIDs []string) ([]*ReactionCount, error) {
+	query := `
+		SELECT message_id, emoji, COUNT(*) AS count
+		FROM message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+	`
+
+	var counts []*ReactionCount
+	err := r.db.SelectContext(ctx, &counts, query, pq.Array(messageIDs))
+	return counts, err
+}
+
+// ListByUser retrieves the reactions of a user, oldest first
+func (r *reactionRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*MessageReaction, error) {
+	query := `
+		SELECT message_id, user_id, emoji, created_at
+		FROM message_reactions
+		WHERE user_id = $1
+		ORDER BY created_at, message_id, emoji
+		LIMIT $2 OFFSET $3
+	`
+
+	var reactions []*MessageReaction
+	err := r.db.SelectContext(ctx, &reactions, query, userID, limit, offset)
+	return reactions, err
+}
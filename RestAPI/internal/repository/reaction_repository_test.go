@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReactionRepository_Add(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReactionRepository(db)
+	ctx := context.Background()
+
+	t.Run("first reaction", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO message_reactions .* ON CONFLICT \(message_id, user_id, emoji\) DO NOTHING`).
+			WithArgs("m1", "bob", "👍").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		added, err := repo.Add(ctx, "m1", "bob", "👍")
+
+		require.NoError(t, err)
+		assert.True(t, added)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("repeat is a no-op", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO message_reactions`).
+			WithArgs("m1", "bob", "👍").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		added, err := repo.Add(ctx, "m1", "bob", "👍")
+
+		require.NoError(t, err)
+		assert.False(t, added)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message deleted", func(t *testing.T) {
+		mock.ExpectExec(`INSERT INTO message_reactions`).
+			WithArgs("gone", "bob", "👍").
+			WillReturnError(&pq.Error{Code: "23503"})
+
+		_, err := repo.Add(ctx, "gone", "bob", "👍")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReactionRepository_Counts(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReactionRepository(db)
+
+	mock.ExpectQuery(`SELECT message_id, emoji, COUNT\(\*\) AS count FROM message_reactions WHERE message_id = ANY\(\$1\) GROUP BY message_id, emoji`).
+		WithArgs(pq.Array([]string{"m1", "m2"})).
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "emoji", "count"}).
+			AddRow("m1", "👍", 3).
+			AddRow("m1", ":tada:", 1))
+
+	counts, err := repo.Counts(context.Background(), []string{"m1", "m2"})
+
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	assert.Equal(t, &ReactionCount{MessageID: "m1", Emoji: "👍", Count: 3}, counts[0])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReactionRepository_ListByUser(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReactionRepository(db)
+
+	mock.ExpectQuery(`SELECT message_id, user_id, emoji, created_at FROM message_reactions WHERE user_id = \$1 ORDER BY created_at, message_id, emoji LIMIT \$2 OFFSET \$3`).
+		WithArgs("bob", 500, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "user_id", "emoji", "created_at"}).
+			AddRow("m1", "bob", "👍", time.Now()))
+
+	reactions, err := repo.ListByUser(context.Background(), "bob", 500, 0)
+
+	require.NoError(t, err)
+	require.Len(t, reactions, 1)
+	assert.Equal(t, "👍", reactions[0].Emoji)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// MessageReceipt is one recipient's delivery and read state of a message.
+// ReadAt 이 nil 이면 전달만 되고 아직 읽지 않은 상태다.
+type MessageReceipt struct {
+	MessageID   string     `db:"message_id" json:"message_id"`
+	UserID      string     `db:"user_id" json:"user_id"`
+	DeliveredAt time.Time  `db:"delivered_at" json:"delivered_at"`
+	ReadAt      *time.Time `db:"read_at" json:"read_at,omitempty"`
+}
+
+// ReceiptSummary counts the recipients that acknowledged a message
+type ReceiptSummary struct {
+	Delivered int64 `db:"delivered" json:"delivered"`
+	Read      int64 `db:"read" json:"read"`
+}
+
+// ReceiptScope selects the conversation a read watermark applies to: a room or a direct message peer
+type ReceiptScope struct {
+	RoomID string
+	PeerID string
+}
+
+// ReceiptRepository defines message receipt data access methods.
+// Mark* 는 처음 기록될 때만 changed 가 true 다 — 같은 확인을 다시 보내도 이벤트가 중복되지 않는다.
+type ReceiptRepository interface {
+	MarkDelivered(ctx context.Context, messageID, userID string) (receipt *MessageReceipt, changed bool, err error)
+	MarkRead(ctx context.Context, messageID, userID string) (receipt *MessageReceipt, changed bool, err error)
+	MarkReadUpTo(ctx context.Context, userID string, scope ReceiptScope, upTo *Message, limit int) ([]string, error)
+	Summary(ctx context.Context, messageID string) (*ReceiptSummary, error)
+	List(ctx context.Context, messageID string, limit, offset int) ([]*MessageReceipt, error)
+	ListByUser(ctx context.Context, userID string, limit, offset int) ([]*MessageReceipt, error)
+	Count(ctx context.Context, messageID string) (int64, error)
+}
+
+// receiptRepository implements ReceiptRepository
+type receiptRepository struct {
+	db *sqlx.DB
+}
+
+// NewReceiptRepository creates a new receipt repository
+func NewReceiptRepository(db *sqlx.DB) ReceiptRepository {
+	return &receiptRepository{db: db}
+}
+
+// receiptRow is a receipt with whether the statement that returned it set the acknowledged column
+type receiptRow struct {
+	MessageReceipt
+	Changed bool `db:"changed"`
+}
+
+// MarkDelivered records the first delivery of a message to userID.
+// CURRENT_TIMESTAMP 는 문장 안에서 고정이므로, 반환된 값이 그와 같으면 이번 문장이 기록한 것이다.
+func (r *receiptRepository) MarkDelivered(ctx context.Context, messageID, userID string) (*MessageReceipt, bool, error) {
+	query := `
+		INSERT INTO message_receipts (message_id, user_id, delivered_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP)
+		ON CONFLICT (message_id, user_id) DO UPDATE SET delivered_at = message_receipts.delivered_at
+		RETURNING message_id, user_id, delivered_at, read_at, delivered_at = CURRENT_TIMESTAMP AS changed
+	`
+
+	return r.mark(ctx, query, messageID, userID)
+}
+
+// MarkRead records the first read of a message by userID, and its delivery if none was recorded
+func (r *receiptRepository) MarkRead(ctx context.Context, messageID, userID string) (*MessageReceipt, bool, error) {
+	query := `
+		INSERT INTO message_receipts (message_id, user_id, delivered_at, read_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (message_id, user_id) DO UPDATE SET read_at = COALESCE(message_receipts.read_at, EXCLUDED.read_at)
+		RETURNING message_id, user_id, delivered_at, read_at, read_at = CURRENT_TIMESTAMP AS changed
+	`
+
+	return r.mark(ctx, query, messageID, userID)
+}
+
+func (r *receiptRepository) mark(ctx context.Context, query, messageID, userID string) (*MessageReceipt, bool, error) {
+	var row receiptRow
+	err := r.db.GetContext(ctx, &row, query, messageID, userID)
+	if err != nil {
+		// 메시지나 사용자가 그사이 지워졌다.
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return nil, false, fmt.Errorf("message or user not found: %s, %s: %w", messageID, userID, sql.ErrNoRows)
+		}
+		return nil, false, err
+	}
+	return &row.MessageReceipt, row.Changed, nil
+}
+
+// MarkReadUpTo marks as read, for userID, the messages of the scope created up to and including upTo.
+// 다른 사람이 보낸 메시지만 대상이며, 한 번에 최신 limit 건까지만 기록한다. 새로 읽음 처리된 message_id 를 돌려준다.
+func (r *receiptRepository) MarkReadUpTo(ctx context.Context, userID string, scope ReceiptScope, upTo *Message, limit int) ([]string, error) {
+	var filter string
+	var target string
+	switch {
+	case scope.RoomID != "":
+		filter, target = `m.room_id = $2`, scope.RoomID
+	case scope.PeerID != "":
+		filter, target = `m.user_id = $2 AND m.recipient_id = $1`, scope.PeerID
+	default:
+		return nil, fmt.Errorf("receipt scope needs a room or a peer")
+	}
+
+	query := `
+		INSERT INTO message_receipts (message_id, user_id, delivered_at, read_at)
+		SELECT m.message_id, $1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+		FROM messages m
+		WHERE ` + filter + ` AND m.user_id <> $1 AND (m.created_at, m.id) <= ($3, $4)
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $5
+		ON CONFLICT (message_id, user_id) DO UPDATE SET read_at = EXCLUDED.read_at
+		WHERE message_receipts.read_at IS NULL
+		RETURNING message_id
+	`
+
+	var messageIDs []string
+	err := r.db.SelectContext(ctx, &messageIDs, query, userID, target, upTo.CreatedAt, upTo.ID, limit)
+	return messageIDs, err
+}
+
+// Summary counts the recipients that acknowledged delivery and read of a message
+func (r *receiptRepository) Summary(ctx context.Context, messageID string) (*ReceiptSummary, error) {
+	query := `
+		SELECT COUNT(*) AS delivered, COUNT(read_at) AS read
+		FROM message_receipts
+		WHERE message_id = $1
+	`
+
+	var summary ReceiptSummary
+	err := r.db.GetContext(ctx, &summary, query, messageID)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+// List retrieves the receipts of a message, earliest delivery first
+func (r *receiptRepository) List(ctx context.Context, messageID string, limit, offset int) ([]*MessageReceipt, error) {
+	query := `
+		SELECT message_id, user_id, delivered_at, read_at
+		FROM message_receipts
+		WHERE message_id = $1
+		ORDER BY delivered_at, user_id
+		LIMIT $2 OFFSET $3
+	`
+
+	var receipts []*MessageReceipt
+	err := r.db.SelectContext(ctx, &receipts, query, messageID, limit, offset)
+	return receipts, err
+}
+
+// ListByUser retrieves the receipts a user acknowledged, earliest delivery first
+func (r *receiptRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*MessageReceipt, error) {
+	query := `
+		SELECT message_id, user_id, delivered_at, read_at
+		FROM message_receipts
+		WHERE user_id = $1
+		ORDER BY delivered_at, message_id
+		LIMIT $2 OFFSET $3
+	`
+
+	var receipts []*MessageReceipt
+	err := r.db.SelectContext(ctx, &receipts, query, userID, limit, offset)
+	return receipts, err
+}
+
+// Count returns the number of receipts of a message
+func (r *receiptRepository) Count(ctx context.Context, messageID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM message_receipts WHERE message_id = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, messageID)
+	return count, err
+}
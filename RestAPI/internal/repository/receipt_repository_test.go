@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var receiptTestColumns = []string{"message_id", "user_id", "delivered_at", "read_at", "changed"}
+
+func TestReceiptRepository_MarkRead(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReceiptRepository(db)
+	ctx := context.Background()
+
+	t.Run("first read", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery(`INSERT INTO message_receipts .* ON CONFLICT \(message_id, user_id\) DO UPDATE SET read_at = COALESCE`).
+			WithArgs("m1", "bob").
+			WillReturnRows(sqlmock.NewRows(receiptTestColumns).AddRow("m1", "bob", now, now, true))
+
+		receipt, changed, err := repo.MarkRead(ctx, "m1", "bob")
+
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, "bob", receipt.UserID)
+		require.NotNil(t, receipt.ReadAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("message deleted", func(t *testing.T) {
+		mock.ExpectQuery(`INSERT INTO message_receipts`).
+			WithArgs("gone", "bob").
+			WillReturnError(&pq.Error{Code: "23503"})
+
+		_, _, err := repo.MarkRead(ctx, "gone", "bob")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestReceiptRepository_MarkReadUpTo(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReceiptRepository(db)
+	ctx := context.Background()
+	upTo := &Message{ID: 42, MessageID: "m42", CreatedAt: time.Now()}
+
+	t.Run("room", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT m.message_id, \$1, .* WHERE m.room_id = \$2 AND m.user_id <> \$1 .* WHERE message_receipts.read_at IS NULL`).
+			WithArgs("bob", testRoomID, upTo.CreatedAt, int64(42), 1000).
+			WillReturnRows(sqlmock.NewRows([]string{"message_id"}).AddRow("m41").AddRow("m42"))
+
+		ids, err := repo.MarkReadUpTo(ctx, "bob", ReceiptScope{RoomID: testRoomID}, upTo, 1000)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"m41", "m42"}, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("direct messages from the peer", func(t *testing.T) {
+		mock.ExpectQuery(`WHERE m.user_id = \$2 AND m.recipient_id = \$1`).
+			WithArgs("bob", "alice", upTo.CreatedAt, int64(42), 1000).
+			WillReturnRows(sqlmock.NewRows([]string{"message_id"}))
+
+		ids, err := repo.MarkReadUpTo(ctx, "bob", ReceiptScope{PeerID: "alice"}, upTo, 1000)
+
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no scope", func(t *testing.T) {
+		_, err := repo.MarkReadUpTo(ctx, "bob", ReceiptScope{}, upTo, 1000)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestReceiptRepository_Summary(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReceiptRepository(db)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) AS delivered, COUNT\(read_at\) AS read FROM message_receipts`).
+		WithArgs("m1").
+		WillReturnRows(sqlmock.NewRows([]string{"delivered", "read"}).AddRow(3, 1))
+
+	summary, err := repo.Summary(context.Background(), "m1")
+
+	require.NoError(t, err)
+	assert.Equal(t, &ReceiptSummary{Delivered: 3, Read: 1}, summary)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReceiptRepository_ListByUser(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewReceiptRepository(db)
+
+	mock.ExpectQuery(`SELECT message_id, user_id, delivered_at, read_at FROM message_receipts WHERE user_id = \$1 ORDER BY delivered_at, message_id LIMIT \$2 OFFSET \$3`).
+		WithArgs("bob", 500, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"message_id", "user_id", "delivered_at", "read_at"}).
+			AddRow("m1", "bob", time.Now(), nil))
+
+	receipts, err := repo.ListByUser(context.Background(), "bob", 500, 0)
+
+	require.NoError(t, err)
+	require.Len(t, receipts, 1)
+	assert.Nil(t, receipts[0].ReadAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
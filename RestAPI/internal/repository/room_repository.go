@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Room member roles, from most to least privileged
+const (
+	RoomRoleOwner     = "owner"
+	RoomRoleModerator = "moderator"
+	RoomRoleMember    = "member"
+)
+
+// IsValidRoomRole reports whether role is a known room member role
+func IsValidRoomRole(role string) bool {
+	return role == RoomRoleOwner || role == RoomRoleModerator || role == RoomRoleMember
+}
+
+// Room errors. 두 not-found 오류는 sql.ErrNoRows 를 감싸므로 errors.Is(err, sql.ErrNoRows) 로도 잡힌다.
+var (
+	ErrLastOwner          = errors.New("room must keep at least one owner")
+	ErrRoomNotFound       = fmt.Errorf("room not found: %w", sql.ErrNoRows)
+	ErrRoomMemberNotFound = fmt.Errorf("room member not found: %w", sql.ErrNoRows)
+)
+
+// Room represents a conversation
+type Room struct {
+	ID          int64     `db:"id" json:"-"`
+	RoomID      string    `db:"room_id" json:"room_id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	IsPrivate   bool      `db:"is_private" json:"is_private"`
+	CreatedBy   string    `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// RoomUpdate holds the room fields to change; nil fields are left as they are
+type RoomUpdate struct {
+	Name        *string
+	Description *string
+	IsPrivate   *bool
+}
+
+// RoomMember is a user's membership in a room
+type RoomMember struct {
+	RoomID   string    `db:"room_id" json:"room_id"`
+	UserID   string    `db:"user_id" json:"user_id"`
+	Role     string    `db:"role" json:"role"`
+	JoinedAt time.Time `db:"joined_at" json:"joined_at"`
+}
+
+// RoomRepository defines room and membership data access methods.
+// 역할을 바꾸거나 멤버를 빼는 작업은 방 행을 잠그고 소유자 수를 확인한다 — 동시에 나가도 소유자가 0 명이 되지 않는다.
+type RoomRepository interface {
+	Create(ctx context.Context, room *Room, ownerID string) error
+	GetByRoomID(ctx context.Context, roomID string) (*Room, error)
+	List(ctx context.Context, viewerID string, includePrivate bool, limit, offset int) ([]*Room, error)
+	Count(ctx context.Context, viewerID string, includePrivate bool) (int64, error)
+	Update(ctx context.Context, roomID string, update RoomUpdate) (*Room, error)
+	Delete(ctx context.Context, roomID string) error
+	GetMember(ctx context.Context, roomID, userID string) (*RoomMember, error)
+	ListMembers(ctx context.Context, roomID string, limit, offset int) ([]*RoomMember, error)
+	ListMemberships(ctx context.Context, userID string, limit, offset int) ([]*RoomMember, error)
+	CountMembers(ctx context.Context, roomID string) (int64, error)
+	SetMember(ctx context.Context, roomID, userID, role string) (*RoomMember, error)
+	RemoveMember(ctx context.Context, roomID, userID string) error
+}
+
+// roomRepository implements RoomRepository
+type roomRepository struct {
+	db *sqlx.DB
+}
+
+// NewRoomRepository creates a new room repository
+func NewRoomRepository(db *sqlx.DB) RoomRepository {
+	return &roomRepository{db: db}
+}
+
+// Create creates a room with ownerID as its first owner.
+// 소유자가 없으면 sql.ErrNoRows 를 감싸서 돌려준다.
+func (r *roomRepository) Create(ctx context.Context, room *Room, ownerID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO rooms (name, description, is_private, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, room_id, created_at, updated_at
+	`, room.Name, room.Description, room.IsPrivate, room.CreatedBy).
+		Scan(&room.ID, &room.RoomID, &room.CreatedAt, &room.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO room_members (room_id, user_id, role)
+		SELECT $1, $2, 'owner'
+		WHERE EXISTS (SELECT 1 FROM users WHERE user_id = $2 AND deleted_at IS NULL)
+	`, room.RoomID, ownerID)
+	if err != nil {
+		return err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return err
+	} else if rows == 0 {
+		return fmt.Errorf("user not found: %s: %w", ownerID, sql.ErrNoRows)
+	}
+
+	return tx.Commit()
+}
+
+// GetByRoomID retrieves a room by room_id
+func (r *roomRepository) GetByRoomID(ctx context.Context, roomID string) (*Room, error) {
+	query := `
+		SELECT id, room_id, name, description, is_private, created_by, created_at, updated_at
+		FROM rooms
+		WHERE room_id = $1
+	`
+
+	var room Room
+	err := r.db.GetContext(ctx, &room, query, roomID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", roomID, ErrRoomNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// List retrieves public rooms and the private rooms viewerID belongs to, newest first.
+// includePrivate 면 비공개 방까지 모두 돌려준다(관리자·인증 비활성). 식별되지 않은 호출자는 viewerID 가 비어 공개 방만 본다.
+func (r *roomRepository) List(ctx context.Context, viewerID string, includePrivate bool, limit, offset int) ([]*Room, error) {
+	query := `
+		SELECT id, room_id, name, description, is_private, created_by, created_at, updated_at
+		FROM rooms
+		WHERE $1 OR NOT is_private
+		   OR EXISTS (SELECT 1 FROM room_members m WHERE m.room_id = rooms.room_id AND m.user_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	var rooms []*Room
+	err := r.db.SelectContext(ctx, &rooms, query, includePrivate, viewerID, limit, offset)
+	return rooms, err
+}
+
+// Count returns the number of rooms List would return in total
+func (r *roomRepository) Count(ctx context.Context, viewerID string, includePrivate bool) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM rooms
+		WHERE $1 OR NOT is_private
+		   OR EXISTS (SELECT 1 FROM room_members m WHERE m.room_id = rooms.room_id AND m.user_id = $2)
+	`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, includePrivate, viewerID)
+	return count, err
+}
+
+// Update changes the non-nil fields of a room and returns the updated room
+func (r *roomRepository) Update(ctx context.Context, roomID string, update RoomUpdate) (*Room, error) {
+	query := `
+		UPDATE rooms
+		SET name = COALESCE($1, name),
+		    description = COALESCE($2, description),
+		    is_private = COALESCE($3, is_private),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE room_id = $4
+		RETURNING id, room_id, name, description, is_private, created_by, created_at, updated_at
+	`
+
+	var room Room
+	err := r.db.GetContext(ctx, &room, query, update.Name, update.Description, update.IsPrivate, roomID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s: %w", roomID, ErrRoomNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &room, nil
+}
+
+// Delete deletes a room and its memberships; the room's messages are kept
+func (r *roomRepository) Delete(ctx context.Context, roomID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM rooms WHERE room_id = $1`, roomID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%s: %w", roomID, ErrRoomNotFound)
+	}
+	return nil
+}
+
+// GetMember retrieves a user's membership in a room
+func (r *roomRepository) GetMember(ctx context.Context, roomID, userID string) (*RoomMember, error) {
+	query := `SELECT room_id, user_id, role, joined_at FROM room_members WHERE room_id = $1 AND user_id = $2`
+
+	var member RoomMember
+	err := r.db.GetContext(ctx, &member, query, roomID, userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%s in %s: %w", userID, roomID, ErrRoomMemberNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembers retrieves the members of a room, owners first
+func (r *roomRepository) ListMembers(ctx context.Context, roomID string, limit, offset int) ([]*RoomMember, error) {
+	query := `
+		SELECT room_id, user_id, role, joined_at
+		FROM room_members
+		WHERE room_id = $1
+		ORDER BY CASE role WHEN 'owner' THEN 0 WHEN 'moderator' THEN 1 ELSE 2 END, joined_at
+		LIMIT $2 OFFSET $3
+	`
+
+	var members []*RoomMember
+	err := r.db.SelectContext(ctx, &members, query, roomID, limit, offset)
+	return members, err
+}
+
+// ListMemberships retrieves the rooms a user belongs to, earliest joined first
+func (r *roomRepository) ListMemberships(ctx context.Context, userID string, limit, offset int) ([]*RoomMember, error) {
+	query := `
+		SELECT room_id, user_id, role, joined_at
+		FROM room_members
+		WHERE user_id = $1
+		ORDER BY joined_at, room_id
+		LIMIT $2 OFFSET $3
+	`
+
+	var members []*RoomMember
+	err := r.db.SelectContext(ctx, &members, query, userID, limit, offset)
+	return members, err
+}
+
+// CountMembers returns the number of members of a room
+func (r *roomRepository) CountMembers(ctx context.Context, roomID string) (int64, error) {
+	query := `SELECT COUNT(*) FROM room_members WHERE room_id = $1`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, roomID)
+	return count, err
+}
+
+// SetMember adds an active user to a room or changes the member's role.
+// 사용자가 없으면 sql.ErrNoRows 를, 마지막 소유자를 강등하면 ErrLastOwner 를 감싸서 돌려준다.
+func (r *roomRepository) SetMember(ctx context.Context, roomID, userID, role string) (*RoomMember, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockRoom(ctx, tx, roomID); err != nil {
+		return nil, err
+	}
+	if role != RoomRoleOwner {
+		if err := r.checkNotLastOwner(ctx, tx, roomID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var member RoomMember
+	err = tx.GetContext(ctx, &member, `
+		INSERT INTO room_members (room_id, user_id, role)
+		SELECT $1, $2, $3
+		WHERE EXISTS (SELECT 1 FROM users WHERE user_id = $2 AND deleted_at IS NULL)
+		ON CONFLICT (room_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING room_id, user_id, role, joined_at
+	`, roomID, userID, role)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found: %s: %w", userID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveMember removes a user from a room.
+// 멤버가 아니면 ErrRoomMemberNotFound 를, 마지막 소유자면 ErrLastOwner 를 감싸서 돌려준다.
+func (r *roomRepository) RemoveMember(ctx context.Context, roomID, userID string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.lockRoom(ctx, tx, roomID); err != nil {
+		return err
+	}
+	if err := r.checkNotLastOwner(ctx, tx, roomID, userID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM room_members WHERE room_id = $1 AND user_id = $2`, roomID, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%s in %s: %w", userID, roomID, ErrRoomMemberNotFound)
+	}
+
+	return tx.Commit()
+}
+
+// lockRoom locks the room row for the rest of the transaction
+func (r *roomRepository) lockRoom(ctx context.Context, tx *sqlx.Tx, roomID string) error {
+	var id int64
+	err := tx.GetContext(ctx, &id, `SELECT id FROM rooms WHERE room_id = $1 FOR UPDATE`, roomID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%s: %w", roomID, ErrRoomNotFound)
+	}
+	return err
+}
+
+// checkNotLastOwner fails with ErrLastOwner when userID is the room's only owner
+func (r *roomRepository) checkNotLastOwner(ctx context.Context, tx *sqlx.Tx, roomID, userID string) error {
+	var lastOwner bool
+	err := tx.GetContext(ctx, &lastOwner, `
+		SELECT COALESCE(BOOL_AND(user_id = $2), FALSE)
+		FROM room_members
+		WHERE room_id = $1 AND role = 'owner'
+	`, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if lastOwner {
+		return fmt.Errorf("%s in %s: %w", userID, roomID, ErrLastOwner)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRoomID = "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10"
+
+func TestRoomRepository_Create(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewRoomRepository(db)
+	ctx := context.Background()
+
+	t.Run("created with owner", func(t *testing.T) {
+		now := time.Now()
+		room := &Room{Name: "general", CreatedBy: "alice"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO rooms \(name, description, is_private, created_by\)`).
+			WithArgs("general", "", false, "alice").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "room_id", "created_at", "updated_at"}).AddRow(1, testRoomID, now, now))
+		mock.ExpectExec(`INSERT INTO room_members \(room_id, user_id, role\) SELECT \$1, \$2, 'owner'`).
+			WithArgs(testRoomID, "alice").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.Create(ctx, room, "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, testRoomID, room.RoomID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("owner not found rolls back", func(t *testing.T) {
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO rooms`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "room_id", "created_at", "updated_at"}).AddRow(2, testRoomID, now, now))
+		mock.ExpectExec(`INSERT INTO room_members`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.Create(ctx, &Room{Name: "general", CreatedBy: "ghost"}, "ghost")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRoomRepository_GetByRoomID_NotFound(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewRoomRepository(db)
+
+	mock.ExpectQuery(`SELECT (.+) FROM rooms WHERE room_id = \$1`).
+		WithArgs(testRoomID).
+		WillReturnError(sql.ErrNoRows)
+
+	room, err := repo.GetByRoomID(context.Background(), testRoomID)
+
+	assert.Nil(t, room)
+	assert.ErrorIs(t, err, ErrRoomNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRoomRepository_SetMember(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewRoomRepository(db)
+	ctx := context.Background()
+
+	t.Run("adds member", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM rooms WHERE room_id = \$1 FOR UPDATE`).
+			WithArgs(testRoomID).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT COALESCE\(BOOL_AND\(user_id = \$2\), FALSE\) FROM room_members`).
+			WithArgs(testRoomID, "bob").
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(false))
+		mock.ExpectQuery(`INSERT INTO room_members (.+) ON CONFLICT \(room_id, user_id\) DO UPDATE SET role = EXCLUDED.role`).
+			WithArgs(testRoomID, "bob", RoomRoleMember).
+			WillReturnRows(sqlmock.NewRows([]string{"room_id", "user_id", "role", "joined_at"}).
+				AddRow(testRoomID, "bob", RoomRoleMember, time.Now()))
+		mock.ExpectCommit()
+
+		member, err := repo.SetMember(ctx, testRoomID, "bob", RoomRoleMember)
+
+		require.NoError(t, err)
+		assert.Equal(t, RoomRoleMember, member.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("refuses to demote the last owner", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM rooms`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT COALESCE\(BOOL_AND`).
+			WithArgs(testRoomID, "alice").
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(true))
+		mock.ExpectRollback()
+
+		_, err := repo.SetMember(ctx, testRoomID, "alice", RoomRoleModerator)
+
+		assert.ErrorIs(t, err, ErrLastOwner)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("promoting to owner skips the owner check", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM rooms`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`INSERT INTO room_members`).
+			WithArgs(testRoomID, "ghost", RoomRoleOwner).
+			WillReturnRows(sqlmock.NewRows([]string{"room_id", "user_id", "role", "joined_at"}))
+		mock.ExpectRollback()
+
+		_, err := repo.SetMember(ctx, testRoomID, "ghost", RoomRoleOwner)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NotErrorIs(t, err, ErrRoomNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRoomRepository_RemoveMember(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewRoomRepository(db)
+	ctx := context.Background()
+
+	t.Run("removed", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM rooms`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT COALESCE\(BOOL_AND`).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(false))
+		mock.ExpectExec(`DELETE FROM room_members WHERE room_id = \$1 AND user_id = \$2`).
+			WithArgs(testRoomID, "bob").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.RemoveMember(ctx, testRoomID, "bob")
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM rooms`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(`SELECT COALESCE\(BOOL_AND`).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(false))
+		mock.ExpectExec(`DELETE FROM room_members`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.RemoveMember(ctx, testRoomID, "carol")
+
+		assert.ErrorIs(t, err, ErrRoomMemberNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("room not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id FROM rooms`).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		err := repo.RemoveMember(ctx, testRoomID, "bob")
+
+		assert.ErrorIs(t, err, ErrRoomNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRoomRepository_ListMemberships(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewRoomRepository(db)
+
+	mock.ExpectQuery(`SELECT room_id, user_id, role, joined_at FROM room_members WHERE user_id = \$1 ORDER BY joined_at, room_id LIMIT \$2 OFFSET \$3`).
+		WithArgs("alice", 500, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"room_id", "user_id", "role", "joined_at"}).
+			AddRow(testRoomID, "alice", RoomRoleOwner, time.Now()))
+
+	members, err := repo.ListMemberships(context.Background(), "alice", 500, 0)
+
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, RoomRoleOwner, members[0].Role)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,22 +3,91 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
-// User represents a user in the database
+// User represents a user in the database.
+// PasswordHash 는 GetCredentials 로만 채워진다 — 일반 조회와 캐시에는 해시가 실리지 않는다.
 type User struct {
-	ID        int64          `db:"id" json:"id"`
-	UserID    string         `db:"user_id" json:"user_id"`
-	Username  sql.NullString `db:"username" json:"username,omitempty"`
-	Email     sql.NullString `db:"email" json:"email,omitempty"`
-	Status    string         `db:"status" json:"status"`
-	LastSeen  sql.NullTime   `db:"last_seen" json:"last_seen,omitempty"`
-	CreatedAt time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+	ID           int64          `db:"id" json:"id"`
+	UserID       string         `db:"user_id" json:"user_id"`
+	Username     sql.NullString `db:"username" json:"username,omitempty"`
+	Email        sql.NullString `db:"email" json:"email,omitempty"`
+	PasswordHash sql.NullString `db:"password_hash" json:"-"`
+	Role         string         `db:"role" json:"role"`
+	Status       string         `db:"status" json:"status"`
+	LastSeen     sql.NullTime   `db:"last_seen" json:"last_seen,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// ProfileUpdate holds the profile fields to change; nil fields are left as they are
+type ProfileUpdate struct {
+	Username *sql.NullString
+	Email    *sql.NullString
+}
+
+// UniqueViolationError reports the field whose value is already used by another user
+type UniqueViolationError struct {
+	Field string
+	Err   error
+}
+
+func (e *UniqueViolationError) Error() string {
+	return fmt.Sprintf("%s already in use: %v", e.Field, e.Err)
+}
+
+func (e *UniqueViolationError) Unwrap() error {
+	return e.Err
+}
+
+// userUniqueFields maps unique constraints on users to the JSON field they protect
+var userUniqueFields = map[string]string{
+	"users_user_id_key":        "user_id",
+	"users_username_lower_key": "username",
+	"users_email_lower_key":    "email",
+}
+
+// UserFilter narrows a user search; zero fields are ignored.
+// Query, Username, Email 은 부분 일치(대소문자 무시)이고 pg_trgm 인덱스를 탄다.
+type UserFilter struct {
+	Query          string
+	Username       string
+	Email          string
+	Status         string
+	LastSeenAfter  *time.Time
+	LastSeenBefore *time.Time
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	// UsernameOnly keeps Query off the email column for callers who may not see emails
+	UsernameOnly bool
+}
+
+// UserSort orders a user search; an empty Field sorts by created_at
+type UserSort struct {
+	Field string
+	Desc  bool
+}
+
+// userSortColumns maps the sort fields accepted by Search to their SQL expressions
+var userSortColumns = map[string]string{
+	"created_at": "created_at",
+	"last_seen":  "last_seen",
+	"username":   "LOWER(username)",
+	"email":      "LOWER(email)",
+	"user_id":    "user_id",
+}
+
+// IsValidUserSortField reports whether Search can sort by field
+func IsValidUserSortField(field string) bool {
+	_, ok := userSortColumns[field]
+	return ok
 }
 
 // UserRepository defines user data access methods
@@ -26,13 +95,20 @@ type UserRepository interface {
 	Create(ctx context.Context, user *User) error
 	GetByUserID(ctx context.Context, userID string) (*User, error)
 	GetByID(ctx context.Context, id int64) (*User, error)
+	GetCredentials(ctx context.Context, userID string) (*User, error)
 	Update(ctx context.Context, user *User) error
+	UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*User, error)
 	UpdateStatus(ctx context.Context, userID string, status string) error
+	UpdateRole(ctx context.Context, userID string, role string) (previous string, err error)
 	UpdateLastSeen(ctx context.Context, userID string) error
 	Delete(ctx context.Context, userID string) error
 	List(ctx context.Context, limit, offset int) ([]*User, error)
 	ListByStatus(ctx context.Context, status string, limit, offset int) ([]*User, error)
+	ListByUserIDs(ctx context.Context, userIDs []string) ([]*User, error)
+	Search(ctx context.Context, filter UserFilter, sort UserSort, limit, offset int) ([]*User, error)
 	Count(ctx context.Context) (int64, error)
+	CountSearch(ctx context.Context, filter UserFilter) (int64, error)
+	CountByStatus(ctx context.Context, status string) (int64, error)
 	Exists(ctx context.Context, userID string) (bool, error)
 }
 
@@ -49,23 +125,24 @@ func NewUserRepository(db *sqlx.DB) UserRepository {
 // Create creates a new user
 func (r *userRepository) Create(ctx context.Context, user *User) error {
 	query := `
-		INSERT INTO users (user_id, username, email, status)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO users (user_id, username, email, password_hash, role, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, created_at, updated_at
 	`
 
-	return r.db.QueryRowxContext(
+	err := r.db.QueryRowxContext(
 		ctx, query,
-		user.UserID, user.Username, user.Email, user.Status,
+		user.UserID, user.Username, user.Email, user.PasswordHash, user.Role, user.Status,
 	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	return asUniqueViolation(err)
 }
 
 // GetByUserID retrieves a user by user_id
 func (r *userRepository) GetByUserID(ctx context.Context, userID string) (*User, error) {
 	query := `
-		SELECT id, user_id, username, email, status, last_seen, created_at, updated_at
+		SELECT id, user_id, username, email, role, status, last_seen, created_at, updated_at
 		FROM users
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
 	var user User
@@ -79,9 +156,9 @@ func (r *userRepository) GetByUserID(ctx context.Context, userID string) (*User,
 // GetByID retrieves a user by ID
 func (r *userRepository) GetByID(ctx context.Context, id int64) (*User, error) {
 	query := `
-		SELECT id, user_id, username, email, status, last_seen, created_at, updated_at
+		SELECT id, user_id, username, email, role, status, last_seen, created_at, updated_at
 		FROM users
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	var user User
@@ -92,12 +169,32 @@ func (r *userRepository) GetByID(ctx context.Context, id int64) (*User, error) {
 	return &user, err
 }
 
+// GetCredentials retrieves a user together with the password hash for login
+func (r *userRepository) GetCredentials(ctx context.Context, userID string) (*User, error) {
+	query := `
+		SELECT id, user_id, username, email, password_hash, role, status, last_seen, created_at, updated_at
+		FROM users
+		WHERE user_id = $1 AND deleted_at IS NULL
+	`
+
+	// 로그인은 '없는 사용자' 와 DB 장애를 구분해야 하므로 sql.ErrNoRows 를 감싸서 돌려준다.
+	var user User
+	err := r.db.GetContext(ctx, &user, query, userID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found: %s: %w", userID, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // Update updates a user
 func (r *userRepository) Update(ctx context.Context, user *User) error {
 	query := `
 		UPDATE users
 		SET username = $1, email = $2, status = $3, last_seen = $4
-		WHERE user_id = $5
+		WHERE user_id = $5 AND deleted_at IS NULL
 		RETURNING updated_at
 	`
 
@@ -107,12 +204,45 @@ func (r *userRepository) Update(ctx context.Context, user *User) error {
 	).Scan(&user.UpdatedAt)
 }
 
+// UpdateProfile changes the given profile fields and returns the updated user.
+// 상태·last_seen 은 건드리지 않는다 — 전체 레코드를 덮어쓰는 Update 는 동시에 들어온 상태 변경을 되돌릴 수 있다.
+func (r *userRepository) UpdateProfile(ctx context.Context, userID string, update ProfileUpdate) (*User, error) {
+	query := `
+		UPDATE users
+		SET username = CASE WHEN $1 THEN $2 ELSE username END,
+		    email = CASE WHEN $3 THEN $4 ELSE email END
+		WHERE user_id = $5 AND deleted_at IS NULL
+		RETURNING id, user_id, username, email, role, status, last_seen, created_at, updated_at
+	`
+
+	var username, email sql.NullString
+	if update.Username != nil {
+		username = *update.Username
+	}
+	if update.Email != nil {
+		email = *update.Email
+	}
+
+	var user User
+	err := r.db.GetContext(
+		ctx, &user, query,
+		update.Username != nil, username, update.Email != nil, email, userID,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("user not found: %s: %w", userID, err)
+	}
+	if err != nil {
+		return nil, asUniqueViolation(err)
+	}
+	return &user, nil
+}
+
 // UpdateStatus updates user status
 func (r *userRepository) UpdateStatus(ctx context.Context, userID string, status string) error {
 	query := `
 		UPDATE users
 		SET status = $1, last_seen = CURRENT_TIMESTAMP
-		WHERE user_id = $2
+		WHERE user_id = $2 AND deleted_at IS NULL
 	`
 
 	result, err := r.db.ExecContext(ctx, query, status, userID)
@@ -132,34 +262,64 @@ func (r *userRepository) UpdateStatus(ctx context.Context, userID string, status
 	return nil
 }
 
+// UpdateRole updates user role and returns the role it replaced
+func (r *userRepository) UpdateRole(ctx context.Context, userID string, role string) (string, error) {
+	query := `
+		UPDATE users u
+		SET role = $1
+		FROM (SELECT user_id, role FROM users WHERE user_id = $2 AND deleted_at IS NULL FOR UPDATE) old
+		WHERE u.user_id = old.user_id
+		RETURNING old.role
+	`
+
+	var previous string
+	err := r.db.QueryRowxContext(ctx, query, role, userID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user not found: %s: %w", userID, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return previous, nil
+}
+
 // UpdateLastSeen updates user's last seen timestamp
 func (r *userRepository) UpdateLastSeen(ctx context.Context, userID string) error {
 	query := `
 		UPDATE users
 		SET last_seen = CURRENT_TIMESTAMP
-		WHERE user_id = $1
+		WHERE user_id = $1 AND deleted_at IS NULL
 	`
 
 	_, err := r.db.ExecContext(ctx, query, userID)
 	return err
 }
 
-// Delete deletes a user
+// Delete soft-deletes a user and revokes the user's API keys.
+// 행은 erasure 가 끝날 때까지 남지만 다른 모든 쿼리에서 제외된다. 이미 삭제된 사용자는 찾을 수 없는 것으로 본다.
 func (r *userRepository) Delete(ctx context.Context, userID string) error {
-	query := `DELETE FROM users WHERE user_id = $1`
-
-	result, err := r.db.ExecContext(ctx, query, userID)
-	if err != nil {
-		return err
-	}
+	query := `
+		WITH deleted AS (
+			UPDATE users
+			SET deleted_at = CURRENT_TIMESTAMP, status = 'offline'
+			WHERE user_id = $1 AND deleted_at IS NULL
+			RETURNING user_id
+		), revoked AS (
+			UPDATE api_keys
+			SET revoked_at = CURRENT_TIMESTAMP
+			WHERE owner_user_id IN (SELECT user_id FROM deleted) AND revoked_at IS NULL
+		)
+		SELECT COUNT(*) FROM deleted
+	`
 
-	rows, err := result.RowsAffected()
-	if err != nil {
+	var rows int64
+	if err := r.db.GetContext(ctx, &rows, query, userID); err != nil {
 		return err
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("user not found: %s", userID)
+		return fmt.Errorf("user not found: %s: %w", userID, sql.ErrNoRows)
 	}
 
 	return nil
@@ -168,8 +328,9 @@ func (r *userRepository) Delete(ctx context.Context, userID string) error {
 // List retrieves a paginated list of users
 func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*User, error) {
 	query := `
-		SELECT id, user_id, username, email, status, last_seen, created_at, updated_at
+		SELECT id, user_id, username, email, role, status, last_seen, created_at, updated_at
 		FROM users
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -179,12 +340,38 @@ func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*User,
 	return users, err
 }
 
+// Search retrieves users matching the filter in the requested order
+func (r *userRepository) Search(ctx context.Context, filter UserFilter, sort UserSort, limit, offset int) ([]*User, error) {
+	column, ok := userSortColumns[sort.Field]
+	if !ok {
+		column = userSortColumns["created_at"]
+	}
+	direction := "ASC"
+	if sort.Desc {
+		direction = "DESC"
+	}
+
+	// 정렬 컬럼은 화이트리스트에서만 고르므로 문자열로 붙여도 안전하다. id 로 순서를 고정해 페이지가 흔들리지 않게 한다.
+	where, args := filter.whereClause()
+	query := fmt.Sprintf(`
+		SELECT id, user_id, username, email, role, status, last_seen, created_at, updated_at
+		FROM users
+		%s
+		ORDER BY %s %s NULLS LAST, id %s
+		LIMIT $%d OFFSET $%d
+	`, where, column, direction, direction, len(args)+1, len(args)+2)
+
+	var users []*User
+	err := r.db.SelectContext(ctx, &users, query, append(args, limit, offset)...)
+	return users, err
+}
+
 // ListByStatus retrieves users by status
 func (r *userRepository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*User, error) {
 	query := `
-		SELECT id, user_id, username, email, status, last_seen, created_at, updated_at
+		SELECT id, user_id, username, email, role, status, last_seen, created_at, updated_at
 		FROM users
-		WHERE status = $1
+		WHERE status = $1 AND deleted_at IS NULL
 		ORDER BY last_seen DESC
 		LIMIT $2 OFFSET $3
 	`
@@ -194,9 +381,41 @@ func (r *userRepository) ListByStatus(ctx context.Context, status string, limit,
 	return users, err
 }
 
+// ListByUserIDs retrieves the given users in the order of userIDs; unknown IDs are skipped
+func (r *userRepository) ListByUserIDs(ctx context.Context, userIDs []string) ([]*User, error) {
+	if len(userIDs) == 0 {
+		return []*User{}, nil
+	}
+
+	query := `
+		SELECT id, user_id, username, email, role, status, last_seen, created_at, updated_at
+		FROM users
+		WHERE user_id = ANY($1) AND deleted_at IS NULL
+	`
+
+	var found []*User
+	if err := r.db.SelectContext(ctx, &found, query, pq.Array(userIDs)); err != nil {
+		return nil, err
+	}
+
+	// 호출측(접속자 목록)은 Redis 에서 정한 순서를 유지해야 하므로 입력 순서로 다시 맞춘다.
+	byID := make(map[string]*User, len(found))
+	for _, user := range found {
+		byID[user.UserID] = user
+	}
+
+	users := make([]*User, 0, len(found))
+	for _, userID := range userIDs {
+		if user, ok := byID[userID]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 // Count returns the total number of users
 func (r *userRepository) Count(ctx context.Context) (int64, error) {
-	query := `SELECT COUNT(*) FROM users`
+	query := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
 
 	var count int64
 	err := r.db.GetContext(ctx, &count, query)
@@ -205,9 +424,86 @@ func (r *userRepository) Count(ctx context.Context) (int64, error) {
 
 // Exists checks if a user exists
 func (r *userRepository) Exists(ctx context.Context, userID string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE user_id = $1 AND deleted_at IS NULL)`
 
 	var exists bool
 	err := r.db.GetContext(ctx, &exists, query, userID)
 	return exists, err
 }
+
+// CountSearch returns the number of users matching the filter
+func (r *userRepository) CountSearch(ctx context.Context, filter UserFilter) (int64, error) {
+	where, args := filter.whereClause()
+	query := "SELECT COUNT(*) FROM users " + where
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, args...)
+	return count, err
+}
+
+// CountByStatus returns the number of users with the given status
+func (r *userRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	query := `SELECT COUNT(*) FROM users WHERE status = $1 AND deleted_at IS NULL`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, status)
+	return count, err
+}
+
+// asUniqueViolation converts a unique constraint violation on users into a UniqueViolationError
+func asUniqueViolation(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		if field, ok := userUniqueFields[pqErr.Constraint]; ok {
+			return &UniqueViolationError{Field: field, Err: err}
+		}
+	}
+	return err
+}
+
+// whereClause builds the WHERE clause and its arguments.
+// 값은 전부 플레이스홀더로 넘기고 컬럼 이름만 고정 문자열로 붙인다.
+func (f UserFilter) whereClause() (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if f.Query != "" && f.UsernameOnly {
+		add("username ILIKE $?", containsPattern(f.Query))
+	} else if f.Query != "" {
+		add("(username ILIKE $? OR email ILIKE $?)", containsPattern(f.Query))
+	}
+	if f.Username != "" {
+		add("username ILIKE $?", containsPattern(f.Username))
+	}
+	if f.Email != "" {
+		add("email ILIKE $?", containsPattern(f.Email))
+	}
+	if f.Status != "" {
+		add("status = $?", f.Status)
+	}
+	if f.LastSeenAfter != nil {
+		add("last_seen >= $?", *f.LastSeenAfter)
+	}
+	if f.LastSeenBefore != nil {
+		add("last_seen < $?", *f.LastSeenBefore)
+	}
+	if f.CreatedAfter != nil {
+		add("created_at >= $?", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		add("created_at < $?", *f.CreatedBefore)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// containsPattern builds an ILIKE pattern matching term anywhere, with LIKE wildcards in term escaped
+func containsPattern(term string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(term)
+	return "%" + escaped + "%"
+}
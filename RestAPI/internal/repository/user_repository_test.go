@@ -3,11 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -37,6 +39,7 @@ func TestUserRepository_Create(t *testing.T) {
 			UserID:   "test_user_123",
 			Username: sql.NullString{String: "testuser", Valid: true},
 			Email:    sql.NullString{String: "test@example.com", Valid: true},
+			Role:     "user",
 			Status:   "offline",
 		}
 
@@ -45,7 +48,7 @@ func TestUserRepository_Create(t *testing.T) {
 			AddRow(int64(1), now, now)
 
 		mock.ExpectQuery(`INSERT INTO users`).
-			WithArgs(user.UserID, user.Username, user.Email, user.Status).
+			WithArgs(user.UserID, user.Username, user.Email, user.PasswordHash, user.Role, user.Status).
 			WillReturnRows(rows)
 
 		err := repo.Create(ctx, user)
@@ -64,7 +67,7 @@ func TestUserRepository_Create(t *testing.T) {
 		}
 
 		mock.ExpectQuery(`INSERT INTO users`).
-			WithArgs(user.UserID, user.Username, user.Email, user.Status).
+			WithArgs(user.UserID, user.Username, user.Email, user.PasswordHash, user.Role, user.Status).
 			WillReturnError(sql.ErrConnDone)
 
 		err := repo.Create(ctx, user)
@@ -116,6 +119,43 @@ func TestUserRepository_GetByUserID(t *testing.T) {
 	})
 }
 
+func TestUserRepository_GetCredentials(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("user found", func(t *testing.T) {
+		userID := "test_user_123"
+		now := time.Now()
+
+		rows := sqlmock.NewRows([]string{"id", "user_id", "username", "email", "password_hash", "status", "last_seen", "created_at", "updated_at"}).
+			AddRow(int64(1), userID, "testuser", "test@example.com", "$2a$10$hash", "online", now, now, now)
+
+		mock.ExpectQuery(`SELECT (.+)password_hash(.+) FROM users WHERE user_id`).
+			WithArgs(userID).
+			WillReturnRows(rows)
+
+		user, err := repo.GetCredentials(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.True(t, user.PasswordHash.Valid)
+		assert.Equal(t, "$2a$10$hash", user.PasswordHash.String)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT (.+) FROM users WHERE user_id`).
+			WithArgs("nonexistent_user").
+			WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetCredentials(ctx, "nonexistent_user")
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUserRepository_UpdateStatus(t *testing.T) {
 	db, mock := setupTestDB(t)
 	repo := NewUserRepository(db)
@@ -151,17 +191,96 @@ func TestUserRepository_UpdateStatus(t *testing.T) {
 	})
 }
 
+func TestUserRepository_UpdateProfile(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	email := sql.NullString{String: "new@example.com", Valid: true}
+
+	t.Run("only the given fields", func(t *testing.T) {
+		now := time.Now()
+		rows := sqlmock.NewRows([]string{"id", "user_id", "username", "email", "role", "status", "last_seen", "created_at", "updated_at"}).
+			AddRow(int64(1), "test_user", "testuser", "new@example.com", "user", "online", now, now, now)
+
+		mock.ExpectQuery(`UPDATE users SET username = CASE WHEN \$1 THEN \$2 ELSE username END`).
+			WithArgs(false, sql.NullString{}, true, email, "test_user").
+			WillReturnRows(rows)
+
+		user, err := repo.UpdateProfile(ctx, "test_user", ProfileUpdate{Email: &email})
+
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", user.Email.String)
+		assert.Equal(t, "testuser", user.Username.String)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE users`).
+			WithArgs(false, sql.NullString{}, true, email, "ghost").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdateProfile(ctx, "ghost", ProfileUpdate{Email: &email})
+
+		assert.True(t, errors.Is(err, sql.ErrNoRows))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("email taken", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE users`).
+			WithArgs(false, sql.NullString{}, true, email, "test_user").
+			WillReturnError(&pq.Error{Code: "23505", Constraint: "users_email_lower_key"})
+
+		_, err := repo.UpdateProfile(ctx, "test_user", ProfileUpdate{Email: &email})
+
+		var conflict *UniqueViolationError
+		require.True(t, errors.As(err, &conflict))
+		assert.Equal(t, "email", conflict.Field)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_UpdateRole(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("successful role update", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE users u SET role = \$1 FROM \(SELECT user_id, role FROM users WHERE user_id = \$2 AND deleted_at IS NULL FOR UPDATE\) old WHERE u.user_id = old.user_id RETURNING old.role`).
+			WithArgs("admin", "test_user_123").
+			WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("user"))
+
+		previous, err := repo.UpdateRole(ctx, "test_user_123", "admin")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "user", previous)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mock.ExpectQuery(`UPDATE users u SET role`).
+			WithArgs("admin", "nonexistent_user").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UpdateRole(ctx, "nonexistent_user", "admin")
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Contains(t, err.Error(), "user not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUserRepository_Delete(t *testing.T) {
 	db, mock := setupTestDB(t)
 	repo := NewUserRepository(db)
 	ctx := context.Background()
 
-	t.Run("successful deletion", func(t *testing.T) {
+	t.Run("soft deletes and revokes API keys", func(t *testing.T) {
 		userID := "test_user_123"
 
-		mock.ExpectExec(`DELETE FROM users WHERE user_id`).
+		mock.ExpectQuery(`UPDATE users SET deleted_at = CURRENT_TIMESTAMP(.+)WHERE user_id = \$1 AND deleted_at IS NULL(.+)UPDATE api_keys SET revoked_at`).
 			WithArgs(userID).
-			WillReturnResult(sqlmock.NewResult(0, 1))
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(1)))
 
 		err := repo.Delete(ctx, userID)
 
@@ -172,13 +291,13 @@ func TestUserRepository_Delete(t *testing.T) {
 	t.Run("user not found", func(t *testing.T) {
 		userID := "nonexistent_user"
 
-		mock.ExpectExec(`DELETE FROM users WHERE user_id`).
+		mock.ExpectQuery(`UPDATE users SET deleted_at`).
 			WithArgs(userID).
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(0)))
 
 		err := repo.Delete(ctx, userID)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
 		assert.Contains(t, err.Error(), "user not found")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -197,7 +316,7 @@ func TestUserRepository_List(t *testing.T) {
 			AddRow(int64(1), "user1", "name1", "email1@test.com", "online", now, now, now).
 			AddRow(int64(2), "user2", "name2", "email2@test.com", "offline", now, now, now)
 
-		mock.ExpectQuery(`SELECT (.+) FROM users ORDER BY created_at DESC`).
+		mock.ExpectQuery(`SELECT (.+) FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC`).
 			WithArgs(limit, offset).
 			WillReturnRows(rows)
 
@@ -215,7 +334,7 @@ func TestUserRepository_List(t *testing.T) {
 
 		rows := sqlmock.NewRows([]string{"id", "user_id", "username", "email", "status", "last_seen", "created_at", "updated_at"})
 
-		mock.ExpectQuery(`SELECT (.+) FROM users ORDER BY created_at DESC`).
+		mock.ExpectQuery(`SELECT (.+) FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC`).
 			WithArgs(limit, offset).
 			WillReturnRows(rows)
 
@@ -314,6 +433,102 @@ func TestUserRepository_ListByStatus(t *testing.T) {
 	})
 }
 
+func TestUserRepository_ListByUserIDs(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("keeps the requested order", func(t *testing.T) {
+		now := time.Now()
+
+		rows := sqlmock.NewRows([]string{"id", "user_id", "username", "email", "status", "last_seen", "created_at", "updated_at"}).
+			AddRow(int64(1), "user1", "name1", "email1@test.com", "online", now, now, now).
+			AddRow(int64(2), "user2", "name2", "email2@test.com", "online", now, now, now)
+
+		mock.ExpectQuery(`SELECT (.+) FROM users WHERE user_id = ANY`).
+			WithArgs(pq.Array([]string{"user2", "ghost", "user1"})).
+			WillReturnRows(rows)
+
+		users, err := repo.ListByUserIDs(ctx, []string{"user2", "ghost", "user1"})
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, "user2", users[0].UserID)
+		assert.Equal(t, "user1", users[1].UserID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("empty input skips the query", func(t *testing.T) {
+		users, err := repo.ListByUserIDs(ctx, nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
+}
+
+func TestUserRepository_Search(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("defaults to created_at", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT (.+) FROM users WHERE deleted_at IS NULL ORDER BY created_at ASC NULLS LAST, id ASC LIMIT \$1 OFFSET \$2`).
+			WithArgs(20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+
+		_, err := repo.Search(ctx, UserFilter{}, UserSort{}, 20, 0)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("filters and sort", func(t *testing.T) {
+		now := time.Now()
+		since := now.Add(-time.Hour)
+
+		rows := sqlmock.NewRows([]string{"id", "user_id", "username", "email", "status", "last_seen", "created_at", "updated_at"}).
+			AddRow(int64(1), "user1", "alice_1", "alice@test.com", "online", now, now, now)
+
+		mock.ExpectQuery(`FROM users WHERE deleted_at IS NULL AND \(username ILIKE \$1 OR email ILIKE \$1\) AND status = \$2 AND last_seen >= \$3 ORDER BY LOWER\(username\) DESC NULLS LAST, id DESC LIMIT \$4 OFFSET \$5`).
+			WithArgs(`%ali\_1%`, "online", since, 10, 0).
+			WillReturnRows(rows)
+
+		users, err := repo.Search(ctx, UserFilter{Query: "ali_1", Status: "online", LastSeenAfter: &since}, UserSort{Field: "username", Desc: true}, 10, 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("query on username only", func(t *testing.T) {
+		mock.ExpectQuery(`FROM users WHERE deleted_at IS NULL AND username ILIKE \$1 ORDER BY created_at ASC NULLS LAST, id ASC LIMIT \$2 OFFSET \$3`).
+			WithArgs(`%example.com%`, 20, 0).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}))
+
+		_, err := repo.Search(ctx, UserFilter{Query: "example.com", UsernameOnly: true}, UserSort{}, 20, 0)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepository_CountSearch(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewUserRepository(db)
+	ctx := context.Background()
+
+	before := time.Now()
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users WHERE deleted_at IS NULL AND email ILIKE \$1 AND created_at < \$2`).
+		WithArgs(`%100\%%`, before).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(int64(3)))
+
+	count, err := repo.CountSearch(ctx, UserFilter{Email: "100%", CreatedBefore: &before})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUserRepository_GetByID(t *testing.T) {
 	db, mock := setupTestDB(t)
 	repo := NewUserRepository(db)
@@ -0,0 +1,328 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// WebhookSubscription is an endpoint that receives events.
+// 서명 키는 매 전송마다 HMAC 에 써야 하므로 해시가 아닌 원문으로 저장하고, 생성 응답에서만 보여준다.
+type WebhookSubscription struct {
+	ID          string         `db:"id" json:"id"`
+	URL         string         `db:"url" json:"url"`
+	Secret      string         `db:"secret" json:"-"`
+	EventTypes  pq.StringArray `db:"event_types" json:"event_types"`
+	Description string         `db:"description" json:"description"`
+	Active      bool           `db:"active" json:"active"`
+	CreatedBy   string         `db:"created_by" json:"created_by"`
+	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscription.
+// URL 과 Secret 은 전송 워커가 가져갈 때만 채워진다.
+type WebhookDelivery struct {
+	ID             string           `db:"id" json:"id"`
+	SubscriptionID string           `db:"subscription_id" json:"subscription_id"`
+	EventID        string           `db:"event_id" json:"event_id"`
+	EventType      string           `db:"event_type" json:"event_type"`
+	UserID         string           `db:"user_id" json:"-"`
+	Payload        *json.RawMessage `db:"payload" json:"payload"`
+	Status         string           `db:"status" json:"status"`
+	Attempts       int              `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time        `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode *int             `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      string           `db:"last_error" json:"last_error,omitempty"`
+	LeaseUntil     *time.Time       `db:"lease_until" json:"-"`
+	CreatedAt      time.Time        `db:"created_at" json:"created_at"`
+	DeliveredAt    *time.Time       `db:"delivered_at" json:"delivered_at,omitempty"`
+	URL            string           `db:"url" json:"-"`
+	Secret         string           `db:"secret" json:"-"`
+}
+
+// WebhookRepository defines webhook subscription and delivery data access methods.
+// 전송은 export 와 같은 임대(lease) 방식이라 여러 복제본이 같은 전송을 동시에 보내지 않는다.
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *WebhookSubscription) error
+	GetSubscription(ctx context.Context, id string) (*WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, limit, offset int) ([]*WebhookSubscription, error)
+	CountSubscriptions(ctx context.Context) (int64, error)
+	UpdateSubscription(ctx context.Context, sub *WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+
+	Enqueue(ctx context.Context, eventID, eventType, userID string, payload json.RawMessage) (int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	MarkSucceeded(ctx context.Context, id string, statusCode int) error
+	MarkFailed(ctx context.Context, id string, statusCode int, message string, retryAfter time.Duration, final bool) error
+	Release(ctx context.Context, id string) error
+	GetDelivery(ctx context.Context, subscriptionID, id string) (*WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]*WebhookDelivery, error)
+	CountDeliveries(ctx context.Context, subscriptionID, status string) (int64, error)
+	Redeliver(ctx context.Context, subscriptionID, id string) (*WebhookDelivery, error)
+	DeleteSucceeded(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// webhookRepository implements WebhookRepository
+type webhookRepository struct {
+	db *sqlx.DB
+}
+
+// NewWebhookRepository creates a new webhook repository
+func NewWebhookRepository(db *sqlx.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `id, url, secret, event_types, description, active, created_by, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, user_id, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, lease_until, created_at, delivered_at`
+
+// CreateSubscription creates a subscription
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, description, active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	return r.db.QueryRowxContext(ctx, query, sub.URL, sub.Secret, sub.EventTypes, sub.Description, sub.Active, sub.CreatedBy).
+		Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+// GetSubscription retrieves a subscription by ID
+func (r *webhookRepository) GetSubscription(ctx context.Context, id string) (*WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	var sub WebhookSubscription
+	err := r.db.GetContext(ctx, &sub, query, id)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook subscription not found: %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions retrieves subscriptions, oldest first
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, limit, offset int) ([]*WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		ORDER BY created_at, id
+		LIMIT $1 OFFSET $2
+	`
+
+	var subs []*WebhookSubscription
+	err := r.db.SelectContext(ctx, &subs, query, limit, offset)
+	return subs, err
+}
+
+// CountSubscriptions returns the number of subscriptions
+func (r *webhookRepository) CountSubscriptions(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM webhook_subscriptions`)
+	return count, err
+}
+
+// UpdateSubscription stores the mutable fields of a subscription
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, secret = $3, event_types = $4, description = $5, active = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query, sub.ID, sub.URL, sub.Secret, sub.EventTypes, sub.Description, sub.Active).
+		Scan(&sub.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("webhook subscription not found: %s: %w", sub.ID, err)
+	}
+	return err
+}
+
+// DeleteSubscription deletes a subscription together with its deliveries
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("webhook subscription not found: %s: %w", id, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// Enqueue adds a delivery of the event for every active subscription that wants its type and returns how many were added
+func (r *webhookRepository) Enqueue(ctx context.Context, eventID, eventType, userID string, payload json.RawMessage) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, user_id, payload)
+		SELECT id, $1, $2, $3, $4
+		FROM webhook_subscriptions
+		WHERE active AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+	`
+
+	result, err := r.db.ExecContext(ctx, query, eventID, eventType, userID, jsonbParam(&payload))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimDue leases up to limit pending deliveries whose attempt is due, oldest due first, with the URL and secret to send them with
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1,
+		    lease_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			  AND (lease_until IS NULL OR lease_until < CURRENT_TIMESTAMP)
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.user_id, d.payload, d.status, d.attempts,
+		          d.next_attempt_at, d.last_status_code, d.last_error, d.lease_until, d.created_at, d.delivered_at,
+		          s.url, s.secret
+	`
+
+	var deliveries []*WebhookDelivery
+	err := r.db.SelectContext(ctx, &deliveries, query, limit, lease.Seconds())
+	return deliveries, err
+}
+
+// MarkSucceeded records a delivered event
+func (r *webhookRepository) MarkSucceeded(ctx context.Context, id string, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded', last_status_code = $2, last_error = '', lease_until = NULL,
+		    delivered_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, statusCode)
+	return err
+}
+
+// MarkFailed records a failed attempt; a final failure moves the delivery to the dead state, otherwise it is due again after retryAfter.
+// statusCode 0 은 응답을 받지 못한 실패(연결 오류, 시간 초과)다.
+func (r *webhookRepository) MarkFailed(ctx context.Context, id string, statusCode int, message string, retryAfter time.Duration, final bool) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET last_status_code = NULLIF($2, 0), last_error = $3, lease_until = NULL,
+		    status = CASE WHEN $5 THEN 'dead' ELSE status END,
+		    next_attempt_at = CASE WHEN $5 THEN next_attempt_at ELSE CURRENT_TIMESTAMP + make_interval(secs => $4) END
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id, statusCode, message, retryAfter.Seconds(), final)
+	return err
+}
+
+// Release gives back a leased delivery without counting the attempt, e.g. on shutdown
+func (r *webhookRepository) Release(ctx context.Context, id string) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET lease_until = NULL, attempts = GREATEST(attempts - 1, 0)
+		WHERE id = $1 AND status = 'pending'
+	`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// GetDelivery retrieves a delivery of a subscription
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID, id string) (*WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND subscription_id = $2`
+
+	var delivery WebhookDelivery
+	err := r.db.GetContext(ctx, &delivery, query, id, subscriptionID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("webhook delivery not found: %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries retrieves the deliveries of a subscription with the given status (all when empty), newest first
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]*WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4
+	`
+
+	var deliveries []*WebhookDelivery
+	err := r.db.SelectContext(ctx, &deliveries, query, subscriptionID, status, limit, offset)
+	return deliveries, err
+}
+
+// CountDeliveries returns the number of deliveries of a subscription with the given status (all when empty)
+func (r *webhookRepository) CountDeliveries(ctx context.Context, subscriptionID, status string) (int64, error) {
+	query := `SELECT COUNT(*) FROM webhook_deliveries WHERE subscription_id = $1 AND ($2 = '' OR status = $2)`
+
+	var count int64
+	err := r.db.GetContext(ctx, &count, query, subscriptionID, status)
+	return count, err
+}
+
+// Redeliver moves a dead delivery back to pending with fresh attempts.
+// dead 가 아니면 바꾸지 않고 sql.ErrNoRows 를 감싸서 돌려준다.
+func (r *webhookRepository) Redeliver(ctx context.Context, subscriptionID, id string) (*WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, lease_until = NULL
+		WHERE id = $1 AND subscription_id = $2 AND status = 'dead'
+		RETURNING ` + webhookDeliveryColumns
+
+	var delivery WebhookDelivery
+	err := r.db.GetContext(ctx, &delivery, query, id, subscriptionID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("dead webhook delivery not found: %s: %w", id, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// DeleteSucceeded removes deliveries that succeeded more than olderThan ago and returns how many were removed.
+// dead 전송은 손으로 재전송할 수 있도록 남겨둔다.
+func (r *webhookRepository) DeleteSucceeded(ctx context.Context, olderThan time.Duration) (int64, error) {
+	query := `
+		DELETE FROM webhook_deliveries
+		WHERE status = 'succeeded' AND delivered_at < CURRENT_TIMESTAMP - make_interval(secs => $1)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, olderThan.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
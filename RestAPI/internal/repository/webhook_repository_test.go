@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var webhookDeliveryTestColumns = []string{
+	"id", "subscription_id", "event_id", "event_type", "user_id", "payload", "status", "attempts",
+	"next_attempt_at", "last_status_code", "last_error", "lease_until", "created_at", "delivered_at",
+}
+
+func TestWebhookRepository_CreateSubscription(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewWebhookRepository(db)
+
+	sub := &WebhookSubscription{
+		URL: "https://crm.example/hooks", Secret: "whsec_x", EventTypes: pq.StringArray{"user.created"}, Active: true, CreatedBy: "admin",
+	}
+
+	mock.ExpectQuery(`INSERT INTO webhook_subscriptions \(url, secret, event_types, description, active, created_by\)`).
+		WithArgs("https://crm.example/hooks", "whsec_x", sub.EventTypes, "", true, "admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "updated_at"}).AddRow("w1", time.Now(), time.Now()))
+
+	require.NoError(t, repo.CreateSubscription(context.Background(), sub))
+	assert.Equal(t, "w1", sub.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_DeleteSubscription(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewWebhookRepository(db)
+
+	mock.ExpectExec(`DELETE FROM webhook_subscriptions WHERE id = \$1`).WithArgs("w1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM webhook_subscriptions`).WithArgs("w2").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.DeleteSubscription(context.Background(), "w1"))
+	assert.ErrorIs(t, repo.DeleteSubscription(context.Background(), "w2"), sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_Enqueue(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewWebhookRepository(db)
+
+	payload := json.RawMessage(`{"type":"user.created"}`)
+	mock.ExpectExec(`INSERT INTO webhook_deliveries (.+) FROM webhook_subscriptions WHERE active AND \(cardinality\(event_types\) = 0 OR \$2 = ANY\(event_types\)\)`).
+		WithArgs("e1", "user.created", "alice", string(payload)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	count, err := repo.Enqueue(context.Background(), "e1", "user.created", "alice", payload)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_ClaimDue(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewWebhookRepository(db)
+
+	columns := append(append([]string{}, webhookDeliveryTestColumns...), "url", "secret")
+	now := time.Now()
+	mock.ExpectQuery(`UPDATE webhook_deliveries d SET attempts = d.attempts \+ 1, (.+) FROM webhook_subscriptions s (.+) FOR UPDATE SKIP LOCKED`).
+		WithArgs(20, float64(60)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(
+			"d1", "w1", "e1", "user.created", "alice", []byte(`{}`), DeliveryPending, 1,
+			now, nil, "", now.Add(time.Minute), now, nil, "https://crm.example/hooks", "whsec_x",
+		))
+
+	deliveries, err := repo.ClaimDue(context.Background(), 20, time.Minute)
+
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "https://crm.example/hooks", deliveries[0].URL)
+	assert.Equal(t, "whsec_x", deliveries[0].Secret)
+	assert.Nil(t, deliveries[0].LastStatusCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_MarkFailed(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewWebhookRepository(db)
+
+	mock.ExpectExec(`UPDATE webhook_deliveries SET last_status_code = NULLIF\(\$2, 0\), (.+) status = CASE WHEN \$5 THEN 'dead'`).
+		WithArgs("d1", 503, "HTTP 503", float64(20), false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.MarkFailed(context.Background(), "d1", 503, "HTTP 503", 20*time.Second, false))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookRepository_Redeliver(t *testing.T) {
+	db, mock := setupTestDB(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(`UPDATE webhook_deliveries SET status = 'pending', attempts = 0, (.+) WHERE id = \$1 AND subscription_id = \$2 AND status = 'dead'`).
+		WithArgs("d1", "w1").
+		WillReturnRows(sqlmock.NewRows(webhookDeliveryTestColumns).AddRow(
+			"d1", "w1", "e1", "user.created", "alice", []byte(`{}`), DeliveryPending, 0,
+			time.Now(), 500, "HTTP 500", nil, time.Now(), nil,
+		))
+	mock.ExpectQuery(`UPDATE webhook_deliveries`).WithArgs("d2", "w1").WillReturnError(sql.ErrNoRows)
+
+	delivery, err := repo.Redeliver(ctx, "w1", "d1")
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryPending, delivery.Status)
+	assert.Equal(t, 500, *delivery.LastStatusCode)
+
+	_, err = repo.Redeliver(ctx, "w1", "d2")
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// apiKeyPrefix starts every API key so leaked keys are easy to recognise in logs and scanners
+const apiKeyPrefix = "rtmc"
+
+// APIKeyUsageFlushInterval is how often buffered usage counters are written to the database
+const APIKeyUsageFlushInterval = 30 * time.Second
+
+// IssueAPIKeyInput describes a key to issue
+type IssueAPIKeyInput struct {
+	Name        string
+	OwnerUserID string
+	Scopes      []string
+	ExpiresAt   *time.Time
+	CreatedBy   string
+}
+
+// IssuedAPIKey is returned once at issuance and is the only place the key appears in plain text
+type IssuedAPIKey struct {
+	Key string `json:"key"`
+	*repository.APIKey
+}
+
+// apiKeyUsage accumulates uses of one key between flushes
+type apiKeyUsage struct {
+	count    int64
+	lastUsed time.Time
+}
+
+// APIKeyService issues, revokes and authenticates API keys.
+// 사용량은 요청마다 UPDATE 하지 않고 메모리에 모았다가 주기적으로 한 번에 기록한다 — 퍼블리셔 데몬은 초당 수십 건을 보낸다.
+type APIKeyService struct {
+	repo     repository.APIKeyRepository
+	userRepo repository.UserRepository
+
+	mu    sync.Mutex
+	usage map[string]*apiKeyUsage
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService(repo repository.APIKeyRepository, userRepo repository.UserRepository) *APIKeyService {
+	return &APIKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+		usage:    make(map[string]*apiKeyUsage),
+	}
+}
+
+// IssueKey creates a key for the owner and returns it in plain text
+func (s *APIKeyService) IssueKey(ctx context.Context, input IssueAPIKeyInput) (*IssuedAPIKey, error) {
+	if len(input.Scopes) == 0 {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "At least one scope is required", 400)
+	}
+	for _, scope := range input.Scopes {
+		if !middleware.IsValidScope(scope) {
+			return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid scope: "+scope, 400)
+		}
+	}
+	// 키는 역할을 물려받지 않고 관리자 라우트는 admin 역할을 요구하므로, admin scope 키는 쓸 곳이 없다.
+	// 발급을 막아 쓸 수 없는 권한을 가진 키가 생기지 않게 한다 — 관리 작업은 관리자 토큰으로 한다.
+	if slices.Contains(input.Scopes, middleware.ScopeAdmin) {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "The admin scope cannot be issued to API keys; admin routes require an admin access token", 400)
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "expires_at must be in the future", 400)
+	}
+
+	if _, err := s.userRepo.GetByUserID(ctx, input.OwnerUserID); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Owner not found", 404)
+	}
+
+	keyID, secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to generate API key", 500)
+	}
+
+	plain := apiKeyPrefix + "_" + keyID + "_" + secret
+	key := &repository.APIKey{
+		KeyID:       keyID,
+		Name:        input.Name,
+		SecretHash:  hashToken(plain),
+		OwnerUserID: input.OwnerUserID,
+		Scopes:      input.Scopes,
+		CreatedBy:   input.CreatedBy,
+	}
+	if input.ExpiresAt != nil {
+		key.ExpiresAt = sql.NullTime{Time: *input.ExpiresAt, Valid: true}
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		logger.Errorf("Failed to create API key: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to create API key", 500)
+	}
+
+	logger.Infof("API key issued: %s (owner %s, scopes %v)", keyID, input.OwnerUserID, input.Scopes)
+	return &IssuedAPIKey{Key: plain, APIKey: key}, nil
+}
+
+// ListKeys retrieves paginated API keys, optionally only those of one owner
+func (s *APIKeyService) ListKeys(ctx context.Context, ownerUserID string, limit, offset int) ([]*repository.APIKey, int64, error) {
+	keys, err := s.repo.List(ctx, ownerUserID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list API keys: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list API keys", 500)
+	}
+
+	total, err := s.repo.Count(ctx, ownerUserID)
+	if err != nil {
+		logger.Errorf("Failed to count API keys: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count API keys", 500)
+	}
+
+	return keys, total, nil
+}
+
+// RevokeKey revokes an API key; later requests with it are rejected immediately
+func (s *APIKeyService) RevokeKey(ctx context.Context, keyID string) error {
+	err := s.repo.Revoke(ctx, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.New(apperrors.ErrCodeNotFound, "API key not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to revoke API key: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to revoke API key", 500)
+	}
+
+	logger.Infof("API key revoked: %s", keyID)
+	return nil
+}
+
+// AuthenticateAPIKey implements middleware.APIKeyAuthenticator.
+// 폐기가 즉시 반영되도록 캐시 없이 매번 key_id 유니크 인덱스로 조회한다.
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, plain string) (*middleware.APIKeyPrincipal, error) {
+	keyID, ok := parseAPIKeyID(plain)
+	if !ok {
+		return nil, nil
+	}
+
+	key, err := s.repo.GetByKeyID(ctx, keyID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(plain)), []byte(key.SecretHash)) != 1 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if key.RevokedAt.Valid || (key.ExpiresAt.Valid && !now.Before(key.ExpiresAt.Time)) {
+		return nil, nil
+	}
+
+	s.recordUse(key.KeyID, now)
+
+	return &middleware.APIKeyPrincipal{
+		KeyID:       key.KeyID,
+		OwnerUserID: key.OwnerUserID,
+		Scopes:      key.Scopes,
+	}, nil
+}
+
+// StartUsageFlusher writes buffered usage counters every interval until Close is called
+func (s *APIKeyService) StartUsageFlusher(interval time.Duration) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.FlushUsage(context.Background())
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the flusher and writes the remaining usage counters
+func (s *APIKeyService) Close() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+
+	s.FlushUsage(context.Background())
+}
+
+// FlushUsage writes buffered usage counters to the database.
+// 기록에 실패한 카운터는 다음 주기에 다시 시도하도록 되돌려 놓는다.
+func (s *APIKeyService) FlushUsage(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.usage
+	s.usage = make(map[string]*apiKeyUsage)
+	s.mu.Unlock()
+
+	for keyID, usage := range pending {
+		if err := s.repo.RecordUsage(ctx, keyID, usage.count, usage.lastUsed); err != nil {
+			logger.Warnf("Failed to record usage of API key %s: %v", keyID, err)
+
+			s.mu.Lock()
+			s.addUsage(keyID, usage.count, usage.lastUsed)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// recordUse counts one authenticated request for the key
+func (s *APIKeyService) recordUse(keyID string, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addUsage(keyID, 1, at)
+}
+
+// addUsage merges a usage delta; callers hold s.mu
+func (s *APIKeyService) addUsage(keyID string, count int64, at time.Time) {
+	usage, ok := s.usage[keyID]
+	if !ok {
+		usage = &apiKeyUsage{}
+		s.usage[keyID] = usage
+	}
+
+	usage.count += count
+	if at.After(usage.lastUsed) {
+		usage.lastUsed = at
+	}
+}
+
+// newAPIKeySecret returns a random public key ID and a 256-bit secret
+func newAPIKeySecret() (string, string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	secret, err := newRefreshToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(id), secret, nil
+}
+
+// parseAPIKeyID extracts the key ID from "rtmc_<key id>_<secret>".
+// secret 은 base64url 이라 '_' 를 포함할 수 있으므로 앞의 두 구분자만 본다.
+func parseAPIKeyID(plain string) (string, bool) {
+	parts := strings.SplitN(plain, "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *repository.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetByKeyID(ctx context.Context, keyID string) (*repository.APIKey, error) {
+	args := m.Called(ctx, keyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) List(ctx context.Context, ownerUserID string, limit, offset int) ([]*repository.APIKey, error) {
+	args := m.Called(ctx, ownerUserID, limit, offset)
+	return args.Get(0).([]*repository.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Count(ctx context.Context, ownerUserID string) (int64, error) {
+	args := m.Called(ctx, ownerUserID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, keyID string) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) RecordUsage(ctx context.Context, keyID string, count int64, lastUsedAt time.Time) error {
+	args := m.Called(ctx, keyID, count, lastUsedAt)
+	return args.Error(0)
+}
+
+func setupAPIKeyService() (*APIKeyService, *MockAPIKeyRepository, *MockUserRepository) {
+	keyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+
+	userRepo.On("GetByUserID", mock.Anything, "publisher").Return(&repository.User{UserID: "publisher", Role: middleware.RoleUser}, nil)
+	userRepo.On("GetByUserID", mock.Anything, "root").Return(&repository.User{UserID: "root", Role: middleware.RoleAdmin}, nil)
+
+	return NewAPIKeyService(keyRepo, userRepo), keyRepo, userRepo
+}
+
+// issueTestKey issues a key and makes the mock repository return it on lookup
+func issueTestKey(t *testing.T, svc *APIKeyService, keyRepo *MockAPIKeyRepository, input IssueAPIKeyInput) *IssuedAPIKey {
+	t.Helper()
+
+	keyRepo.On("Create", mock.Anything, mock.AnythingOfType("*repository.APIKey")).Return(nil).Once()
+
+	issued, err := svc.IssueKey(context.Background(), input)
+	require.NoError(t, err)
+
+	keyRepo.On("GetByKeyID", mock.Anything, issued.KeyID).Return(issued.APIKey, nil)
+	return issued
+}
+
+func TestAPIKeyService_IssueKey(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("stores only the hash", func(t *testing.T) {
+		svc, keyRepo, _ := setupAPIKeyService()
+		issued := issueTestKey(t, svc, keyRepo, IssueAPIKeyInput{
+			Name: "nightly-publisher", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeMessagesWrite},
+		})
+
+		assert.Contains(t, issued.Key, "rtmc_"+issued.KeyID+"_")
+		assert.Equal(t, hashToken(issued.Key), issued.SecretHash)
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		svc, _, _ := setupAPIKeyService()
+		_, err := svc.IssueKey(ctx, IssueAPIKeyInput{Name: "k", OwnerUserID: "publisher", Scopes: []string{"messages:everything"}})
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("admin scope is not issuable", func(t *testing.T) {
+		svc, keyRepo, _ := setupAPIKeyService()
+		_, err := svc.IssueKey(ctx, IssueAPIKeyInput{Name: "k", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeAdmin}})
+		assertStatus(t, err, 400)
+
+		// 관리자 소유여도 키는 역할이 없어 관리자 라우트를 통과할 수 없으므로 발급하지 않는다.
+		_, err = svc.IssueKey(ctx, IssueAPIKeyInput{
+			Name: "k", OwnerUserID: "root", Scopes: []string{middleware.ScopeMessagesRead, middleware.ScopeAdmin},
+		})
+		assertStatus(t, err, 400)
+		keyRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		svc, _, _ := setupAPIKeyService()
+		past := time.Now().Add(-time.Hour)
+		_, err := svc.IssueKey(ctx, IssueAPIKeyInput{
+			Name: "k", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeMessagesWrite}, ExpiresAt: &past,
+		})
+		assertStatus(t, err, 400)
+	})
+}
+
+func TestAPIKeyService_AuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	svc, keyRepo, _ := setupAPIKeyService()
+
+	issued := issueTestKey(t, svc, keyRepo, IssueAPIKeyInput{
+		Name: "publisher", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeMessagesWrite},
+	})
+
+	t.Run("valid key", func(t *testing.T) {
+		principal, err := svc.AuthenticateAPIKey(ctx, issued.Key)
+		require.NoError(t, err)
+		require.NotNil(t, principal)
+		assert.Equal(t, "publisher", principal.OwnerUserID)
+		assert.Equal(t, []string{middleware.ScopeMessagesWrite}, principal.Scopes)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		principal, err := svc.AuthenticateAPIKey(ctx, "rtmc_"+issued.KeyID+"_not-the-secret")
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("malformed key", func(t *testing.T) {
+		principal, err := svc.AuthenticateAPIKey(ctx, "not-a-key")
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		keyRepo.On("GetByKeyID", mock.Anything, "0000000000000000").
+			Return(nil, fmt.Errorf("api key not found: 0000000000000000: %w", sql.ErrNoRows))
+
+		principal, err := svc.AuthenticateAPIKey(ctx, "rtmc_0000000000000000_secret")
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		keyRepo.On("GetByKeyID", mock.Anything, "1111111111111111").Return(nil, fmt.Errorf("connection refused"))
+
+		_, err := svc.AuthenticateAPIKey(ctx, "rtmc_1111111111111111_secret")
+		assert.Error(t, err)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		revoked := issueTestKey(t, svc, keyRepo, IssueAPIKeyInput{
+			Name: "old", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeMessagesWrite},
+		})
+		revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+		principal, err := svc.AuthenticateAPIKey(ctx, revoked.Key)
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("expired key", func(t *testing.T) {
+		expired := issueTestKey(t, svc, keyRepo, IssueAPIKeyInput{
+			Name: "temp", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeMessagesWrite},
+		})
+		expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true}
+
+		principal, err := svc.AuthenticateAPIKey(ctx, expired.Key)
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+}
+
+func TestAPIKeyService_FlushUsage(t *testing.T) {
+	ctx := context.Background()
+	svc, keyRepo, _ := setupAPIKeyService()
+
+	issued := issueTestKey(t, svc, keyRepo, IssueAPIKeyInput{
+		Name: "publisher", OwnerUserID: "publisher", Scopes: []string{middleware.ScopeMessagesWrite},
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.AuthenticateAPIKey(ctx, issued.Key)
+		require.NoError(t, err)
+	}
+
+	// 첫 기록이 실패하면 카운터가 보존돼 다음 flush 에서 합산된다.
+	keyRepo.On("RecordUsage", mock.Anything, issued.KeyID, int64(3), mock.Anything).Return(fmt.Errorf("timeout")).Once()
+	svc.FlushUsage(ctx)
+
+	_, err := svc.AuthenticateAPIKey(ctx, issued.Key)
+	require.NoError(t, err)
+
+	keyRepo.On("RecordUsage", mock.Anything, issued.KeyID, int64(4), mock.Anything).Return(nil).Once()
+	svc.Close()
+
+	keyRepo.AssertExpectations(t)
+}
+
+func TestAPIKeyService_RevokeKey(t *testing.T) {
+	ctx := context.Background()
+	svc, keyRepo, _ := setupAPIKeyService()
+
+	keyRepo.On("Revoke", ctx, "abc").Return(nil)
+	keyRepo.On("Revoke", ctx, "missing").Return(fmt.Errorf("api key not found: missing: %w", sql.ErrNoRows))
+
+	assert.NoError(t, svc.RevokeKey(ctx, "abc"))
+	assertStatus(t, svc.RevokeKey(ctx, "missing"), 404)
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/blobstore"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// sniffLength is how much of the content http.DetectContentType looks at
+const sniffLength = 512
+
+// maxAttachmentFileName matches attachments.file_name VARCHAR(255)
+const maxAttachmentFileName = 255
+
+// attachmentSweepLimit bounds how many unsent uploads one sweep removes
+const attachmentSweepLimit = 100
+
+// AttachmentService stores uploaded files and links them to messages.
+// 형식은 클라이언트가 보낸 Content-Type 이 아니라 내용으로 판별한다. blob 은 내용의 SHA-256 으로 저장하므로
+// 같은 파일을 여러 번 올려도 저장소에는 한 번만 있고, 업로드마다 attachments 행이 하나씩 생긴다.
+type AttachmentService struct {
+	attachments  repository.AttachmentRepository
+	messages     repository.MessageRepository
+	rooms        repository.RoomRepository
+	store        blobstore.Store
+	maxSize      int64
+	allowedTypes []string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewAttachmentService creates a new attachment service
+func NewAttachmentService(
+	attachments repository.AttachmentRepository,
+	messages repository.MessageRepository,
+	rooms repository.RoomRepository,
+	store blobstore.Store,
+	maxSize int64,
+	allowedTypes []string,
+) *AttachmentService {
+	return &AttachmentService{
+		attachments:  attachments,
+		messages:     messages,
+		rooms:        rooms,
+		store:        store,
+		maxSize:      maxSize,
+		allowedTypes: allowedTypes,
+	}
+}
+
+// MaxSize returns the largest accepted upload in bytes
+func (s *AttachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload stores a file for uploaderID. The file can then be sent with one message.
+// 내용을 임시 파일에 받으면서 해시와 크기를 재고, 형식을 확인한 뒤에야 저장소에 올린다.
+func (s *AttachmentService) Upload(ctx context.Context, uploaderID, fileName string, content io.Reader) (*repository.Attachment, error) {
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		logger.Errorf("Failed to buffer upload: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to store attachment", 500)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(content, s.maxSize+1))
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeBadRequest, "Failed to read upload", 400)
+	}
+	if size == 0 {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "The file is empty", 400)
+	}
+	if size > s.maxSize {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, fmt.Sprintf("The file exceeds %d bytes", s.maxSize), 413)
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		logger.Errorf("Failed to read buffered upload: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to store attachment", 500)
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !slices.Contains(s.allowedTypes, contentType) {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "File type "+contentType+" is not allowed", 415)
+	}
+
+	// 행을 먼저 만들어야 같은 내용의 blob 을 지우려던 작업이 이 업로드를 참조로 본다.
+	attachment := &repository.Attachment{
+		UploaderID:  uploaderID,
+		FileName:    cleanFileName(fileName),
+		ContentType: contentType,
+		SizeBytes:   size,
+		SHA256:      hex.EncodeToString(hash.Sum(nil)),
+	}
+	if err := s.attachments.Create(ctx, attachment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "User not found", 404)
+		}
+		logger.Errorf("Failed to create attachment: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to store attachment", 500)
+	}
+
+	if err := s.storeBlob(ctx, attachment.SHA256, tmp, size, contentType); err != nil {
+		// 지우지 못한 행은 보내지 않은 업로드로 남아 정리 워커가 지운다.
+		if err := s.attachments.DeleteUnclaimed(ctx, []string{attachment.ID}); err != nil {
+			logger.Warnf("Failed to remove attachment %s without content: %v", attachment.ID, err)
+		}
+		return nil, err
+	}
+
+	logger.Infof("Attachment uploaded: %s by %s (%s, %d bytes)", attachment.ID, uploaderID, contentType, size)
+	return attachment, nil
+}
+
+// storeBlob uploads the content unless a blob with the same digest is already stored
+func (s *AttachmentService) storeBlob(ctx context.Context, digest string, content io.ReadSeeker, size int64, contentType string) error {
+	key := BlobKey(digest)
+
+	exists, err := s.store.Exists(ctx, key)
+	if err != nil {
+		logger.Errorf("Failed to check blob %s: %v", key, err)
+		return apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to store attachment", 500)
+	}
+	if exists {
+		return nil
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to store attachment", 500)
+	}
+	if err := s.store.Put(ctx, key, content, size, contentType); err != nil {
+		logger.Errorf("Failed to store blob %s: %v", key, err)
+		return apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to store attachment", 500)
+	}
+	return nil
+}
+
+// GetAttachment returns an attachment's metadata.
+// 올린 사람, 관리자, 그리고 첨부가 실린 메시지를 읽을 수 있는 사람만 볼 수 있고 나머지는 404 다.
+// callerID 가 비어 있으면(인증 비활성) 제한하지 않는다.
+func (s *AttachmentService) GetAttachment(ctx context.Context, callerID string, admin bool, id string) (*repository.Attachment, error) {
+	attachment, err := s.attachments.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Attachment not found", 404)
+		}
+		logger.Errorf("Failed to get attachment: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get attachment", 500)
+	}
+
+	if callerID == "" || admin || attachment.UploaderID == callerID {
+		return attachment, nil
+	}
+
+	// 보낸 뒤 DBWorker 가 메시지를 저장하기 전까지는 올린 사람만 볼 수 있다.
+	if attachment.MessageID == nil {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "Attachment not found", 404)
+	}
+	message, err := s.messages.GetByMessageID(ctx, *attachment.MessageID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Attachment not found", 404)
+	}
+	if err := checkCanRead(ctx, s.rooms, message, callerID); err != nil {
+		if appErr := apperrors.GetAppError(err); appErr != nil && appErr.StatusCode == 404 {
+			return nil, apperrors.New(apperrors.ErrCodeNotFound, "Attachment not found", 404)
+		}
+		return nil, err
+	}
+	// 회수된 메시지의 첨부는 내용과 함께 감춘다.
+	if message.RecalledAt != nil {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "The message has been recalled", 410)
+	}
+
+	return attachment, nil
+}
+
+// OpenAttachment returns an attachment with its content; the caller closes the content
+func (s *AttachmentService) OpenAttachment(ctx context.Context, callerID string, admin bool, id string) (*repository.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachment(ctx, callerID, admin, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := s.store.Open(ctx, BlobKey(attachment.SHA256))
+	if err != nil {
+		logger.Errorf("Failed to open blob of attachment %s: %v", id, err)
+		return nil, nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to read attachment", 500)
+	}
+
+	return attachment, content, nil
+}
+
+// ClaimAttachments links the sender's uploads to a message about to be published and returns their queue metadata
+func (s *AttachmentService) ClaimAttachments(ctx context.Context, userID, messageID string, ids []string) ([]models.QueueAttachment, error) {
+	claimed, err := s.attachments.Claim(ctx, userID, messageID, ids)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Attachment not found", 404)
+	}
+	if errors.Is(err, repository.ErrAttachmentSent) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeConflict, "Attachment was already sent", 409)
+	}
+	if err != nil {
+		logger.Errorf("Failed to claim attachments: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to attach files", 500)
+	}
+
+	metadata := make([]models.QueueAttachment, 0, len(claimed))
+	for _, attachment := range claimed {
+		metadata = append(metadata, models.QueueAttachment{
+			ID:          attachment.ID,
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			SizeBytes:   attachment.SizeBytes,
+			SHA256:      attachment.SHA256,
+		})
+	}
+	return metadata, nil
+}
+
+// ReleaseAttachments unlinks the attachments of a message that failed to publish.
+// 실패는 기록만 한다 — 풀리지 않은 첨부는 다시 올리면 된다.
+func (s *AttachmentService) ReleaseAttachments(ctx context.Context, messageID string) {
+	if err := s.attachments.Release(ctx, messageID); err != nil {
+		logger.Warnf("Failed to release attachments of %s: %v", messageID, err)
+	}
+}
+
+// LoadAttachments fills the attachments of the messages in a list with one query
+func (s *AttachmentService) LoadAttachments(ctx context.Context, messages []*repository.Message) error {
+	messageIDs := make([]string, 0, len(messages))
+	for _, message := range messages {
+		// 회수된 메시지의 첨부는 보여 주지 않는다.
+		if message.RecalledAt == nil {
+			messageIDs = append(messageIDs, message.MessageID)
+		}
+	}
+	if len(messageIDs) == 0 {
+		return nil
+	}
+
+	attachments, err := s.attachments.ListByMessages(ctx, messageIDs)
+	if err != nil {
+		logger.Errorf("Failed to list attachments: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get attachments", 500)
+	}
+
+	byMessage := make(map[string][]*repository.Attachment, len(messageIDs))
+	for _, attachment := range attachments {
+		byMessage[*attachment.MessageID] = append(byMessage[*attachment.MessageID], attachment)
+	}
+	for _, message := range messages {
+		if list, ok := byMessage[message.MessageID]; ok {
+			message.Attachments = list
+		}
+	}
+	return nil
+}
+
+// DeleteUploaderBlobs removes the blobs of a user's uploads that no other user's upload shares and returns how many were removed.
+// 사용자 삭제(erasure) 때 행이 ON DELETE CASCADE 로 지워지기 전에 부른다. 여러 번 불러도 결과가 같다.
+func (s *AttachmentService) DeleteUploaderBlobs(ctx context.Context, uploaderID string) (int, error) {
+	attachments, err := s.attachments.ListByUploader(ctx, uploaderID)
+	if err != nil {
+		return 0, err
+	}
+	return s.deleteBlobs(ctx, attachments)
+}
+
+// SweepUnclaimed removes uploads never sent within ttl, with their blobs unless shared, and returns how many were removed
+func (s *AttachmentService) SweepUnclaimed(ctx context.Context, ttl time.Duration) (int, error) {
+	attachments, err := s.attachments.ListUnclaimed(ctx, time.Now().Add(-ttl), attachmentSweepLimit)
+	if err != nil || len(attachments) == 0 {
+		return 0, err
+	}
+
+	// blob 을 먼저 지운다 — 행을 지운 뒤 실패하면 어떤 blob 이 남았는지 알 수 없다.
+	if _, err := s.deleteBlobs(ctx, attachments); err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		ids = append(ids, attachment.ID)
+	}
+	if err := s.attachments.DeleteUnclaimed(ctx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// StartSweeper removes uploads not sent within ttl every interval, until Close is called
+func (s *AttachmentService) StartSweeper(interval, ttl time.Duration) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if n, err := s.SweepUnclaimed(context.Background(), ttl); err != nil {
+					logger.Warnf("Attachment sweep failed: %v", err)
+				} else if n > 0 {
+					logger.Infof("Attachment sweep: %d unsent upload(s) removed", n)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the sweeper
+func (s *AttachmentService) Close() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+}
+
+// deleteBlobs removes the blob of each content used by the given attachments unless another attachment uses it too
+func (s *AttachmentService) deleteBlobs(ctx context.Context, attachments []*repository.Attachment) (int, error) {
+	idsByDigest := make(map[string][]string)
+	digests := make([]string, 0, len(attachments))
+	for _, attachment := range attachments {
+		if _, ok := idsByDigest[attachment.SHA256]; !ok {
+			digests = append(digests, attachment.SHA256)
+		}
+		idsByDigest[attachment.SHA256] = append(idsByDigest[attachment.SHA256], attachment.ID)
+	}
+
+	removed := 0
+	for _, digest := range digests {
+		key := BlobKey(digest)
+		deleted, err := s.attachments.DeleteBlob(ctx, digest, idsByDigest[digest], func(ctx context.Context) error {
+			return s.store.Delete(ctx, key)
+		})
+		if err != nil {
+			return removed, fmt.Errorf("delete blob %s: %w", key, err)
+		}
+		if deleted {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// BlobKey is the blob store key of content with the given hex SHA-256: sha256/<first two hex digits>/<digest>.
+// 앞 두 글자로 나눠 로컬 저장소의 한 디렉터리에 파일이 몰리지 않게 한다.
+func BlobKey(digest string) string {
+	return path.Join("sha256", digest[:2], digest)
+}
+
+// cleanFileName keeps the base name of a client file name, without control characters, at most 255 characters
+func cleanFileName(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = path.Base(name)
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "attachment"
+	}
+	if utf8.RuneCountInString(name) > maxAttachmentFileName {
+		name = string([]rune(name)[:maxAttachmentFileName])
+	}
+	return name
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/blobstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAttachmentRepository is a mock implementation of AttachmentRepository
+type MockAttachmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentRepository) Create(ctx context.Context, attachment *repository.Attachment) error {
+	args := m.Called(ctx, attachment)
+	if args.Error(0) == nil {
+		attachment.ID = fmt.Sprintf("att-%d", len(m.Calls))
+		attachment.CreatedAt = time.Now()
+	}
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) Get(ctx context.Context, id string) (*repository.Attachment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Claim(ctx context.Context, uploaderID, messageID string, ids []string) ([]*repository.Attachment, error) {
+	args := m.Called(ctx, uploaderID, messageID, ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) Release(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockAttachmentRepository) ListByMessages(ctx context.Context, messageIDs []string) ([]*repository.Attachment, error) {
+	args := m.Called(ctx, messageIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListByUploader(ctx context.Context, uploaderID string) ([]*repository.Attachment, error) {
+	args := m.Called(ctx, uploaderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) ListUnclaimed(ctx context.Context, before time.Time, limit int) ([]*repository.Attachment, error) {
+	args := m.Called(ctx, before, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Attachment), args.Error(1)
+}
+
+func (m *MockAttachmentRepository) DeleteUnclaimed(ctx context.Context, ids []string) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+// DeleteBlob calls deleteBlob when the mock reports the content unreferenced
+func (m *MockAttachmentRepository) DeleteBlob(ctx context.Context, sha256 string, exceptIDs []string, deleteBlob func(ctx context.Context) error) (bool, error) {
+	args := m.Called(ctx, sha256, exceptIDs)
+	if !args.Bool(0) || args.Error(1) != nil {
+		return false, args.Error(1)
+	}
+	return true, deleteBlob(ctx)
+}
+
+// countingStore counts the blobs written to a local store
+type countingStore struct {
+	*blobstore.LocalStore
+	puts int
+}
+
+func (s *countingStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	s.puts++
+	return s.LocalStore.Put(ctx, key, r, size, contentType)
+}
+
+// testBlobDigest is the SHA-256 of "hello"
+const testBlobDigest = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+// pngContent is the PNG signature followed by some bytes, enough for content sniffing
+const pngContent = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+func setupAttachmentService(t *testing.T) (*AttachmentService, *MockAttachmentRepository, *MockMessageRepository, *MockRoomRepository, *countingStore) {
+	t.Helper()
+
+	local, err := blobstore.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	store := &countingStore{LocalStore: local}
+
+	attachments := new(MockAttachmentRepository)
+	messages := new(MockMessageRepository)
+	rooms := new(MockRoomRepository)
+	svc := NewAttachmentService(attachments, messages, rooms, store, 64, []string{"image/png", "text/plain"})
+	return svc, attachments, messages, rooms, store
+}
+
+func TestAttachmentService_Upload(t *testing.T) {
+	ctx := context.Background()
+	svc, attachments, _, _, store := setupAttachmentService(t)
+
+	t.Run("sniffs the type and dedupes the blob", func(t *testing.T) {
+		attachments.On("Create", ctx, mock.AnythingOfType("*repository.Attachment")).Return(nil).Twice()
+
+		first, err := svc.Upload(ctx, "alice", `C:\photos\cat.png`, strings.NewReader(pngContent))
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", first.ContentType)
+		assert.Equal(t, "cat.png", first.FileName)
+		assert.Equal(t, int64(len(pngContent)), first.SizeBytes)
+
+		// 같은 내용을 다른 이름으로 다시 올려도 blob 은 하나다.
+		second, err := svc.Upload(ctx, "bob", "copy.txt", strings.NewReader(pngContent))
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", second.ContentType)
+		assert.Equal(t, first.SHA256, second.SHA256)
+		assert.Equal(t, 1, store.puts)
+
+		exists, err := store.Exists(ctx, BlobKey(first.SHA256))
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("charset parameter is dropped", func(t *testing.T) {
+		attachments.On("Create", ctx, mock.AnythingOfType("*repository.Attachment")).Return(nil).Once()
+
+		attachment, err := svc.Upload(ctx, "alice", "notes.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain", attachment.ContentType)
+	})
+
+	t.Run("type not allowed", func(t *testing.T) {
+		_, err := svc.Upload(ctx, "alice", "page.png", strings.NewReader("<html><body>hi</body></html>"))
+		assertStatus(t, err, 415)
+	})
+
+	t.Run("too large", func(t *testing.T) {
+		_, err := svc.Upload(ctx, "alice", "big.txt", strings.NewReader(strings.Repeat("a", 65)))
+		assertStatus(t, err, 413)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := svc.Upload(ctx, "alice", "empty.txt", strings.NewReader(""))
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("uploader missing", func(t *testing.T) {
+		attachments.On("Create", ctx, mock.AnythingOfType("*repository.Attachment")).
+			Return(fmt.Errorf("user not found: ghost: %w", sql.ErrNoRows)).Once()
+
+		_, err := svc.Upload(ctx, "ghost", "notes.txt", strings.NewReader("hello"))
+		assertStatus(t, err, 404)
+	})
+
+	attachments.AssertExpectations(t)
+}
+
+func TestAttachmentService_GetAttachment(t *testing.T) {
+	ctx := context.Background()
+	svc, attachments, messages, rooms, _ := setupAttachmentService(t)
+
+	roomID := testRoomID
+	sentTo := "room-msg"
+	recalledMsg := "recalled"
+	recalledAt := time.Now()
+	attachments.On("Get", ctx, "unsent").Return(&repository.Attachment{ID: "unsent", UploaderID: "alice"}, nil)
+	attachments.On("Get", ctx, "sent").Return(&repository.Attachment{ID: "sent", UploaderID: "alice", MessageID: &sentTo}, nil)
+	attachments.On("Get", ctx, "in-recalled").Return(&repository.Attachment{ID: "in-recalled", UploaderID: "alice", MessageID: &recalledMsg}, nil)
+	attachments.On("Get", ctx, "missing").Return(nil, fmt.Errorf("attachment not found: missing: %w", sql.ErrNoRows))
+	messages.On("GetByMessageID", ctx, "room-msg").Return(&repository.Message{MessageID: "room-msg", UserID: "alice", RoomID: &roomID}, nil)
+	messages.On("GetByMessageID", ctx, "recalled").Return(&repository.Message{MessageID: "recalled", UserID: "alice", RecalledAt: &recalledAt}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "bob").Return(&repository.RoomMember{RoomID: testRoomID, UserID: "bob"}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "carol").Return(nil, fmt.Errorf("carol: %w", repository.ErrRoomMemberNotFound))
+
+	t.Run("uploader and admin", func(t *testing.T) {
+		_, err := svc.GetAttachment(ctx, "alice", false, "unsent")
+		assert.NoError(t, err)
+
+		_, err = svc.GetAttachment(ctx, "admin", true, "unsent")
+		assert.NoError(t, err)
+	})
+
+	t.Run("unsent attachment is private", func(t *testing.T) {
+		_, err := svc.GetAttachment(ctx, "bob", false, "unsent")
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("readers of the message", func(t *testing.T) {
+		_, err := svc.GetAttachment(ctx, "bob", false, "sent")
+		assert.NoError(t, err)
+
+		_, err = svc.GetAttachment(ctx, "carol", false, "sent")
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("recalled message", func(t *testing.T) {
+		_, err := svc.GetAttachment(ctx, "bob", false, "in-recalled")
+		assertStatus(t, err, 410)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := svc.GetAttachment(ctx, "alice", false, "missing")
+		assertStatus(t, err, 404)
+	})
+}
+
+func TestAttachmentService_OpenAttachment(t *testing.T) {
+	ctx := context.Background()
+	svc, attachments, _, _, _ := setupAttachmentService(t)
+
+	attachments.On("Create", ctx, mock.AnythingOfType("*repository.Attachment")).Return(nil).Once()
+	uploaded, err := svc.Upload(ctx, "alice", "notes.txt", strings.NewReader("hello"))
+	require.NoError(t, err)
+	attachments.On("Get", ctx, uploaded.ID).Return(uploaded, nil)
+
+	attachment, content, err := svc.OpenAttachment(ctx, "alice", false, uploaded.ID)
+	require.NoError(t, err)
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(data))
+	assert.Equal(t, "notes.txt", attachment.FileName)
+}
+
+func TestAttachmentService_ClaimAttachments(t *testing.T) {
+	ctx := context.Background()
+	svc, attachments, _, _, _ := setupAttachmentService(t)
+
+	t.Run("claimed", func(t *testing.T) {
+		messageID := "m1"
+		attachments.On("Claim", ctx, "alice", "m1", []string{"a1"}).Return([]*repository.Attachment{
+			{ID: "a1", UploaderID: "alice", MessageID: &messageID, FileName: "cat.png", ContentType: "image/png", SizeBytes: 16, SHA256: "abc"},
+		}, nil).Once()
+
+		claimed, err := svc.ClaimAttachments(ctx, "alice", "m1", []string{"a1"})
+
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, "a1", claimed[0].ID)
+		assert.Equal(t, "cat.png", claimed[0].FileName)
+		assert.Equal(t, int64(16), claimed[0].SizeBytes)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		attachments.On("Claim", ctx, "alice", "m2", []string{"gone"}).Return(nil, fmt.Errorf("gone: %w", sql.ErrNoRows)).Once()
+		attachments.On("Claim", ctx, "alice", "m3", []string{"a1"}).Return(nil, fmt.Errorf("a1: %w", repository.ErrAttachmentSent)).Once()
+		attachments.On("Claim", ctx, "alice", "m4", []string{"a2"}).Return(nil, errors.New("connection refused")).Once()
+
+		_, err := svc.ClaimAttachments(ctx, "alice", "m2", []string{"gone"})
+		assertStatus(t, err, 404)
+
+		_, err = svc.ClaimAttachments(ctx, "alice", "m3", []string{"a1"})
+		assertStatus(t, err, 409)
+
+		_, err = svc.ClaimAttachments(ctx, "alice", "m4", []string{"a2"})
+		assertStatus(t, err, 500)
+	})
+
+	attachments.AssertExpectations(t)
+}
+
+func TestAttachmentService_LoadAttachments(t *testing.T) {
+	ctx := context.Background()
+	svc, attachments, _, _, _ := setupAttachmentService(t)
+
+	m1 := "m1"
+	recalledAt := time.Now()
+	attachments.On("ListByMessages", ctx, []string{"m1", "m2"}).Return([]*repository.Attachment{
+		{ID: "a1", MessageID: &m1},
+		{ID: "a2", MessageID: &m1},
+	}, nil).Once()
+
+	list := []*repository.Message{{MessageID: "m1"}, {MessageID: "m2"}, {MessageID: "m3", RecalledAt: &recalledAt}}
+	require.NoError(t, svc.LoadAttachments(ctx, list))
+
+	require.Len(t, list[0].Attachments, 2)
+	assert.Equal(t, "a2", list[0].Attachments[1].ID)
+	assert.Nil(t, list[1].Attachments)
+	assert.Nil(t, list[2].Attachments)
+	attachments.AssertExpectations(t)
+}
+
+func TestAttachmentService_DeleteUploaderBlobs(t *testing.T) {
+	ctx := context.Background()
+	svc, attachments, _, _, store := setupAttachmentService(t)
+
+	attachments.On("Create", ctx, mock.AnythingOfType("*repository.Attachment")).Return(nil).Twice()
+	own, err := svc.Upload(ctx, "alice", "notes.txt", strings.NewReader("hello"))
+	require.NoError(t, err)
+	shared, err := svc.Upload(ctx, "alice", "cat.png", strings.NewReader(pngContent))
+	require.NoError(t, err)
+
+	// bob 도 같은 그림을 올렸으므로 그 blob 은 남는다.
+	attachments.On("ListByUploader", ctx, "alice").Return([]*repository.Attachment{own, shared}, nil)
+	attachments.On("DeleteBlob", ctx, own.SHA256, []string{own.ID}).Return(true, nil)
+	attachments.On("DeleteBlob", ctx, shared.SHA256, []string{shared.ID}).Return(false, nil)
+
+	removed, err := svc.DeleteUploaderBlobs(ctx, "alice")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	exists, err := store.Exists(ctx, BlobKey(own.SHA256))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	exists, err = store.Exists(ctx, BlobKey(shared.SHA256))
+	require.NoError(t, err)
+	assert.True(t, exists)
+	attachments.AssertExpectations(t)
+}
+
+func TestAttachmentService_SweepUnclaimed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes stale uploads and their blobs", func(t *testing.T) {
+		svc, attachments, _, _, store := setupAttachmentService(t)
+
+		attachments.On("Create", ctx, mock.AnythingOfType("*repository.Attachment")).Return(nil).Twice()
+		first, err := svc.Upload(ctx, "alice", "notes.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+		second, err := svc.Upload(ctx, "alice", "again.txt", strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		attachments.On("ListUnclaimed", ctx, mock.AnythingOfType("time.Time"), attachmentSweepLimit).
+			Return([]*repository.Attachment{first, second}, nil).Once()
+		attachments.On("DeleteBlob", ctx, first.SHA256, []string{first.ID, second.ID}).Return(true, nil).Once()
+		attachments.On("DeleteUnclaimed", ctx, []string{first.ID, second.ID}).Return(nil).Once()
+
+		removed, err := svc.SweepUnclaimed(ctx, time.Hour)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, removed)
+		exists, err := store.Exists(ctx, BlobKey(first.SHA256))
+		require.NoError(t, err)
+		assert.False(t, exists)
+		attachments.AssertExpectations(t)
+	})
+
+	t.Run("rows stay when the blob check fails", func(t *testing.T) {
+		svc, attachments, _, _, _ := setupAttachmentService(t)
+
+		stale := &repository.Attachment{ID: "a1", SHA256: testBlobDigest}
+		attachments.On("ListUnclaimed", ctx, mock.AnythingOfType("time.Time"), attachmentSweepLimit).
+			Return([]*repository.Attachment{stale}, nil).Once()
+		attachments.On("DeleteBlob", ctx, testBlobDigest, []string{"a1"}).Return(false, errors.New("connection refused")).Once()
+
+		_, err := svc.SweepUnclaimed(ctx, time.Hour)
+
+		assert.Error(t, err)
+		attachments.AssertNotCalled(t, "DeleteUnclaimed", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// Audit writer settings
+const (
+	auditQueueSize    = 1024
+	auditWriteTimeout = 3 * time.Second
+)
+
+// AuditService records and queries the audit log.
+// Start 이후에는 기록을 큐에 넣고 워커가 써서, 요청이 audit_log INSERT 를 기다리지 않는다.
+type AuditService struct {
+	repo repository.AuditRepository
+
+	mu    sync.RWMutex
+	queue chan *repository.AuditLog
+	done  chan struct{}
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		repo: repo,
+	}
+}
+
+// Start writes queued audit entries in the background until Close is called
+func (s *AuditService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue = make(chan *repository.AuditLog, auditQueueSize)
+	s.done = make(chan struct{})
+
+	go func(queue <-chan *repository.AuditLog) {
+		defer close(s.done)
+
+		for row := range queue {
+			ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+			if err := s.repo.Create(ctx, row); err != nil {
+				logger.Errorf("Failed to record audit entry %s %s/%s: %v", row.Action, row.TargetType, row.TargetID, err)
+			}
+			cancel()
+		}
+	}(s.queue)
+}
+
+// Close stops accepting queued entries and waits until the queued ones are written
+func (s *AuditService) Close() {
+	s.mu.Lock()
+	queue := s.queue
+	s.queue = nil
+	s.mu.Unlock()
+
+	if queue != nil {
+		close(queue)
+		<-s.done
+	}
+}
+
+// RecordAudit implements middleware.AuditRecorder.
+// before/after 는 핸들러가 응답 뒤에 고칠 수도 있으므로 큐에 넣기 전에 인코딩한다.
+// 큐가 가득 찼거나 워커가 없으면 기록을 버리지 않고 호출한 요청 안에서 직접 쓴다.
+func (s *AuditService) RecordAudit(ctx context.Context, entry *middleware.AuditEntry) error {
+	row, err := newAuditLog(entry)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	if s.queue != nil {
+		select {
+		case s.queue <- row:
+			s.mu.RUnlock()
+			return nil
+		default:
+			logger.Warnf("Audit queue is full, writing %s synchronously", row.Action)
+		}
+	}
+	s.mu.RUnlock()
+
+	return s.repo.Create(ctx, row)
+}
+
+// newAuditLog converts a middleware entry into an audit_log row
+func newAuditLog(entry *middleware.AuditEntry) (*repository.AuditLog, error) {
+	before, err := auditJSON(entry.Before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode before value: %w", err)
+	}
+	after, err := auditJSON(entry.After)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode after value: %w", err)
+	}
+
+	return &repository.AuditLog{
+		OccurredAt: entry.OccurredAt,
+		ActorID:    entry.ActorID,
+		ActorType:  entry.ActorType,
+		APIKeyID:   entry.APIKeyID,
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		TargetID:   entry.TargetID,
+		Before:     before,
+		After:      after,
+		RequestID:  entry.RequestID,
+		ClientIP:   entry.ClientIP,
+		Outcome:    entry.Outcome,
+		StatusCode: entry.StatusCode,
+	}, nil
+}
+
+// ListAuditLogs retrieves paginated audit entries matching the filter
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*repository.AuditLog, int64, error) {
+	if filter.Since != nil && filter.Until != nil && !filter.Since.Before(*filter.Until) {
+		return nil, 0, apperrors.New(apperrors.ErrCodeValidation, "since must be before until", 400)
+	}
+
+	entries, err := s.repo.List(ctx, filter, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list audit log: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list audit log", 500)
+	}
+
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		logger.Errorf("Failed to count audit log: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count audit log", 500)
+	}
+
+	return entries, total, nil
+}
+
+// auditJSON encodes a before/after value; nil stays NULL
+func auditJSON(value interface{}) (*json.RawMessage, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := json.RawMessage(data)
+	return &raw, nil
+}
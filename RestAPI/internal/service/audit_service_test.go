@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAuditRepository is a mock implementation of AuditRepository
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) Create(ctx context.Context, entry *repository.AuditLog) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*repository.AuditLog, error) {
+	args := m.Called(ctx, filter, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.AuditLog), args.Error(1)
+}
+
+func (m *MockAuditRepository) Count(ctx context.Context, filter repository.AuditFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestAuditService_RecordAudit(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	var stored *repository.AuditLog
+	repo.On("Create", ctx, mock.AnythingOfType("*repository.AuditLog")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*repository.AuditLog) }).
+		Return(nil)
+
+	err := svc.RecordAudit(ctx, &middleware.AuditEntry{
+		ActorID:    "root",
+		ActorType:  middleware.ActorUser,
+		Action:     "user.role.update",
+		TargetType: "user",
+		TargetID:   "alice",
+		Before:     map[string]string{"role": "user"},
+		After:      map[string]string{"role": "admin"},
+		Outcome:    middleware.AuditSuccess,
+		StatusCode: 200,
+	})
+
+	require.NoError(t, err)
+	require.NotNil(t, stored.Before)
+	assert.JSONEq(t, `{"role":"user"}`, string(*stored.Before))
+	assert.JSONEq(t, `{"role":"admin"}`, string(*stored.After))
+	assert.Equal(t, "alice", stored.TargetID)
+}
+
+func TestAuditService_RecordAuditQueued(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+	svc.Start()
+
+	// 쓰기가 끝나지 않아도 RecordAudit 는 바로 돌아와야 한다.
+	release := make(chan struct{})
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*repository.AuditLog")).
+		Run(func(mock.Arguments) { <-release }).
+		Return(nil)
+
+	for _, target := range []string{"alice", "bob"} {
+		err := svc.RecordAudit(ctx, &middleware.AuditEntry{
+			ActorID:    "root",
+			ActorType:  middleware.ActorUser,
+			Action:     "user.delete",
+			TargetType: "user",
+			TargetID:   target,
+			Outcome:    middleware.AuditSuccess,
+			StatusCode: 204,
+		})
+		require.NoError(t, err)
+	}
+
+	// Close 는 큐에 남은 기록을 모두 쓴 뒤에 돌아온다.
+	close(release)
+	svc.Close()
+	repo.AssertNumberOfCalls(t, "Create", 2)
+
+	// 닫힌 뒤의 기록은 요청 안에서 직접 쓴다.
+	require.NoError(t, svc.RecordAudit(ctx, &middleware.AuditEntry{Action: "user.delete", TargetType: "user", TargetID: "carol"}))
+	repo.AssertNumberOfCalls(t, "Create", 3)
+}
+
+func TestAuditService_ListAuditLogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("filtered list", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		svc := NewAuditService(repo)
+
+		filter := repository.AuditFilter{ActorID: "root"}
+		repo.On("List", ctx, filter, 20, 0).Return([]*repository.AuditLog{{ID: 1, ActorID: "root"}}, nil)
+		repo.On("Count", ctx, filter).Return(int64(1), nil)
+
+		entries, total, err := svc.ListAuditLogs(ctx, filter, 20, 0)
+
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("inverted time range", func(t *testing.T) {
+		svc := NewAuditService(new(MockAuditRepository))
+
+		since := time.Now()
+		until := since.Add(-time.Hour)
+		_, _, err := svc.ListAuditLogs(ctx, repository.AuditFilter{Since: &since, Until: &until}, 20, 0)
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		svc := NewAuditService(repo)
+
+		repo.On("List", ctx, repository.AuditFilter{}, 20, 0).Return(nil, fmt.Errorf("connection refused"))
+
+		_, _, err := svc.ListAuditLogs(ctx, repository.AuditFilter{}, 20, 0)
+		assertStatus(t, err, 500)
+	})
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password length limits. bcrypt 는 72 바이트 이후를 조용히 잘라내므로 상한을 명시한다.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// dummyPasswordHash is compared against when the user does not exist.
+// 없는 사용자에서 bcrypt 를 건너뛰면 응답 시간 차이로 사용자 존재 여부가 드러난다.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), bcrypt.DefaultCost)
+
+// TokenPair is returned by login and refresh
+type TokenPair struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	RefreshExpiresIn int64  `json:"refresh_expires_in"`
+}
+
+// refreshSession is stored in Redis under the hash of a refresh token
+type refreshSession struct {
+	UserID     string `json:"user_id"`
+	Username   string `json:"username"`
+	FamilyID   string `json:"family_id"`
+	IssuedAtMs int64  `json:"issued_at_ms"`
+}
+
+// AuthService issues, rotates and revokes tokens.
+// refresh token 원문은 저장하지 않고 SHA-256 해시를 키로 쓴다 — Redis 가 유출돼도 토큰을 재사용할 수 없다.
+type AuthService struct {
+	userRepo    repository.UserRepository
+	redis       *services.RedisService
+	keys        *middleware.KeySet
+	accessHours int
+	refreshTTL  time.Duration
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(
+	userRepo repository.UserRepository,
+	redis *services.RedisService,
+	keys *middleware.KeySet,
+	accessHours, refreshHours int,
+) *AuthService {
+	refreshTTL := cache.TTLRefreshToken
+	if refreshHours > 0 {
+		refreshTTL = time.Duration(refreshHours) * time.Hour
+	}
+
+	return &AuthService{
+		userRepo:    userRepo,
+		redis:       redis,
+		keys:        keys,
+		accessHours: accessHours,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// HashPassword validates the password length and returns its bcrypt hash
+func HashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return "", apperrors.New(apperrors.ErrCodeValidation, "Password must be between 8 and 72 bytes", 400)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to hash password", 500)
+	}
+
+	return string(hash), nil
+}
+
+// Login verifies the user's password and issues a new token pair
+func (s *AuthService) Login(ctx context.Context, userID, password string) (*TokenPair, error) {
+	user, err := s.userRepo.GetCredentials(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.Errorf("Failed to load credentials: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to verify credentials", 500)
+	}
+
+	// 사용자 부재·비밀번호 미설정·불일치를 같은 응답으로 돌려 어느 쪽인지 알 수 없게 한다.
+	if user == nil || !user.PasswordHash.Valid {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return nil, apperrors.New(apperrors.ErrCodeUnauthorized, "Invalid credentials", 401)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(password)); err != nil {
+		return nil, apperrors.New(apperrors.ErrCodeUnauthorized, "Invalid credentials", 401)
+	}
+
+	pair, err := s.issueTokens(ctx, user, uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("User logged in: %s", user.UserID)
+	return pair, nil
+}
+
+// Refresh rotates a refresh token and issues a new token pair.
+// 이미 교체된 토큰이 다시 들어오면 탈취로 보고 같은 계열(family)의 살아 있는 토큰까지 폐기한다.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	tokenHash := hashToken(refreshToken)
+
+	data, err := s.redis.GetDel(ctx, cache.SessionKey(tokenHash))
+	if errors.Is(err, services.ErrCacheMiss) {
+		s.detectReuse(ctx, tokenHash)
+		return nil, apperrors.New(apperrors.ErrCodeUnauthorized, "Invalid or expired refresh token", 401)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load refresh token: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to refresh token", 503)
+	}
+
+	var session refreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeUnauthorized, "Invalid or expired refresh token", 401)
+	}
+
+	revokedBefore, err := s.revokedBefore(ctx, session.UserID)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to refresh token", 503)
+	}
+	if session.IssuedAtMs <= revokedBefore {
+		return nil, apperrors.New(apperrors.ErrCodeUnauthorized, "Invalid or expired refresh token", 401)
+	}
+
+	if err := s.redis.Set(ctx, cache.RotatedTokenKey(tokenHash), session.FamilyID, s.refreshTTL); err != nil {
+		logger.Warnf("Failed to record rotated refresh token: %v", err)
+	}
+
+	// 역할 변경·사용자 삭제가 다음 refresh 에 반영되도록 세션이 아닌 DB 의 현재 값으로 발급한다.
+	user, err := s.userRepo.GetCredentials(ctx, session.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.New(apperrors.ErrCodeUnauthorized, "Invalid or expired refresh token", 401)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load user for refresh: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to refresh token", 500)
+	}
+
+	return s.issueTokens(ctx, user, session.FamilyID)
+}
+
+// Logout revokes the presented access token and, when given, its refresh token family
+func (s *AuthService) Logout(ctx context.Context, claims *middleware.JWTClaims, refreshToken string) error {
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		// jti 는 토큰이 만료되면 어차피 거부되므로 남은 수명만큼만 보관한다.
+		if ttl := time.Until(claims.ExpiresAt.Time); ttl > 0 {
+			if err := s.redis.Set(ctx, cache.RevokedTokenKey(claims.ID), "1", ttl); err != nil {
+				logger.Errorf("Failed to revoke access token: %v", err)
+				return apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to revoke token", 503)
+			}
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	tokenHash := hashToken(refreshToken)
+	data, err := s.redis.Get(ctx, cache.SessionKey(tokenHash))
+	if errors.Is(err, services.ErrCacheMiss) {
+		return nil
+	}
+	if err != nil {
+		return apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to revoke token", 503)
+	}
+
+	var session refreshSession
+	if err := json.Unmarshal([]byte(data), &session); err != nil || session.UserID != claims.UserID {
+		// 다른 사용자의 refresh token 은 건드리지 않는다.
+		return nil
+	}
+
+	s.revokeFamily(ctx, session.FamilyID)
+	if err := s.redis.Delete(ctx, cache.SessionKey(tokenHash)); err != nil {
+		logger.Warnf("Failed to delete refresh token: %v", err)
+	}
+
+	logger.Infof("User logged out: %s", claims.UserID)
+	return nil
+}
+
+// RevokeUser invalidates every access and refresh token issued to the user so far.
+// 밀리초 단위로 기록한다 — 초 단위면 폐기 직후 같은 초에 다시 로그인해 받은 토큰까지 폐기된다.
+func (s *AuthService) RevokeUser(ctx context.Context, userID string) error {
+	ttl := s.refreshTTL
+	if access := time.Duration(s.accessHours) * time.Hour; access > ttl {
+		ttl = access
+	}
+
+	if err := s.redis.Set(ctx, cache.RevokedUserKey(userID), time.Now().UnixMilli(), ttl); err != nil {
+		logger.Errorf("Failed to revoke user tokens: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to revoke tokens", 503)
+	}
+
+	logger.Infof("All tokens revoked for user: %s", userID)
+	return nil
+}
+
+// IsRevoked implements middleware.RevocationChecker
+func (s *AuthService) IsRevoked(ctx context.Context, claims *middleware.JWTClaims) (bool, error) {
+	values, err := s.redis.MGet(ctx, cache.RevokedTokenKey(claims.ID), cache.RevokedUserKey(claims.UserID))
+	if err != nil {
+		return false, err
+	}
+
+	if claims.ID != "" && values[0] != nil {
+		return true, nil
+	}
+
+	// iat_ms 가 없는 토큰은 iat 로 비교한다. 같은 초에 발급됐다면 폐기된 쪽으로 본다.
+	issuedAt := claims.IssuedAtMs
+	if issuedAt == 0 && claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.UnixMilli()
+	}
+
+	if values[1] != nil && issuedAt != 0 {
+		revokedBefore, err := parseUnix(values[1])
+		if err != nil {
+			return false, err
+		}
+		return issuedAt <= revokedBefore, nil
+	}
+
+	return false, nil
+}
+
+// issueTokens signs an access token carrying the user's role and stores a fresh refresh token for the family
+func (s *AuthService) issueTokens(ctx context.Context, user *repository.User, familyID string) (*TokenPair, error) {
+	role := user.Role
+	if role == "" {
+		role = middleware.RoleUser
+	}
+
+	accessToken, err := s.keys.IssueToken(middleware.JWTClaims{
+		UserID:   user.UserID,
+		Username: user.Username.String,
+		Roles:    []string{role},
+		Scopes:   middleware.ScopesForRoles(role),
+	}, s.accessHours)
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to issue token", 500)
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to issue token", 500)
+	}
+
+	session, _ := json.Marshal(refreshSession{
+		UserID:     user.UserID,
+		Username:   user.Username.String,
+		FamilyID:   familyID,
+		IssuedAtMs: time.Now().UnixMilli(),
+	})
+
+	tokenHash := hashToken(refreshToken)
+	if err := s.redis.Set(ctx, cache.SessionKey(tokenHash), session, s.refreshTTL); err != nil {
+		logger.Errorf("Failed to store refresh token: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to issue token", 503)
+	}
+
+	if err := s.redis.Set(ctx, cache.RefreshFamilyKey(familyID), tokenHash, s.refreshTTL); err != nil {
+		logger.Errorf("Failed to store refresh token family: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to issue token", 503)
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int64(time.Duration(s.accessHours) * time.Hour / time.Second),
+		RefreshExpiresIn: int64(s.refreshTTL / time.Second),
+	}, nil
+}
+
+// detectReuse revokes the family when an already rotated refresh token is presented
+func (s *AuthService) detectReuse(ctx context.Context, tokenHash string) {
+	familyID, err := s.redis.Get(ctx, cache.RotatedTokenKey(tokenHash))
+	if err != nil {
+		return
+	}
+
+	logger.Warnf("Refresh token reuse detected, revoking token family %s", familyID)
+	s.revokeFamily(ctx, familyID)
+}
+
+// revokeFamily deletes the live refresh token of a rotation family
+func (s *AuthService) revokeFamily(ctx context.Context, familyID string) {
+	current, err := s.redis.GetDel(ctx, cache.RefreshFamilyKey(familyID))
+	if err != nil {
+		return
+	}
+
+	if err := s.redis.Delete(ctx, cache.SessionKey(current)); err != nil {
+		logger.Warnf("Failed to delete refresh token of family %s: %v", familyID, err)
+	}
+}
+
+// revokedBefore returns the unix time in milliseconds before which the user's tokens are revoked, or 0
+func (s *AuthService) revokedBefore(ctx context.Context, userID string) (int64, error) {
+	value, err := s.redis.Get(ctx, cache.RevokedUserKey(userID))
+	if errors.Is(err, services.ErrCacheMiss) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return parseUnix(value)
+}
+
+// parseUnix parses a unix millisecond timestamp returned by Redis
+func parseUnix(value interface{}) (int64, error) {
+	text, _ := value.(string)
+	return strconv.ParseInt(text, 10, 64)
+}
+
+// newRefreshToken returns 256 bits of randomness encoded for transport
+func newRefreshToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the storage key for a refresh token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
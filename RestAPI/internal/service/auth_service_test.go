@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/config"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "test-secret-key-that-is-at-least-32-chars"
+
+func setupTestRedis(t *testing.T) (*miniredis.Miniredis, *services.RedisService) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	port, err := strconv.Atoi(server.Port())
+	require.NoError(t, err)
+
+	redisService, err := services.NewRedisService(&config.RedisConfig{Host: server.Host(), Port: port})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		redisService.Close()
+	})
+
+	return server, redisService
+}
+
+func setupAuthService(t *testing.T) (*AuthService, *MockUserRepository, *miniredis.Miniredis) {
+	t.Helper()
+
+	server, redisService := setupTestRedis(t)
+	mockRepo := new(MockUserRepository)
+
+	hash, err := HashPassword("correct-password")
+	require.NoError(t, err)
+
+	mockRepo.On("GetCredentials", context.Background(), "alice").Return(&repository.User{
+		UserID:       "alice",
+		Username:     sql.NullString{String: "Alice", Valid: true},
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+	}, nil)
+	mockRepo.On("GetCredentials", context.Background(), "root").Return(&repository.User{
+		UserID:       "root",
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		Role:         middleware.RoleAdmin,
+	}, nil)
+	mockRepo.On("GetCredentials", context.Background(), "ghost").
+		Return(nil, fmt.Errorf("user not found: ghost: %w", sql.ErrNoRows))
+
+	return NewAuthService(mockRepo, redisService, middleware.NewHMACKeySet(testJWTSecret), 1, 24), mockRepo, server
+}
+
+func assertStatus(t *testing.T, err error, statusCode int) {
+	t.Helper()
+
+	appErr := apperrors.GetAppError(err)
+	require.NotNil(t, appErr, "expected AppError, got %v", err)
+	assert.Equal(t, statusCode, appErr.StatusCode)
+}
+
+func TestAuthService_Login(t *testing.T) {
+	ctx := context.Background()
+	authService, _, _ := setupAuthService(t)
+
+	t.Run("valid credentials", func(t *testing.T) {
+		pair, err := authService.Login(ctx, "alice", "correct-password")
+		require.NoError(t, err)
+
+		assert.Equal(t, "Bearer", pair.TokenType)
+		assert.Equal(t, int64(3600), pair.ExpiresIn)
+		assert.NotEmpty(t, pair.RefreshToken)
+
+		claims, err := middleware.ValidateToken(pair.AccessToken, testJWTSecret)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", claims.UserID)
+		assert.NotEmpty(t, claims.ID, "access token 에 jti 가 있어야 한다")
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := authService.Login(ctx, "alice", "wrong-password")
+		assertStatus(t, err, 401)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := authService.Login(ctx, "ghost", "whatever-password")
+		assertStatus(t, err, 401)
+	})
+}
+
+func TestAuthService_RolesInToken(t *testing.T) {
+	ctx := context.Background()
+	authService, _, _ := setupAuthService(t)
+
+	for _, tc := range []struct {
+		userID   string
+		role     string
+		hasAdmin bool
+	}{
+		{"alice", middleware.RoleUser, false},
+		{"root", middleware.RoleAdmin, true},
+	} {
+		t.Run(tc.userID, func(t *testing.T) {
+			pair, err := authService.Login(ctx, tc.userID, "correct-password")
+			require.NoError(t, err)
+
+			// refresh 로 받은 토큰도 같은 역할을 가져야 한다.
+			pair, err = authService.Refresh(ctx, pair.RefreshToken)
+			require.NoError(t, err)
+
+			claims, err := middleware.ValidateToken(pair.AccessToken, testJWTSecret)
+			require.NoError(t, err)
+			assert.Equal(t, []string{tc.role}, claims.Roles)
+			assert.Contains(t, claims.Scopes, middleware.ScopeMessagesRead)
+			assert.Equal(t, tc.hasAdmin, slices.Contains(claims.Scopes, middleware.ScopeAdmin))
+		})
+	}
+}
+
+func TestAuthService_RefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	authService, _, _ := setupAuthService(t)
+
+	first, err := authService.Login(ctx, "alice", "correct-password")
+	require.NoError(t, err)
+
+	second, err := authService.Refresh(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// 교체된 토큰을 다시 쓰면 거부되고, 같은 계열의 최신 토큰도 함께 폐기된다.
+	_, err = authService.Refresh(ctx, first.RefreshToken)
+	assertStatus(t, err, 401)
+
+	_, err = authService.Refresh(ctx, second.RefreshToken)
+	assertStatus(t, err, 401)
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	ctx := context.Background()
+	authService, _, _ := setupAuthService(t)
+
+	pair, err := authService.Login(ctx, "alice", "correct-password")
+	require.NoError(t, err)
+
+	claims, err := middleware.ValidateToken(pair.AccessToken, testJWTSecret)
+	require.NoError(t, err)
+
+	revoked, err := authService.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	require.NoError(t, authService.Logout(ctx, claims, pair.RefreshToken))
+
+	revoked, err = authService.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = authService.Refresh(ctx, pair.RefreshToken)
+	assertStatus(t, err, 401)
+}
+
+func TestAuthService_RevokeUser(t *testing.T) {
+	ctx := context.Background()
+	authService, _, _ := setupAuthService(t)
+
+	pair, err := authService.Login(ctx, "alice", "correct-password")
+	require.NoError(t, err)
+
+	claims, err := middleware.ValidateToken(pair.AccessToken, testJWTSecret)
+	require.NoError(t, err)
+
+	require.NoError(t, authService.RevokeUser(ctx, "alice"))
+
+	revoked, err := authService.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	_, err = authService.Refresh(ctx, pair.RefreshToken)
+	assertStatus(t, err, 401)
+}
+
+func TestAuthService_RevokeUser_ReloginSameSecond(t *testing.T) {
+	ctx := context.Background()
+	authService, _, _ := setupAuthService(t)
+
+	pair, err := authService.Login(ctx, "alice", "correct-password")
+	require.NoError(t, err)
+
+	require.NoError(t, authService.RevokeUser(ctx, "alice"))
+	// 로그아웃 직후 같은 초 안에 다시 로그인한다.
+	time.Sleep(2 * time.Millisecond)
+
+	relogin, err := authService.Login(ctx, "alice", "correct-password")
+	require.NoError(t, err)
+
+	claims, err := middleware.ValidateToken(relogin.AccessToken, testJWTSecret)
+	require.NoError(t, err)
+
+	revoked, err := authService.IsRevoked(ctx, claims)
+	require.NoError(t, err)
+	assert.False(t, revoked, "a token issued after the revocation must stay valid")
+
+	_, err = authService.Refresh(ctx, relogin.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = authService.Refresh(ctx, pair.RefreshToken)
+	assertStatus(t, err, 401)
+}
+
+func TestAuthService_RedisUnavailable(t *testing.T) {
+	ctx := context.Background()
+	authService, _, server := setupAuthService(t)
+
+	pair, err := authService.Login(ctx, "alice", "correct-password")
+	require.NoError(t, err)
+
+	claims, err := middleware.ValidateToken(pair.AccessToken, testJWTSecret)
+	require.NoError(t, err)
+
+	server.Close()
+
+	// 폐기 여부를 확인할 수 없으면 미들웨어가 거부할 수 있도록 오류를 돌려줘야 한다.
+	_, err = authService.IsRevoked(ctx, claims)
+	assert.Error(t, err)
+
+	_, err = authService.Refresh(ctx, pair.RefreshToken)
+	assertStatus(t, err, 503)
+}
+
+func TestHashPassword(t *testing.T) {
+	_, err := HashPassword("short")
+	assertStatus(t, err, 400)
+
+	hash, err := HashPassword("long-enough-password")
+	require.NoError(t, err)
+	assert.NotEqual(t, "long-enough-password", hash)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// ConversationService handles direct messages between two users.
+// 스레드 접근은 라우트의 selfOrAdmin 이 경로의 userID 로 제한한다 — 서비스는 두 참여자 사이의 메시지만 돌려준다.
+type ConversationService struct {
+	messages repository.MessageRepository
+	users    *UserService
+}
+
+// NewConversationService creates a new conversation service
+func NewConversationService(messages repository.MessageRepository, users *UserService) *ConversationService {
+	return &ConversationService{
+		messages: messages,
+		users:    users,
+	}
+}
+
+// CheckCanMessage fails unless recipientID is an active user
+func (s *ConversationService) CheckCanMessage(ctx context.Context, senderID, recipientID string) error {
+	// 캐시를 거치는 조회라 전송마다 DB 를 치지 않는다. 삭제 시 캐시가 지워지므로 삭제된 사용자는 404 다.
+	if _, err := s.users.GetUser(ctx, recipientID); err != nil {
+		if appErr := apperrors.GetAppError(err); appErr != nil && appErr.StatusCode == 404 {
+			return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Recipient not found", 404)
+		}
+		return err
+	}
+	return nil
+}
+
+// ListConversations lists the user's direct message peers with the latest message of each, most recent first
+func (s *ConversationService) ListConversations(ctx context.Context, userID string, limit, offset int) ([]*repository.Conversation, int64, error) {
+	conversations, err := s.messages.ListConversations(ctx, userID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list conversations: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list conversations", 500)
+	}
+
+	total, err := s.messages.CountConversations(ctx, userID)
+	if err != nil {
+		logger.Errorf("Failed to count conversations: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count conversations", 500)
+	}
+
+	return conversations, total, nil
+}
+
+// GetThread retrieves the direct messages between userID and peerID, newest first
+func (s *ConversationService) GetThread(ctx context.Context, userID, peerID string, limit, offset int) ([]*repository.Message, int64, error) {
+	messages, err := s.messages.ListThread(ctx, userID, peerID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to get thread: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get messages", 500)
+	}
+
+	total, err := s.messages.CountThread(ctx, userID, peerID)
+	if err != nil {
+		logger.Errorf("Failed to count thread: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count messages", 500)
+	}
+
+	if err := withThreadSummaries(ctx, s.messages, messages); err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupConversationService() (*ConversationService, *MockMessageRepository, *MockUserRepository) {
+	messages := new(MockMessageRepository)
+	users := new(MockUserRepository)
+	return NewConversationService(messages, NewUserService(users, nil)), messages, users
+}
+
+func TestConversationService_CheckCanMessage(t *testing.T) {
+	ctx := context.Background()
+	svc, _, users := setupConversationService()
+
+	users.On("GetByUserID", ctx, "bob").Return(&repository.User{UserID: "bob"}, nil)
+	users.On("GetByUserID", ctx, "ghost").Return(nil, errors.New("user not found"))
+
+	assert.NoError(t, svc.CheckCanMessage(ctx, "alice", "bob"))
+	assertStatus(t, svc.CheckCanMessage(ctx, "alice", "ghost"), 404)
+}
+
+func TestConversationService_ListConversations(t *testing.T) {
+	ctx := context.Background()
+	svc, messages, _ := setupConversationService()
+
+	bob := "bob"
+	conversations := []*repository.Conversation{
+		{PeerID: "bob", Message: repository.Message{MessageID: "m2", UserID: "alice", RecipientID: &bob, CreatedAt: time.Now()}},
+	}
+	messages.On("ListConversations", ctx, "alice", 20, 0).Return(conversations, nil)
+	messages.On("CountConversations", ctx, "alice").Return(int64(1), nil)
+
+	list, total, err := svc.ListConversations(ctx, "alice", 20, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, "bob", list[0].PeerID)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestConversationService_GetThread_Error(t *testing.T) {
+	ctx := context.Background()
+	svc, messages, _ := setupConversationService()
+
+	messages.On("ListThread", ctx, "alice", "bob", 20, 0).Return([]*repository.Message(nil), errors.New("connection reset"))
+
+	_, _, err := svc.GetThread(ctx, "alice", "bob", 20, 0)
+
+	assertStatus(t, err, 500)
+}
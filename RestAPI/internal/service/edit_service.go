@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/encryption"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
+)
+
+// editPublishTimeout bounds the RabbitMQ publish of an edit or recall event
+const editPublishTimeout = 5 * time.Second
+
+// MessagePublisher publishes a serialized queue message under its publisher_information.message_id
+type MessagePublisher interface {
+	PublishMessage(ctx context.Context, messageID string, message []byte) error
+}
+
+// EditService lets authors correct their messages within the edit window.
+// 이전 내용은 message_revisions 에 남고, 수정 이벤트는 RabbitMQ 로 발행돼 MainServer 가 접속 중인 클라이언트에 알린다.
+type EditService struct {
+	messages   repository.MessageRepository
+	rooms      repository.RoomRepository
+	publisher  MessagePublisher
+	window     time.Duration
+	cipher     *encryption.MessageCipher
+	moderation *ModerationService
+}
+
+// NewEditService creates a new edit service
+func NewEditService(messages repository.MessageRepository, rooms repository.RoomRepository, publisher MessagePublisher, window time.Duration) *EditService {
+	return &EditService{
+		messages:  messages,
+		rooms:     rooms,
+		publisher: publisher,
+		window:    window,
+	}
+}
+
+// SetCipher enables editing encrypted messages.
+// DBWorker 와 같은 키로 새 내용을 암호화해 저장한다 — 없으면 암호화된 메시지를 평문으로 덮어쓰지 않도록 수정을 거절한다.
+func (s *EditService) SetCipher(cipher *encryption.MessageCipher) {
+	s.cipher = cipher
+}
+
+// SetModeration checks edited content with the same filters as new messages
+func (s *EditService) SetModeration(moderation *ModerationService) {
+	s.moderation = moderation
+}
+
+// EditMessage replaces the content of the editor's message and publishes an edit event.
+// editorID 가 비어 있으면(인증 비활성) 작성자를 확인하지 않는다. 관리자도 남의 메시지는 고칠 수 없다.
+func (s *EditService) EditMessage(ctx context.Context, editorID, messageID, content string) (*repository.Message, error) {
+	if content == "" {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Content is required", 400).
+			WithFields(map[string]string{"content": "is required"})
+	}
+
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	if message.RecalledAt != nil {
+		return nil, apperrors.New(apperrors.ErrCodeConflict, "The message has been recalled", 409)
+	}
+	if editorID != "" && message.UserID != editorID {
+		return nil, apperrors.New(apperrors.ErrCodeForbidden, "Only the author can edit this message", 403)
+	}
+	if time.Since(message.CreatedAt) > s.window {
+		return nil, apperrors.New(apperrors.ErrCodeForbidden, "The edit window for this message has passed", 403)
+	}
+
+	stored := content
+	if message.IsEncrypted {
+		if s.cipher == nil {
+			return nil, apperrors.New(apperrors.ErrCodeServiceUnavail, "Editing encrypted messages requires the message encryption key", 503)
+		}
+		stored = s.cipher.Encrypt(content)
+	}
+
+	// 같은 내용으로 고치면 남길 이전 버전도, 알릴 변경도 없다. IV 가 고정이라 암호문끼리 비교해도 된다.
+	if stored == message.Content {
+		return message, nil
+	}
+
+	// 수정으로 필터를 우회하지 못하도록 새 내용도 검사한다.
+	checked := &moderation.Message{UserID: message.UserID, Content: content, Edit: true}
+	var decision *moderation.Decision
+	if s.moderation != nil {
+		if decision, err = s.moderation.Moderate(ctx, checked); err != nil {
+			return nil, err
+		}
+	}
+
+	// 조회와 수정 사이에 회수되거나 삭제되면 저장소가 ErrNoRows 를 돌려준다.
+	edited, err := s.messages.UpdateContent(ctx, messageID, stored, message.IsEncrypted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to edit message: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to edit message", 500)
+	}
+
+	editedAt := time.Now()
+	if edited.EditedAt != nil {
+		editedAt = *edited.EditedAt
+	}
+	roomID, recipientID := destination(edited)
+	s.publish(ctx, edited.MessageID, models.NewEditQueueMessage(edited.MessageID, edited.UserID, roomID, recipientID, content, editedAt))
+	if s.moderation != nil {
+		s.moderation.FlagMessage(ctx, edited.MessageID, checked, decision)
+	}
+	return edited, nil
+}
+
+// RecallMessage tombstones a message and the replies of its thread, publishing a recall event for each.
+// 작성자나 관리자만 회수할 수 있고, actorID 가 비어 있으면(인증 비활성) 확인하지 않는다.
+// 행은 감사 목적으로 남으며, 이미 회수된 메시지를 다시 회수하면 이벤트 없이 그대로 돌려준다.
+func (s *EditService) RecallMessage(ctx context.Context, actorID string, admin bool, messageID string) (*repository.Message, error) {
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	if actorID != "" && !admin && message.UserID != actorID {
+		return nil, apperrors.New(apperrors.ErrCodeForbidden, "Only the author or an admin can recall this message", 403)
+	}
+
+	recalled, changed, err := s.messages.Recall(ctx, messageID, actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to recall message: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to recall message", 500)
+	}
+
+	if changed {
+		s.publishRecall(ctx, recalled)
+	}
+
+	// 스레드의 답글도 함께 회수한다. 회수 뒤 도착한 답글까지 거두도록 다시 회수할 때도 매번 훑는다.
+	replies, err := s.messages.RecallReplies(ctx, messageID, actorID)
+	if err != nil {
+		logger.Errorf("Failed to recall replies: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to recall replies", 500)
+	}
+	for _, reply := range replies {
+		s.publishRecall(ctx, reply)
+	}
+
+	return recalled, nil
+}
+
+// publishRecall sends the recall event of a recalled message
+func (s *EditService) publishRecall(ctx context.Context, message *repository.Message) {
+	recalledAt := time.Now()
+	if message.RecalledAt != nil {
+		recalledAt = *message.RecalledAt
+	}
+	roomID, recipientID := destination(message)
+	s.publish(ctx, message.MessageID, models.NewRecallQueueMessage(message.MessageID, message.UserID, roomID, recipientID, recalledAt))
+}
+
+// ListRevisions lists the prior versions of a message, most recently replaced first.
+// 메시지를 읽을 수 있는 사람(스레드와 같은 규칙)과 관리자만 볼 수 있고 나머지는 404 다.
+func (s *EditService) ListRevisions(ctx context.Context, callerID string, admin bool, messageID string, limit, offset int) ([]*repository.MessageRevision, int64, error) {
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	if callerID != "" && !admin {
+		if err := checkCanRead(ctx, s.rooms, message, callerID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	// 회수된 메시지는 이전 버전의 내용도 보여주지 않는다.
+	if message.RecalledAt != nil {
+		return []*repository.MessageRevision{}, 0, nil
+	}
+
+	revisions, err := s.messages.ListRevisions(ctx, messageID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list message revisions: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get revisions", 500)
+	}
+
+	total, err := s.messages.CountRevisions(ctx, messageID)
+	if err != nil {
+		logger.Errorf("Failed to count message revisions: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count revisions", 500)
+	}
+
+	return revisions, total, nil
+}
+
+// destination returns the room and direct message recipient of a message, empty when unset
+func destination(message *repository.Message) (roomID, recipientID string) {
+	if message.RoomID != nil {
+		roomID = *message.RoomID
+	}
+	if message.RecipientID != nil {
+		recipientID = *message.RecipientID
+	}
+	return roomID, recipientID
+}
+
+// publish sends an edit or recall event. 편집 이벤트는 전송과 마찬가지로 평문 내용을 싣는다.
+// 변경은 이미 커밋됐으므로 발행 실패는 기록만 한다 — 클라이언트는 다음 조회에서 새 상태를 받는다.
+func (s *EditService) publish(ctx context.Context, messageID string, event *models.QueueMessage) {
+	payload, err := event.WithRequestID(middleware.RequestIDFromContext(ctx)).ToJSON()
+	if err != nil {
+		logger.Warnf("Failed to serialize %s event (%s): %v", event.Message.Command, messageID, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, editPublishTimeout)
+	defer cancel()
+
+	if err := s.publisher.PublishMessage(ctx, messageID, payload); err != nil {
+		logger.Warnf("Failed to publish %s event (%s): %v", event.Message.Command, messageID, err)
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher keeps every published payload and its message ID
+type recordingPublisher struct {
+	payloads   [][]byte
+	messageIDs []string
+	err        error
+}
+
+func (p *recordingPublisher) PublishMessage(ctx context.Context, messageID string, message []byte) error {
+	p.payloads = append(p.payloads, message)
+	p.messageIDs = append(p.messageIDs, messageID)
+	return p.err
+}
+
+func setupEditService() (*EditService, *MockMessageRepository, *recordingPublisher) {
+	messages := new(MockMessageRepository)
+	publisher := &recordingPublisher{}
+	return NewEditService(messages, new(MockRoomRepository), publisher, 15*time.Minute), messages, publisher
+}
+
+func TestEditService_EditMessage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("author within the window", func(t *testing.T) {
+		svc, messages, publisher := setupEditService()
+		bob := "bob"
+		now := time.Now()
+		messages.On("GetByMessageID", ctx, "m1").
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", RecipientID: &bob, Content: "helo", CreatedAt: now.Add(-time.Minute)}, nil)
+		messages.On("UpdateContent", ctx, "m1", "hello", false).
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", RecipientID: &bob, Content: "hello", EditedAt: &now}, nil)
+
+		message, err := svc.EditMessage(ctx, "alice", "m1", "hello")
+
+		require.NoError(t, err)
+		assert.Equal(t, "hello", message.Content)
+		require.Len(t, publisher.payloads, 1)
+		var event models.QueueMessage
+		require.NoError(t, json.Unmarshal(publisher.payloads[0], &event))
+		assert.Equal(t, models.CommandEditMessage, event.Message.Command)
+		assert.Equal(t, "m1", event.PublisherInformation.MessageID)
+		assert.Equal(t, "bob", event.RecipientID)
+	})
+
+	t.Run("publish failure keeps the edit", func(t *testing.T) {
+		svc, messages, publisher := setupEditService()
+		publisher.err = errors.New("connection closed")
+		messages.On("GetByMessageID", ctx, "m1").
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", Content: "helo", CreatedAt: time.Now()}, nil)
+		messages.On("UpdateContent", ctx, "m1", "hello", false).
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", Content: "hello"}, nil)
+
+		_, err := svc.EditMessage(ctx, "alice", "m1", "hello")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("unchanged content", func(t *testing.T) {
+		svc, messages, publisher := setupEditService()
+		messages.On("GetByMessageID", ctx, "m1").
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", Content: "hello", CreatedAt: time.Now()}, nil)
+
+		_, err := svc.EditMessage(ctx, "alice", "m1", "hello")
+
+		require.NoError(t, err)
+		messages.AssertNotCalled(t, "UpdateContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Empty(t, publisher.payloads)
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		svc, messages, _ := setupEditService()
+		messages.On("GetByMessageID", ctx, "recent").
+			Return(&repository.Message{MessageID: "recent", UserID: "alice", Content: "x", CreatedAt: time.Now()}, nil)
+		messages.On("GetByMessageID", ctx, "old").
+			Return(&repository.Message{MessageID: "old", UserID: "alice", Content: "x", CreatedAt: time.Now().Add(-time.Hour)}, nil)
+		messages.On("GetByMessageID", ctx, "secret").
+			Return(&repository.Message{MessageID: "secret", UserID: "alice", Content: "x", IsEncrypted: true, CreatedAt: time.Now()}, nil)
+		messages.On("GetByMessageID", ctx, "gone").Return(nil, errors.New("message not found: gone"))
+
+		_, err := svc.EditMessage(ctx, "bob", "recent", "y")
+		assertStatus(t, err, 403)
+
+		_, err = svc.EditMessage(ctx, "alice", "old", "y")
+		assertStatus(t, err, 403)
+
+		_, err = svc.EditMessage(ctx, "alice", "secret", "y")
+		assertStatus(t, err, 503)
+
+		_, err = svc.EditMessage(ctx, "alice", "gone", "y")
+		assertStatus(t, err, 404)
+
+		_, err = svc.EditMessage(ctx, "alice", "recent", "")
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("encrypted message is stored encrypted", func(t *testing.T) {
+		svc, messages, publisher := setupEditService()
+		cipher, err := encryption.NewMessageCipher(
+			base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))),
+			base64.StdEncoding.EncodeToString([]byte(strings.Repeat("v", 16))),
+		)
+		require.NoError(t, err)
+		svc.SetCipher(cipher)
+
+		messages.On("GetByMessageID", ctx, "secret").
+			Return(&repository.Message{MessageID: "secret", UserID: "alice", Content: cipher.Encrypt("helo"), IsEncrypted: true, CreatedAt: time.Now()}, nil)
+		messages.On("UpdateContent", ctx, "secret", cipher.Encrypt("hello"), true).
+			Return(&repository.Message{MessageID: "secret", UserID: "alice", Content: cipher.Encrypt("hello"), IsEncrypted: true}, nil)
+
+		_, err = svc.EditMessage(ctx, "alice", "secret", "hello")
+
+		require.NoError(t, err)
+		var event models.QueueMessage
+		require.NoError(t, json.Unmarshal(publisher.payloads[0], &event))
+		assert.Equal(t, "hello", event.Message.Content, "이벤트는 전송과 같이 평문을 싣는다")
+	})
+
+	t.Run("moderated", func(t *testing.T) {
+		svc, messages, publisher := setupEditService()
+		flags := new(MockModerationRepository)
+		svc.SetModeration(NewModerationService(newTestPipeline(t), flags))
+		messages.On("GetByMessageID", ctx, "m1").
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", Content: "helo", CreatedAt: time.Now()}, nil)
+		messages.On("UpdateContent", ctx, "m1", "hello?", false).
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", Content: "hello?"}, nil)
+		flags.On("Create", ctx, mock.MatchedBy(func(flag *repository.ModerationFlag) bool {
+			return flag.MessageID == "m1" && flag.IsEdit
+		})).Return(nil)
+
+		_, err := svc.EditMessage(ctx, "alice", "m1", "spam")
+		assertStatus(t, err, 422)
+		messages.AssertNotCalled(t, "UpdateContent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		_, err = svc.EditMessage(ctx, "alice", "m1", "hello?")
+		require.NoError(t, err)
+		assert.Len(t, publisher.payloads, 1)
+		flags.AssertExpectations(t)
+	})
+}
+
+func TestEditService_ListRevisions(t *testing.T) {
+	ctx := context.Background()
+	svc, messages, _ := setupEditService()
+
+	bob := "bob"
+	messages.On("GetByMessageID", ctx, "m1").Return(&repository.Message{MessageID: "m1", UserID: "alice", RecipientID: &bob}, nil)
+	messages.On("ListRevisions", ctx, "m1", 20, 0).Return([]*repository.MessageRevision{{MessageID: "m1", Content: "helo"}}, nil)
+	messages.On("CountRevisions", ctx, "m1").Return(int64(1), nil)
+
+	revisions, total, err := svc.ListRevisions(ctx, "bob", false, "m1", 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, int64(1), total)
+
+	_, _, err = svc.ListRevisions(ctx, "carol", false, "m1", 20, 0)
+	assertStatus(t, err, 404)
+
+	_, _, err = svc.ListRevisions(ctx, "carol", true, "m1", 20, 0)
+	assert.NoError(t, err)
+
+	t.Run("room members", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		rooms := new(MockRoomRepository)
+		svc := NewEditService(messages, rooms, &recordingPublisher{}, 15*time.Minute)
+
+		roomID := testRoomID
+		messages.On("GetByMessageID", ctx, "m2").Return(&repository.Message{MessageID: "m2", UserID: "alice", RoomID: &roomID}, nil)
+		messages.On("ListRevisions", ctx, "m2", 20, 0).Return([]*repository.MessageRevision{{MessageID: "m2", Content: "helo"}}, nil)
+		messages.On("CountRevisions", ctx, "m2").Return(int64(1), nil)
+		rooms.On("GetMember", ctx, testRoomID, "bob").Return(&repository.RoomMember{RoomID: testRoomID, UserID: "bob"}, nil)
+		rooms.On("GetMember", ctx, testRoomID, "carol").Return(nil, fmt.Errorf("carol: %w", sql.ErrNoRows))
+
+		revisions, _, err := svc.ListRevisions(ctx, "bob", false, "m2", 20, 0)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1)
+
+		_, _, err = svc.ListRevisions(ctx, "carol", false, "m2", 20, 0)
+		assertStatus(t, err, 404)
+	})
+}
+
+func TestEditService_RecallMessage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("author recalls", func(t *testing.T) {
+		ctx := middleware.ContextWithRequestID(ctx, "req-1")
+		svc, messages, publisher := setupEditService()
+		roomID := testRoomID
+		now := time.Now()
+		messages.On("GetByMessageID", ctx, "m1").Return(&repository.Message{MessageID: "m1", UserID: "alice", RoomID: &roomID}, nil)
+		messages.On("Recall", ctx, "m1", "alice").
+			Return(&repository.Message{MessageID: "m1", UserID: "alice", RoomID: &roomID, RecalledAt: &now}, true, nil)
+		messages.On("RecallReplies", ctx, "m1", "alice").
+			Return([]*repository.Message{{MessageID: "r1", UserID: "bob", RoomID: &roomID, RecalledAt: &now}}, nil)
+
+		message, err := svc.RecallMessage(ctx, "alice", false, "m1")
+
+		require.NoError(t, err)
+		assert.NotNil(t, message.RecalledAt)
+		require.Len(t, publisher.payloads, 2, "답글도 함께 회수된다")
+		var event models.QueueMessage
+		require.NoError(t, json.Unmarshal(publisher.payloads[0], &event))
+		assert.Equal(t, models.CommandRecall, event.Message.Command)
+		assert.Empty(t, event.Message.Content)
+		assert.Equal(t, "m1", event.PublisherInformation.MessageID)
+		assert.Equal(t, "req-1", event.PublisherInformation.RequestID, "회수 요청의 ID 가 이벤트에 실린다")
+		assert.Equal(t, testRoomID, event.RoomID)
+
+		require.NoError(t, json.Unmarshal(publisher.payloads[1], &event))
+		assert.Equal(t, "r1", event.PublisherInformation.MessageID)
+		assert.Equal(t, "req-1", event.PublisherInformation.RequestID)
+		assert.Equal(t, "bob", event.ID)
+		assert.Equal(t, []string{"m1", "r1"}, publisher.messageIDs)
+	})
+
+	t.Run("repeat recall is silent", func(t *testing.T) {
+		svc, messages, publisher := setupEditService()
+		now := time.Now()
+		messages.On("GetByMessageID", ctx, "m1").Return(&repository.Message{MessageID: "m1", UserID: "alice", RecalledAt: &now}, nil)
+		messages.On("Recall", ctx, "m1", "admin").Return(&repository.Message{MessageID: "m1", UserID: "alice", RecalledAt: &now}, false, nil)
+		messages.On("RecallReplies", ctx, "m1", "admin").Return([]*repository.Message{}, nil)
+
+		_, err := svc.RecallMessage(ctx, "admin", true, "m1")
+
+		require.NoError(t, err)
+		assert.Empty(t, publisher.payloads)
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		svc, messages, _ := setupEditService()
+		messages.On("GetByMessageID", ctx, "m1").Return(&repository.Message{MessageID: "m1", UserID: "alice"}, nil)
+
+		_, err := svc.RecallMessage(ctx, "bob", false, "m1")
+
+		assertStatus(t, err, 403)
+		messages.AssertNotCalled(t, "Recall", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("recalled message cannot be edited", func(t *testing.T) {
+		svc, messages, _ := setupEditService()
+		now := time.Now()
+		messages.On("GetByMessageID", ctx, "m1").Return(&repository.Message{MessageID: "m1", UserID: "alice", CreatedAt: now, RecalledAt: &now}, nil)
+
+		_, err := svc.EditMessage(ctx, "alice", "m1", "again")
+
+		assertStatus(t, err, 409)
+	})
+}
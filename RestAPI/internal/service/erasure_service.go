@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// BlobEraser removes the attachment content of a user being erased
+type BlobEraser interface {
+	DeleteUploaderBlobs(ctx context.Context, uploaderID string) (int, error)
+}
+
+// ErasureService handles user erasure requests and runs the erasure worker.
+// 요청은 사용자를 soft delete 하고 작업을 기록만 한다. 메시지 처리는 워커가 배치 단위로 커밋하므로
+// 프로세스가 중간에 죽어도 lease 가 만료되면 남은 메시지부터 이어서 진행된다.
+type ErasureService struct {
+	repo      repository.ErasureRepository
+	users     *UserService
+	redis     *services.RedisService
+	batchSize int
+	lease     time.Duration
+	blobs     BlobEraser
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewErasureService creates a new erasure service
+func NewErasureService(
+	repo repository.ErasureRepository,
+	users *UserService,
+	redis *services.RedisService,
+	batchSize int,
+	lease time.Duration,
+) *ErasureService {
+	return &ErasureService{
+		repo:      repo,
+		users:     users,
+		redis:     redis,
+		batchSize: batchSize,
+		lease:     lease,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// SetBlobEraser removes the user's attachment content on erasure.
+// 설정하지 않으면(첨부 비활성) 첨부 행만 사용자와 함께 지워지고 blob 은 저장소에 남는다.
+func (s *ErasureService) SetBlobEraser(blobs BlobEraser) {
+	s.blobs = blobs
+}
+
+// RequestErasure soft-deletes the user and queues the erasure of the user's data
+func (s *ErasureService) RequestErasure(ctx context.Context, userID, messageMode, requestedBy string) (*repository.UserErasure, error) {
+	if messageMode == "" {
+		messageMode = repository.ErasureAnonymize
+	}
+	if messageMode != repository.ErasureAnonymize && messageMode != repository.ErasureDelete {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid erasure request", 400).
+			WithFields(map[string]string{"messages": "must be anonymize or delete"})
+	}
+
+	// 이미 soft delete 된 사용자도 erasure 대상이므로 404 는 무시한다. 존재 여부는 Create 가 판단한다.
+	if err := s.users.DeleteUser(ctx, userID); err != nil {
+		if appErr := apperrors.GetAppError(err); appErr == nil || appErr.StatusCode != 404 {
+			return nil, err
+		}
+	}
+
+	erasure := &repository.UserErasure{
+		UserID:      userID,
+		MessageMode: messageMode,
+		RequestedBy: requestedBy,
+	}
+	if err := s.repo.Create(ctx, erasure); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "User not found", 404)
+		case errors.Is(err, repository.ErrErasureExists):
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeConflict, "Erasure already requested", 409)
+		}
+		logger.Errorf("Failed to create erasure: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to request erasure", 500)
+	}
+
+	logger.Infof("Erasure requested: %s (messages: %s, by %s)", userID, messageMode, requestedBy)
+	s.notify()
+	return erasure, nil
+}
+
+// GetErasure retrieves the erasure request of a user
+func (s *ErasureService) GetErasure(ctx context.Context, userID string) (*repository.UserErasure, error) {
+	erasure, err := s.repo.GetByUserID(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Erasure not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get erasure: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get erasure", 500)
+	}
+	return erasure, nil
+}
+
+// RunPending processes erasures until none is left to claim and returns how many completed
+func (s *ErasureService) RunPending(ctx context.Context) (int, error) {
+	completed := 0
+	for {
+		erasure, err := s.repo.ClaimNext(ctx, s.lease)
+		if err != nil || erasure == nil {
+			return completed, err
+		}
+
+		if err := s.process(ctx, erasure); err != nil {
+			s.fail(ctx, erasure, err)
+			if ctx.Err() != nil {
+				return completed, ctx.Err()
+			}
+			continue
+		}
+		completed++
+	}
+}
+
+// Start runs pending erasures every interval and right after each request, until Close is called
+func (s *ErasureService) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := s.RunPending(ctx); err != nil && ctx.Err() == nil {
+				logger.Warnf("Erasure worker failed: %v", err)
+			} else if n > 0 {
+				logger.Infof("Erasure worker: %d erasure(s) completed", n)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-s.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the worker; an erasure in progress is released and resumed on the next start
+func (s *ErasureService) Close() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+		s.cancel = nil
+	}
+}
+
+// process erases the user's messages batch by batch, purges the user's Redis keys, removes the user's attachment
+// content and completes the erasure.
+// 각 단계는 멱등이다 — 재시도는 남은 메시지와 아직 하지 않은 단계만 처리한다.
+func (s *ErasureService) process(ctx context.Context, erasure *repository.UserErasure) error {
+	for {
+		messageIDs, err := s.repo.ProcessMessages(ctx, erasure, s.batchSize, s.lease)
+		if err != nil {
+			return err
+		}
+		s.evictMessages(ctx, messageIDs)
+
+		if len(messageIDs) < s.batchSize {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	if !erasure.CachePurged {
+		if err := s.purgeUserCache(ctx, erasure.UserID); err != nil {
+			return err
+		}
+		if err := s.repo.MarkCachePurged(ctx, erasure.ID); err != nil {
+			return err
+		}
+		erasure.CachePurged = true
+	}
+
+	// 첨부 행은 Complete 에서 사용자와 함께 지워지므로 그 전에 blob 을 지운다.
+	if s.blobs != nil {
+		n, err := s.blobs.DeleteUploaderBlobs(ctx, erasure.UserID)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Infof("Erasure: %d attachment blob(s) of %s removed", n, erasure.UserID)
+		}
+	}
+
+	if err := s.repo.Complete(ctx, erasure); err != nil {
+		return err
+	}
+
+	logger.Infof("Erasure completed: %s (%d message(s), mode %s)", erasure.UserID, erasure.MessagesProcessed, erasure.MessageMode)
+	return nil
+}
+
+// fail records the error, or releases the lease when the worker is shutting down
+func (s *ErasureService) fail(ctx context.Context, erasure *repository.UserErasure, cause error) {
+	// 종료 중이면 ctx 가 이미 취소됐으므로 짧은 별도 컨텍스트로 기록한다.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 3*time.Second)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		if err := s.repo.Release(recordCtx, erasure.ID); err != nil {
+			logger.Warnf("Failed to release erasure (%s): %v", erasure.UserID, err)
+		}
+		return
+	}
+
+	logger.Errorf("Erasure failed (%s), retrying after the lease expires: %v", erasure.UserID, cause)
+	if err := s.repo.RecordFailure(recordCtx, erasure.ID, cause.Error()); err != nil {
+		logger.Warnf("Failed to record erasure failure (%s): %v", erasure.UserID, err)
+	}
+}
+
+// evictMessages drops cached entries of erased messages.
+// DB 변경은 이미 커밋됐으므로 실패해도 작업을 되돌리지 않는다 — 남은 키는 TTL 로 사라진다.
+func (s *ErasureService) evictMessages(ctx context.Context, messageIDs []string) {
+	if s.redis == nil || len(messageIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(messageIDs)*2)
+	for _, messageID := range messageIDs {
+		keys = append(keys, cache.MessageKey(messageID), cache.MessageStatusKey(messageID))
+	}
+
+	if err := s.redis.Delete(ctx, keys...); err != nil {
+		logger.Warnf("Failed to evict erased messages from cache: %v", err)
+	}
+}
+
+// purgeUserCache removes the user's Redis keys.
+// revoked:user 는 남긴다 — 이미 발급된 access token 을 만료 시까지 막는 데 필요하고 TTL 로 사라진다.
+func (s *ErasureService) purgeUserCache(ctx context.Context, userID string) error {
+	if s.redis == nil {
+		return nil
+	}
+
+	if err := s.redis.Delete(ctx, cache.UserKey(userID), cache.UserStatusKey(userID)); err != nil {
+		return err
+	}
+
+	_, err := s.redis.ZRem(ctx, cache.PresenceOnlineKey(), userID)
+	return err
+}
+
+// notify wakes the worker without blocking
+func (s *ErasureService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/blobstore"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockErasureRepository is a mock implementation of ErasureRepository
+type MockErasureRepository struct {
+	mock.Mock
+}
+
+func (m *MockErasureRepository) Create(ctx context.Context, erasure *repository.UserErasure) error {
+	args := m.Called(ctx, erasure)
+	return args.Error(0)
+}
+
+func (m *MockErasureRepository) GetByUserID(ctx context.Context, userID string) (*repository.UserErasure, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.UserErasure), args.Error(1)
+}
+
+func (m *MockErasureRepository) ClaimNext(ctx context.Context, lease time.Duration) (*repository.UserErasure, error) {
+	args := m.Called(ctx, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.UserErasure), args.Error(1)
+}
+
+func (m *MockErasureRepository) ProcessMessages(ctx context.Context, erasure *repository.UserErasure, batchSize int, lease time.Duration) ([]string, error) {
+	args := m.Called(ctx, erasure, batchSize, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockErasureRepository) MarkCachePurged(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockErasureRepository) Complete(ctx context.Context, erasure *repository.UserErasure) error {
+	args := m.Called(ctx, erasure)
+	return args.Error(0)
+}
+
+func (m *MockErasureRepository) RecordFailure(ctx context.Context, id int64, message string) error {
+	args := m.Called(ctx, id, message)
+	return args.Error(0)
+}
+
+func (m *MockErasureRepository) Release(ctx context.Context, id int64) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestErasureService_RequestErasure(t *testing.T) {
+	ctx := context.Background()
+	notFound := fmt.Errorf("user not found: alice: %w", sql.ErrNoRows)
+
+	setup := func() (*ErasureService, *MockErasureRepository, *MockUserRepository) {
+		repo := new(MockErasureRepository)
+		userRepo := new(MockUserRepository)
+		return NewErasureService(repo, NewUserService(userRepo, nil), nil, 2, time.Minute), repo, userRepo
+	}
+
+	t.Run("soft-deletes and queues", func(t *testing.T) {
+		svc, repo, userRepo := setup()
+
+		userRepo.On("Delete", ctx, "alice").Return(nil)
+		repo.On("Create", ctx, mock.MatchedBy(func(e *repository.UserErasure) bool {
+			return e.UserID == "alice" && e.MessageMode == repository.ErasureAnonymize && e.RequestedBy == "root"
+		})).Return(nil)
+
+		erasure, err := svc.RequestErasure(ctx, "alice", "", "root")
+
+		require.NoError(t, err)
+		assert.Equal(t, repository.ErasureAnonymize, erasure.MessageMode)
+		assert.Len(t, svc.wake, 1)
+		userRepo.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("already soft-deleted user", func(t *testing.T) {
+		svc, repo, userRepo := setup()
+
+		userRepo.On("Delete", ctx, "alice").Return(notFound)
+		repo.On("Create", ctx, mock.Anything).Return(nil)
+
+		_, err := svc.RequestErasure(ctx, "alice", repository.ErasureDelete, "root")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		svc, repo, userRepo := setup()
+
+		userRepo.On("Delete", ctx, "alice").Return(notFound)
+		repo.On("Create", ctx, mock.Anything).Return(notFound)
+
+		_, err := svc.RequestErasure(ctx, "alice", "", "root")
+
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("already requested", func(t *testing.T) {
+		svc, repo, userRepo := setup()
+
+		userRepo.On("Delete", ctx, "alice").Return(notFound)
+		repo.On("Create", ctx, mock.Anything).Return(fmt.Errorf("alice: %w", repository.ErrErasureExists))
+
+		_, err := svc.RequestErasure(ctx, "alice", "", "root")
+
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("invalid mode", func(t *testing.T) {
+		svc, repo, userRepo := setup()
+
+		_, err := svc.RequestErasure(ctx, "alice", "shred", "root")
+
+		assertStatus(t, err, 400)
+		assert.Contains(t, apperrors.GetAppError(err).Fields, "messages")
+		userRepo.AssertNotCalled(t, "Delete")
+		repo.AssertNotCalled(t, "Create")
+	})
+}
+
+func TestErasureService_RunPending(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("erases in batches and completes", func(t *testing.T) {
+		server, redisService := setupTestRedis(t)
+		repo := new(MockErasureRepository)
+		svc := NewErasureService(repo, NewUserService(new(MockUserRepository), redisService), redisService, 2, time.Minute)
+
+		for _, key := range []string{cache.UserKey("alice"), cache.MessageStatusKey("m-1"), cache.MessageStatusKey("m-3")} {
+			require.NoError(t, server.Set(key, "x"))
+		}
+		_, err := server.ZAdd(cache.PresenceOnlineKey(), 1, "alice")
+		require.NoError(t, err)
+
+		erasure := &repository.UserErasure{ID: 3, UserID: "alice", MessageMode: repository.ErasureAnonymize}
+		repo.On("ClaimNext", ctx, time.Minute).Return(erasure, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("ProcessMessages", ctx, erasure, 2, time.Minute).Return([]string{"m-1", "m-2"}, nil).Once()
+		repo.On("ProcessMessages", ctx, erasure, 2, time.Minute).Return([]string{"m-3"}, nil).Once()
+		repo.On("MarkCachePurged", ctx, int64(3)).Return(nil)
+		repo.On("Complete", ctx, erasure).Return(nil)
+
+		completed, err := svc.RunPending(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		assert.Empty(t, server.Keys())
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("attachment blobs are removed before the rows", func(t *testing.T) {
+		repo := new(MockErasureRepository)
+		svc := NewErasureService(repo, NewUserService(new(MockUserRepository), nil), nil, 2, time.Minute)
+		attachments := new(MockAttachmentRepository)
+		blobs, err := blobstore.NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+		svc.SetBlobEraser(NewAttachmentService(attachments, nil, nil, blobs, 64, nil))
+
+		key := BlobKey(testBlobDigest)
+		require.NoError(t, blobs.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"))
+
+		erasure := &repository.UserErasure{ID: 3, UserID: "alice", MessageMode: repository.ErasureDelete, CachePurged: true}
+		repo.On("ClaimNext", ctx, time.Minute).Return(erasure, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("ProcessMessages", ctx, erasure, 2, time.Minute).Return([]string{}, nil)
+		attachments.On("ListByUploader", ctx, "alice").
+			Return([]*repository.Attachment{{ID: "a1", UploaderID: "alice", SHA256: testBlobDigest}}, nil)
+		attachments.On("DeleteBlob", ctx, testBlobDigest, []string{"a1"}).Return(true, nil)
+		repo.On("Complete", ctx, erasure).Return(nil).Run(func(mock.Arguments) {
+			exists, err := blobs.Exists(ctx, key)
+			require.NoError(t, err)
+			assert.False(t, exists, "the blob must be gone before Complete drops the attachment rows")
+		})
+
+		completed, err := svc.RunPending(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		attachments.AssertExpectations(t)
+	})
+
+	t.Run("resumed job skips the finished cache purge", func(t *testing.T) {
+		repo := new(MockErasureRepository)
+		svc := NewErasureService(repo, NewUserService(new(MockUserRepository), nil), nil, 2, time.Minute)
+
+		erasure := &repository.UserErasure{ID: 3, UserID: "alice", MessageMode: repository.ErasureDelete, CachePurged: true}
+		repo.On("ClaimNext", ctx, time.Minute).Return(erasure, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("ProcessMessages", ctx, erasure, 2, time.Minute).Return([]string{}, nil)
+		repo.On("Complete", ctx, erasure).Return(nil)
+
+		completed, err := svc.RunPending(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		repo.AssertNotCalled(t, "MarkCachePurged", mock.Anything, mock.Anything)
+	})
+
+	t.Run("failure is recorded and left for retry", func(t *testing.T) {
+		repo := new(MockErasureRepository)
+		svc := NewErasureService(repo, NewUserService(new(MockUserRepository), nil), nil, 2, time.Minute)
+
+		erasure := &repository.UserErasure{ID: 3, UserID: "alice", MessageMode: repository.ErasureDelete}
+		repo.On("ClaimNext", ctx, time.Minute).Return(erasure, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("ProcessMessages", ctx, erasure, 2, time.Minute).Return(nil, fmt.Errorf("connection reset"))
+		repo.On("RecordFailure", mock.Anything, int64(3), "connection reset").Return(nil)
+
+		completed, err := svc.RunPending(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, completed)
+		repo.AssertNotCalled(t, "Complete", mock.Anything, mock.Anything)
+		repo.AssertExpectations(t)
+	})
+}
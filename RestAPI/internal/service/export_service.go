@@ -0,0 +1,698 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/blobstore"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/encryption"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+const (
+	// exportBatchSize is how many records are read per query; the lease is renewed after each message batch
+	exportBatchSize = 500
+	// exportMaxAttempts is how many times an export is tried before it is marked failed
+	exportMaxAttempts = 3
+	// exportSweepLimit is how many expired archives are removed per sweep
+	exportSweepLimit = 100
+)
+
+// ExportSources are the repositories a data export reads from.
+// Users 와 Messages 외에는 nil 이어도 된다 — 해당 파일은 빈 배열로 남는다. Blobs 가 없으면 첨부는 메타데이터만 내보낸다.
+type ExportSources struct {
+	Users       repository.UserRepository
+	Messages    repository.MessageRepository
+	APIKeys     repository.APIKeyRepository
+	Audit       repository.AuditRepository
+	Rooms       repository.RoomRepository
+	Receipts    repository.ReceiptRepository
+	Reactions   repository.ReactionRepository
+	Attachments repository.AttachmentRepository
+	Blobs       blobstore.Store
+	Moderation  repository.ModerationRepository
+}
+
+// ExportService handles per-user data export requests and runs the export worker.
+// 요청은 작업을 기록만 하고, 워커가 사용자 데이터를 JSON 파일로 묶은 zip 을 directory 에 쓴다.
+// 압축 파일은 ttl 동안만 내려받을 수 있고 이후 정리 때 지워진다.
+type ExportService struct {
+	repo    repository.ExportRepository
+	sources ExportSources
+	cipher  *encryption.MessageCipher
+	dir     string
+	ttl     time.Duration
+	lease   time.Duration
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewExportService creates a new export service
+func NewExportService(
+	repo repository.ExportRepository,
+	sources ExportSources,
+	dir string,
+	ttl time.Duration,
+	lease time.Duration,
+) *ExportService {
+	return &ExportService{
+		repo:    repo,
+		sources: sources,
+		dir:     dir,
+		ttl:     ttl,
+		lease:   lease,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// SetCipher decrypts encrypted messages in exports.
+// 설정하지 않으면 암호화된 메시지는 저장된 암호문 그대로 내보내고 manifest 에 그 수를 남긴다.
+func (s *ExportService) SetCipher(cipher *encryption.MessageCipher) {
+	s.cipher = cipher
+}
+
+// RequestExport queues a data export of the user
+func (s *ExportService) RequestExport(ctx context.Context, userID, requestedBy string) (*repository.UserExport, error) {
+	export := &repository.UserExport{
+		UserID:      userID,
+		RequestedBy: requestedBy,
+	}
+	if err := s.repo.Create(ctx, export); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "User not found", 404)
+		case errors.Is(err, repository.ErrExportInProgress):
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeConflict, "Export already in progress", 409)
+		}
+		logger.Errorf("Failed to create export: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to request export", 500)
+	}
+
+	logger.Infof("Export requested: %s (%s, by %s)", userID, export.ID, requestedBy)
+	s.notify()
+	return export, nil
+}
+
+// GetExport retrieves an export of the user
+func (s *ExportService) GetExport(ctx context.Context, userID, exportID string) (*repository.UserExport, error) {
+	// 형식이 틀린 ID 는 PostgreSQL 이 UUID 변환 오류를 내므로 조회 전에 404 로 끊는다.
+	if _, err := uuid.Parse(exportID); err != nil {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "Export not found", 404)
+	}
+
+	export, err := s.repo.Get(ctx, userID, exportID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Export not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get export: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get export", 500)
+	}
+	return export, nil
+}
+
+// OpenExport returns a downloadable export and the path of its archive
+func (s *ExportService) OpenExport(ctx context.Context, userID, exportID string) (*repository.UserExport, string, error) {
+	export, err := s.GetExport(ctx, userID, exportID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case export.Status == repository.ExportPending || export.Status == repository.ExportRunning:
+		return nil, "", apperrors.New(apperrors.ErrCodeConflict, "Export is not ready yet", 409)
+	case export.Status == repository.ExportFailed:
+		return nil, "", apperrors.New(apperrors.ErrCodeInvalidOperation, "Export failed", 409)
+	case !export.Downloadable(time.Now()):
+		return nil, "", apperrors.New(apperrors.ErrCodeNotFound, "Export has expired", 410)
+	}
+
+	path := filepath.Join(s.dir, export.FileName)
+	if _, err := os.Stat(path); err != nil {
+		logger.Errorf("Export archive missing (%s): %v", export.ID, err)
+		return nil, "", apperrors.New(apperrors.ErrCodeNotFound, "Export archive not found", 404)
+	}
+	return export, path, nil
+}
+
+// RunPending processes exports until none is left to claim and returns how many completed
+func (s *ExportService) RunPending(ctx context.Context) (int, error) {
+	completed := 0
+	for {
+		export, err := s.repo.ClaimNext(ctx, s.lease)
+		if err != nil || export == nil {
+			return completed, err
+		}
+
+		if err := s.process(ctx, export); err != nil {
+			s.fail(ctx, export, err)
+			if ctx.Err() != nil {
+				return completed, ctx.Err()
+			}
+			continue
+		}
+		completed++
+	}
+}
+
+// SweepExpired removes archives whose download window has passed and returns how many were removed
+func (s *ExportService) SweepExpired(ctx context.Context) (int, error) {
+	exports, err := s.repo.ListExpired(ctx, exportSweepLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, export := range exports {
+		if export.FileName != "" {
+			err := os.Remove(filepath.Join(s.dir, export.FileName))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				logger.Warnf("Failed to remove expired export (%s): %v", export.ID, err)
+				continue
+			}
+		}
+		if err := s.repo.MarkExpired(ctx, export.ID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Start runs pending exports and removes expired archives every interval, and runs exports right after each
+// request, until Close is called
+func (s *ExportService) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		sweep := true
+		for {
+			if n, err := s.RunPending(ctx); err != nil && ctx.Err() == nil {
+				logger.Warnf("Export worker failed: %v", err)
+			} else if n > 0 {
+				logger.Infof("Export worker: %d export(s) completed", n)
+			}
+
+			if sweep {
+				if n, err := s.SweepExpired(ctx); err != nil && ctx.Err() == nil {
+					logger.Warnf("Export sweep failed: %v", err)
+				} else if n > 0 {
+					logger.Infof("Export sweep: %d expired archive(s) removed", n)
+				}
+			}
+
+			select {
+			case <-ticker.C:
+				sweep = true
+			case <-s.wake:
+				sweep = false
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the worker; an export in progress is released and rebuilt on the next start
+func (s *ExportService) Close() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+		s.cancel = nil
+	}
+}
+
+// process writes the archive to a temporary file, moves it into place and completes the export.
+// 임시 파일에 다 쓴 뒤 rename 하므로 다운로드 경로에는 완성된 파일만 나타난다.
+func (s *ExportService) process(ctx context.Context, export *repository.UserExport) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+
+	fileName := export.ID + ".zip"
+	tmpPath := filepath.Join(s.dir, fileName+".tmp")
+
+	size, err := s.writeArchive(ctx, export, tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(s.dir, fileName)); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	export.FileName = fileName
+	export.SizeBytes = size
+	if err := s.repo.Complete(ctx, export, s.ttl); err != nil {
+		return err
+	}
+
+	logger.Infof("Export completed: %s (%s, %d bytes)", export.UserID, export.ID, size)
+	return nil
+}
+
+// fail records the error, or releases the lease when the worker is shutting down
+func (s *ExportService) fail(ctx context.Context, export *repository.UserExport, cause error) {
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 3*time.Second)
+	defer cancel()
+
+	if ctx.Err() != nil {
+		if err := s.repo.Release(recordCtx, export.ID); err != nil {
+			logger.Warnf("Failed to release export (%s): %v", export.ID, err)
+		}
+		return
+	}
+
+	final := export.Attempts >= exportMaxAttempts
+	if final {
+		logger.Errorf("Export failed (%s, %s), giving up after %d attempts: %v", export.UserID, export.ID, export.Attempts, cause)
+	} else {
+		logger.Errorf("Export failed (%s, %s), retrying after the lease expires: %v", export.UserID, export.ID, cause)
+	}
+	if err := s.repo.RecordFailure(recordCtx, export.ID, cause.Error(), final); err != nil {
+		logger.Warnf("Failed to record export failure (%s): %v", export.ID, err)
+	}
+}
+
+// exportManifest describes the archive. Files maps each file to its record count;
+// EncryptedMessages counts messages whose content is still the stored ciphertext,
+// MissingAttachments attachments whose content is not in the archive.
+type exportManifest struct {
+	ExportID           string         `json:"export_id"`
+	UserID             string         `json:"user_id"`
+	RequestedAt        time.Time      `json:"requested_at"`
+	GeneratedAt        time.Time      `json:"generated_at"`
+	Files              map[string]int `json:"files"`
+	EncryptedMessages  int            `json:"encrypted_messages"`
+	MissingAttachments int            `json:"missing_attachments"`
+	Notes              []string       `json:"notes,omitempty"`
+}
+
+// exportedAttachment is an attachment as written to attachments.json; File is its content in the archive
+type exportedAttachment struct {
+	*repository.Attachment
+	File string `json:"file,omitempty"`
+}
+
+// exportedMessage is a message as written to messages.json; Decrypted marks content that was decrypted for the export
+type exportedMessage struct {
+	*repository.Message
+	Decrypted bool `json:"decrypted,omitempty"`
+}
+
+// writeArchive writes the user's data to a zip file at path and returns its size
+func (s *ExportService) writeArchive(ctx context.Context, export *repository.UserExport, path string) (int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	manifest := exportManifest{
+		ExportID:    export.ID,
+		UserID:      export.UserID,
+		RequestedAt: export.RequestedAt,
+		GeneratedAt: time.Now().UTC(),
+		Files:       map[string]int{},
+	}
+
+	user, err := s.sources.Users.GetByUserID(ctx, export.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if err := writeJSONFile(zw, "profile.json", user); err != nil {
+		return 0, err
+	}
+	manifest.Files["profile.json"] = 1
+
+	steps := []struct {
+		name  string
+		write func(context.Context, *jsonArrayWriter, *exportManifest) error
+	}{
+		{"messages.json", s.writeMessages},
+		{"room_members.json", s.writeRoomMembers},
+		{"message_receipts.json", s.writeReceipts},
+		{"message_reactions.json", s.writeReactions},
+		{"moderation_flags.json", s.writeModerationFlags},
+		{"api_keys.json", s.writeAPIKeys},
+		{"audit_log.json", s.writeAuditLog},
+	}
+	for _, step := range steps {
+		array, err := newJSONArrayWriter(zw, step.name)
+		if err != nil {
+			return 0, err
+		}
+		if err := step.write(ctx, array, &manifest); err != nil {
+			return 0, err
+		}
+		if err := array.Close(); err != nil {
+			return 0, err
+		}
+		manifest.Files[step.name] = array.count
+	}
+
+	// 첨부 내용은 파일마다 따로 쓰므로 배열 파일과 함께 스트리밍할 수 없다 — zip 은 한 번에 한 파일만 쓴다.
+	if err := s.writeAttachments(ctx, zw, &manifest); err != nil {
+		return 0, err
+	}
+
+	if manifest.EncryptedMessages > 0 {
+		manifest.Notes = append(manifest.Notes,
+			"Messages with is_encrypted=true and no decrypted flag contain the stored ciphertext (AES-256-CBC, base64).")
+	}
+	if manifest.MissingAttachments > 0 {
+		manifest.Notes = append(manifest.Notes,
+			"Attachments without a file field are listed with their metadata only; their content could not be read.")
+	}
+	if err := writeJSONFile(zw, "manifest.json", manifest); err != nil {
+		return 0, err
+	}
+
+	if err := zw.Close(); err != nil {
+		return 0, err
+	}
+	if err := file.Sync(); err != nil {
+		return 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeMessages writes every message of the user, decrypting encrypted content when a cipher is set
+func (s *ExportService) writeMessages(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	var afterID int64
+	for {
+		messages, err := s.sources.Messages.ListByUserAfter(ctx, manifest.UserID, afterID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			record := exportedMessage{Message: message}
+			if message.IsEncrypted {
+				record.Decrypted = s.decrypt(message)
+				if !record.Decrypted {
+					manifest.EncryptedMessages++
+				}
+			}
+			if err := array.Add(record); err != nil {
+				return err
+			}
+			afterID = message.ID
+		}
+
+		if len(messages) < exportBatchSize {
+			return nil
+		}
+		// 메시지가 많으면 lease 보다 오래 걸릴 수 있다 — 다른 워커가 같은 작업을 집지 않도록 연장한다.
+		if err := s.repo.ExtendLease(ctx, manifest.ExportID, s.lease); err != nil {
+			return err
+		}
+	}
+}
+
+// decrypt replaces the message content with its plaintext and reports whether it succeeded
+func (s *ExportService) decrypt(message *repository.Message) bool {
+	if s.cipher == nil {
+		return false
+	}
+
+	plain, err := s.cipher.Decrypt(message.Content)
+	if err != nil {
+		logger.Warnf("Export: message %s could not be decrypted: %v", message.MessageID, err)
+		return false
+	}
+	message.Content = plain
+	return true
+}
+
+// writeRoomMembers writes the user's room memberships and roles
+func (s *ExportService) writeRoomMembers(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	if s.sources.Rooms == nil {
+		return nil
+	}
+	return writePages(array, func(offset int) ([]*repository.RoomMember, error) {
+		return s.sources.Rooms.ListMemberships(ctx, manifest.UserID, exportBatchSize, offset)
+	})
+}
+
+// writeReceipts writes the delivery and read receipts the user acknowledged
+func (s *ExportService) writeReceipts(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	if s.sources.Receipts == nil {
+		return nil
+	}
+	return writePages(array, func(offset int) ([]*repository.MessageReceipt, error) {
+		return s.sources.Receipts.ListByUser(ctx, manifest.UserID, exportBatchSize, offset)
+	})
+}
+
+// writeReactions writes the user's emoji reactions
+func (s *ExportService) writeReactions(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	if s.sources.Reactions == nil {
+		return nil
+	}
+	return writePages(array, func(offset int) ([]*repository.MessageReaction, error) {
+		return s.sources.Reactions.ListByUser(ctx, manifest.UserID, exportBatchSize, offset)
+	})
+}
+
+// writeModerationFlags writes the review queue entries of the user's messages, with the flagged content
+func (s *ExportService) writeModerationFlags(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	if s.sources.Moderation == nil {
+		return nil
+	}
+	return writePages(array, func(offset int) ([]*repository.ModerationFlag, error) {
+		return s.sources.Moderation.ListByUser(ctx, manifest.UserID, exportBatchSize, offset)
+	})
+}
+
+// writeAttachments writes the content of each of the user's uploads under attachments/<id>/<file name>,
+// then their metadata to attachments.json. 내용을 읽지 못한 첨부는 메타데이터만 남기고 manifest 에 센다.
+func (s *ExportService) writeAttachments(ctx context.Context, zw *zip.Writer, manifest *exportManifest) error {
+	const name = "attachments.json"
+
+	var attachments []*repository.Attachment
+	if s.sources.Attachments != nil {
+		var err error
+		if attachments, err = s.sources.Attachments.ListByUploader(ctx, manifest.UserID); err != nil {
+			return err
+		}
+	}
+
+	records := make([]exportedAttachment, 0, len(attachments))
+	for _, attachment := range attachments {
+		record := exportedAttachment{Attachment: attachment}
+		file, err := s.writeAttachmentContent(ctx, zw, attachment)
+		if err != nil {
+			return err
+		}
+		if file == "" {
+			manifest.MissingAttachments++
+		}
+		record.File = file
+		records = append(records, record)
+	}
+
+	if err := writeJSONFile(zw, name, records); err != nil {
+		return err
+	}
+	manifest.Files[name] = len(records)
+	return nil
+}
+
+// writeAttachmentContent copies an attachment's blob into the archive and returns its path there, or "" when
+// the blob cannot be read. 저장소 오류로 내보내기 전체를 실패시키지 않는다 — 쓰기 오류만 돌려준다.
+func (s *ExportService) writeAttachmentContent(ctx context.Context, zw *zip.Writer, attachment *repository.Attachment) (string, error) {
+	if s.sources.Blobs == nil {
+		return "", nil
+	}
+
+	content, err := s.sources.Blobs.Open(ctx, BlobKey(attachment.SHA256))
+	if err != nil {
+		logger.Warnf("Export: content of attachment %s could not be read: %v", attachment.ID, err)
+		return "", nil
+	}
+	defer content.Close()
+
+	file := path.Join("attachments", attachment.ID, attachment.FileName)
+	w, err := zw.Create(file)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		return "", fmt.Errorf("copy attachment %s: %w", attachment.ID, err)
+	}
+	return file, nil
+}
+
+// writeAPIKeys writes the API keys owned by the user; secret hashes are never serialized
+func (s *ExportService) writeAPIKeys(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	if s.sources.APIKeys == nil {
+		return nil
+	}
+
+	for offset := 0; ; offset += exportBatchSize {
+		keys, err := s.sources.APIKeys.List(ctx, manifest.UserID, exportBatchSize, offset)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := array.Add(key); err != nil {
+				return err
+			}
+		}
+		if len(keys) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// writeAuditLog writes audit entries the user performed or that targeted the user.
+// 작업 시작 시각으로 상한을 고정해 도중에 쌓이는 기록 때문에 OFFSET 이 밀리지 않게 한다.
+func (s *ExportService) writeAuditLog(ctx context.Context, array *jsonArrayWriter, manifest *exportManifest) error {
+	if s.sources.Audit == nil {
+		return nil
+	}
+
+	until := manifest.GeneratedAt
+	filters := []repository.AuditFilter{
+		{ActorID: manifest.UserID, Until: &until},
+		{TargetType: "user", TargetID: manifest.UserID, Until: &until},
+	}
+	for i, filter := range filters {
+		for offset := 0; ; offset += exportBatchSize {
+			entries, err := s.sources.Audit.List(ctx, filter, exportBatchSize, offset)
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				// 자기 자신을 대상으로 한 작업은 첫 번째 조회에 이미 들어 있다.
+				if i > 0 && entry.ActorID == manifest.UserID {
+					continue
+				}
+				if err := array.Add(entry); err != nil {
+					return err
+				}
+			}
+			if len(entries) < exportBatchSize {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// writePages adds the records of each page returned by list, starting at offset 0, until a page is short
+func writePages[T any](array *jsonArrayWriter, list func(offset int) ([]T, error)) error {
+	for offset := 0; ; offset += exportBatchSize {
+		records, err := list(offset)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := array.Add(record); err != nil {
+				return err
+			}
+		}
+		if len(records) < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+// notify wakes the worker without blocking
+func (s *ExportService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writeJSONFile writes v as an indented JSON file into the archive
+func writeJSONFile(zw *zip.Writer, name string, v interface{}) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// jsonArrayWriter streams a JSON array into an archive file so a long history is never held in memory
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+// newJSONArrayWriter starts a JSON array file in the archive
+func newJSONArrayWriter(zw *zip.Writer, name string) (*jsonArrayWriter, error) {
+	w, err := zw.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonArrayWriter{w: w}, nil
+}
+
+// Add appends one element
+func (a *jsonArrayWriter) Add(v interface{}) error {
+	data, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export record: %w", err)
+	}
+
+	sep := "\n  "
+	if a.count > 0 {
+		sep = ",\n  "
+	}
+	if _, err := io.WriteString(a.w, sep); err != nil {
+		return err
+	}
+	if _, err := a.w.Write(data); err != nil {
+		return err
+	}
+	a.count++
+	return nil
+}
+
+// Close ends the array
+func (a *jsonArrayWriter) Close() error {
+	end := "]\n"
+	if a.count > 0 {
+		end = "\n]\n"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
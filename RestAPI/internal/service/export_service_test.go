@@ -0,0 +1,568 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/blobstore"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testExportID = "5b8f0f5e-3c1a-4d8e-9f7a-2f6b1c0d9e11"
+
+// MockExportRepository is a mock implementation of ExportRepository
+type MockExportRepository struct {
+	mock.Mock
+}
+
+func (m *MockExportRepository) Create(ctx context.Context, export *repository.UserExport) error {
+	args := m.Called(ctx, export)
+	if args.Error(0) == nil {
+		export.ID = testExportID
+		export.Status = repository.ExportPending
+	}
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Get(ctx context.Context, userID, exportID string) (*repository.UserExport, error) {
+	args := m.Called(ctx, userID, exportID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.UserExport), args.Error(1)
+}
+
+func (m *MockExportRepository) ClaimNext(ctx context.Context, lease time.Duration) (*repository.UserExport, error) {
+	args := m.Called(ctx, lease)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.UserExport), args.Error(1)
+}
+
+func (m *MockExportRepository) ExtendLease(ctx context.Context, id string, lease time.Duration) error {
+	args := m.Called(ctx, id, lease)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Complete(ctx context.Context, export *repository.UserExport, ttl time.Duration) error {
+	args := m.Called(ctx, export, ttl)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) RecordFailure(ctx context.Context, id, message string, final bool) error {
+	args := m.Called(ctx, id, message, final)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) Release(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockExportRepository) ListExpired(ctx context.Context, limit int) ([]*repository.UserExport, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*repository.UserExport), args.Error(1)
+}
+
+func (m *MockExportRepository) MarkExpired(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockMessageRepository is a mock implementation of MessageRepository
+type MockMessageRepository struct {
+	mock.Mock
+}
+
+func (m *MockMessageRepository) Create(ctx context.Context, message *repository.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) GetByMessageID(ctx context.Context, messageID string) (*repository.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) GetByID(ctx context.Context, id int64) (*repository.Message, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByRequestID(ctx context.Context, requestID string, limit int) ([]*repository.Message, error) {
+	args := m.Called(ctx, requestID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) UpdateStatus(ctx context.Context, messageID string, status string) (string, error) {
+	args := m.Called(ctx, messageID, status)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockMessageRepository) MarkAsProcessed(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) ListReplies(ctx context.Context, rootID string, limit, offset int) ([]*repository.Message, error) {
+	args := m.Called(ctx, rootID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountReplies(ctx context.Context, rootID string) (int64, error) {
+	args := m.Called(ctx, rootID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ThreadSummaries(ctx context.Context, rootIDs []string) (map[string]*repository.ThreadSummary, error) {
+	args := m.Called(ctx, rootIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*repository.ThreadSummary), args.Error(1)
+}
+
+func (m *MockMessageRepository) RecallReplies(ctx context.Context, rootID, recalledBy string) ([]*repository.Message, error) {
+	args := m.Called(ctx, rootID, recalledBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) Recall(ctx context.Context, messageID, recalledBy string) (*repository.Message, bool, error) {
+	args := m.Called(ctx, messageID, recalledBy)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*repository.Message), args.Bool(1), args.Error(2)
+}
+
+func (m *MockMessageRepository) UpdateContent(ctx context.Context, messageID, content string, isEncrypted bool) (*repository.Message, error) {
+	args := m.Called(ctx, messageID, content, isEncrypted)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListRevisions(ctx context.Context, messageID string, limit, offset int) ([]*repository.MessageRevision, error) {
+	args := m.Called(ctx, messageID, limit, offset)
+	return args.Get(0).([]*repository.MessageRevision), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountRevisions(ctx context.Context, messageID string) (int64, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*repository.Message, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByUserAfter(ctx context.Context, userID string, afterID int64, limit int) ([]*repository.Message, error) {
+	args := m.Called(ctx, userID, afterID, limit)
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByRoom(ctx context.Context, roomID string, limit, offset int) ([]*repository.Message, error) {
+	args := m.Called(ctx, roomID, limit, offset)
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*repository.Message, error) {
+	args := m.Called(ctx, status, limit, offset)
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListRecent(ctx context.Context, limit, offset int) ([]*repository.Message, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) Delete(ctx context.Context, messageID string) error {
+	args := m.Called(ctx, messageID)
+	return args.Error(0)
+}
+
+func (m *MockMessageRepository) Count(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountByUser(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountByRoom(ctx context.Context, roomID string) (int64, error) {
+	args := m.Called(ctx, roomID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListConversations(ctx context.Context, userID string, limit, offset int) ([]*repository.Conversation, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]*repository.Conversation), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountConversations(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) ListThread(ctx context.Context, userID, peerID string, limit, offset int) ([]*repository.Message, error) {
+	args := m.Called(ctx, userID, peerID, limit, offset)
+	return args.Get(0).([]*repository.Message), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountThread(ctx context.Context, userID, peerID string) (int64, error) {
+	args := m.Called(ctx, userID, peerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMessageRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// readArchive returns the files of a zip archive by name
+func readArchive(t *testing.T, path string) map[string][]byte {
+	t.Helper()
+
+	reader, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	files := map[string][]byte{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		files[f.Name] = data
+	}
+	return files
+}
+
+func TestExportService_RequestExport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("queues", func(t *testing.T) {
+		repo := new(MockExportRepository)
+		svc := NewExportService(repo, ExportSources{}, t.TempDir(), time.Hour, time.Minute)
+
+		repo.On("Create", ctx, mock.MatchedBy(func(e *repository.UserExport) bool {
+			return e.UserID == "alice" && e.RequestedBy == "alice"
+		})).Return(nil)
+
+		export, err := svc.RequestExport(ctx, "alice", "alice")
+
+		require.NoError(t, err)
+		assert.Equal(t, testExportID, export.ID)
+		assert.Len(t, svc.wake, 1)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		repo := new(MockExportRepository)
+		svc := NewExportService(repo, ExportSources{}, t.TempDir(), time.Hour, time.Minute)
+
+		repo.On("Create", ctx, mock.Anything).Return(fmt.Errorf("user not found: ghost: %w", sql.ErrNoRows))
+
+		_, err := svc.RequestExport(ctx, "ghost", "root")
+
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("already in progress", func(t *testing.T) {
+		repo := new(MockExportRepository)
+		svc := NewExportService(repo, ExportSources{}, t.TempDir(), time.Hour, time.Minute)
+
+		repo.On("Create", ctx, mock.Anything).Return(fmt.Errorf("alice: %w", repository.ErrExportInProgress))
+
+		_, err := svc.RequestExport(ctx, "alice", "alice")
+
+		assertStatus(t, err, 409)
+	})
+}
+
+func TestExportService_OpenExport(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := new(MockExportRepository)
+	svc := NewExportService(repo, ExportSources{}, dir, time.Hour, time.Minute)
+
+	future := sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}
+	past := sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true}
+
+	t.Run("malformed id", func(t *testing.T) {
+		_, _, err := svc.OpenExport(ctx, "alice", "../../etc/passwd")
+
+		assertStatus(t, err, 404)
+		repo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	tests := []struct {
+		name   string
+		export *repository.UserExport
+		status int
+	}{
+		{"not ready", &repository.UserExport{Status: repository.ExportRunning}, 409},
+		{"expired", &repository.UserExport{Status: repository.ExportCompleted, FileName: "a.zip", ExpiresAt: past}, 410},
+		{"swept", &repository.UserExport{Status: repository.ExportExpired, ExpiresAt: past}, 410},
+		{"file missing", &repository.UserExport{Status: repository.ExportCompleted, FileName: "missing.zip", ExpiresAt: future}, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo.ExpectedCalls = nil
+			repo.On("Get", ctx, "alice", testExportID).Return(tt.export, nil)
+
+			_, _, err := svc.OpenExport(ctx, "alice", testExportID)
+
+			assertStatus(t, err, tt.status)
+		})
+	}
+
+	t.Run("downloadable", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, testExportID+".zip"), []byte("zip"), 0o600))
+		repo.ExpectedCalls = nil
+		repo.On("Get", ctx, "alice", testExportID).
+			Return(&repository.UserExport{ID: testExportID, Status: repository.ExportCompleted, FileName: testExportID + ".zip", ExpiresAt: future}, nil)
+
+		_, path, err := svc.OpenExport(ctx, "alice", testExportID)
+
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(dir, testExportID+".zip"), path)
+	})
+}
+
+func TestExportService_RunPending(t *testing.T) {
+	ctx := context.Background()
+	cipher, err := encryption.NewMessageCipher(
+		base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))),
+		base64.StdEncoding.EncodeToString([]byte(strings.Repeat("v", 16))),
+	)
+	require.NoError(t, err)
+
+	setup := func(t *testing.T) (*ExportService, *MockExportRepository, *MockUserRepository, *MockMessageRepository, *MockAuditRepository) {
+		repo := new(MockExportRepository)
+		userRepo := new(MockUserRepository)
+		messageRepo := new(MockMessageRepository)
+		apiKeyRepo := new(MockAPIKeyRepository)
+		auditRepo := new(MockAuditRepository)
+
+		apiKeyRepo.On("List", mock.Anything, "alice", exportBatchSize, 0).
+			Return([]*repository.APIKey{{KeyID: "k-1", OwnerUserID: "alice", SecretHash: "secret"}}, nil)
+
+		svc := NewExportService(repo, ExportSources{
+			Users: userRepo, Messages: messageRepo, APIKeys: apiKeyRepo, Audit: auditRepo,
+		}, t.TempDir(), 24*time.Hour, time.Minute)
+		return svc, repo, userRepo, messageRepo, auditRepo
+	}
+
+	messages := func() []*repository.Message {
+		return []*repository.Message{
+			{ID: 1, MessageID: "m-1", UserID: "alice", Content: "plain"},
+			{ID: 2, MessageID: "m-2", UserID: "alice", Content: cipher.Encrypt("secret"), IsEncrypted: true},
+		}
+	}
+
+	t.Run("writes the archive and completes", func(t *testing.T) {
+		svc, repo, userRepo, messageRepo, auditRepo := setup(t)
+		svc.SetCipher(cipher)
+
+		export := &repository.UserExport{ID: testExportID, UserID: "alice", Attempts: 1}
+		repo.On("ClaimNext", ctx, time.Minute).Return(export, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("Complete", ctx, export, 24*time.Hour).Return(nil)
+
+		userRepo.On("GetByUserID", mock.Anything, "alice").Return(&repository.User{UserID: "alice"}, nil)
+		messageRepo.On("ListByUserAfter", mock.Anything, "alice", int64(0), exportBatchSize).Return(messages(), nil)
+		auditRepo.On("List", mock.Anything, mock.MatchedBy(func(f repository.AuditFilter) bool { return f.ActorID == "alice" }), exportBatchSize, 0).
+			Return([]*repository.AuditLog{{ID: 1, ActorID: "alice", TargetID: "alice"}}, nil)
+		auditRepo.On("List", mock.Anything, mock.MatchedBy(func(f repository.AuditFilter) bool { return f.TargetID == "alice" }), exportBatchSize, 0).
+			Return([]*repository.AuditLog{{ID: 1, ActorID: "alice", TargetID: "alice"}, {ID: 2, ActorID: "root", TargetID: "alice"}}, nil)
+
+		completed, err := svc.RunPending(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, completed)
+		assert.Equal(t, testExportID+".zip", export.FileName)
+		assert.NoFileExists(t, filepath.Join(svc.dir, testExportID+".zip.tmp"))
+
+		files := readArchive(t, filepath.Join(svc.dir, export.FileName))
+		assert.ElementsMatch(t, []string{
+			"manifest.json", "profile.json", "messages.json", "room_members.json", "message_receipts.json",
+			"message_reactions.json", "moderation_flags.json", "attachments.json", "api_keys.json", "audit_log.json",
+		}, slices.Collect(maps.Keys(files)))
+
+		var exported []map[string]interface{}
+		require.NoError(t, json.Unmarshal(files["messages.json"], &exported))
+		require.Len(t, exported, 2)
+		assert.Equal(t, "secret", exported[1]["content"])
+		assert.Equal(t, true, exported[1]["decrypted"])
+
+		assert.NotContains(t, string(files["api_keys.json"]), "secret")
+
+		var manifest exportManifest
+		require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+		assert.Equal(t, 2, manifest.Files["messages.json"])
+		assert.Equal(t, 2, manifest.Files["audit_log.json"])
+		assert.Zero(t, manifest.EncryptedMessages)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("without a key encrypted messages stay encrypted", func(t *testing.T) {
+		svc, repo, userRepo, messageRepo, auditRepo := setup(t)
+
+		export := &repository.UserExport{ID: testExportID, UserID: "alice", Attempts: 1}
+		repo.On("ClaimNext", ctx, time.Minute).Return(export, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("Complete", ctx, export, 24*time.Hour).Return(nil)
+
+		userRepo.On("GetByUserID", mock.Anything, "alice").Return(&repository.User{UserID: "alice"}, nil)
+		messageRepo.On("ListByUserAfter", mock.Anything, "alice", int64(0), exportBatchSize).Return(messages(), nil)
+		auditRepo.On("List", mock.Anything, mock.Anything, exportBatchSize, 0).Return([]*repository.AuditLog{}, nil)
+
+		_, err := svc.RunPending(ctx)
+		require.NoError(t, err)
+
+		files := readArchive(t, filepath.Join(svc.dir, export.FileName))
+		var manifest exportManifest
+		require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+		assert.Equal(t, 1, manifest.EncryptedMessages)
+		assert.NotEmpty(t, manifest.Notes)
+		assert.Equal(t, "[]\n", string(files["audit_log.json"]))
+	})
+
+	t.Run("includes rooms, receipts, reactions, flags and attachment content", func(t *testing.T) {
+		svc, repo, userRepo, messageRepo, auditRepo := setup(t)
+
+		rooms, receipts, reactions := new(MockRoomRepository), new(MockReceiptRepository), new(MockReactionRepository)
+		attachments, flags := new(MockAttachmentRepository), new(MockModerationRepository)
+		blobs, err := blobstore.NewLocalStore(t.TempDir())
+		require.NoError(t, err)
+		svc.sources.Rooms, svc.sources.Receipts, svc.sources.Reactions = rooms, receipts, reactions
+		svc.sources.Attachments, svc.sources.Blobs, svc.sources.Moderation = attachments, blobs, flags
+
+		require.NoError(t, blobs.Put(ctx, BlobKey(testBlobDigest), strings.NewReader("hello"), 5, "text/plain"))
+		missing := strings.Repeat("0", 64)
+
+		export := &repository.UserExport{ID: testExportID, UserID: "alice", Attempts: 1}
+		repo.On("ClaimNext", ctx, time.Minute).Return(export, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("Complete", ctx, export, 24*time.Hour).Return(nil)
+
+		userRepo.On("GetByUserID", mock.Anything, "alice").Return(&repository.User{UserID: "alice"}, nil)
+		messageRepo.On("ListByUserAfter", mock.Anything, "alice", int64(0), exportBatchSize).Return([]*repository.Message{}, nil)
+		auditRepo.On("List", mock.Anything, mock.Anything, exportBatchSize, 0).Return([]*repository.AuditLog{}, nil)
+		rooms.On("ListMemberships", mock.Anything, "alice", exportBatchSize, 0).
+			Return([]*repository.RoomMember{{RoomID: testRoomID, UserID: "alice", Role: repository.RoomRoleOwner}}, nil)
+		receipts.On("ListByUser", mock.Anything, "alice", exportBatchSize, 0).
+			Return([]*repository.MessageReceipt{{MessageID: "m-9", UserID: "alice"}}, nil)
+		reactions.On("ListByUser", mock.Anything, "alice", exportBatchSize, 0).
+			Return([]*repository.MessageReaction{{MessageID: "m-9", UserID: "alice", Emoji: "👍"}}, nil)
+		flags.On("ListByUser", mock.Anything, "alice", exportBatchSize, 0).
+			Return([]*repository.ModerationFlag{{ID: "f-1", UserID: "alice", Content: "flagged words"}}, nil)
+		attachments.On("ListByUploader", mock.Anything, "alice").Return([]*repository.Attachment{
+			{ID: "a-1", UploaderID: "alice", FileName: "notes.txt", SHA256: testBlobDigest},
+			{ID: "a-2", UploaderID: "alice", FileName: "lost.png", SHA256: missing},
+		}, nil)
+
+		_, err = svc.RunPending(ctx)
+		require.NoError(t, err)
+
+		files := readArchive(t, filepath.Join(svc.dir, export.FileName))
+		assert.Equal(t, "hello", string(files["attachments/a-1/notes.txt"]))
+		assert.Contains(t, string(files["room_members.json"]), testRoomID)
+		assert.Contains(t, string(files["message_receipts.json"]), "m-9")
+		assert.Contains(t, string(files["message_reactions.json"]), "👍")
+		assert.Contains(t, string(files["moderation_flags.json"]), "flagged words")
+
+		var exported []map[string]interface{}
+		require.NoError(t, json.Unmarshal(files["attachments.json"], &exported))
+		require.Len(t, exported, 2)
+		assert.Equal(t, "attachments/a-1/notes.txt", exported[0]["file"])
+		assert.NotContains(t, exported[1], "file")
+
+		var manifest exportManifest
+		require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+		assert.Equal(t, 2, manifest.Files["attachments.json"])
+		assert.Equal(t, 1, manifest.Files["room_members.json"])
+		assert.Equal(t, 1, manifest.MissingAttachments)
+		assert.NotEmpty(t, manifest.Notes)
+	})
+
+	t.Run("last attempt marks the export failed", func(t *testing.T) {
+		svc, repo, userRepo, _, _ := setup(t)
+
+		export := &repository.UserExport{ID: testExportID, UserID: "alice", Attempts: exportMaxAttempts}
+		repo.On("ClaimNext", ctx, time.Minute).Return(export, nil).Once()
+		repo.On("ClaimNext", ctx, time.Minute).Return(nil, nil).Once()
+		repo.On("RecordFailure", mock.Anything, testExportID, "connection reset", true).Return(nil)
+
+		userRepo.On("GetByUserID", mock.Anything, "alice").Return(nil, fmt.Errorf("connection reset"))
+
+		completed, err := svc.RunPending(ctx)
+
+		require.NoError(t, err)
+		assert.Zero(t, completed)
+		entries, err := os.ReadDir(svc.dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestExportService_SweepExpired(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockExportRepository)
+	svc := NewExportService(repo, ExportSources{}, t.TempDir(), time.Hour, time.Minute)
+
+	path := filepath.Join(svc.dir, testExportID+".zip")
+	require.NoError(t, os.WriteFile(path, []byte("zip"), 0o600))
+
+	repo.On("ListExpired", ctx, exportSweepLimit).Return([]*repository.UserExport{
+		{ID: testExportID, FileName: testExportID + ".zip"},
+		{ID: "already-gone", FileName: "already-gone.zip"},
+	}, nil)
+	repo.On("MarkExpired", ctx, testExportID).Return(nil)
+	repo.On("MarkExpired", ctx, "already-gone").Return(nil)
+
+	removed, err := svc.SweepExpired(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+	assert.NoFileExists(t, path)
+	repo.AssertExpectations(t)
+}
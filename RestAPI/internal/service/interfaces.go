@@ -2,17 +2,26 @@ package service
 
 import (
 	"context"
+	"io"
 
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
 )
 
 // UserServiceInterface defines the interface for user business logic
 type UserServiceInterface interface {
 	CreateUser(ctx context.Context, userID, username, email string) (*repository.User, error)
+	CreateUserWithPassword(ctx context.Context, userID, username, email, password string) (*repository.User, error)
 	GetUser(ctx context.Context, userID string) (*repository.User, error)
+	UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*repository.User, error)
 	UpdateUserStatus(ctx context.Context, userID, status string) error
-	GetOnlineUsers(ctx context.Context, limit, offset int) ([]*repository.User, error)
-	ListUsers(ctx context.Context, limit, offset int) ([]*repository.User, int64, error)
+	UpdateUserRole(ctx context.Context, userID, role string) error
+	Heartbeat(ctx context.Context, userID string) (*PresenceState, error)
+	GetOnlineUsers(ctx context.Context, limit, offset int) ([]*repository.User, int64, error)
+	ListUsers(ctx context.Context, filter repository.UserFilter, sort repository.UserSort, limit, offset int) ([]*repository.User, int64, error)
 	DeleteUser(ctx context.Context, userID string) error
 	UpdateLastSeen(ctx context.Context, userID string) error
 }
@@ -27,8 +36,141 @@ type MessageServiceInterface interface {
 	MarkAsProcessed(ctx context.Context, messageID string) error
 	DeleteMessage(ctx context.Context, messageID string) error
 	GetMessageStats(ctx context.Context) (map[string]interface{}, error)
+	LookupMessage(ctx context.Context, identifier string) (*MessageLookup, error)
+}
+
+// AuthServiceInterface defines the interface for token issuance and revocation
+type AuthServiceInterface interface {
+	Login(ctx context.Context, userID, password string) (*TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Logout(ctx context.Context, claims *middleware.JWTClaims, refreshToken string) error
+	RevokeUser(ctx context.Context, userID string) error
+	IsRevoked(ctx context.Context, claims *middleware.JWTClaims) (bool, error)
+}
+
+// APIKeyServiceInterface defines the interface for API key management
+type APIKeyServiceInterface interface {
+	IssueKey(ctx context.Context, input IssueAPIKeyInput) (*IssuedAPIKey, error)
+	ListKeys(ctx context.Context, ownerUserID string, limit, offset int) ([]*repository.APIKey, int64, error)
+	RevokeKey(ctx context.Context, keyID string) error
+	AuthenticateAPIKey(ctx context.Context, key string) (*middleware.APIKeyPrincipal, error)
+}
+
+// AuditServiceInterface defines the interface for the audit log
+type AuditServiceInterface interface {
+	RecordAudit(ctx context.Context, entry *middleware.AuditEntry) error
+	ListAuditLogs(ctx context.Context, filter repository.AuditFilter, limit, offset int) ([]*repository.AuditLog, int64, error)
+}
+
+// ErasureServiceInterface defines the interface for user erasure
+type ErasureServiceInterface interface {
+	RequestErasure(ctx context.Context, userID, messageMode, requestedBy string) (*repository.UserErasure, error)
+	GetErasure(ctx context.Context, userID string) (*repository.UserErasure, error)
+	RunPending(ctx context.Context) (int, error)
+}
+
+// ExportServiceInterface defines the interface for per-user data exports
+type ExportServiceInterface interface {
+	RequestExport(ctx context.Context, userID, requestedBy string) (*repository.UserExport, error)
+	GetExport(ctx context.Context, userID, exportID string) (*repository.UserExport, error)
+	OpenExport(ctx context.Context, userID, exportID string) (*repository.UserExport, string, error)
+	RunPending(ctx context.Context) (int, error)
+	SweepExpired(ctx context.Context) (int, error)
+}
+
+// RoomServiceInterface defines the interface for rooms and room membership
+type RoomServiceInterface interface {
+	CreateRoom(ctx context.Context, actor RoomActor, input CreateRoomInput) (*repository.Room, error)
+	GetRoom(ctx context.Context, actor RoomActor, roomID string) (*repository.Room, error)
+	ListRooms(ctx context.Context, actor RoomActor, limit, offset int) ([]*repository.Room, int64, error)
+	UpdateRoom(ctx context.Context, actor RoomActor, roomID string, update repository.RoomUpdate) (*repository.Room, error)
+	DeleteRoom(ctx context.Context, actor RoomActor, roomID string) error
+	ListMembers(ctx context.Context, actor RoomActor, roomID string, limit, offset int) ([]*repository.RoomMember, int64, error)
+	SetMember(ctx context.Context, actor RoomActor, roomID, userID, role string) (*repository.RoomMember, error)
+	Join(ctx context.Context, actor RoomActor, roomID string) (*repository.RoomMember, error)
+	RemoveMember(ctx context.Context, actor RoomActor, roomID, userID string) error
+	ListMessages(ctx context.Context, actor RoomActor, roomID string, limit, offset int) ([]*repository.Message, int64, error)
+	CheckCanPost(ctx context.Context, roomID, userID string) error
+}
+
+// ReceiptServiceInterface defines the interface for message delivery and read receipts
+type ReceiptServiceInterface interface {
+	MarkDelivered(ctx context.Context, userID, messageID string) (*repository.MessageReceipt, error)
+	MarkRead(ctx context.Context, userID, messageID string) (*repository.MessageReceipt, error)
+	MarkReadUpTo(ctx context.Context, userID string, scope repository.ReceiptScope, upToMessageID string) ([]string, error)
+	Summary(ctx context.Context, messageID string) (*repository.ReceiptSummary, error)
+	ListReceipts(ctx context.Context, callerID string, admin bool, messageID string, limit, offset int) ([]*repository.MessageReceipt, int64, error)
+}
+
+// EditServiceInterface defines the interface for message editing, recall and revision history
+type EditServiceInterface interface {
+	EditMessage(ctx context.Context, editorID, messageID, content string) (*repository.Message, error)
+	RecallMessage(ctx context.Context, actorID string, admin bool, messageID string) (*repository.Message, error)
+	ListRevisions(ctx context.Context, callerID string, admin bool, messageID string, limit, offset int) ([]*repository.MessageRevision, int64, error)
+}
+
+// ThreadServiceInterface defines the interface for message replies
+type ThreadServiceInterface interface {
+	PrepareReply(ctx context.Context, req *models.MessageRequest) error
+	ListReplies(ctx context.Context, callerID string, admin bool, messageID string, limit, offset int) ([]*repository.Message, int64, error)
+}
+
+// ReactionServiceInterface defines the interface for emoji reactions
+type ReactionServiceInterface interface {
+	AddReaction(ctx context.Context, userID, messageID, emoji string) (map[string]int64, error)
+	RemoveReaction(ctx context.Context, userID, messageID, emoji string) (map[string]int64, error)
+	AttachReactions(ctx context.Context, messages []*repository.Message) error
+}
+
+// AttachmentServiceInterface defines the interface for file attachments
+type AttachmentServiceInterface interface {
+	Upload(ctx context.Context, uploaderID, fileName string, content io.Reader) (*repository.Attachment, error)
+	GetAttachment(ctx context.Context, callerID string, admin bool, id string) (*repository.Attachment, error)
+	OpenAttachment(ctx context.Context, callerID string, admin bool, id string) (*repository.Attachment, io.ReadCloser, error)
+	ClaimAttachments(ctx context.Context, userID, messageID string, ids []string) ([]models.QueueAttachment, error)
+	ReleaseAttachments(ctx context.Context, messageID string)
+	LoadAttachments(ctx context.Context, messages []*repository.Message) error
+}
+
+// ModerationServiceInterface defines the interface for content moderation and the review queue
+type ModerationServiceInterface interface {
+	Moderate(ctx context.Context, msg *moderation.Message) (*moderation.Decision, error)
+	FlagMessage(ctx context.Context, messageID string, msg *moderation.Message, decision *moderation.Decision)
+	ListFlags(ctx context.Context, status string, limit, offset int) ([]*repository.ModerationFlag, int64, error)
+	ReviewFlag(ctx context.Context, reviewerID, flagID, decision string) (*repository.ModerationFlag, error)
+}
+
+// WebhookServiceInterface defines the interface for webhook subscriptions and their delivery log
+type WebhookServiceInterface interface {
+	EventNotifier
+	CreateSubscription(ctx context.Context, input WebhookInput) (*IssuedWebhook, error)
+	GetSubscription(ctx context.Context, id string) (*repository.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, limit, offset int) ([]*repository.WebhookSubscription, int64, error)
+	UpdateSubscription(ctx context.Context, id string, update WebhookUpdate) (*repository.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]*repository.WebhookDelivery, int64, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*repository.WebhookDelivery, error)
 }
 
 // Ensure implementations satisfy interfaces
 var _ UserServiceInterface = (*UserService)(nil)
 var _ MessageServiceInterface = (*MessageService)(nil)
+var _ AuthServiceInterface = (*AuthService)(nil)
+var _ middleware.RevocationChecker = (*AuthService)(nil)
+var _ APIKeyServiceInterface = (*APIKeyService)(nil)
+var _ middleware.APIKeyAuthenticator = (*APIKeyService)(nil)
+var _ AuditServiceInterface = (*AuditService)(nil)
+var _ middleware.AuditRecorder = (*AuditService)(nil)
+var _ ErasureServiceInterface = (*ErasureService)(nil)
+var _ ExportServiceInterface = (*ExportService)(nil)
+var _ RoomServiceInterface = (*RoomService)(nil)
+var _ ReceiptServiceInterface = (*ReceiptService)(nil)
+var _ EditServiceInterface = (*EditService)(nil)
+var _ ThreadServiceInterface = (*ThreadService)(nil)
+var _ ReactionServiceInterface = (*ReactionService)(nil)
+var _ AttachmentServiceInterface = (*AttachmentService)(nil)
+var _ ModerationServiceInterface = (*ModerationService)(nil)
+var _ WebhookServiceInterface = (*WebhookService)(nil)
+var _ MessageRecaller = (*EditService)(nil)
+var _ MessagePublisher = (*services.RabbitMQService)(nil)
+var _ TokenRevoker = (*AuthService)(nil)
@@ -2,7 +2,13 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
 
+	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
@@ -10,10 +16,38 @@ import (
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
 )
 
+// messageLookupLimit caps the messages a lookup by request ID returns
+const messageLookupLimit = 50
+
+// messageStatusFailed is the status of a message that could not be delivered, set by DBWorker or an operator
+const messageStatusFailed = "failed"
+
+// Identifier kinds a message lookup can match
+const (
+	LookupMatchedByID        = "id"
+	LookupMatchedByMessageID = "message_id"
+	LookupMatchedByRequestID = "request_id"
+)
+
+// MessageTrace ties a stored message to the HTTP request and the queue message that produced it
+type MessageTrace struct {
+	RequestID string              `json:"request_id"`
+	MessageID string              `json:"message_id"`
+	Message   *repository.Message `json:"message"`
+}
+
+// MessageLookup is the result of a lookup by row ID, message_id or request ID
+type MessageLookup struct {
+	Identifier string          `json:"identifier"`
+	MatchedBy  string          `json:"matched_by"`
+	Messages   []*MessageTrace `json:"messages"`
+}
+
 // MessageService handles message business logic
 type MessageService struct {
 	messageRepo repository.MessageRepository
 	redis       *services.RedisService
+	events      EventNotifier
 }
 
 // NewMessageService creates a new message service
@@ -27,7 +61,12 @@ func NewMessageService(
 	}
 }
 
-// GetMessage retrieves a message by ID
+// SetEvents reports message.failed when a message's status becomes failed, e.g. to webhook subscriptions
+func (s *MessageService) SetEvents(events EventNotifier) {
+	s.events = events
+}
+
+// GetMessage retrieves a message by ID, with its reply count and latest reply when it starts a thread
 func (s *MessageService) GetMessage(ctx context.Context, messageID string) (*repository.Message, error) {
 	message, err := s.messageRepo.GetByMessageID(ctx, messageID)
 	if err != nil {
@@ -35,6 +74,10 @@ func (s *MessageService) GetMessage(ctx context.Context, messageID string) (*rep
 		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
 	}
 
+	if err := withThreadSummaries(ctx, s.messageRepo, []*repository.Message{message}); err != nil {
+		return nil, err
+	}
+
 	return message, nil
 }
 
@@ -89,9 +132,13 @@ func (s *MessageService) GetMessagesByStatus(ctx context.Context, status string,
 	return messages, total, nil
 }
 
-// UpdateMessageStatus updates message status
+// UpdateMessageStatus updates message status and reports message.failed when the message becomes failed
 func (s *MessageService) UpdateMessageStatus(ctx context.Context, messageID, status string) error {
-	if err := s.messageRepo.UpdateStatus(ctx, messageID, status); err != nil {
+	previous, err := s.messageRepo.UpdateStatus(ctx, messageID, status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if err != nil {
 		logger.Errorf("Failed to update message status: %v", err)
 		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to update message status", 500)
 	}
@@ -103,9 +150,29 @@ func (s *MessageService) UpdateMessageStatus(ctx context.Context, messageID, sta
 		}
 	}
 
+	if status == messageStatusFailed && previous != messageStatusFailed {
+		s.notifyFailed(ctx, messageID, previous)
+	}
 	return nil
 }
 
+// notifyFailed reports a message that became failed after it was published.
+// 상태는 이미 바뀌었으므로 메시지를 다시 읽지 못해도 ID 만으로 이벤트를 보낸다.
+func (s *MessageService) notifyFailed(ctx context.Context, messageID, previous string) {
+	if s.events == nil {
+		return
+	}
+
+	data := MessageEventData{MessageID: messageID, Error: "status changed from " + previous + " to " + messageStatusFailed}
+	message, err := s.messageRepo.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Failed to load failed message %s for its event: %v", messageID, err)
+	} else {
+		data = messageEventData(message, data.Error)
+	}
+	s.events.Notify(ctx, EventMessageFailed, data.UserID, data)
+}
+
 // MarkAsProcessed marks a message as processed
 func (s *MessageService) MarkAsProcessed(ctx context.Context, messageID string) error {
 	if err := s.messageRepo.MarkAsProcessed(ctx, messageID); err != nil {
@@ -157,3 +224,56 @@ func (s *MessageService) GetMessageStats(ctx context.Context) (map[string]interf
 
 	return stats, nil
 }
+
+// LookupMessage finds the messages behind an identifier: a messages row ID, a message_id or an X-Request-ID.
+// 숫자는 행 ID 로, UUID 는 message_id 로 먼저 찾고, 없으면 request ID 로 본다 — 요청 ID 도 대개 UUID 이고
+// 클라이언트가 X-Request-ID 를 직접 정하면 숫자일 수도 있다. 발행만 되고 아직 저장되지 않은 메시지는 찾지 못한다.
+func (s *MessageService) LookupMessage(ctx context.Context, identifier string) (*MessageLookup, error) {
+	if identifier == "" {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Identifier is required", 400)
+	}
+
+	lookup := &MessageLookup{Identifier: identifier}
+	var message *repository.Message
+	if id, err := strconv.ParseInt(identifier, 10, 64); err == nil && id > 0 {
+		message, _ = s.messageRepo.GetByID(ctx, id)
+		lookup.MatchedBy = LookupMatchedByID
+	} else if _, err := uuid.Parse(identifier); err == nil {
+		message, _ = s.messageRepo.GetByMessageID(ctx, identifier)
+		lookup.MatchedBy = LookupMatchedByMessageID
+	}
+	if message != nil && message.ID != 0 {
+		lookup.Messages = []*MessageTrace{newMessageTrace(message)}
+		return lookup, nil
+	}
+
+	messages, err := s.messageRepo.ListByRequestID(ctx, identifier, messageLookupLimit)
+	if err != nil {
+		logger.Errorf("Failed to look up messages by request ID: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to look up messages", 500)
+	}
+	if len(messages) == 0 {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "No message matches the identifier", 404)
+	}
+
+	lookup.MatchedBy = LookupMatchedByRequestID
+	lookup.Messages = make([]*MessageTrace, 0, len(messages))
+	for _, message := range messages {
+		lookup.Messages = append(lookup.Messages, newMessageTrace(message))
+	}
+	return lookup, nil
+}
+
+// newMessageTrace reads the request ID that publisher_info recorded for message.
+// request_id 가 생기기 전에 저장된 행이나 다른 생산자의 행은 빈 값으로 둔다.
+func newMessageTrace(message *repository.Message) *MessageTrace {
+	var info models.QueuePublisherInformation
+	if json.Unmarshal([]byte(message.PublisherInfo), &info) != nil {
+		info = models.QueuePublisherInformation{}
+	}
+	return &MessageTrace{
+		RequestID: info.RequestID,
+		MessageID: message.MessageID,
+		Message:   message,
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageService_LookupMessage(t *testing.T) {
+	ctx := context.Background()
+	const messageID = "6f1c2b7e-3d4a-4e5f-8a9b-0c1d2e3f4a5b"
+	const requestID = "0b9a8c7d-6e5f-4a3b-9c2d-1e0f9a8b7c6d"
+	stored := func() *repository.Message {
+		return &repository.Message{
+			ID:            42,
+			MessageID:     messageID,
+			UserID:        "alice",
+			PublisherInfo: `{"message_id":"` + messageID + `","request_id":"` + requestID + `","source":"restapi"}`,
+		}
+	}
+
+	t.Run("by row ID", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("GetByID", ctx, int64(42)).Return(stored(), nil)
+
+		lookup, err := NewMessageService(messages, nil).LookupMessage(ctx, "42")
+
+		require.NoError(t, err)
+		assert.Equal(t, LookupMatchedByID, lookup.MatchedBy)
+		require.Len(t, lookup.Messages, 1)
+		assert.Equal(t, requestID, lookup.Messages[0].RequestID)
+		assert.Equal(t, messageID, lookup.Messages[0].MessageID)
+	})
+
+	t.Run("by message_id", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("GetByMessageID", ctx, messageID).Return(stored(), nil)
+
+		lookup, err := NewMessageService(messages, nil).LookupMessage(ctx, messageID)
+
+		require.NoError(t, err)
+		assert.Equal(t, LookupMatchedByMessageID, lookup.MatchedBy)
+		assert.Equal(t, requestID, lookup.Messages[0].RequestID)
+		messages.AssertNotCalled(t, "ListByRequestID")
+	})
+
+	t.Run("a UUID that is a request ID", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("GetByMessageID", ctx, requestID).Return(nil, errors.New("message not found"))
+		messages.On("ListByRequestID", ctx, requestID, messageLookupLimit).Return([]*repository.Message{stored()}, nil)
+
+		lookup, err := NewMessageService(messages, nil).LookupMessage(ctx, requestID)
+
+		require.NoError(t, err)
+		assert.Equal(t, LookupMatchedByRequestID, lookup.MatchedBy)
+		assert.Equal(t, messageID, lookup.Messages[0].MessageID)
+	})
+
+	t.Run("a client-chosen request ID", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		legacy := &repository.Message{ID: 1, MessageID: "m0", PublisherInfo: "not json"}
+		messages.On("ListByRequestID", ctx, "checkout-17", messageLookupLimit).Return([]*repository.Message{stored(), legacy}, nil)
+
+		lookup, err := NewMessageService(messages, nil).LookupMessage(ctx, "checkout-17")
+
+		require.NoError(t, err)
+		require.Len(t, lookup.Messages, 2)
+		assert.Empty(t, lookup.Messages[1].RequestID, "publisher_info 를 읽을 수 없는 행도 그대로 돌려준다")
+		messages.AssertNotCalled(t, "GetByID")
+		messages.AssertNotCalled(t, "GetByMessageID")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("GetByID", ctx, int64(7)).Return(nil, errors.New("message not found with ID: 7"))
+		messages.On("ListByRequestID", ctx, "7", messageLookupLimit).Return([]*repository.Message{}, nil)
+
+		_, err := NewMessageService(messages, nil).LookupMessage(ctx, "7")
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("ListByRequestID", ctx, "req", messageLookupLimit).Return(nil, errors.New("connection refused"))
+
+		_, err := NewMessageService(messages, nil).LookupMessage(ctx, "req")
+		assertStatus(t, err, 500)
+	})
+
+	t.Run("empty identifier", func(t *testing.T) {
+		_, err := NewMessageService(new(MockMessageRepository), nil).LookupMessage(ctx, "")
+		assertStatus(t, err, 400)
+	})
+}
+
+func TestMessageService_UpdateMessageStatus(t *testing.T) {
+	ctx := context.Background()
+	roomID := testRoomID
+	stored := &repository.Message{MessageID: "m1", UserID: "alice", RoomID: &roomID, Command: "chat", Content: "secret plan"}
+
+	t.Run("reports the transition to failed", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("UpdateStatus", ctx, "m1", "failed").Return("pending", nil)
+		messages.On("GetByMessageID", ctx, "m1").Return(stored, nil)
+		events := &recordingNotifier{}
+		svc := NewMessageService(messages, nil)
+		svc.SetEvents(events)
+
+		require.NoError(t, svc.UpdateMessageStatus(ctx, "m1", "failed"))
+
+		require.Len(t, events.events, 1)
+		assert.Equal(t, EventMessageFailed, events.events[0].eventType)
+		assert.Equal(t, "alice", events.events[0].userID)
+		data := events.events[0].data.(MessageEventData)
+		assert.Equal(t, testRoomID, data.RoomID)
+		assert.Equal(t, "status changed from pending to failed", data.Error)
+
+		// 이벤트는 외부로 나가므로 메시지 내용이 실리면 안 된다.
+		payload, err := json.Marshal(data)
+		require.NoError(t, err)
+		assert.NotContains(t, string(payload), "secret plan")
+	})
+
+	t.Run("already failed", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("UpdateStatus", ctx, "m1", "failed").Return("failed", nil)
+		events := &recordingNotifier{}
+		svc := NewMessageService(messages, nil)
+		svc.SetEvents(events)
+
+		require.NoError(t, svc.UpdateMessageStatus(ctx, "m1", "failed"))
+
+		assert.Empty(t, events.events)
+		messages.AssertNotCalled(t, "GetByMessageID")
+	})
+
+	t.Run("other statuses", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("UpdateStatus", ctx, "m1", "processed").Return("pending", nil)
+		events := &recordingNotifier{}
+		svc := NewMessageService(messages, nil)
+		svc.SetEvents(events)
+
+		require.NoError(t, svc.UpdateMessageStatus(ctx, "m1", "processed"))
+
+		assert.Empty(t, events.events)
+	})
+
+	t.Run("message vanished after the update", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("UpdateStatus", ctx, "m1", "failed").Return("processed", nil)
+		messages.On("GetByMessageID", ctx, "m1").Return(nil, fmt.Errorf("connection reset"))
+		events := &recordingNotifier{}
+		svc := NewMessageService(messages, nil)
+		svc.SetEvents(events)
+
+		require.NoError(t, svc.UpdateMessageStatus(ctx, "m1", "failed"))
+
+		require.Len(t, events.events, 1)
+		assert.Equal(t, "m1", events.events[0].data.(MessageEventData).MessageID)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		messages := new(MockMessageRepository)
+		messages.On("UpdateStatus", ctx, "gone", "failed").Return("", fmt.Errorf("message not found: gone: %w", sql.ErrNoRows))
+
+		err := NewMessageService(messages, nil).UpdateMessageStatus(ctx, "gone", "failed")
+		assertStatus(t, err, 404)
+	})
+}
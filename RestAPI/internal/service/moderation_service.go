@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
+	"github.com/sirupsen/logrus"
+)
+
+// Review decisions on a moderation flag
+const (
+	ReviewApprove = "approve"
+	ReviewRemove  = "remove"
+)
+
+// MessageRecaller recalls a message removed on review
+type MessageRecaller interface {
+	RecallMessage(ctx context.Context, actorID string, admin bool, messageID string) (*repository.Message, error)
+}
+
+// ModerationService checks content before it is published and keeps the review queue of flagged messages
+type ModerationService struct {
+	pipeline atomic.Pointer[moderation.Pipeline]
+	flags    repository.ModerationRepository
+	recaller MessageRecaller
+}
+
+// NewModerationService creates a new moderation service. Without a repository flags are only logged.
+func NewModerationService(pipeline *moderation.Pipeline, flags repository.ModerationRepository) *ModerationService {
+	s := &ModerationService{flags: flags}
+	s.pipeline.Store(pipeline)
+	return s
+}
+
+// SetPipeline replaces the filters; messages already being checked finish with the old ones
+func (s *ModerationService) SetPipeline(pipeline *moderation.Pipeline) {
+	s.pipeline.Store(pipeline)
+}
+
+// Filters returns the names of the current filters in order
+func (s *ModerationService) Filters() []string {
+	return s.pipeline.Load().Filters()
+}
+
+// SetRecaller enables removing messages on review
+func (s *ModerationService) SetRecaller(recaller MessageRecaller) {
+	s.recaller = recaller
+}
+
+// Moderate runs the filters on a message. A rejection is returned as a 422 error; a flag is returned in the
+// decision for FlagMessage once the message is published.
+func (s *ModerationService) Moderate(ctx context.Context, msg *moderation.Message) (*moderation.Decision, error) {
+	decision, err := s.pipeline.Load().Run(ctx, msg)
+	if err != nil {
+		logger.Warnf("Moderation filter skipped: %v", err)
+	}
+	middleware.RecordModerationDecision(string(decision.Action))
+
+	rejection := decision.Rejection()
+	if rejection == nil {
+		return decision, nil
+	}
+
+	logger.WithFields(logrus.Fields{
+		"user_id": msg.UserID,
+		"edit":    msg.Edit,
+		"filter":  rejection.Filter,
+		"reason":  rejection.Reason,
+	}).Info("Message rejected by moderation")
+
+	return decision, apperrors.New(apperrors.ErrCodeContentRejected, "Message rejected: "+rejection.Reason, 422).
+		WithFields(map[string]string{"content": rejection.Reason})
+}
+
+// FlagMessage puts a published message the filters flagged in the review queue.
+// 발행은 이미 끝났으므로 기록에 실패해도 로그만 남긴다.
+func (s *ModerationService) FlagMessage(ctx context.Context, messageID string, msg *moderation.Message, decision *moderation.Decision) {
+	if decision == nil || decision.Action != moderation.ActionFlag {
+		return
+	}
+
+	logger.WithFields(logrus.Fields{
+		"message_id": messageID,
+		"user_id":    msg.UserID,
+		"edit":       msg.Edit,
+		"verdicts":   decision.Verdicts,
+	}).Warn("Message flagged by moderation")
+
+	if s.flags == nil {
+		return
+	}
+
+	verdicts, err := json.Marshal(decision.Verdicts)
+	if err != nil {
+		logger.Errorf("Failed to encode moderation verdicts of %s: %v", messageID, err)
+		return
+	}
+	raw := json.RawMessage(verdicts)
+	flag := &repository.ModerationFlag{MessageID: messageID, UserID: msg.UserID, Content: msg.Content, IsEdit: msg.Edit, Verdicts: &raw}
+	if err := s.flags.Create(ctx, flag); err != nil {
+		logger.Errorf("Failed to queue flagged message %s for review: %v", messageID, err)
+	}
+}
+
+// ListFlags lists the review queue, oldest first. An empty status lists every flag.
+func (s *ModerationService) ListFlags(ctx context.Context, status string, limit, offset int) ([]*repository.ModerationFlag, int64, error) {
+	switch status {
+	case "", repository.FlagPending, repository.FlagApproved, repository.FlagRemoved:
+	default:
+		return nil, 0, apperrors.New(apperrors.ErrCodeValidation, "status must be pending, approved or removed", 400)
+	}
+
+	flags, err := s.flags.List(ctx, status, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list moderation flags: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list moderation flags", 500)
+	}
+
+	total, err := s.flags.Count(ctx, status)
+	if err != nil {
+		logger.Errorf("Failed to count moderation flags: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count moderation flags", 500)
+	}
+
+	return flags, total, nil
+}
+
+// ReviewFlag records a reviewer's decision on a pending flag. Removing recalls the message first,
+// so a failed recall leaves the flag pending.
+func (s *ModerationService) ReviewFlag(ctx context.Context, reviewerID, flagID, decision string) (*repository.ModerationFlag, error) {
+	var status string
+	switch decision {
+	case ReviewApprove:
+		status = repository.FlagApproved
+	case ReviewRemove:
+		status = repository.FlagRemoved
+	default:
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "decision must be approve or remove", 400).
+			WithFields(map[string]string{"decision": "must be approve or remove"})
+	}
+
+	flag, err := s.flags.Get(ctx, flagID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Moderation flag not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get moderation flag: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get moderation flag", 500)
+	}
+	if flag.Status != repository.FlagPending {
+		return nil, apperrors.New(apperrors.ErrCodeConflict, "The flag was already reviewed", 409)
+	}
+
+	if status == repository.FlagRemoved {
+		if err := s.recall(ctx, reviewerID, flag.MessageID); err != nil {
+			return nil, err
+		}
+	}
+
+	reviewed, err := s.flags.Review(ctx, flagID, status, reviewerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeConflict, "The flag was already reviewed", 409)
+	}
+	if err != nil {
+		logger.Errorf("Failed to review moderation flag: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to review moderation flag", 500)
+	}
+
+	logger.Infof("Moderation flag %s reviewed by %s: %s", flagID, reviewerID, status)
+	return reviewed, nil
+}
+
+// recall recalls a message removed on review. 이미 지워진 메시지는 지울 것이 없으므로 제거된 것으로 본다.
+func (s *ModerationService) recall(ctx context.Context, reviewerID, messageID string) error {
+	if s.recaller == nil {
+		return apperrors.New(apperrors.ErrCodeServiceUnavail, "Removing messages requires message recall", 503)
+	}
+
+	_, err := s.recaller.RecallMessage(ctx, reviewerID, true, messageID)
+	if appErr := apperrors.GetAppError(err); appErr != nil && appErr.StatusCode == 404 {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/moderation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockModerationRepository is a mock implementation of ModerationRepository
+type MockModerationRepository struct {
+	mock.Mock
+}
+
+func (m *MockModerationRepository) Create(ctx context.Context, flag *repository.ModerationFlag) error {
+	args := m.Called(ctx, flag)
+	return args.Error(0)
+}
+
+func (m *MockModerationRepository) Get(ctx context.Context, id string) (*repository.ModerationFlag, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ModerationFlag), args.Error(1)
+}
+
+func (m *MockModerationRepository) List(ctx context.Context, status string, limit, offset int) ([]*repository.ModerationFlag, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ModerationFlag), args.Error(1)
+}
+
+func (m *MockModerationRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*repository.ModerationFlag, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ModerationFlag), args.Error(1)
+}
+
+func (m *MockModerationRepository) Count(ctx context.Context, status string) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockModerationRepository) Review(ctx context.Context, id, status, reviewerID string) (*repository.ModerationFlag, error) {
+	args := m.Called(ctx, id, status, reviewerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ModerationFlag), args.Error(1)
+}
+
+// fakeRecaller records recalled messages
+type fakeRecaller struct {
+	recalled []string
+	err      error
+}
+
+func (r *fakeRecaller) RecallMessage(ctx context.Context, actorID string, admin bool, messageID string) (*repository.Message, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	r.recalled = append(r.recalled, messageID)
+	return &repository.Message{MessageID: messageID}, nil
+}
+
+func newTestPipeline(t *testing.T) *moderation.Pipeline {
+	t.Helper()
+
+	question, err := moderation.NewRegexRule("question", `\?`, moderation.ActionFlag, "asks a question", false)
+	require.NoError(t, err)
+	return moderation.NewPipeline(moderation.NewBlockedWords([]string{"spam"}, moderation.ActionReject), question)
+}
+
+func TestModerationService_Moderate(t *testing.T) {
+	ctx := context.Background()
+	svc := NewModerationService(newTestPipeline(t), nil)
+
+	decision, err := svc.Moderate(ctx, &moderation.Message{UserID: "alice", Content: "hello"})
+	require.NoError(t, err)
+	assert.Equal(t, moderation.ActionAllow, decision.Action)
+
+	decision, err = svc.Moderate(ctx, &moderation.Message{UserID: "alice", Content: "hello?"})
+	require.NoError(t, err)
+	assert.Equal(t, moderation.ActionFlag, decision.Action)
+
+	_, err = svc.Moderate(ctx, &moderation.Message{UserID: "alice", Content: "SPAM"})
+	assertStatus(t, err, 422)
+	appErr := apperrors.GetAppError(err)
+	assert.Equal(t, apperrors.ErrCodeContentRejected, appErr.Code)
+	assert.Equal(t, `Message rejected: contains blocked word "spam"`, appErr.Message)
+
+	t.Run("reload", func(t *testing.T) {
+		svc.SetPipeline(moderation.NewPipeline())
+
+		decision, err := svc.Moderate(ctx, &moderation.Message{UserID: "alice", Content: "SPAM"})
+		require.NoError(t, err)
+		assert.Equal(t, moderation.ActionAllow, decision.Action)
+		assert.Empty(t, svc.Filters())
+	})
+}
+
+func TestModerationService_FlagMessage(t *testing.T) {
+	ctx := context.Background()
+	flags := new(MockModerationRepository)
+	svc := NewModerationService(newTestPipeline(t), flags)
+	msg := &moderation.Message{UserID: "alice", Content: "hello?"}
+
+	decision, err := svc.Moderate(ctx, msg)
+	require.NoError(t, err)
+	flags.On("Create", ctx, mock.MatchedBy(func(flag *repository.ModerationFlag) bool {
+		var verdicts []moderation.Verdict
+		return flag.MessageID == "m1" && flag.UserID == "alice" && flag.Content == "hello?" &&
+			json.Unmarshal(*flag.Verdicts, &verdicts) == nil && len(verdicts) == 1 && verdicts[0].Filter == "rule:question"
+	})).Return(nil).Once()
+
+	svc.FlagMessage(ctx, "m1", msg, decision)
+
+	// 허용된 메시지와 저장소 오류는 발행을 막지 않는다.
+	allowed, err := svc.Moderate(ctx, &moderation.Message{UserID: "alice", Content: "hello"})
+	require.NoError(t, err)
+	svc.FlagMessage(ctx, "m2", msg, allowed)
+	flags.On("Create", ctx, mock.Anything).Return(errors.New("connection refused")).Once()
+	svc.FlagMessage(ctx, "m3", msg, decision)
+
+	flags.AssertExpectations(t)
+	flags.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestModerationService_ListFlags(t *testing.T) {
+	ctx := context.Background()
+	flags := new(MockModerationRepository)
+	svc := NewModerationService(moderation.NewPipeline(), flags)
+
+	flags.On("List", ctx, repository.FlagPending, 20, 0).Return([]*repository.ModerationFlag{{ID: "f1"}}, nil)
+	flags.On("Count", ctx, repository.FlagPending).Return(int64(1), nil)
+
+	list, total, err := svc.ListFlags(ctx, repository.FlagPending, 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(1), total)
+
+	_, _, err = svc.ListFlags(ctx, "open", 20, 0)
+	assertStatus(t, err, 400)
+}
+
+func TestModerationService_ReviewFlag(t *testing.T) {
+	ctx := context.Background()
+	pending := &repository.ModerationFlag{ID: "f1", MessageID: "m1", Status: repository.FlagPending}
+
+	setup := func() (*ModerationService, *MockModerationRepository, *fakeRecaller) {
+		flags := new(MockModerationRepository)
+		recaller := &fakeRecaller{}
+		svc := NewModerationService(moderation.NewPipeline(), flags)
+		svc.SetRecaller(recaller)
+		return svc, flags, recaller
+	}
+
+	t.Run("approve", func(t *testing.T) {
+		svc, flags, recaller := setup()
+		flags.On("Get", ctx, "f1").Return(pending, nil)
+		flags.On("Review", ctx, "f1", repository.FlagApproved, "admin").
+			Return(&repository.ModerationFlag{ID: "f1", Status: repository.FlagApproved}, nil)
+
+		flag, err := svc.ReviewFlag(ctx, "admin", "f1", ReviewApprove)
+
+		require.NoError(t, err)
+		assert.Equal(t, repository.FlagApproved, flag.Status)
+		assert.Empty(t, recaller.recalled)
+	})
+
+	t.Run("remove recalls the message", func(t *testing.T) {
+		svc, flags, recaller := setup()
+		flags.On("Get", ctx, "f1").Return(pending, nil)
+		flags.On("Review", ctx, "f1", repository.FlagRemoved, "admin").
+			Return(&repository.ModerationFlag{ID: "f1", Status: repository.FlagRemoved}, nil)
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f1", ReviewRemove)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"m1"}, recaller.recalled)
+	})
+
+	t.Run("remove of a deleted message", func(t *testing.T) {
+		svc, flags, recaller := setup()
+		recaller.err = apperrors.New(apperrors.ErrCodeNotFound, "Message not found", 404)
+		flags.On("Get", ctx, "f1").Return(pending, nil)
+		flags.On("Review", ctx, "f1", repository.FlagRemoved, "admin").
+			Return(&repository.ModerationFlag{ID: "f1", Status: repository.FlagRemoved}, nil)
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f1", ReviewRemove)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed recall leaves the flag pending", func(t *testing.T) {
+		svc, flags, recaller := setup()
+		recaller.err = apperrors.New(apperrors.ErrCodeDatabaseError, "Failed to recall message", 500)
+		flags.On("Get", ctx, "f1").Return(pending, nil)
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f1", ReviewRemove)
+
+		assertStatus(t, err, 500)
+		flags.AssertNotCalled(t, "Review", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already reviewed", func(t *testing.T) {
+		svc, flags, _ := setup()
+		flags.On("Get", ctx, "f1").Return(&repository.ModerationFlag{ID: "f1", Status: repository.FlagApproved}, nil)
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f1", ReviewRemove)
+
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("reviewed concurrently", func(t *testing.T) {
+		svc, flags, _ := setup()
+		flags.On("Get", ctx, "f1").Return(pending, nil)
+		flags.On("Review", ctx, "f1", repository.FlagApproved, "admin").
+			Return(nil, fmt.Errorf("pending moderation flag not found: f1: %w", sql.ErrNoRows))
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f1", ReviewApprove)
+
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("unknown flag", func(t *testing.T) {
+		svc, flags, _ := setup()
+		flags.On("Get", ctx, "f2").Return(nil, fmt.Errorf("moderation flag not found: f2: %w", sql.ErrNoRows))
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f2", ReviewApprove)
+
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("invalid decision", func(t *testing.T) {
+		svc, _, _ := setup()
+
+		_, err := svc.ReviewFlag(ctx, "admin", "f1", "delete")
+
+		assertStatus(t, err, 400)
+	})
+}
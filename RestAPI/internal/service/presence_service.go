@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// Presence statuses published on the presence channel
+const (
+	PresenceOnline  = "online"
+	PresenceOffline = "offline"
+)
+
+// sweepScript removes members whose last heartbeat is older than ARGV[1] and returns them.
+// 조회와 삭제를 한 스크립트로 묶어야 여러 레플리카가 동시에 sweep 해도 오프라인 이벤트가 한 번만 나간다.
+var sweepScript = redis.NewScript(`
+local cutoff = "(" .. ARGV[1]
+local expired = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", cutoff)
+if #expired > 0 then
+	redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", cutoff)
+end
+return expired
+`)
+
+// PresenceEvent is published on the presence channel when a user comes online or goes offline
+type PresenceEvent struct {
+	UserID string    `json:"user_id"`
+	Status string    `json:"status"`
+	At     time.Time `json:"at"`
+}
+
+// PresenceState is the result of a heartbeat
+type PresenceState struct {
+	UserID     string    `json:"user_id"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	TTLSeconds int       `json:"ttl_seconds"`
+}
+
+// PresenceService tracks online users from heartbeats.
+// 접속자는 Redis sorted set 하나에 마지막 하트비트 시각(ms)을 점수로 둔다 — 개별 키 TTL 과 달리
+// 만료된 사용자를 한 번에 찾을 수 있고, 접속자 수와 페이지 조회가 ZCOUNT/ZREVRANGEBYSCORE 로 끝난다.
+type PresenceService struct {
+	redis         *services.RedisService
+	userRepo      repository.UserRepository
+	ttl           time.Duration
+	channel       string
+	persistStatus bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewPresenceService creates a new presence service.
+// persistStatus 가 true 면 온라인/오프라인 전환을 users.status 에도 기록한다.
+func NewPresenceService(redis *services.RedisService, userRepo repository.UserRepository, ttl time.Duration, channel string, persistStatus bool) *PresenceService {
+	return &PresenceService{
+		redis:         redis,
+		userRepo:      userRepo,
+		ttl:           ttl,
+		channel:       channel,
+		persistStatus: persistStatus,
+	}
+}
+
+// Heartbeat marks the user present for another TTL
+func (s *PresenceService) Heartbeat(ctx context.Context, userID string) (*PresenceState, error) {
+	now := time.Now()
+
+	added, err := s.redis.ZAdd(ctx, cache.PresenceOnlineKey(), redis.Z{Score: float64(now.UnixMilli()), Member: userID})
+	if err != nil {
+		logger.Errorf("Failed to record heartbeat (%s): %v", userID, err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to record heartbeat", 503)
+	}
+
+	// ZADD 가 새 멤버를 추가했을 때만 오프라인→온라인 전환이다. 갱신은 이벤트를 내지 않는다.
+	if added > 0 {
+		s.announce(ctx, userID, PresenceOnline, now)
+	}
+
+	return &PresenceState{
+		UserID:     userID,
+		Status:     PresenceOnline,
+		ExpiresAt:  now.Add(s.ttl),
+		TTLSeconds: int(s.ttl.Seconds()),
+	}, nil
+}
+
+// Leave removes the user from the online set without touching users.status.
+// 명시적 오프라인 전환이나 삭제처럼 호출측이 이미 DB 를 갱신한 경우에 쓴다.
+func (s *PresenceService) Leave(ctx context.Context, userID string) error {
+	removed, err := s.redis.ZRem(ctx, cache.PresenceOnlineKey(), userID)
+	if err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		s.publish(ctx, PresenceEvent{UserID: userID, Status: PresenceOffline, At: time.Now()})
+	}
+	return nil
+}
+
+// ListOnline returns a page of online user IDs, most recent heartbeat first, and the total online count
+func (s *PresenceService) ListOnline(ctx context.Context, limit, offset int) ([]string, int64, error) {
+	cutoff := strconv.FormatInt(time.Now().Add(-s.ttl).UnixMilli(), 10)
+
+	// sweep 전이라도 TTL 이 지난 멤버는 세지 않는다.
+	total, err := s.redis.ZCount(ctx, cache.PresenceOnlineKey(), cutoff, "+inf")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	userIDs, err := s.redis.ZRevRangeByScore(ctx, cache.PresenceOnlineKey(), cutoff, "+inf", int64(offset), int64(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return userIDs, total, nil
+}
+
+// Sweep removes users whose heartbeat expired and announces them as offline
+func (s *PresenceService) Sweep(ctx context.Context) (int, error) {
+	now := time.Now()
+	cutoff := now.Add(-s.ttl).UnixMilli()
+
+	result, err := s.redis.RunScript(ctx, sweepScript, []string{cache.PresenceOnlineKey()}, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	expired, _ := result.([]interface{})
+	for _, member := range expired {
+		if userID, ok := member.(string); ok {
+			s.announce(ctx, userID, PresenceOffline, now)
+		}
+	}
+
+	return len(expired), nil
+}
+
+// StartSweeper runs Sweep every interval until Close is called
+func (s *PresenceService) StartSweeper(interval time.Duration) {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if n, err := s.Sweep(context.Background()); err != nil {
+					logger.Warnf("Presence sweep failed: %v", err)
+				} else if n > 0 {
+					logger.Debugf("Presence sweep: %d user(s) went offline", n)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the sweeper
+func (s *PresenceService) Close() {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+}
+
+// announce publishes a transition and, if configured, persists it to users.status
+func (s *PresenceService) announce(ctx context.Context, userID, status string, at time.Time) {
+	s.publish(ctx, PresenceEvent{UserID: userID, Status: status, At: at})
+
+	if !s.persistStatus {
+		return
+	}
+
+	if err := s.userRepo.UpdateStatus(ctx, userID, status); err != nil {
+		logger.Warnf("Failed to persist presence (%s -> %s): %v", userID, status, err)
+		return
+	}
+
+	// 캐시된 사용자 레코드에도 status 가 들어 있으므로 함께 비운다.
+	if err := s.redis.Delete(ctx, cache.UserKey(userID), cache.UserStatusKey(userID)); err != nil {
+		logger.Warnf("Failed to evict user cache (%s): %v", userID, err)
+	}
+}
+
+// publish sends a presence event; subscribers are best-effort, so failures are only logged
+func (s *PresenceService) publish(ctx context.Context, event PresenceEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Warnf("Failed to marshal presence event: %v", err)
+		return
+	}
+
+	if err := s.redis.Publish(ctx, s.channel, payload); err != nil {
+		logger.Warnf("Failed to publish presence event (%s -> %s): %v", event.UserID, event.Status, err)
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPresenceService(t *testing.T, persist bool) (*PresenceService, *MockUserRepository, *miniredis.Miniredis, *redis.PubSub) {
+	t.Helper()
+
+	server, redisService := setupTestRedis(t)
+	userRepo := new(MockUserRepository)
+
+	events := redisService.Subscribe(context.Background(), "presence")
+	_, err := events.Receive(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { events.Close() })
+
+	return NewPresenceService(redisService, userRepo, time.Minute, "presence", persist), userRepo, server, events
+}
+
+// nextEvent reads one presence event or fails after a short wait
+func nextEvent(t *testing.T, events *redis.PubSub) PresenceEvent {
+	t.Helper()
+
+	select {
+	case msg := <-events.Channel():
+		var event PresenceEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no presence event published")
+		return PresenceEvent{}
+	}
+}
+
+// expireHeartbeat moves the user's last heartbeat past the TTL
+func expireHeartbeat(t *testing.T, server *miniredis.Miniredis, userID string) {
+	t.Helper()
+
+	_, err := server.ZAdd(cache.PresenceOnlineKey(), float64(time.Now().Add(-2*time.Minute).UnixMilli()), userID)
+	require.NoError(t, err)
+}
+
+func TestPresenceService_Heartbeat(t *testing.T) {
+	ctx := context.Background()
+	svc, userRepo, _, events := setupPresenceService(t, true)
+
+	userRepo.On("UpdateStatus", mock.Anything, "alice", PresenceOnline).Return(nil).Once()
+
+	state, err := svc.Heartbeat(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, PresenceOnline, state.Status)
+	assert.Equal(t, 60, state.TTLSeconds)
+
+	event := nextEvent(t, events)
+	assert.Equal(t, "alice", event.UserID)
+	assert.Equal(t, PresenceOnline, event.Status)
+
+	// 이미 온라인인 사용자의 하트비트는 이벤트도, DB 쓰기도 만들지 않는다.
+	_, err = svc.Heartbeat(ctx, "alice")
+	require.NoError(t, err)
+
+	select {
+	case msg := <-events.Channel():
+		t.Fatalf("unexpected event: %s", msg.Payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+	userRepo.AssertExpectations(t)
+}
+
+func TestPresenceService_ListOnline(t *testing.T) {
+	ctx := context.Background()
+	svc, _, server, _ := setupPresenceService(t, false)
+
+	for _, userID := range []string{"alice", "bob", "carol"} {
+		_, err := svc.Heartbeat(ctx, userID)
+		require.NoError(t, err)
+	}
+	expireHeartbeat(t, server, "bob")
+
+	userIDs, total, err := svc.ListOnline(ctx, 10, 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.ElementsMatch(t, []string{"alice", "carol"}, userIDs)
+
+	page, _, err := svc.ListOnline(ctx, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+}
+
+func TestPresenceService_Sweep(t *testing.T) {
+	ctx := context.Background()
+	svc, userRepo, server, events := setupPresenceService(t, true)
+
+	userRepo.On("UpdateStatus", mock.Anything, mock.Anything, PresenceOnline).Return(nil)
+	for _, userID := range []string{"alice", "bob"} {
+		_, err := svc.Heartbeat(ctx, userID)
+		require.NoError(t, err)
+		nextEvent(t, events)
+	}
+	expireHeartbeat(t, server, "bob")
+
+	userRepo.On("UpdateStatus", mock.Anything, "bob", PresenceOffline).Return(nil).Once()
+
+	n, err := svc.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	event := nextEvent(t, events)
+	assert.Equal(t, "bob", event.UserID)
+	assert.Equal(t, PresenceOffline, event.Status)
+
+	// 두 번째 sweep 은 이미 제거된 사용자를 다시 알리지 않는다.
+	n, err = svc.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	members, err := server.ZMembers(cache.PresenceOnlineKey())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, members)
+	userRepo.AssertExpectations(t)
+}
+
+func TestPresenceService_Leave(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, events := setupPresenceService(t, false)
+
+	_, err := svc.Heartbeat(ctx, "alice")
+	require.NoError(t, err)
+	nextEvent(t, events)
+
+	require.NoError(t, svc.Leave(ctx, "alice"))
+	assert.Equal(t, PresenceOffline, nextEvent(t, events).Status)
+
+	// 이미 나간 사용자는 조용히 넘어간다.
+	require.NoError(t, svc.Leave(ctx, "alice"))
+}
+
+func TestUserService_GetOnlineUsers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("from users.status without Redis", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		svc := NewUserService(userRepo, nil)
+
+		userRepo.On("ListByStatus", ctx, PresenceOnline, 10, 0).Return([]*repository.User{{UserID: "alice"}}, nil)
+		userRepo.On("CountByStatus", ctx, PresenceOnline).Return(int64(1), nil)
+
+		users, total, err := svc.GetOnlineUsers(ctx, 10, 0)
+
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("from presence", func(t *testing.T) {
+		presence, userRepo, _, _ := setupPresenceService(t, false)
+		svc := NewUserService(userRepo, nil)
+		svc.SetPresence(presence)
+
+		userRepo.On("GetByUserID", ctx, "alice").Return(&repository.User{UserID: "alice"}, nil)
+		userRepo.On("GetByUserID", ctx, "ghost").Return(nil, assert.AnError)
+		userRepo.On("ListByUserIDs", ctx, []string{"alice"}).Return([]*repository.User{{UserID: "alice"}}, nil)
+
+		_, err := svc.Heartbeat(ctx, "alice")
+		require.NoError(t, err)
+
+		_, err = svc.Heartbeat(ctx, "ghost")
+		assertStatus(t, err, 404)
+
+		users, total, err := svc.GetOnlineUsers(ctx, 10, 0)
+
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, int64(1), total)
+	})
+
+	t.Run("heartbeat without Redis", func(t *testing.T) {
+		svc := NewUserService(new(MockUserRepository), nil)
+
+		_, err := svc.Heartbeat(ctx, "alice")
+		assertStatus(t, err, 503)
+	})
+}
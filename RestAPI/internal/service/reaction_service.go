@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// Reaction event types published on the reactions channel
+const (
+	ReactionAdded   = "added"
+	ReactionRemoved = "removed"
+)
+
+// maxEmojiLength matches message_reactions.emoji VARCHAR(64)
+const maxEmojiLength = 64
+
+// reactionCacheMarker is a field every cached reaction hash holds.
+// 반응이 없는 메시지도 캐시에 남겨 매번 DB 를 치지 않게 한다. 빈 이모지는 받지 않으므로 실제 반응과 겹치지 않는다.
+const reactionCacheMarker = ""
+
+// ReactionEvent is published on the reactions channel when a reaction is added or removed.
+// Counts 는 변경 후의 이모지별 집계라 클라이언트가 그대로 덮어쓰면 된다.
+type ReactionEvent struct {
+	Type        string           `json:"type"`
+	MessageID   string           `json:"message_id"`
+	UserID      string           `json:"user_id"`
+	Emoji       string           `json:"emoji"`
+	RoomID      string           `json:"room_id,omitempty"`
+	RecipientID string           `json:"recipient_id,omitempty"`
+	SenderID    string           `json:"sender_id"`
+	Counts      map[string]int64 `json:"counts"`
+	At          time.Time        `json:"at"`
+}
+
+// ReactionService records emoji reactions and serves their counts.
+// 메시지를 읽을 수 있는 사람만 반응할 수 있고, 집계는 Redis 해시(message:reactions:<id>)에 캐시한다.
+type ReactionService struct {
+	reactions repository.ReactionRepository
+	messages  repository.MessageRepository
+	rooms     repository.RoomRepository
+	redis     *services.RedisService
+	channel   string
+}
+
+// NewReactionService creates a new reaction service.
+// redis 가 nil 이면 집계를 매번 DB 에서 읽고 이벤트는 발행하지 않는다.
+func NewReactionService(reactions repository.ReactionRepository, messages repository.MessageRepository, rooms repository.RoomRepository, redis *services.RedisService, channel string) *ReactionService {
+	return &ReactionService{
+		reactions: reactions,
+		messages:  messages,
+		rooms:     rooms,
+		redis:     redis,
+		channel:   channel,
+	}
+}
+
+// AddReaction adds userID's reaction and returns the message's reaction counts. Repeating it is a no-op.
+func (s *ReactionService) AddReaction(ctx context.Context, userID, messageID, emoji string) (map[string]int64, error) {
+	return s.change(ctx, ReactionAdded, userID, messageID, emoji, s.reactions.Add)
+}
+
+// RemoveReaction removes userID's reaction and returns the message's reaction counts. Removing a missing reaction is a no-op.
+func (s *ReactionService) RemoveReaction(ctx context.Context, userID, messageID, emoji string) (map[string]int64, error) {
+	return s.change(ctx, ReactionRemoved, userID, messageID, emoji, s.reactions.Remove)
+}
+
+func (s *ReactionService) change(ctx context.Context, eventType, userID, messageID, emoji string,
+	record func(ctx context.Context, messageID, userID, emoji string) (bool, error)) (map[string]int64, error) {
+	if err := validateEmoji(emoji); err != nil {
+		return nil, err
+	}
+
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if err := checkCanRead(ctx, s.rooms, message, userID); err != nil {
+		return nil, err
+	}
+	// 회수된 메시지에는 새 반응을 달 수 없지만, 달아 둔 반응을 떼는 것은 막지 않는다.
+	if eventType == ReactionAdded && message.RecalledAt != nil {
+		return nil, apperrors.New(apperrors.ErrCodeConflict, "The message has been recalled", 409)
+	}
+
+	changed, err := record(ctx, messageID, userID, emoji)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to record reaction: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to record reaction", 500)
+	}
+
+	// 변경 뒤 집계를 DB 에서 다시 읽어 캐시를 덮어쓴다. 반복 요청이어도 캐시를 맞춰 두는 편이 싸다.
+	loaded, err := s.load(ctx, []string{messageID})
+	if err != nil {
+		return nil, err
+	}
+	counts := loaded[messageID]
+
+	if changed {
+		roomID, recipientID := destination(message)
+		s.publish(ctx, ReactionEvent{
+			Type:        eventType,
+			MessageID:   messageID,
+			UserID:      userID,
+			Emoji:       emoji,
+			RoomID:      roomID,
+			RecipientID: recipientID,
+			SenderID:    message.UserID,
+			Counts:      counts,
+			At:          time.Now(),
+		})
+	}
+
+	return counts, nil
+}
+
+// AttachReactions fills the reaction counts of the messages in a list, from the cache when it has them
+func (s *ReactionService) AttachReactions(ctx context.Context, messages []*repository.Message) error {
+	counts := make(map[string]map[string]int64, len(messages))
+	missing := make([]string, 0, len(messages))
+	for _, message := range messages {
+		if cached, ok := s.cached(ctx, message.MessageID); ok {
+			counts[message.MessageID] = cached
+			continue
+		}
+		missing = append(missing, message.MessageID)
+	}
+
+	if len(missing) > 0 {
+		loaded, err := s.load(ctx, missing)
+		if err != nil {
+			return err
+		}
+		for messageID, messageCounts := range loaded {
+			counts[messageID] = messageCounts
+		}
+	}
+
+	for _, message := range messages {
+		if messageCounts := counts[message.MessageID]; len(messageCounts) > 0 {
+			message.Reactions = messageCounts
+		}
+	}
+	return nil
+}
+
+// load reads the reaction counts of the messages from the database and caches them.
+// 반응이 없는 메시지도 빈 집계로 돌려주고 캐시한다.
+func (s *ReactionService) load(ctx context.Context, messageIDs []string) (map[string]map[string]int64, error) {
+	rows, err := s.reactions.Counts(ctx, messageIDs)
+	if err != nil {
+		logger.Errorf("Failed to count reactions: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count reactions", 500)
+	}
+
+	counts := make(map[string]map[string]int64, len(messageIDs))
+	for _, messageID := range messageIDs {
+		counts[messageID] = map[string]int64{}
+	}
+	for _, row := range rows {
+		counts[row.MessageID][row.Emoji] = row.Count
+	}
+
+	for messageID, messageCounts := range counts {
+		s.store(ctx, messageID, messageCounts)
+	}
+	return counts, nil
+}
+
+// cached reads a message's reaction counts from Redis
+func (s *ReactionService) cached(ctx context.Context, messageID string) (map[string]int64, bool) {
+	if s.redis == nil {
+		return nil, false
+	}
+
+	fields, err := s.redis.HGetAll(ctx, cache.MessageReactionsKey(messageID))
+	if err != nil {
+		logger.Warnf("Failed to read cached reactions (%s): %v", messageID, err)
+		return nil, false
+	}
+	if _, ok := fields[reactionCacheMarker]; !ok {
+		return nil, false
+	}
+
+	counts := make(map[string]int64, len(fields)-1)
+	for emoji, value := range fields {
+		if emoji == reactionCacheMarker {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		counts[emoji] = count
+	}
+	return counts, true
+}
+
+// store replaces a message's cached reaction counts. 캐시 실패는 기록만 한다 — 다음 조회가 DB 에서 다시 채운다.
+func (s *ReactionService) store(ctx context.Context, messageID string, counts map[string]int64) {
+	if s.redis == nil {
+		return
+	}
+
+	key := cache.MessageReactionsKey(messageID)
+	values := make([]interface{}, 0, 2*(len(counts)+1))
+	values = append(values, reactionCacheMarker, 0)
+	for emoji, count := range counts {
+		values = append(values, emoji, count)
+	}
+
+	// 떼어 낸 이모지 필드가 남지 않도록 해시를 통째로 바꾼다.
+	if err := s.redis.Delete(ctx, key); err != nil {
+		logger.Warnf("Failed to cache reactions (%s): %v", messageID, err)
+		return
+	}
+	if err := s.redis.HSet(ctx, key, values...); err != nil {
+		logger.Warnf("Failed to cache reactions (%s): %v", messageID, err)
+		return
+	}
+	if err := s.redis.Expire(ctx, key, cache.TTLReactions); err != nil {
+		logger.Warnf("Failed to cache reactions (%s): %v", messageID, err)
+	}
+}
+
+// publish sends a reaction event. 발행 실패는 기록된 반응을 되돌리지 않는다 — 클라이언트는 다음 조회에서 집계를 맞춘다.
+func (s *ReactionService) publish(ctx context.Context, event ReactionEvent) {
+	if s.redis == nil {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Warnf("Failed to marshal reaction event: %v", err)
+		return
+	}
+
+	if err := s.redis.Publish(ctx, s.channel, payload); err != nil {
+		logger.Warnf("Failed to publish reaction event (%s %s): %v", event.MessageID, event.Type, err)
+	}
+}
+
+// validateEmoji accepts an emoji character or shortcode: up to 64 characters, without spaces or control characters
+func validateEmoji(emoji string) error {
+	invalid := emoji == "" || !utf8.ValidString(emoji) || utf8.RuneCountInString(emoji) > maxEmojiLength ||
+		strings.ContainsFunc(emoji, func(r rune) bool { return unicode.IsSpace(r) || unicode.IsControl(r) })
+	if invalid {
+		return apperrors.New(apperrors.ErrCodeValidation, "Invalid emoji", 400).
+			WithFields(map[string]string{"emoji": "must be 1-64 characters without spaces"})
+	}
+	return nil
+}
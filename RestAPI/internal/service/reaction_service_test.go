@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReactionRepository is a mock implementation of ReactionRepository
+type MockReactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockReactionRepository) Add(ctx context.Context, messageID, userID, emoji string) (bool, error) {
+	args := m.Called(ctx, messageID, userID, emoji)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReactionRepository) Remove(ctx context.Context, messageID, userID, emoji string) (bool, error) {
+	args := m.Called(ctx, messageID, userID, emoji)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReactionRepository) Counts(ctx context.Context, messageIDs []string) ([]*repository.ReactionCount, error) {
+	args := m.Called(ctx, messageIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.ReactionCount), args.Error(1)
+}
+
+func (m *MockReactionRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*repository.MessageReaction, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.MessageReaction), args.Error(1)
+}
+
+func setupReactionService(t *testing.T) (*ReactionService, *MockReactionRepository, *MockMessageRepository, *MockRoomRepository, *redis.PubSub) {
+	t.Helper()
+
+	_, redisService := setupTestRedis(t)
+	events := redisService.Subscribe(context.Background(), "message_reactions")
+	_, err := events.Receive(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { events.Close() })
+
+	reactions := new(MockReactionRepository)
+	messages := new(MockMessageRepository)
+	rooms := new(MockRoomRepository)
+	return NewReactionService(reactions, messages, rooms, redisService, "message_reactions"), reactions, messages, rooms, events
+}
+
+// nextReactionEvent reads one reaction event, or returns false when none arrives shortly
+func nextReactionEvent(t *testing.T, events *redis.PubSub) (ReactionEvent, bool) {
+	t.Helper()
+
+	select {
+	case msg := <-events.Channel():
+		var event ReactionEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		return event, true
+	case <-time.After(100 * time.Millisecond):
+		return ReactionEvent{}, false
+	}
+}
+
+func TestReactionService_AddReaction(t *testing.T) {
+	ctx := context.Background()
+	svc, reactions, messages, rooms, events := setupReactionService(t)
+
+	roomID := testRoomID
+	bob := "bob"
+	recalledAt := time.Now()
+	messages.On("GetByMessageID", ctx, "room-msg").Return(&repository.Message{MessageID: "room-msg", UserID: "alice", RoomID: &roomID}, nil)
+	messages.On("GetByMessageID", ctx, "dm").Return(&repository.Message{MessageID: "dm", UserID: "alice", RecipientID: &bob}, nil)
+	messages.On("GetByMessageID", ctx, "recalled").Return(&repository.Message{MessageID: "recalled", UserID: "alice", RecalledAt: &recalledAt}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "bob").Return(&repository.RoomMember{RoomID: testRoomID, UserID: "bob"}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "carol").Return(nil, fmt.Errorf("carol: %w", repository.ErrRoomMemberNotFound))
+
+	t.Run("first reaction publishes counts", func(t *testing.T) {
+		reactions.On("Add", ctx, "room-msg", "bob", "👍").Return(true, nil).Once()
+		reactions.On("Counts", ctx, []string{"room-msg"}).Return([]*repository.ReactionCount{
+			{MessageID: "room-msg", Emoji: "👍", Count: 2},
+		}, nil).Once()
+
+		counts, err := svc.AddReaction(ctx, "bob", "room-msg", "👍")
+
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"👍": 2}, counts)
+		event, ok := nextReactionEvent(t, events)
+		require.True(t, ok)
+		assert.Equal(t, ReactionAdded, event.Type)
+		assert.Equal(t, "room-msg", event.MessageID)
+		assert.Equal(t, testRoomID, event.RoomID)
+		assert.Equal(t, "alice", event.SenderID)
+		assert.Equal(t, map[string]int64{"👍": 2}, event.Counts)
+	})
+
+	t.Run("repeat reaction is silent", func(t *testing.T) {
+		reactions.On("Add", ctx, "room-msg", "bob", "👍").Return(false, nil).Once()
+		reactions.On("Counts", ctx, []string{"room-msg"}).Return([]*repository.ReactionCount{
+			{MessageID: "room-msg", Emoji: "👍", Count: 2},
+		}, nil).Once()
+
+		_, err := svc.AddReaction(ctx, "bob", "room-msg", "👍")
+
+		require.NoError(t, err)
+		_, ok := nextReactionEvent(t, events)
+		assert.False(t, ok)
+	})
+
+	t.Run("cannot read the message", func(t *testing.T) {
+		_, err := svc.AddReaction(ctx, "carol", "room-msg", "👍")
+		assertStatus(t, err, 404)
+
+		_, err = svc.AddReaction(ctx, "carol", "dm", "👍")
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("recalled message", func(t *testing.T) {
+		_, err := svc.AddReaction(ctx, "bob", "recalled", "👍")
+		assertStatus(t, err, 409)
+	})
+
+	t.Run("invalid emoji", func(t *testing.T) {
+		for _, emoji := range []string{"", "thumbs up", strings.Repeat("a", maxEmojiLength+1)} {
+			_, err := svc.AddReaction(ctx, "bob", "room-msg", emoji)
+			assertStatus(t, err, 400)
+		}
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		messages.On("GetByMessageID", ctx, "gone").Return(nil, errors.New("message not found")).Once()
+
+		_, err := svc.AddReaction(ctx, "bob", "gone", "👍")
+		assertStatus(t, err, 404)
+	})
+
+	reactions.AssertExpectations(t)
+}
+
+func TestReactionService_RemoveReaction(t *testing.T) {
+	ctx := context.Background()
+	svc, reactions, messages, _, events := setupReactionService(t)
+
+	bob := "bob"
+	messages.On("GetByMessageID", ctx, "dm").Return(&repository.Message{MessageID: "dm", UserID: "alice", RecipientID: &bob}, nil)
+	reactions.On("Remove", ctx, "dm", "bob", "🎉").Return(true, nil).Once()
+	reactions.On("Counts", ctx, []string{"dm"}).Return([]*repository.ReactionCount{}, nil).Once()
+
+	counts, err := svc.RemoveReaction(ctx, "bob", "dm", "🎉")
+
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+	event, ok := nextReactionEvent(t, events)
+	require.True(t, ok)
+	assert.Equal(t, ReactionRemoved, event.Type)
+	assert.Equal(t, "bob", event.RecipientID)
+	assert.Empty(t, event.Counts)
+	reactions.AssertExpectations(t)
+}
+
+func TestReactionService_AttachReactions(t *testing.T) {
+	ctx := context.Background()
+	svc, reactions, _, _, _ := setupReactionService(t)
+
+	t.Run("misses load once and are cached", func(t *testing.T) {
+		reactions.On("Counts", ctx, []string{"m1", "m2"}).Return([]*repository.ReactionCount{
+			{MessageID: "m1", Emoji: "👍", Count: 3},
+			{MessageID: "m1", Emoji: ":tada:", Count: 1},
+		}, nil).Once()
+
+		list := []*repository.Message{{MessageID: "m1"}, {MessageID: "m2"}}
+		require.NoError(t, svc.AttachReactions(ctx, list))
+		assert.Equal(t, map[string]int64{"👍": 3, ":tada:": 1}, list[0].Reactions)
+		assert.Nil(t, list[1].Reactions)
+
+		// 두 번째 조회는 캐시에서 읽으므로 Counts 가 다시 불리지 않는다. 반응이 없는 m2 도 캐시에 있다.
+		again := []*repository.Message{{MessageID: "m1"}, {MessageID: "m2"}}
+		require.NoError(t, svc.AttachReactions(ctx, again))
+		assert.Equal(t, map[string]int64{"👍": 3, ":tada:": 1}, again[0].Reactions)
+		assert.Nil(t, again[1].Reactions)
+
+		ttl, err := svc.redis.GetClient().TTL(ctx, cache.MessageReactionsKey("m1")).Result()
+		require.NoError(t, err)
+		assert.Positive(t, ttl)
+	})
+
+	t.Run("database failure", func(t *testing.T) {
+		reactions.On("Counts", ctx, []string{"m3"}).Return(nil, errors.New("connection refused")).Once()
+
+		err := svc.AttachReactions(ctx, []*repository.Message{{MessageID: "m3"}})
+		assertStatus(t, err, 500)
+	})
+
+	reactions.AssertExpectations(t)
+}
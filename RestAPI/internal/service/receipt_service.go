@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// Receipt event types published on the receipts channel
+const (
+	ReceiptDelivered = "delivered"
+	ReceiptRead      = "read"
+)
+
+// maxReadWatermarkMessages bounds how many messages one read watermark marks.
+// 오래 쌓인 방에서 한 요청이 수만 건을 쓰지 않도록 최신 메시지부터 이만큼만 기록한다.
+const maxReadWatermarkMessages = 1000
+
+// ReceiptEvent is published on the receipts channel when a user first receives or reads messages.
+// SenderID 는 메시지 하나 또는 1:1 대화의 상대처럼 보낸 사람이 한 명일 때만 채운다.
+type ReceiptEvent struct {
+	Type       string    `json:"type"`
+	UserID     string    `json:"user_id"`
+	MessageIDs []string  `json:"message_ids"`
+	RoomID     string    `json:"room_id,omitempty"`
+	SenderID   string    `json:"sender_id,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// ReceiptService records per-recipient delivery and read receipts.
+// 받을 수 있는 사람만 확인을 남길 수 있다 — 1:1 메시지는 받은 사람, 대화방 메시지는 방 멤버,
+// 브로드캐스트는 보낸 사람을 뺀 누구나다. 그 밖의 메시지는 없는 메시지와 같이 404 다.
+type ReceiptService struct {
+	receipts repository.ReceiptRepository
+	messages repository.MessageRepository
+	rooms    repository.RoomRepository
+	redis    *services.RedisService
+	channel  string
+}
+
+// NewReceiptService creates a new receipt service.
+// redis 가 nil 이면 확인은 기록하되 이벤트는 발행하지 않는다.
+func NewReceiptService(receipts repository.ReceiptRepository, messages repository.MessageRepository, rooms repository.RoomRepository, redis *services.RedisService, channel string) *ReceiptService {
+	return &ReceiptService{
+		receipts: receipts,
+		messages: messages,
+		rooms:    rooms,
+		redis:    redis,
+		channel:  channel,
+	}
+}
+
+// MarkDelivered records that the message reached userID
+func (s *ReceiptService) MarkDelivered(ctx context.Context, userID, messageID string) (*repository.MessageReceipt, error) {
+	return s.mark(ctx, ReceiptDelivered, userID, messageID, s.receipts.MarkDelivered)
+}
+
+// MarkRead records that userID read the message
+func (s *ReceiptService) MarkRead(ctx context.Context, userID, messageID string) (*repository.MessageReceipt, error) {
+	return s.mark(ctx, ReceiptRead, userID, messageID, s.receipts.MarkRead)
+}
+
+func (s *ReceiptService) mark(ctx context.Context, eventType, userID, messageID string,
+	record func(ctx context.Context, messageID, userID string) (*repository.MessageReceipt, bool, error)) (*repository.MessageReceipt, error) {
+	message, err := s.recipientMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, changed, err := record(ctx, messageID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to record %s receipt: %v", eventType, err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to record receipt", 500)
+	}
+
+	if changed {
+		event := ReceiptEvent{Type: eventType, UserID: userID, MessageIDs: []string{messageID}, SenderID: message.UserID, At: time.Now()}
+		if message.RoomID != nil {
+			event.RoomID = *message.RoomID
+		}
+		s.publish(ctx, event)
+	}
+	return receipt, nil
+}
+
+// MarkReadUpTo marks as read every message of a room or direct message thread up to upToMessageID.
+// 워터마크 메시지는 그 대화에 속해야 한다. 새로 읽음 처리된 message_id 를 돌려준다.
+func (s *ReceiptService) MarkReadUpTo(ctx context.Context, userID string, scope repository.ReceiptScope, upToMessageID string) ([]string, error) {
+	if (scope.RoomID == "") == (scope.PeerID == "") {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Exactly one of room_id or peer_id is required", 400)
+	}
+
+	upTo, err := s.messages.GetByMessageID(ctx, upToMessageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", upToMessageID)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	event := ReceiptEvent{Type: ReceiptRead, UserID: userID, RoomID: scope.RoomID, SenderID: scope.PeerID}
+	if scope.RoomID != "" {
+		if err := s.checkRoomMember(ctx, scope.RoomID, userID); err != nil {
+			return nil, err
+		}
+		if upTo.RoomID == nil || *upTo.RoomID != scope.RoomID {
+			return nil, apperrors.New(apperrors.ErrCodeValidation, "up_to_message_id is not a message of the room", 400)
+		}
+	} else if !isDirectBetween(upTo, userID, scope.PeerID) {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "up_to_message_id is not a message of the conversation", 400)
+	}
+
+	messageIDs, err := s.receipts.MarkReadUpTo(ctx, userID, scope, upTo, maxReadWatermarkMessages)
+	if err != nil {
+		logger.Errorf("Failed to record read watermark: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to record receipts", 500)
+	}
+
+	if len(messageIDs) > 0 {
+		event.MessageIDs = messageIDs
+		event.At = time.Now()
+		s.publish(ctx, event)
+	}
+	return messageIDs, nil
+}
+
+// Summary counts the recipients that received and read a message
+func (s *ReceiptService) Summary(ctx context.Context, messageID string) (*repository.ReceiptSummary, error) {
+	summary, err := s.receipts.Summary(ctx, messageID)
+	if err != nil {
+		logger.Errorf("Failed to summarize receipts: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get receipts", 500)
+	}
+	return summary, nil
+}
+
+// ListReceipts lists who received and read a message. Only the sender or an admin may see them.
+// callerID 가 비어 있으면(인증 비활성) 제한하지 않는다.
+func (s *ReceiptService) ListReceipts(ctx context.Context, callerID string, admin bool, messageID string, limit, offset int) ([]*repository.MessageReceipt, int64, error) {
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+	if callerID != "" && !admin && message.UserID != callerID {
+		return nil, 0, apperrors.New(apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	receipts, err := s.receipts.List(ctx, messageID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list receipts: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get receipts", 500)
+	}
+
+	total, err := s.receipts.Count(ctx, messageID)
+	if err != nil {
+		logger.Errorf("Failed to count receipts: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count receipts", 500)
+	}
+
+	return receipts, total, nil
+}
+
+// recipientMessage loads the message and fails unless userID is one of its recipients
+func (s *ReceiptService) recipientMessage(ctx context.Context, userID, messageID string) (*repository.Message, error) {
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	if message.UserID == userID {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Senders do not acknowledge their own messages", 400)
+	}
+
+	switch {
+	case message.RecipientID != nil:
+		if *message.RecipientID != userID {
+			return nil, apperrors.New(apperrors.ErrCodeNotFound, "Message not found", 404)
+		}
+	case message.RoomID != nil:
+		if err := s.checkRoomMember(ctx, *message.RoomID, userID); err != nil {
+			return nil, apperrors.New(apperrors.ErrCodeNotFound, "Message not found", 404)
+		}
+	}
+	return message, nil
+}
+
+// checkRoomMember fails with 404 unless userID is a member of the room
+func (s *ReceiptService) checkRoomMember(ctx context.Context, roomID, userID string) error {
+	_, err := s.rooms.GetMember(ctx, roomID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.New(apperrors.ErrCodeNotFound, "Room not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get room member: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get room member", 500)
+	}
+	return nil
+}
+
+// isDirectBetween reports whether the message is a direct message between userID and peerID in either direction
+func isDirectBetween(message *repository.Message, userID, peerID string) bool {
+	if message.RecipientID == nil {
+		return false
+	}
+	return (message.UserID == userID && *message.RecipientID == peerID) ||
+		(message.UserID == peerID && *message.RecipientID == userID)
+}
+
+// publish sends a receipt event. 발행 실패는 기록된 확인을 되돌리지 않는다 — 클라이언트는 다음 조회에서 상태를 맞춘다.
+func (s *ReceiptService) publish(ctx context.Context, event ReceiptEvent) {
+	if s.redis == nil {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Warnf("Failed to marshal receipt event: %v", err)
+		return
+	}
+
+	if err := s.redis.Publish(ctx, s.channel, payload); err != nil {
+		logger.Warnf("Failed to publish receipt event (%s %s): %v", event.UserID, event.Type, err)
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReceiptRepository is a mock implementation of ReceiptRepository
+type MockReceiptRepository struct {
+	mock.Mock
+}
+
+func (m *MockReceiptRepository) MarkDelivered(ctx context.Context, messageID, userID string) (*repository.MessageReceipt, bool, error) {
+	args := m.Called(ctx, messageID, userID)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*repository.MessageReceipt), args.Bool(1), args.Error(2)
+}
+
+func (m *MockReceiptRepository) MarkRead(ctx context.Context, messageID, userID string) (*repository.MessageReceipt, bool, error) {
+	args := m.Called(ctx, messageID, userID)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*repository.MessageReceipt), args.Bool(1), args.Error(2)
+}
+
+func (m *MockReceiptRepository) MarkReadUpTo(ctx context.Context, userID string, scope repository.ReceiptScope, upTo *repository.Message, limit int) ([]string, error) {
+	args := m.Called(ctx, userID, scope, upTo, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockReceiptRepository) Summary(ctx context.Context, messageID string) (*repository.ReceiptSummary, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.ReceiptSummary), args.Error(1)
+}
+
+func (m *MockReceiptRepository) List(ctx context.Context, messageID string, limit, offset int) ([]*repository.MessageReceipt, error) {
+	args := m.Called(ctx, messageID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.MessageReceipt), args.Error(1)
+}
+
+func (m *MockReceiptRepository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*repository.MessageReceipt, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.MessageReceipt), args.Error(1)
+}
+
+func (m *MockReceiptRepository) Count(ctx context.Context, messageID string) (int64, error) {
+	args := m.Called(ctx, messageID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func setupReceiptService(t *testing.T) (*ReceiptService, *MockReceiptRepository, *MockMessageRepository, *MockRoomRepository, *redis.PubSub) {
+	t.Helper()
+
+	_, redisService := setupTestRedis(t)
+	events := redisService.Subscribe(context.Background(), "message_receipts")
+	_, err := events.Receive(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { events.Close() })
+
+	receipts := new(MockReceiptRepository)
+	messages := new(MockMessageRepository)
+	rooms := new(MockRoomRepository)
+	return NewReceiptService(receipts, messages, rooms, redisService, "message_receipts"), receipts, messages, rooms, events
+}
+
+// nextReceiptEvent reads one receipt event, or returns false when none arrives shortly
+func nextReceiptEvent(t *testing.T, events *redis.PubSub) (ReceiptEvent, bool) {
+	t.Helper()
+
+	select {
+	case msg := <-events.Channel():
+		var event ReceiptEvent
+		require.NoError(t, json.Unmarshal([]byte(msg.Payload), &event))
+		return event, true
+	case <-time.After(100 * time.Millisecond):
+		return ReceiptEvent{}, false
+	}
+}
+
+func TestReceiptService_MarkRead(t *testing.T) {
+	ctx := context.Background()
+	svc, receipts, messages, rooms, events := setupReceiptService(t)
+
+	roomID := testRoomID
+	bob := "bob"
+	messages.On("GetByMessageID", ctx, "room-msg").Return(&repository.Message{MessageID: "room-msg", UserID: "alice", RoomID: &roomID}, nil)
+	messages.On("GetByMessageID", ctx, "dm").Return(&repository.Message{MessageID: "dm", UserID: "alice", RecipientID: &bob}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "bob").Return(&repository.RoomMember{RoomID: testRoomID, UserID: "bob"}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "carol").Return(nil, fmt.Errorf("carol: %w", repository.ErrRoomMemberNotFound))
+
+	t.Run("first read publishes", func(t *testing.T) {
+		receipts.On("MarkRead", ctx, "room-msg", "bob").Return(&repository.MessageReceipt{MessageID: "room-msg", UserID: "bob"}, true, nil).Once()
+
+		receipt, err := svc.MarkRead(ctx, "bob", "room-msg")
+
+		require.NoError(t, err)
+		assert.Equal(t, "bob", receipt.UserID)
+		event, ok := nextReceiptEvent(t, events)
+		require.True(t, ok)
+		assert.Equal(t, ReceiptRead, event.Type)
+		assert.Equal(t, []string{"room-msg"}, event.MessageIDs)
+		assert.Equal(t, testRoomID, event.RoomID)
+		assert.Equal(t, "alice", event.SenderID)
+	})
+
+	t.Run("repeat read is silent", func(t *testing.T) {
+		receipts.On("MarkRead", ctx, "room-msg", "bob").Return(&repository.MessageReceipt{MessageID: "room-msg", UserID: "bob"}, false, nil).Once()
+
+		_, err := svc.MarkRead(ctx, "bob", "room-msg")
+
+		require.NoError(t, err)
+		_, ok := nextReceiptEvent(t, events)
+		assert.False(t, ok)
+	})
+
+	t.Run("not a recipient", func(t *testing.T) {
+		_, err := svc.MarkRead(ctx, "carol", "room-msg")
+		assertStatus(t, err, 404)
+
+		_, err = svc.MarkRead(ctx, "carol", "dm")
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("own message", func(t *testing.T) {
+		_, err := svc.MarkRead(ctx, "alice", "dm")
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("message not found", func(t *testing.T) {
+		messages.On("GetByMessageID", ctx, "gone").Return(nil, errors.New("message not found")).Once()
+
+		_, err := svc.MarkDelivered(ctx, "bob", "gone")
+		assertStatus(t, err, 404)
+	})
+}
+
+func TestReceiptService_MarkReadUpTo(t *testing.T) {
+	ctx := context.Background()
+	svc, receipts, messages, rooms, events := setupReceiptService(t)
+
+	roomID := testRoomID
+	bob := "bob"
+	roomMessage := &repository.Message{ID: 7, MessageID: "room-msg", UserID: "alice", RoomID: &roomID}
+	directMessage := &repository.Message{ID: 8, MessageID: "dm", UserID: "alice", RecipientID: &bob}
+	messages.On("GetByMessageID", ctx, "room-msg").Return(roomMessage, nil)
+	messages.On("GetByMessageID", ctx, "dm").Return(directMessage, nil)
+	rooms.On("GetMember", ctx, testRoomID, "bob").Return(&repository.RoomMember{RoomID: testRoomID, UserID: "bob"}, nil)
+
+	t.Run("room", func(t *testing.T) {
+		scope := repository.ReceiptScope{RoomID: testRoomID}
+		receipts.On("MarkReadUpTo", ctx, "bob", scope, roomMessage, maxReadWatermarkMessages).Return([]string{"m1", "room-msg"}, nil).Once()
+
+		ids, err := svc.MarkReadUpTo(ctx, "bob", scope, "room-msg")
+
+		require.NoError(t, err)
+		assert.Len(t, ids, 2)
+		event, ok := nextReceiptEvent(t, events)
+		require.True(t, ok)
+		assert.Equal(t, []string{"m1", "room-msg"}, event.MessageIDs)
+		assert.Equal(t, testRoomID, event.RoomID)
+	})
+
+	t.Run("direct thread", func(t *testing.T) {
+		scope := repository.ReceiptScope{PeerID: "alice"}
+		receipts.On("MarkReadUpTo", ctx, "bob", scope, directMessage, maxReadWatermarkMessages).Return([]string{}, nil).Once()
+
+		ids, err := svc.MarkReadUpTo(ctx, "bob", scope, "dm")
+
+		require.NoError(t, err)
+		assert.Empty(t, ids)
+		_, ok := nextReceiptEvent(t, events)
+		assert.False(t, ok)
+	})
+
+	t.Run("watermark outside the scope", func(t *testing.T) {
+		_, err := svc.MarkReadUpTo(ctx, "bob", repository.ReceiptScope{PeerID: "carol"}, "dm")
+		assertStatus(t, err, 400)
+
+		_, err = svc.MarkReadUpTo(ctx, "bob", repository.ReceiptScope{RoomID: testRoomID}, "dm")
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("scope required", func(t *testing.T) {
+		_, err := svc.MarkReadUpTo(ctx, "bob", repository.ReceiptScope{}, "dm")
+		assertStatus(t, err, 400)
+
+		_, err = svc.MarkReadUpTo(ctx, "bob", repository.ReceiptScope{RoomID: testRoomID, PeerID: "alice"}, "dm")
+		assertStatus(t, err, 400)
+	})
+}
+
+func TestReceiptService_ListReceipts(t *testing.T) {
+	ctx := context.Background()
+	svc, receipts, messages, _, _ := setupReceiptService(t)
+
+	messages.On("GetByMessageID", ctx, "m1").Return(&repository.Message{MessageID: "m1", UserID: "alice"}, nil)
+	receipts.On("List", ctx, "m1", 20, 0).Return([]*repository.MessageReceipt{{MessageID: "m1", UserID: "bob"}}, nil)
+	receipts.On("Count", ctx, "m1").Return(int64(1), nil)
+
+	list, total, err := svc.ListReceipts(ctx, "alice", false, "m1", 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(1), total)
+
+	_, _, err = svc.ListReceipts(ctx, "bob", true, "m1", 20, 0)
+	assert.NoError(t, err)
+
+	_, _, err = svc.ListReceipts(ctx, "bob", false, "m1", 20, 0)
+	assertStatus(t, err, 404)
+}
@@ -0,0 +1,439 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+const (
+	maxRoomNameLength        = 100
+	maxRoomDescriptionLength = 1000
+)
+
+// RoomActor identifies the caller of a room operation.
+// UserID 가 비어 있으면 호출자를 식별할 수 없는 경우(인증 비활성)다 — 다른 라우트와 같이 관리자처럼 제한하지 않는다.
+type RoomActor struct {
+	UserID      string
+	Admin       bool
+	AuthEnabled bool
+}
+
+// unrestricted reports whether room roles are not checked for the actor.
+// 식별되지 않은 호출자는 인증이 꺼져 있을 때만 제한 없이 통과한다.
+func (a RoomActor) unrestricted() bool {
+	return a.Admin || (a.UserID == "" && !a.AuthEnabled)
+}
+
+// anonymous reports whether auth is enabled but the caller was not identified
+func (a RoomActor) anonymous() bool {
+	return a.UserID == "" && a.AuthEnabled
+}
+
+// errRoomAuthRequired rejects changes by callers that were not identified
+func errRoomAuthRequired() error {
+	return apperrors.New(apperrors.ErrCodeUnauthorized, "Authentication required", 401)
+}
+
+// CreateRoomInput holds the fields of a new room.
+// OwnerID 는 호출자를 식별할 수 없거나 관리자가 다른 사용자 대신 만들 때만 쓴다.
+type CreateRoomInput struct {
+	Name        string
+	Description string
+	IsPrivate   bool
+	OwnerID     string
+}
+
+// RoomService handles rooms, membership and room history.
+// 비공개 방은 멤버가 아니면 404 로 답해 존재 여부를 드러내지 않는다.
+type RoomService struct {
+	rooms    repository.RoomRepository
+	messages repository.MessageRepository
+}
+
+// NewRoomService creates a new room service
+func NewRoomService(rooms repository.RoomRepository, messages repository.MessageRepository) *RoomService {
+	return &RoomService{
+		rooms:    rooms,
+		messages: messages,
+	}
+}
+
+// CreateRoom creates a room owned by the actor
+func (s *RoomService) CreateRoom(ctx context.Context, actor RoomActor, input CreateRoomInput) (*repository.Room, error) {
+	if actor.anonymous() {
+		return nil, errRoomAuthRequired()
+	}
+
+	ownerID := actor.UserID
+	if ownerID == "" || (actor.Admin && input.OwnerID != "") {
+		ownerID = input.OwnerID
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	fields := validateRoomFields(&input.Name, &input.Description)
+	if ownerID == "" {
+		fields["owner_id"] = "is required"
+	}
+	if len(fields) > 0 {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid room", 400).WithFields(fields)
+	}
+
+	room := &repository.Room{
+		Name:        input.Name,
+		Description: input.Description,
+		IsPrivate:   input.IsPrivate,
+		CreatedBy:   ownerID,
+	}
+	if err := s.rooms.Create(ctx, room, ownerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Owner not found", 404)
+		}
+		logger.Errorf("Failed to create room: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to create room", 500)
+	}
+
+	logger.Infof("Room created: %s (%q, owner %s)", room.RoomID, room.Name, ownerID)
+	return room, nil
+}
+
+// GetRoom retrieves a room the actor can see
+func (s *RoomService) GetRoom(ctx context.Context, actor RoomActor, roomID string) (*repository.Room, error) {
+	room, _, err := s.load(ctx, actor, roomID)
+	return room, err
+}
+
+// ListRooms lists public rooms and the private rooms the actor belongs to
+func (s *RoomService) ListRooms(ctx context.Context, actor RoomActor, limit, offset int) ([]*repository.Room, int64, error) {
+	viewerID, all := actor.UserID, actor.unrestricted()
+
+	rooms, err := s.rooms.List(ctx, viewerID, all, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list rooms: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list rooms", 500)
+	}
+
+	total, err := s.rooms.Count(ctx, viewerID, all)
+	if err != nil {
+		logger.Errorf("Failed to count rooms: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count rooms", 500)
+	}
+
+	return rooms, total, nil
+}
+
+// UpdateRoom changes a room's name, description or visibility; only owners may
+func (s *RoomService) UpdateRoom(ctx context.Context, actor RoomActor, roomID string, update repository.RoomUpdate) (*repository.Room, error) {
+	if _, err := s.requireRole(ctx, actor, roomID, repository.RoomRoleOwner); err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		update.Name = &name
+	}
+	if fields := validateRoomFields(update.Name, update.Description); len(fields) > 0 {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid room", 400).WithFields(fields)
+	}
+
+	room, err := s.rooms.Update(ctx, roomID, update)
+	if err != nil {
+		return nil, roomError(err, "Failed to update room")
+	}
+	return room, nil
+}
+
+// DeleteRoom deletes a room and its memberships; only owners may. The room's messages are kept.
+func (s *RoomService) DeleteRoom(ctx context.Context, actor RoomActor, roomID string) error {
+	if _, err := s.requireRole(ctx, actor, roomID, repository.RoomRoleOwner); err != nil {
+		return err
+	}
+
+	if err := s.rooms.Delete(ctx, roomID); err != nil {
+		return roomError(err, "Failed to delete room")
+	}
+
+	logger.Infof("Room deleted: %s", roomID)
+	return nil
+}
+
+// ListMembers lists the members of a room the actor can see
+func (s *RoomService) ListMembers(ctx context.Context, actor RoomActor, roomID string, limit, offset int) ([]*repository.RoomMember, int64, error) {
+	if _, _, err := s.load(ctx, actor, roomID); err != nil {
+		return nil, 0, err
+	}
+
+	members, err := s.rooms.ListMembers(ctx, roomID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list room members: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list room members", 500)
+	}
+
+	total, err := s.rooms.CountMembers(ctx, roomID)
+	if err != nil {
+		logger.Errorf("Failed to count room members: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count room members", 500)
+	}
+
+	return members, total, nil
+}
+
+// SetMember adds a user to a room or changes the member's role.
+// 스스로 참여는 공개 방에 member 로만 된다. moderator 는 member 만 추가할 수 있고, 역할 변경은 owner 만 한다.
+func (s *RoomService) SetMember(ctx context.Context, actor RoomActor, roomID, userID, role string) (*repository.RoomMember, error) {
+	if role == "" {
+		role = repository.RoomRoleMember
+	}
+	if !repository.IsValidRoomRole(role) {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid room member", 400).
+			WithFields(map[string]string{"role": "must be one of owner, moderator, member"})
+	}
+
+	if actor.anonymous() {
+		return nil, errRoomAuthRequired()
+	}
+
+	_, actorMember, err := s.load(ctx, actor, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !actor.unrestricted() {
+		target, err := s.member(ctx, roomID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSetMember(actor, actorMember, target, userID, role); err != nil {
+			return nil, err
+		}
+	}
+
+	member, err := s.rooms.SetMember(ctx, roomID, userID, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) && !errors.Is(err, repository.ErrRoomNotFound) {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "User not found", 404)
+		}
+		return nil, roomError(err, "Failed to set room member")
+	}
+
+	logger.Infof("Room member set: %s in %s as %s (by %s)", userID, roomID, role, actor.UserID)
+	return member, nil
+}
+
+// Join adds the actor to a public room as a member.
+// 이미 멤버면 지금 역할을 그대로 돌려준다 — owner 가 다시 join 해서 강등되지 않도록.
+func (s *RoomService) Join(ctx context.Context, actor RoomActor, roomID string) (*repository.RoomMember, error) {
+	_, member, err := s.load(ctx, actor, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil {
+		return member, nil
+	}
+	return s.SetMember(ctx, actor, roomID, actor.UserID, repository.RoomRoleMember)
+}
+
+// RemoveMember removes a user from a room. Members may leave; owners remove anyone, moderators remove members.
+func (s *RoomService) RemoveMember(ctx context.Context, actor RoomActor, roomID, userID string) error {
+	if actor.anonymous() {
+		return errRoomAuthRequired()
+	}
+
+	_, actorMember, err := s.load(ctx, actor, roomID)
+	if err != nil {
+		return err
+	}
+
+	if !actor.unrestricted() && actor.UserID != userID {
+		target, err := s.member(ctx, roomID, userID)
+		if err != nil {
+			return err
+		}
+		if target == nil {
+			return apperrors.New(apperrors.ErrCodeNotFound, "Member not found", 404)
+		}
+		if !canManage(actorMember, target.Role) {
+			return apperrors.New(apperrors.ErrCodeForbidden, "Not allowed to remove this member", 403)
+		}
+	}
+
+	if err := s.rooms.RemoveMember(ctx, roomID, userID); err != nil {
+		if errors.Is(err, repository.ErrRoomMemberNotFound) {
+			return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Member not found", 404)
+		}
+		return roomError(err, "Failed to remove room member")
+	}
+
+	logger.Infof("Room member removed: %s from %s (by %s)", userID, roomID, actor.UserID)
+	return nil
+}
+
+// ListMessages retrieves the history of a room the actor can see, newest first
+func (s *RoomService) ListMessages(ctx context.Context, actor RoomActor, roomID string, limit, offset int) ([]*repository.Message, int64, error) {
+	if _, _, err := s.load(ctx, actor, roomID); err != nil {
+		return nil, 0, err
+	}
+
+	messages, err := s.messages.ListByRoom(ctx, roomID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to get room messages: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get messages", 500)
+	}
+
+	total, err := s.messages.CountByRoom(ctx, roomID)
+	if err != nil {
+		logger.Errorf("Failed to count room messages: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count messages", 500)
+	}
+
+	if err := withThreadSummaries(ctx, s.messages, messages); err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+// CheckCanPost fails unless userID is a member of the room
+func (s *RoomService) CheckCanPost(ctx context.Context, roomID, userID string) error {
+	if _, err := uuid.Parse(roomID); err != nil {
+		return apperrors.New(apperrors.ErrCodeValidation, "Invalid room_id", 400).
+			WithFields(map[string]string{"room_id": "must be a UUID"})
+	}
+
+	member, err := s.member(ctx, roomID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return apperrors.New(apperrors.ErrCodeForbidden, "User is not a member of the room", 403)
+	}
+	return nil
+}
+
+// load returns the room and the actor's membership (nil when not a member).
+// 볼 수 없는 비공개 방은 없는 방과 같이 404 다.
+func (s *RoomService) load(ctx context.Context, actor RoomActor, roomID string) (*repository.Room, *repository.RoomMember, error) {
+	notFound := apperrors.New(apperrors.ErrCodeNotFound, "Room not found", 404)
+	if _, err := uuid.Parse(roomID); err != nil {
+		return nil, nil, notFound
+	}
+
+	room, err := s.rooms.GetByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, nil, roomError(err, "Failed to get room")
+	}
+
+	var member *repository.RoomMember
+	if actor.UserID != "" {
+		if member, err = s.member(ctx, roomID, actor.UserID); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if room.IsPrivate && member == nil && !actor.unrestricted() {
+		return nil, nil, notFound
+	}
+	return room, member, nil
+}
+
+// requireRole loads the room and fails unless the actor has role in it
+func (s *RoomService) requireRole(ctx context.Context, actor RoomActor, roomID, role string) (*repository.Room, error) {
+	if actor.anonymous() {
+		return nil, errRoomAuthRequired()
+	}
+
+	room, member, err := s.load(ctx, actor, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.unrestricted() && (member == nil || member.Role != role) {
+		return nil, apperrors.New(apperrors.ErrCodeForbidden, "Only room "+role+"s can do this", 403)
+	}
+	return room, nil
+}
+
+// member returns the user's membership, or nil when the user is not a member
+func (s *RoomService) member(ctx context.Context, roomID, userID string) (*repository.RoomMember, error) {
+	member, err := s.rooms.GetMember(ctx, roomID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to get room member: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get room member", 500)
+	}
+	return member, nil
+}
+
+// checkSetMember applies the room role rules to a SetMember call by a member or non-member.
+// 비공개 방의 비멤버는 load 에서 이미 404 이므로 여기서 본인 참여는 공개 방뿐이다.
+func checkSetMember(actor RoomActor, actorMember, target *repository.RoomMember, userID, role string) error {
+	if actorMember == nil {
+		if actor.UserID == userID && role == repository.RoomRoleMember {
+			return nil
+		}
+		return apperrors.New(apperrors.ErrCodeForbidden, "Only room members can add others; self-join is as member", 403)
+	}
+
+	// 이미 같은 역할이면 바뀌는 것이 없다.
+	if target != nil && target.Role == role {
+		return nil
+	}
+
+	if actorMember.Role == repository.RoomRoleOwner {
+		return nil
+	}
+	if target == nil && role == repository.RoomRoleMember && canManage(actorMember, role) {
+		return nil
+	}
+	return apperrors.New(apperrors.ErrCodeForbidden, "Not allowed to add or change this member", 403)
+}
+
+// canManage reports whether a member may add or remove members with the target role
+func canManage(actorMember *repository.RoomMember, targetRole string) bool {
+	if actorMember == nil {
+		return false
+	}
+	switch actorMember.Role {
+	case repository.RoomRoleOwner:
+		return true
+	case repository.RoomRoleModerator:
+		return targetRole == repository.RoomRoleMember
+	}
+	return false
+}
+
+// validateRoomFields checks the non-nil name and description and returns field errors
+func validateRoomFields(name, description *string) map[string]string {
+	fields := map[string]string{}
+	if name != nil {
+		if *name == "" {
+			fields["name"] = "is required"
+		} else if utf8.RuneCountInString(*name) > maxRoomNameLength {
+			fields["name"] = "must be at most 100 characters"
+		}
+	}
+	if description != nil && utf8.RuneCountInString(*description) > maxRoomDescriptionLength {
+		fields["description"] = "must be at most 1000 characters"
+	}
+	return fields
+}
+
+// roomError maps repository errors to API errors
+func roomError(err error, message string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Room not found", 404)
+	case errors.Is(err, repository.ErrLastOwner):
+		return apperrors.Wrap(err, apperrors.ErrCodeConflict, "A room must keep at least one owner; promote another member first", 409)
+	}
+	logger.Errorf("%s: %v", message, err)
+	return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, message, 500)
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testRoomID = "0b6f4f7e-9a51-4c55-8a0e-7f3a2d1c9b10"
+
+// MockRoomRepository is a mock implementation of RoomRepository
+type MockRoomRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoomRepository) Create(ctx context.Context, room *repository.Room, ownerID string) error {
+	args := m.Called(ctx, room, ownerID)
+	if args.Error(0) == nil {
+		room.RoomID = testRoomID
+	}
+	return args.Error(0)
+}
+
+func (m *MockRoomRepository) GetByRoomID(ctx context.Context, roomID string) (*repository.Room, error) {
+	args := m.Called(ctx, roomID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) List(ctx context.Context, viewerID string, includePrivate bool, limit, offset int) ([]*repository.Room, error) {
+	args := m.Called(ctx, viewerID, includePrivate, limit, offset)
+	return args.Get(0).([]*repository.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) Count(ctx context.Context, viewerID string, includePrivate bool) (int64, error) {
+	args := m.Called(ctx, viewerID, includePrivate)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoomRepository) Update(ctx context.Context, roomID string, update repository.RoomUpdate) (*repository.Room, error) {
+	args := m.Called(ctx, roomID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.Room), args.Error(1)
+}
+
+func (m *MockRoomRepository) Delete(ctx context.Context, roomID string) error {
+	args := m.Called(ctx, roomID)
+	return args.Error(0)
+}
+
+func (m *MockRoomRepository) GetMember(ctx context.Context, roomID, userID string) (*repository.RoomMember, error) {
+	args := m.Called(ctx, roomID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.RoomMember), args.Error(1)
+}
+
+func (m *MockRoomRepository) ListMembers(ctx context.Context, roomID string, limit, offset int) ([]*repository.RoomMember, error) {
+	args := m.Called(ctx, roomID, limit, offset)
+	return args.Get(0).([]*repository.RoomMember), args.Error(1)
+}
+
+func (m *MockRoomRepository) ListMemberships(ctx context.Context, userID string, limit, offset int) ([]*repository.RoomMember, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]*repository.RoomMember), args.Error(1)
+}
+
+func (m *MockRoomRepository) CountMembers(ctx context.Context, roomID string) (int64, error) {
+	args := m.Called(ctx, roomID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoomRepository) SetMember(ctx context.Context, roomID, userID, role string) (*repository.RoomMember, error) {
+	args := m.Called(ctx, roomID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.RoomMember), args.Error(1)
+}
+
+func (m *MockRoomRepository) RemoveMember(ctx context.Context, roomID, userID string) error {
+	args := m.Called(ctx, roomID, userID)
+	return args.Error(0)
+}
+
+func setupRoomService() (*RoomService, *MockRoomRepository, *MockMessageRepository) {
+	rooms := new(MockRoomRepository)
+	messages := new(MockMessageRepository)
+	return NewRoomService(rooms, messages), rooms, messages
+}
+
+// expectRoom stubs the room lookup and the given memberships; users missing from roles are not members
+func expectRoom(rooms *MockRoomRepository, private bool, roles map[string]string) {
+	rooms.On("GetByRoomID", mock.Anything, testRoomID).
+		Return(&repository.Room{RoomID: testRoomID, Name: "general", IsPrivate: private}, nil)
+
+	for _, userID := range []string{"alice", "bob", "carol", "dave"} {
+		if role, ok := roles[userID]; ok {
+			rooms.On("GetMember", mock.Anything, testRoomID, userID).
+				Return(&repository.RoomMember{RoomID: testRoomID, UserID: userID, Role: role}, nil)
+		} else {
+			rooms.On("GetMember", mock.Anything, testRoomID, userID).
+				Return(nil, fmt.Errorf("%s: %w", userID, repository.ErrRoomMemberNotFound))
+		}
+	}
+}
+
+func TestRoomService_CreateRoom(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("caller becomes owner", func(t *testing.T) {
+		svc, rooms, _ := setupRoomService()
+		rooms.On("Create", ctx, mock.MatchedBy(func(r *repository.Room) bool {
+			return r.Name == "general" && r.CreatedBy == "alice"
+		}), "alice").Return(nil)
+
+		room, err := svc.CreateRoom(ctx, RoomActor{UserID: "alice"}, CreateRoomInput{Name: "  general ", OwnerID: "mallory"})
+
+		require.NoError(t, err)
+		assert.Equal(t, testRoomID, room.RoomID)
+		rooms.AssertExpectations(t)
+	})
+
+	t.Run("owner required when caller is unidentified", func(t *testing.T) {
+		svc, _, _ := setupRoomService()
+
+		_, err := svc.CreateRoom(ctx, RoomActor{}, CreateRoomInput{Name: "general"})
+
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("name too long", func(t *testing.T) {
+		svc, _, _ := setupRoomService()
+
+		_, err := svc.CreateRoom(ctx, RoomActor{UserID: "alice"}, CreateRoomInput{Name: string(make([]rune, 101))})
+
+		assertStatus(t, err, 400)
+	})
+}
+
+func TestRoomService_GetRoom_PrivateHidden(t *testing.T) {
+	ctx := context.Background()
+	svc, rooms, _ := setupRoomService()
+	expectRoom(rooms, true, map[string]string{"alice": repository.RoomRoleOwner})
+
+	_, err := svc.GetRoom(ctx, RoomActor{UserID: "bob"}, testRoomID)
+	assertStatus(t, err, 404)
+
+	room, err := svc.GetRoom(ctx, RoomActor{UserID: "alice"}, testRoomID)
+	require.NoError(t, err)
+	assert.Equal(t, "general", room.Name)
+
+	_, err = svc.GetRoom(ctx, RoomActor{UserID: "bob", Admin: true}, testRoomID)
+	assert.NoError(t, err)
+
+	_, err = svc.GetRoom(ctx, RoomActor{UserID: "bob"}, "not-a-uuid")
+	assertStatus(t, err, 404)
+}
+
+func TestRoomService_AnonymousWithAuthEnabled(t *testing.T) {
+	ctx := context.Background()
+	svc, rooms, _ := setupRoomService()
+	expectRoom(rooms, true, map[string]string{"alice": repository.RoomRoleOwner})
+	anonymous := RoomActor{AuthEnabled: true}
+
+	// 인증이 켜져 있으면 식별되지 않은 호출자는 관리자처럼 통과하지 않는다.
+	_, err := svc.GetRoom(ctx, anonymous, testRoomID)
+	assertStatus(t, err, 404)
+
+	name := "renamed"
+	_, err = svc.UpdateRoom(ctx, anonymous, testRoomID, repository.RoomUpdate{Name: &name})
+	assertStatus(t, err, 401)
+
+	assertStatus(t, svc.DeleteRoom(ctx, anonymous, testRoomID), 401)
+	assertStatus(t, svc.RemoveMember(ctx, anonymous, testRoomID, "alice"), 401)
+
+	_, err = svc.SetMember(ctx, anonymous, testRoomID, "mallory", repository.RoomRoleOwner)
+	assertStatus(t, err, 401)
+
+	_, err = svc.CreateRoom(ctx, anonymous, CreateRoomInput{Name: "general", OwnerID: "mallory"})
+	assertStatus(t, err, 401)
+
+	rooms.On("List", ctx, "", false, 20, 0).Return([]*repository.Room{}, nil)
+	rooms.On("Count", ctx, "", false).Return(int64(0), nil)
+	_, _, err = svc.ListRooms(ctx, anonymous, 20, 0)
+	require.NoError(t, err)
+
+	rooms.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	rooms.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	rooms.AssertNotCalled(t, "SetMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	rooms.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRoomService_UpdateRoom_OwnersOnly(t *testing.T) {
+	ctx := context.Background()
+	svc, rooms, _ := setupRoomService()
+	expectRoom(rooms, false, map[string]string{"alice": repository.RoomRoleOwner, "bob": repository.RoomRoleModerator})
+
+	name := "random"
+	update := repository.RoomUpdate{Name: &name}
+
+	_, err := svc.UpdateRoom(ctx, RoomActor{UserID: "bob"}, testRoomID, update)
+	assertStatus(t, err, 403)
+
+	rooms.On("Update", ctx, testRoomID, update).Return(&repository.Room{RoomID: testRoomID, Name: name}, nil)
+	room, err := svc.UpdateRoom(ctx, RoomActor{UserID: "alice"}, testRoomID, update)
+	require.NoError(t, err)
+	assert.Equal(t, "random", room.Name)
+}
+
+func TestRoomService_SetMember(t *testing.T) {
+	ctx := context.Background()
+	roles := map[string]string{"alice": repository.RoomRoleOwner, "bob": repository.RoomRoleModerator, "carol": repository.RoomRoleMember}
+	member := func(userID, role string) *repository.RoomMember {
+		return &repository.RoomMember{RoomID: testRoomID, UserID: userID, Role: role}
+	}
+
+	tests := []struct {
+		name   string
+		actor  RoomActor
+		userID string
+		role   string
+		status int
+	}{
+		{"owner grants moderator", RoomActor{UserID: "alice"}, "carol", repository.RoomRoleModerator, 0},
+		{"moderator adds member", RoomActor{UserID: "bob"}, "dave", "", 0},
+		{"moderator cannot promote", RoomActor{UserID: "bob"}, "carol", repository.RoomRoleModerator, 403},
+		{"moderator cannot add moderator", RoomActor{UserID: "bob"}, "dave", repository.RoomRoleModerator, 403},
+		{"member cannot add", RoomActor{UserID: "carol"}, "dave", "", 403},
+		{"self-join as member", RoomActor{UserID: "dave"}, "dave", "", 0},
+		{"self-join as owner", RoomActor{UserID: "dave"}, "dave", repository.RoomRoleOwner, 403},
+		{"non-member cannot add others", RoomActor{UserID: "dave"}, "carol", "", 403},
+		{"admin sets any role", RoomActor{UserID: "dave", Admin: true}, "carol", repository.RoomRoleOwner, 0},
+		{"unknown role", RoomActor{UserID: "alice"}, "carol", "admin", 400},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, rooms, _ := setupRoomService()
+			expectRoom(rooms, false, roles)
+
+			role := tc.role
+			if role == "" {
+				role = repository.RoomRoleMember
+			}
+			rooms.On("SetMember", ctx, testRoomID, tc.userID, role).Return(member(tc.userID, role), nil)
+
+			got, err := svc.SetMember(ctx, tc.actor, testRoomID, tc.userID, tc.role)
+
+			if tc.status != 0 {
+				assertStatus(t, err, tc.status)
+				rooms.AssertNotCalled(t, "SetMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, role, got.Role)
+		})
+	}
+
+	t.Run("last owner", func(t *testing.T) {
+		svc, rooms, _ := setupRoomService()
+		expectRoom(rooms, false, roles)
+		rooms.On("SetMember", ctx, testRoomID, "alice", repository.RoomRoleMember).
+			Return(nil, fmt.Errorf("alice: %w", repository.ErrLastOwner))
+
+		_, err := svc.SetMember(ctx, RoomActor{UserID: "alice"}, testRoomID, "alice", repository.RoomRoleMember)
+
+		assertStatus(t, err, 409)
+	})
+}
+
+func TestRoomService_Join_KeepsRole(t *testing.T) {
+	ctx := context.Background()
+	svc, rooms, _ := setupRoomService()
+	expectRoom(rooms, false, map[string]string{"alice": repository.RoomRoleOwner})
+
+	member, err := svc.Join(ctx, RoomActor{UserID: "alice"}, testRoomID)
+
+	require.NoError(t, err)
+	assert.Equal(t, repository.RoomRoleOwner, member.Role)
+	rooms.AssertNotCalled(t, "SetMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRoomService_RemoveMember(t *testing.T) {
+	ctx := context.Background()
+	roles := map[string]string{"alice": repository.RoomRoleOwner, "bob": repository.RoomRoleModerator, "carol": repository.RoomRoleMember}
+
+	tests := []struct {
+		name   string
+		actor  RoomActor
+		userID string
+		status int
+	}{
+		{"member leaves", RoomActor{UserID: "carol"}, "carol", 0},
+		{"moderator removes member", RoomActor{UserID: "bob"}, "carol", 0},
+		{"moderator cannot remove owner", RoomActor{UserID: "bob"}, "alice", 403},
+		{"member cannot remove others", RoomActor{UserID: "carol"}, "bob", 403},
+		{"owner removes moderator", RoomActor{UserID: "alice"}, "bob", 0},
+		{"target not a member", RoomActor{UserID: "alice"}, "dave", 404},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, rooms, _ := setupRoomService()
+			expectRoom(rooms, false, roles)
+			rooms.On("RemoveMember", ctx, testRoomID, tc.userID).Return(nil)
+
+			err := svc.RemoveMember(ctx, tc.actor, testRoomID, tc.userID)
+
+			if tc.status != 0 {
+				assertStatus(t, err, tc.status)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestRoomService_ListMessages(t *testing.T) {
+	ctx := context.Background()
+	svc, rooms, messages := setupRoomService()
+	expectRoom(rooms, true, map[string]string{"alice": repository.RoomRoleMember})
+
+	roomID := testRoomID
+	messages.On("ListByRoom", ctx, testRoomID, 20, 0).
+		Return([]*repository.Message{{MessageID: "m1", RoomID: &roomID, CreatedAt: time.Now()}}, nil)
+	messages.On("CountByRoom", ctx, testRoomID).Return(int64(1), nil)
+	messages.On("ThreadSummaries", ctx, []string{"m1"}).
+		Return(map[string]*repository.ThreadSummary{"m1": {ReplyCount: 2, LatestReply: &repository.Message{MessageID: "r2"}}}, nil)
+
+	list, total, err := svc.ListMessages(ctx, RoomActor{UserID: "alice"}, testRoomID, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, int64(2), list[0].ReplyCount)
+	assert.Equal(t, "r2", list[0].LatestReply.MessageID)
+
+	_, _, err = svc.ListMessages(ctx, RoomActor{UserID: "bob"}, testRoomID, 20, 0)
+	assertStatus(t, err, 404)
+}
+
+func TestRoomService_CheckCanPost(t *testing.T) {
+	ctx := context.Background()
+	svc, rooms, _ := setupRoomService()
+	expectRoom(rooms, false, map[string]string{"alice": repository.RoomRoleMember})
+
+	assert.NoError(t, svc.CheckCanPost(ctx, testRoomID, "alice"))
+	assertStatus(t, svc.CheckCanPost(ctx, testRoomID, "bob"), 403)
+	assertStatus(t, svc.CheckCanPost(ctx, "general", "alice"), 400)
+}
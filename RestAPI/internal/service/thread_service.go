@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+)
+
+// ThreadService handles replies to messages.
+// 답글의 답글도 원글 스레드의 첫 메시지(thread_root_id) 아래에 평평하게 모이고, parent_message_id 로 바로 위 원글을 가리킨다.
+type ThreadService struct {
+	messages repository.MessageRepository
+	rooms    repository.RoomRepository
+}
+
+// NewThreadService creates a new thread service
+func NewThreadService(messages repository.MessageRepository, rooms repository.RoomRepository) *ThreadService {
+	return &ThreadService{
+		messages: messages,
+		rooms:    rooms,
+	}
+}
+
+// PrepareReply resolves req.ReplyTo before the reply is published.
+// 답글의 목적지는 원글을 따른다 — 방 메시지면 같은 방, 1:1 메시지면 상대방이다. 요청에 다른 목적지가 있으면 400 이다.
+// 방에 쓸 권한은 이어서 발행 경로의 목적지 확인이 검사한다.
+func (s *ThreadService) PrepareReply(ctx context.Context, req *models.MessageRequest) error {
+	parent, err := s.messages.GetByMessageID(ctx, req.ReplyTo)
+	if err != nil {
+		logger.Warnf("Message to reply to not found: %s", req.ReplyTo)
+		return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message to reply to not found", 404)
+	}
+	if parent.RecalledAt != nil {
+		return apperrors.New(apperrors.ErrCodeConflict, "The message has been recalled", 409)
+	}
+
+	switch {
+	case parent.RoomID != nil:
+		if req.RecipientID != "" || (req.RoomID != "" && req.RoomID != *parent.RoomID) {
+			return replyDestinationError()
+		}
+		req.RoomID = *parent.RoomID
+	case parent.RecipientID != nil:
+		var peerID string
+		switch req.UserID {
+		case parent.UserID:
+			peerID = *parent.RecipientID
+		case *parent.RecipientID:
+			peerID = parent.UserID
+		default:
+			return apperrors.New(apperrors.ErrCodeNotFound, "Message to reply to not found", 404)
+		}
+		if req.RoomID != "" || (req.RecipientID != "" && req.RecipientID != peerID) {
+			return replyDestinationError()
+		}
+		req.RecipientID = peerID
+	default:
+		if req.RoomID != "" || req.RecipientID != "" {
+			return replyDestinationError()
+		}
+	}
+
+	req.ThreadRootID = parent.MessageID
+	if parent.ThreadRootID != nil {
+		req.ThreadRootID = *parent.ThreadRootID
+	}
+	return nil
+}
+
+// ListReplies lists the replies of the thread a message belongs to, oldest first.
+// 스레드의 어느 메시지로 물어도 같은 스레드다. 원글을 읽을 수 있는 사람(보낸 사람, 1:1 상대, 방 멤버,
+// 브로드캐스트는 누구나, 관리자)만 볼 수 있고 나머지는 404 다. callerID 가 비어 있으면(인증 비활성) 제한하지 않는다.
+func (s *ThreadService) ListReplies(ctx context.Context, callerID string, admin bool, messageID string, limit, offset int) ([]*repository.Message, int64, error) {
+	message, err := s.messages.GetByMessageID(ctx, messageID)
+	if err != nil {
+		logger.Warnf("Message not found: %s", messageID)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Message not found", 404)
+	}
+
+	if callerID != "" && !admin {
+		if err := checkCanRead(ctx, s.rooms, message, callerID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	rootID := message.MessageID
+	if message.ThreadRootID != nil {
+		rootID = *message.ThreadRootID
+	}
+
+	replies, err := s.messages.ListReplies(ctx, rootID, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list replies: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get replies", 500)
+	}
+
+	total, err := s.messages.CountReplies(ctx, rootID)
+	if err != nil {
+		logger.Errorf("Failed to count replies: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count replies", 500)
+	}
+
+	return replies, total, nil
+}
+
+// CheckCanRead fails with 404 unless userID can read the message
+func (s *ThreadService) CheckCanRead(ctx context.Context, message *repository.Message, userID string) error {
+	return checkCanRead(ctx, s.rooms, message, userID)
+}
+
+// checkCanRead fails with 404 unless userID can read the message:
+// 보낸 사람과 1:1 상대, 방 메시지는 방 멤버, 브로드캐스트는 누구나 읽을 수 있다.
+func checkCanRead(ctx context.Context, rooms repository.RoomRepository, message *repository.Message, userID string) error {
+	switch {
+	case message.RecipientID != nil:
+		if message.UserID != userID && *message.RecipientID != userID {
+			return apperrors.New(apperrors.ErrCodeNotFound, "Message not found", 404)
+		}
+	case message.RoomID != nil:
+		_, err := rooms.GetMember(ctx, *message.RoomID, userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.New(apperrors.ErrCodeNotFound, "Message not found", 404)
+		}
+		if err != nil {
+			logger.Errorf("Failed to get room member: %v", err)
+			return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get room member", 500)
+		}
+	}
+	return nil
+}
+
+// replyDestinationError reports a reply addressed somewhere other than its parent message
+func replyDestinationError() error {
+	return apperrors.New(apperrors.ErrCodeValidation, "A reply goes where the message it replies to went", 400).
+		WithFields(map[string]string{"reply_to": "destination must match the replied message"})
+}
+
+// withThreadSummaries fills the reply count and latest reply of the thread roots in a list.
+// 답글 자체는 스레드의 첫 메시지가 될 수 없으므로 요약 대상에서 뺀다.
+func withThreadSummaries(ctx context.Context, messages repository.MessageRepository, list []*repository.Message) error {
+	rootIDs := make([]string, 0, len(list))
+	for _, message := range list {
+		if message.ThreadRootID == nil {
+			rootIDs = append(rootIDs, message.MessageID)
+		}
+	}
+	if len(rootIDs) == 0 {
+		return nil
+	}
+
+	summaries, err := messages.ThreadSummaries(ctx, rootIDs)
+	if err != nil {
+		logger.Errorf("Failed to summarize threads: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get replies", 500)
+	}
+
+	for _, message := range list {
+		if summary, ok := summaries[message.MessageID]; ok {
+			message.ReplyCount = summary.ReplyCount
+			message.LatestReply = summary.LatestReply
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/models"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupThreadService() (*ThreadService, *MockMessageRepository, *MockRoomRepository) {
+	messages := new(MockMessageRepository)
+	rooms := new(MockRoomRepository)
+	return NewThreadService(messages, rooms), messages, rooms
+}
+
+func TestThreadService_PrepareReply(t *testing.T) {
+	ctx := context.Background()
+	svc, messages, _ := setupThreadService()
+
+	roomID := testRoomID
+	bob := "bob"
+	root := "root"
+	now := time.Now()
+	messages.On("GetByMessageID", ctx, "room-msg").Return(&repository.Message{MessageID: "room-msg", UserID: "alice", RoomID: &roomID}, nil)
+	messages.On("GetByMessageID", ctx, "dm").Return(&repository.Message{MessageID: "dm", UserID: "alice", RecipientID: &bob}, nil)
+	messages.On("GetByMessageID", ctx, "reply").Return(&repository.Message{MessageID: "reply", UserID: "carol", ParentMessageID: &root, ThreadRootID: &root}, nil)
+	messages.On("GetByMessageID", ctx, "recalled").Return(&repository.Message{MessageID: "recalled", UserID: "alice", RecalledAt: &now}, nil)
+	messages.On("GetByMessageID", ctx, "gone").Return(nil, errors.New("message not found: gone"))
+
+	t.Run("room reply goes to the room", func(t *testing.T) {
+		req := &models.MessageRequest{UserID: "bob", ReplyTo: "room-msg"}
+
+		require.NoError(t, svc.PrepareReply(ctx, req))
+		assert.Equal(t, testRoomID, req.RoomID)
+		assert.Equal(t, "room-msg", req.ThreadRootID)
+	})
+
+	t.Run("direct reply goes to the other party", func(t *testing.T) {
+		req := &models.MessageRequest{UserID: "bob", ReplyTo: "dm"}
+
+		require.NoError(t, svc.PrepareReply(ctx, req))
+		assert.Equal(t, "alice", req.RecipientID)
+	})
+
+	t.Run("reply to a reply joins its thread", func(t *testing.T) {
+		req := &models.MessageRequest{UserID: "alice", ReplyTo: "reply"}
+
+		require.NoError(t, svc.PrepareReply(ctx, req))
+		assert.Equal(t, "root", req.ThreadRootID)
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		assertStatus(t, svc.PrepareReply(ctx, &models.MessageRequest{UserID: "bob", ReplyTo: "room-msg", RecipientID: "alice"}), 400)
+		assertStatus(t, svc.PrepareReply(ctx, &models.MessageRequest{UserID: "bob", ReplyTo: "reply", RoomID: testRoomID}), 400)
+		assertStatus(t, svc.PrepareReply(ctx, &models.MessageRequest{UserID: "carol", ReplyTo: "dm"}), 404)
+		assertStatus(t, svc.PrepareReply(ctx, &models.MessageRequest{UserID: "bob", ReplyTo: "recalled"}), 409)
+		assertStatus(t, svc.PrepareReply(ctx, &models.MessageRequest{UserID: "bob", ReplyTo: "gone"}), 404)
+	})
+}
+
+func TestThreadService_ListReplies(t *testing.T) {
+	ctx := context.Background()
+	svc, messages, rooms := setupThreadService()
+
+	roomID := testRoomID
+	root := "room-msg"
+	messages.On("GetByMessageID", ctx, "room-msg").Return(&repository.Message{MessageID: "room-msg", UserID: "alice", RoomID: &roomID}, nil)
+	messages.On("GetByMessageID", ctx, "r1").Return(&repository.Message{MessageID: "r1", UserID: "bob", RoomID: &roomID, ParentMessageID: &root, ThreadRootID: &root}, nil)
+	messages.On("ListReplies", ctx, "room-msg", 20, 0).Return([]*repository.Message{{MessageID: "r1"}}, nil)
+	messages.On("CountReplies", ctx, "room-msg").Return(int64(1), nil)
+	rooms.On("GetMember", ctx, testRoomID, "bob").Return(&repository.RoomMember{RoomID: testRoomID, UserID: "bob"}, nil)
+	rooms.On("GetMember", ctx, testRoomID, "carol").Return(nil, fmt.Errorf("carol: %w", repository.ErrRoomMemberNotFound))
+
+	replies, total, err := svc.ListReplies(ctx, "bob", false, "room-msg", 20, 0)
+	require.NoError(t, err)
+	assert.Len(t, replies, 1)
+	assert.Equal(t, int64(1), total)
+
+	_, _, err = svc.ListReplies(ctx, "bob", false, "r1", 20, 0)
+	assert.NoError(t, err, "답글로 물어도 같은 스레드다")
+
+	_, _, err = svc.ListReplies(ctx, "carol", false, "room-msg", 20, 0)
+	assertStatus(t, err, 404)
+
+	_, _, err = svc.ListReplies(ctx, "carol", true, "room-msg", 20, 0)
+	assert.NoError(t, err)
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"net/mail"
+	"regexp"
+	"strings"
 
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/services"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
@@ -12,10 +17,31 @@ import (
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
 )
 
+// usernamePattern limits usernames to characters that are safe in URLs and logs
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,50}$`)
+
+// maxEmailLength is the longest address RFC 5321 allows
+const maxEmailLength = 254
+
+// UpdateProfileInput holds a partial profile update.
+// nil 필드는 그대로 두고, 빈 문자열은 값을 지운다.
+type UpdateProfileInput struct {
+	Username *string
+	Email    *string
+}
+
+// TokenRevoker invalidates every token already issued to a user
+type TokenRevoker interface {
+	RevokeUser(ctx context.Context, userID string) error
+}
+
 // UserService handles user business logic
 type UserService struct {
 	userRepo repository.UserRepository
 	redis    *services.RedisService
+	presence *PresenceService
+	revoker  TokenRevoker
+	events   EventNotifier
 }
 
 // NewUserService creates a new user service
@@ -26,8 +52,59 @@ func NewUserService(userRepo repository.UserRepository, redis *services.RedisSer
 	}
 }
 
+// SetPresence switches online listings and heartbeats to Redis-backed presence.
+// 설정하지 않으면(Redis 비활성) 접속자 목록은 users.status 로 조회한다.
+func (s *UserService) SetPresence(presence *PresenceService) {
+	s.presence = presence
+}
+
+// SetTokenRevoker revokes a user's tokens when the user is deleted or their role changes.
+// 설정하지 않으면(토큰 발급 비활성) 삭제된 사용자의 access token 은 만료될 때까지 유효하다 — refresh 는 실패한다.
+func (s *UserService) SetTokenRevoker(revoker TokenRevoker) {
+	s.revoker = revoker
+}
+
+// SetEvents reports user.created and user.deleted events, e.g. to webhook subscriptions
+func (s *UserService) SetEvents(events EventNotifier) {
+	s.events = events
+}
+
 // CreateUser creates a new user
 func (s *UserService) CreateUser(ctx context.Context, userID, username, email string) (*repository.User, error) {
+	return s.createUser(ctx, &repository.User{
+		UserID:   userID,
+		Username: sql.NullString{String: username, Valid: username != ""},
+		Email:    sql.NullString{String: email, Valid: email != ""},
+		Role:     middleware.RoleUser,
+		Status:   "offline",
+	})
+}
+
+// CreateUserWithPassword creates a new user that can log in through /api/v1/auth/token
+func (s *UserService) CreateUserWithPassword(ctx context.Context, userID, username, email, password string) (*repository.User, error) {
+	hash, err := HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createUser(ctx, &repository.User{
+		UserID:       userID,
+		Username:     sql.NullString{String: username, Valid: username != ""},
+		Email:        sql.NullString{String: email, Valid: email != ""},
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+		Role:         middleware.RoleUser,
+		Status:       "offline",
+	})
+}
+
+// createUser inserts the user after checking for duplicates
+func (s *UserService) createUser(ctx context.Context, user *repository.User) (*repository.User, error) {
+	userID := user.UserID
+
+	if fields := validateProfile(&user.Username.String, &user.Email.String); len(fields) > 0 {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid user", 400).WithFields(fields)
+	}
+
 	// Check if user already exists
 	exists, err := s.userRepo.Exists(ctx, userID)
 	if err != nil {
@@ -39,14 +116,11 @@ func (s *UserService) CreateUser(ctx context.Context, userID, username, email st
 		return nil, apperrors.New(apperrors.ErrCodeDuplicateKey, "User already exists", 409)
 	}
 
-	user := &repository.User{
-		UserID:   userID,
-		Username: sql.NullString{String: username, Valid: username != ""},
-		Email:    sql.NullString{String: email, Valid: email != ""},
-		Status:   "offline",
-	}
-
 	if err := s.userRepo.Create(ctx, user); err != nil {
+		var conflict *repository.UniqueViolationError
+		if errors.As(err, &conflict) {
+			return nil, profileConflict(conflict)
+		}
 		logger.Errorf("Failed to create user: %v", err)
 		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to create user", 500)
 	}
@@ -56,6 +130,14 @@ func (s *UserService) CreateUser(ctx context.Context, userID, username, email st
 		s.cacheUser(ctx, user)
 	}
 
+	if s.events != nil {
+		s.events.Notify(ctx, EventUserCreated, userID, UserEventData{
+			UserID:   userID,
+			Username: user.Username.String,
+			Email:    user.Email.String,
+		})
+	}
+
 	logger.Infof("User created successfully: %s", userID)
 	return user, nil
 }
@@ -84,6 +166,57 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*repository.U
 	return user, nil
 }
 
+// UpdateProfile changes the username and/or email of a user
+func (s *UserService) UpdateProfile(ctx context.Context, userID string, input UpdateProfileInput) (*repository.User, error) {
+	if input.Username == nil && input.Email == nil {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "No fields to update", 400)
+	}
+
+	var update repository.ProfileUpdate
+	if input.Username != nil {
+		username := strings.TrimSpace(*input.Username)
+		update.Username = &sql.NullString{String: username, Valid: username != ""}
+	}
+	if input.Email != nil {
+		email := strings.TrimSpace(*input.Email)
+		update.Email = &sql.NullString{String: email, Valid: email != ""}
+	}
+
+	var username, email *string
+	if update.Username != nil {
+		username = &update.Username.String
+	}
+	if update.Email != nil {
+		email = &update.Email.String
+	}
+	if fields := validateProfile(username, email); len(fields) > 0 {
+		return nil, apperrors.New(apperrors.ErrCodeValidation, "Invalid profile", 400).WithFields(fields)
+	}
+
+	user, err := s.userRepo.UpdateProfile(ctx, userID, update)
+	if err != nil {
+		var conflict *repository.UniqueViolationError
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, apperrors.New(apperrors.ErrCodeNotFound, "User not found", 404)
+		case errors.As(err, &conflict):
+			return nil, profileConflict(conflict)
+		}
+		logger.Errorf("Failed to update user profile: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to update profile", 500)
+	}
+
+	// 캐시를 새 값으로 덮어쓰지 않고 비운다 — 동시에 들어온 다른 갱신과 순서가 뒤집혀도 다음 조회가 DB 를 본다.
+	if s.redis != nil {
+		if err := s.redis.Delete(ctx, cache.UserKey(userID)); err != nil {
+			logger.Warnf("Failed to evict user cache (%s): %v", userID, err)
+		}
+	}
+
+	logger.Infof("User profile updated: %s", userID)
+	return user, nil
+}
+
 // UpdateUserStatus updates user status (online/offline/away)
 func (s *UserService) UpdateUserStatus(ctx context.Context, userID, status string) error {
 	// Validate status
@@ -106,30 +239,111 @@ func (s *UserService) UpdateUserStatus(ctx context.Context, userID, status strin
 		}
 	}
 
+	// 명시적으로 오프라인이 되면 TTL 만료를 기다리지 않고 접속자 목록에서 뺀다.
+	if status == PresenceOffline && s.presence != nil {
+		if err := s.presence.Leave(ctx, userID); err != nil {
+			logger.Warnf("Failed to clear presence (%s): %v", userID, err)
+		}
+	}
+
 	logger.Infof("User status updated: %s -> %s", userID, status)
 	return nil
 }
 
-// GetOnlineUsers retrieves all online users
-func (s *UserService) GetOnlineUsers(ctx context.Context, limit, offset int) ([]*repository.User, error) {
-	users, err := s.userRepo.ListByStatus(ctx, "online", limit, offset)
+// UpdateUserRole changes the user's role and revokes the tokens that carry the old one.
+// 폐기하지 않으면 강등된 관리자가 access token 이 만료될 때까지 관리자 권한을 계속 쓸 수 있다.
+func (s *UserService) UpdateUserRole(ctx context.Context, userID, role string) error {
+	if !middleware.IsValidRole(role) {
+		return apperrors.New(apperrors.ErrCodeValidation, "Invalid role", 400)
+	}
+
+	previous, err := s.userRepo.UpdateRole(ctx, userID, role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "User not found", 404)
+		}
+		logger.Errorf("Failed to update user role: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to update role", 500)
+	}
+
+	if s.redis != nil {
+		if err := s.redis.Delete(ctx, cache.UserKey(userID)); err != nil {
+			logger.Warnf("Failed to evict user cache (%s): %v", userID, err)
+		}
+	}
+
+	// 같은 역할을 다시 지정한 경우에는 사용자를 로그아웃시키지 않는다.
+	if s.revoker != nil && previous != role {
+		if err := s.revoker.RevokeUser(ctx, userID); err != nil {
+			logger.Warnf("Failed to revoke tokens after role change (%s): %v", userID, err)
+		}
+	}
+
+	logger.Infof("User role updated: %s -> %s", userID, role)
+	return nil
+}
+
+// Heartbeat keeps the user online for another presence TTL
+func (s *UserService) Heartbeat(ctx context.Context, userID string) (*PresenceState, error) {
+	if s.presence == nil {
+		return nil, apperrors.New(apperrors.ErrCodeServiceUnavail, "Presence tracking requires Redis", 503)
+	}
+
+	// 없는 사용자가 접속자 목록을 채우지 않도록 먼저 확인한다. GetUser 는 캐시를 먼저 본다.
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.presence.Heartbeat(ctx, userID)
+}
+
+// GetOnlineUsers retrieves a page of online users and the total online count.
+// Redis 가 있으면 하트비트 기준, 없으면 users.status 기준이다.
+func (s *UserService) GetOnlineUsers(ctx context.Context, limit, offset int) ([]*repository.User, int64, error) {
+	if s.presence == nil {
+		users, err := s.userRepo.ListByStatus(ctx, PresenceOnline, limit, offset)
+		if err != nil {
+			logger.Errorf("Failed to get online users: %v", err)
+			return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get online users", 500)
+		}
+
+		total, err := s.userRepo.CountByStatus(ctx, PresenceOnline)
+		if err != nil {
+			logger.Errorf("Failed to count online users: %v", err)
+			return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count online users", 500)
+		}
+
+		return users, total, nil
+	}
+
+	userIDs, total, err := s.presence.ListOnline(ctx, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list present users: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeCacheError, "Failed to get online users", 503)
+	}
+
+	users, err := s.userRepo.ListByUserIDs(ctx, userIDs)
 	if err != nil {
 		logger.Errorf("Failed to get online users: %v", err)
-		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get online users", 500)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get online users", 500)
 	}
 
-	return users, nil
+	return users, total, nil
 }
 
-// ListUsers retrieves paginated list of users
-func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*repository.User, int64, error) {
-	users, err := s.userRepo.List(ctx, limit, offset)
+// ListUsers retrieves a page of users matching the filter in the requested order
+func (s *UserService) ListUsers(ctx context.Context, filter repository.UserFilter, sort repository.UserSort, limit, offset int) ([]*repository.User, int64, error) {
+	if fields := validateUserSearch(filter, sort); len(fields) > 0 {
+		return nil, 0, apperrors.New(apperrors.ErrCodeValidation, "Invalid search parameters", 400).WithFields(fields)
+	}
+
+	users, err := s.userRepo.Search(ctx, filter, sort, limit, offset)
 	if err != nil {
 		logger.Errorf("Failed to list users: %v", err)
 		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list users", 500)
 	}
 
-	total, err := s.userRepo.Count(ctx)
+	total, err := s.userRepo.CountSearch(ctx, filter)
 	if err != nil {
 		logger.Errorf("Failed to count users: %v", err)
 		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count users", 500)
@@ -138,9 +352,12 @@ func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*repo
 	return users, total, nil
 }
 
-// DeleteUser deletes a user
+// DeleteUser soft-deletes a user; the user's messages stay until an erasure is requested
 func (s *UserService) DeleteUser(ctx context.Context, userID string) error {
 	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "User not found", 404)
+		}
 		logger.Errorf("Failed to delete user: %v", err)
 		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to delete user", 500)
 	}
@@ -152,6 +369,24 @@ func (s *UserService) DeleteUser(ctx context.Context, userID string) error {
 		}
 	}
 
+	if s.presence != nil {
+		if err := s.presence.Leave(ctx, userID); err != nil {
+			logger.Warnf("Failed to clear presence (%s): %v", userID, err)
+		}
+	}
+
+	if s.revoker != nil {
+		if err := s.revoker.RevokeUser(ctx, userID); err != nil {
+			logger.Warnf("Failed to revoke tokens of deleted user (%s): %v", userID, err)
+		}
+	}
+
+	// 삭제 이벤트는 ID 만 담고 사용자와 묶지 않는다. erasure 가 사용자의 전송 기록을 지워도
+	// 연동 시스템이 자기 쪽 사본을 정리할 수 있도록 이 이벤트는 남겨 둔다.
+	if s.events != nil {
+		s.events.Notify(ctx, EventUserDeleted, "", UserEventData{UserID: userID})
+	}
+
 	logger.Infof("User deleted: %s", userID)
 	return nil
 }
@@ -192,3 +427,52 @@ func (s *UserService) getUserFromCache(ctx context.Context, userID string) (*rep
 
 	return &user, nil
 }
+
+// validateUserSearch checks the status, sort field and time ranges of a user search
+func validateUserSearch(filter repository.UserFilter, sort repository.UserSort) map[string]string {
+	fields := map[string]string{}
+
+	validStatuses := map[string]bool{"online": true, "offline": true, "away": true}
+	if filter.Status != "" && !validStatuses[filter.Status] {
+		fields["status"] = "must be one of online, offline, away"
+	}
+	if sort.Field != "" && !repository.IsValidUserSortField(sort.Field) {
+		fields["sort"] = "must be one of created_at, last_seen, username, email, user_id"
+	}
+
+	// 범위는 [after, before) 이므로 같은 시각이면 항상 빈 결과다.
+	if filter.LastSeenAfter != nil && filter.LastSeenBefore != nil && !filter.LastSeenAfter.Before(*filter.LastSeenBefore) {
+		fields["last_seen_before"] = "must be after last_seen_after"
+	}
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		fields["created_before"] = "must be after created_after"
+	}
+
+	return fields
+}
+
+// validateProfile checks the username and email formats; nil or empty values are not checked.
+// 반환값은 JSON 필드 이름 → 사유이며, 비어 있으면 통과다.
+func validateProfile(username, email *string) map[string]string {
+	fields := make(map[string]string)
+
+	if username != nil && *username != "" && !usernamePattern.MatchString(*username) {
+		fields["username"] = "must be 3-50 characters of letters, digits, '_', '.' or '-'"
+	}
+
+	if email != nil && *email != "" {
+		// ParseAddress 는 "Name <addr>" 형식도 받아들이므로 주소 부분만 온 경우로 제한한다.
+		addr, err := mail.ParseAddress(*email)
+		if err != nil || addr.Address != *email || len(*email) > maxEmailLength {
+			fields["email"] = "must be a valid email address"
+		}
+	}
+
+	return fields
+}
+
+// profileConflict reports a username or email that another user already has
+func profileConflict(conflict *repository.UniqueViolationError) error {
+	return apperrors.Wrap(conflict, apperrors.ErrCodeDuplicateKey, conflict.Field+" is already in use", 409).
+		WithFields(map[string]string{conflict.Field: "already in use"})
+}
@@ -4,13 +4,27 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+// mockTokenRevoker records token revocations
+type mockTokenRevoker struct {
+	mock.Mock
+}
+
+func (m *mockTokenRevoker) RevokeUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
 // MockUserRepository is a mock implementation of UserRepository
 type MockUserRepository struct {
 	mock.Mock
@@ -37,6 +51,14 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id int64) (*repository
 	return args.Get(0).(*repository.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetCredentials(ctx context.Context, userID string) (*repository.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, user *repository.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -47,6 +69,19 @@ func (m *MockUserRepository) UpdateStatus(ctx context.Context, userID string, st
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateRole(ctx context.Context, userID string, role string) (string, error) {
+	args := m.Called(ctx, userID, role)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateProfile(ctx context.Context, userID string, update repository.ProfileUpdate) (*repository.User, error) {
+	args := m.Called(ctx, userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*repository.User), args.Error(1)
+}
+
 func (m *MockUserRepository) UpdateLastSeen(ctx context.Context, userID string) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
@@ -73,11 +108,37 @@ func (m *MockUserRepository) ListByStatus(ctx context.Context, status string, li
 	return args.Get(0).([]*repository.User), args.Error(1)
 }
 
+func (m *MockUserRepository) ListByUserIDs(ctx context.Context, userIDs []string) ([]*repository.User, error) {
+	args := m.Called(ctx, userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, filter repository.UserFilter, sort repository.UserSort, limit, offset int) ([]*repository.User, error) {
+	args := m.Called(ctx, filter, sort, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.User), args.Error(1)
+}
+
+func (m *MockUserRepository) CountSearch(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockUserRepository) Count(ctx context.Context) (int64, error) {
 	args := m.Called(ctx)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockUserRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *MockUserRepository) Exists(ctx context.Context, userID string) (bool, error) {
 	args := m.Called(ctx, userID)
 	return args.Bool(0), args.Error(1)
@@ -108,6 +169,23 @@ func TestUserService_CreateUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("notifies user.created", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		events := &recordingNotifier{}
+		service := NewUserService(mockRepo, nil)
+		service.SetEvents(events)
+
+		mockRepo.On("Exists", ctx, "test_user_123").Return(false, nil)
+		mockRepo.On("Create", ctx, mock.AnythingOfType("*repository.User")).Return(nil)
+
+		_, err := service.CreateUser(ctx, "test_user_123", "testuser", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []recordedEvent{
+			{eventType: EventUserCreated, userID: "test_user_123", data: UserEventData{UserID: "test_user_123", Username: "testuser"}},
+		}, events.events)
+	})
+
 	t.Run("user already exists", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		service := NewUserService(mockRepo, nil)
@@ -203,6 +281,187 @@ func TestUserService_GetUser(t *testing.T) {
 	})
 }
 
+func TestUserService_UpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	ptr := func(s string) *string { return &s }
+
+	t.Run("partial update", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		expected := repository.ProfileUpdate{Email: &sql.NullString{String: "new@example.com", Valid: true}}
+		mockRepo.On("UpdateProfile", ctx, "test_user", expected).
+			Return(&repository.User{UserID: "test_user", Email: *expected.Email}, nil)
+
+		user, err := service.UpdateProfile(ctx, "test_user", UpdateProfileInput{Email: ptr("  new@example.com ")})
+
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", user.Email.String)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("empty string clears the field", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		expected := repository.ProfileUpdate{Email: &sql.NullString{}}
+		mockRepo.On("UpdateProfile", ctx, "test_user", expected).Return(&repository.User{UserID: "test_user"}, nil)
+
+		_, err := service.UpdateProfile(ctx, "test_user", UpdateProfileInput{Email: ptr("")})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("field-level validation errors", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository), nil)
+
+		_, err := service.UpdateProfile(ctx, "test_user", UpdateProfileInput{
+			Username: ptr("a b"),
+			Email:    ptr("Alice <alice@example.com>"),
+		})
+
+		assertStatus(t, err, 400)
+		fields := apperrors.GetAppError(err).Fields
+		assert.Contains(t, fields, "username")
+		assert.Contains(t, fields, "email")
+	})
+
+	t.Run("nothing to update", func(t *testing.T) {
+		service := NewUserService(new(MockUserRepository), nil)
+
+		_, err := service.UpdateProfile(ctx, "test_user", UpdateProfileInput{})
+		assertStatus(t, err, 400)
+	})
+
+	t.Run("email already in use", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		mockRepo.On("UpdateProfile", ctx, "test_user", mock.Anything).
+			Return(nil, &repository.UniqueViolationError{Field: "email", Err: errors.New("duplicate key")})
+
+		_, err := service.UpdateProfile(ctx, "test_user", UpdateProfileInput{Email: ptr("taken@example.com")})
+
+		assertStatus(t, err, 409)
+		assert.Equal(t, map[string]string{"email": "already in use"}, apperrors.GetAppError(err).Fields)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		mockRepo.On("UpdateProfile", ctx, "ghost", mock.Anything).
+			Return(nil, fmt.Errorf("user not found: ghost: %w", sql.ErrNoRows))
+
+		_, err := service.UpdateProfile(ctx, "ghost", UpdateProfileInput{Username: ptr("ghost")})
+		assertStatus(t, err, 404)
+	})
+}
+
+func TestUserService_UpdateUserRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("successful role update", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		mockRepo.On("UpdateRole", ctx, "test_user_123", "admin").Return("user", nil)
+
+		err := service.UpdateUserRole(ctx, "test_user_123", "admin")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("revokes tokens of the old role", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		revoker := new(mockTokenRevoker)
+		service := NewUserService(mockRepo, nil)
+		service.SetTokenRevoker(revoker)
+
+		mockRepo.On("UpdateRole", ctx, "test_user_123", "user").Return("admin", nil)
+		revoker.On("RevokeUser", ctx, "test_user_123").Return(nil)
+
+		require.NoError(t, service.UpdateUserRole(ctx, "test_user_123", "user"))
+		revoker.AssertExpectations(t)
+	})
+
+	t.Run("same role keeps tokens", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		revoker := new(mockTokenRevoker)
+		service := NewUserService(mockRepo, nil)
+		service.SetTokenRevoker(revoker)
+
+		mockRepo.On("UpdateRole", ctx, "test_user_123", "admin").Return("admin", nil)
+
+		require.NoError(t, service.UpdateUserRole(ctx, "test_user_123", "admin"))
+		revoker.AssertNotCalled(t, "RevokeUser")
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		mockRepo.On("UpdateRole", ctx, "ghost", "admin").Return("", fmt.Errorf("user not found: ghost: %w", sql.ErrNoRows))
+
+		err := service.UpdateUserRole(ctx, "ghost", "admin")
+		assertStatus(t, err, 404)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		err := service.UpdateUserRole(ctx, "test_user_123", "superuser")
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid role")
+		mockRepo.AssertNotCalled(t, "UpdateRole")
+	})
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("filtered and sorted", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		since := time.Now().Add(-24 * time.Hour)
+		filter := repository.UserFilter{Query: "ali", Status: "online", LastSeenAfter: &since}
+		sort := repository.UserSort{Field: "last_seen", Desc: true}
+
+		mockRepo.On("Search", ctx, filter, sort, 20, 0).Return([]*repository.User{{UserID: "alice"}}, nil)
+		mockRepo.On("CountSearch", ctx, filter).Return(int64(1), nil)
+
+		users, total, err := service.ListUsers(ctx, filter, sort, 20, 0)
+
+		require.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, int64(1), total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, nil)
+
+		now := time.Now()
+		filter := repository.UserFilter{Status: "busy", CreatedAfter: &now, CreatedBefore: &now}
+
+		_, _, err := service.ListUsers(ctx, filter, repository.UserSort{Field: "password_hash"}, 20, 0)
+
+		assertStatus(t, err, 400)
+		assert.Equal(t, map[string]string{
+			"status":         "must be one of online, offline, away",
+			"sort":           "must be one of created_at, last_seen, username, email, user_id",
+			"created_before": "must be after created_after",
+		}, apperrors.GetAppError(err).Fields)
+		mockRepo.AssertNotCalled(t, "Search")
+	})
+}
+
 func TestUserService_UpdateUserStatus(t *testing.T) {
 	ctx := context.Background()
 
@@ -286,6 +545,49 @@ func TestUserService_DeleteUser(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("revokes tokens", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		revoker := new(mockTokenRevoker)
+		service := NewUserService(mockRepo, nil)
+		service.SetTokenRevoker(revoker)
+
+		mockRepo.On("Delete", ctx, "test_user_123").Return(nil)
+		revoker.On("RevokeUser", ctx, "test_user_123").Return(nil)
+
+		err := service.DeleteUser(ctx, "test_user_123")
+
+		assert.NoError(t, err)
+		revoker.AssertExpectations(t)
+	})
+
+	t.Run("notifies user.deleted", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		events := &recordingNotifier{}
+		service := NewUserService(mockRepo, nil)
+		service.SetEvents(events)
+
+		mockRepo.On("Delete", ctx, "test_user_123").Return(nil)
+
+		require.NoError(t, service.DeleteUser(ctx, "test_user_123"))
+		assert.Equal(t, []recordedEvent{
+			{eventType: EventUserDeleted, data: UserEventData{UserID: "test_user_123"}},
+		}, events.events, "erasure 가 지우지 않도록 사용자와 묶지 않는다")
+	})
+
+	t.Run("already deleted", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		events := &recordingNotifier{}
+		service := NewUserService(mockRepo, nil)
+		service.SetEvents(events)
+
+		mockRepo.On("Delete", ctx, "gone").Return(fmt.Errorf("user not found: gone: %w", sql.ErrNoRows))
+
+		err := service.DeleteUser(ctx, "gone")
+
+		assertStatus(t, err, 404)
+		assert.Empty(t, events.events)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		service := NewUserService(mockRepo, nil)
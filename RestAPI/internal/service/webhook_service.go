@@ -0,0 +1,603 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/repository"
+	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/webhook"
+)
+
+// Webhook event types
+const (
+	EventMessageSent   = "message.sent"
+	EventMessageFailed = "message.failed"
+	EventUserCreated   = "user.created"
+	EventUserDeleted   = "user.deleted"
+)
+
+// WebhookEventTypes lists the event types a subscription can ask for
+var WebhookEventTypes = []string{EventMessageSent, EventMessageFailed, EventUserCreated, EventUserDeleted}
+
+const (
+	// minWebhookSecretLength is the shortest signing secret a subscription may set
+	minWebhookSecretLength = 16
+	// webhookErrorBodyLimit is how much of a failed response body is kept in last_error
+	webhookErrorBodyLimit = 256
+)
+
+// EventNotifier is told about events that integrations can subscribe to
+type EventNotifier interface {
+	Notify(ctx context.Context, eventType, userID string, data interface{})
+}
+
+// WebhookEvent is the body of every webhook request.
+// 한 이벤트는 구독마다 따로 전송되지만 ID 는 같으므로 수신측이 재시도로 생긴 중복을 거를 수 있다.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// MessageEventData is the data of message.sent and message.failed events.
+// 내용은 싣지 않는다 — 구독은 관리자가 만들지만 1:1 메시지나 비공개 방의 내용이 외부 엔드포인트로 나가게 된다.
+// 내용이 필요한 수신측은 message_id 로 API 에서 읽는다.
+type MessageEventData struct {
+	MessageID   string `json:"message_id"`
+	UserID      string `json:"user_id"`
+	RoomID      string `json:"room_id,omitempty"`
+	RecipientID string `json:"recipient_id,omitempty"`
+	ReplyTo     string `json:"reply_to,omitempty"`
+	Command     string `json:"command"`
+	Error       string `json:"error,omitempty"`
+}
+
+// messageEventData describes a stored message for a message event
+func messageEventData(message *repository.Message, failure string) MessageEventData {
+	data := MessageEventData{
+		MessageID: message.MessageID,
+		UserID:    message.UserID,
+		Command:   message.Command,
+		Error:     failure,
+	}
+	if message.RoomID != nil {
+		data.RoomID = *message.RoomID
+	}
+	if message.RecipientID != nil {
+		data.RecipientID = *message.RecipientID
+	}
+	if message.ParentMessageID != nil {
+		data.ReplyTo = *message.ParentMessageID
+	}
+	return data
+}
+
+// UserEventData is the data of user.created and user.deleted events
+type UserEventData struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// WebhookOptions configure delivery
+type WebhookOptions struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	BatchSize   int
+	Lease       time.Duration
+	Retention   time.Duration
+}
+
+// WebhookInput holds a new subscription; an empty secret is generated and empty event types mean every type
+type WebhookInput struct {
+	URL         string
+	EventTypes  []string
+	Secret      string
+	Description string
+	CreatedBy   string
+}
+
+// WebhookUpdate holds a partial subscription update; nil fields are left as they are
+type WebhookUpdate struct {
+	URL         *string
+	EventTypes  *[]string
+	Secret      *string
+	Description *string
+	Active      *bool
+}
+
+// IssuedWebhook is returned when a subscription is created and is the only place its secret appears
+type IssuedWebhook struct {
+	Secret string `json:"secret"`
+	*repository.WebhookSubscription
+}
+
+// WebhookService manages webhook subscriptions and runs the delivery worker.
+// 이벤트는 먼저 webhook_deliveries 에 구독별로 기록되고(outbox), 워커가 꺼내 HMAC 서명과 함께 보낸다.
+// 그래서 수신측이 내려가 있거나 API 서버가 재시작돼도 이벤트를 잃지 않는다.
+type WebhookService struct {
+	repo   repository.WebhookRepository
+	opts   WebhookOptions
+	client *http.Client
+
+	wake   chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(repo repository.WebhookRepository, opts WebhookOptions) *WebhookService {
+	return &WebhookService{
+		repo: repo,
+		opts: opts,
+		client: &http.Client{
+			Timeout: opts.Timeout,
+			// 리다이렉트를 따라가면 서명된 요청이 등록하지 않은 주소로 간다.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		wake: make(chan struct{}, 1),
+	}
+}
+
+// CreateSubscription registers an endpoint
+func (s *WebhookService) CreateSubscription(ctx context.Context, input WebhookInput) (*IssuedWebhook, error) {
+	if err := validateWebhookURL(input.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(input.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		if secret, err = webhook.NewSecret(); err != nil {
+			return nil, apperrors.Wrap(err, apperrors.ErrCodeInternal, "Failed to generate webhook secret", 500)
+		}
+	} else if err := validateWebhookSecret(secret); err != nil {
+		return nil, err
+	}
+
+	sub := &repository.WebhookSubscription{
+		URL:         input.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		Description: input.Description,
+		Active:      true,
+		CreatedBy:   input.CreatedBy,
+	}
+	if err := s.repo.CreateSubscription(ctx, sub); err != nil {
+		logger.Errorf("Failed to create webhook subscription: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to create webhook", 500)
+	}
+
+	logger.Infof("Webhook subscription created: %s (%s, events %v, by %s)", sub.ID, sub.URL, []string(sub.EventTypes), input.CreatedBy)
+	return &IssuedWebhook{Secret: secret, WebhookSubscription: sub}, nil
+}
+
+// GetSubscription retrieves a subscription
+func (s *WebhookService) GetSubscription(ctx context.Context, id string) (*repository.WebhookSubscription, error) {
+	// 형식이 틀린 ID 는 PostgreSQL 이 UUID 변환 오류를 내므로 조회 전에 404 로 끊는다.
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "Webhook not found", 404)
+	}
+
+	sub, err := s.repo.GetSubscription(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Webhook not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get webhook subscription: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get webhook", 500)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions retrieves paginated subscriptions
+func (s *WebhookService) ListSubscriptions(ctx context.Context, limit, offset int) ([]*repository.WebhookSubscription, int64, error) {
+	subs, err := s.repo.ListSubscriptions(ctx, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list webhook subscriptions: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list webhooks", 500)
+	}
+
+	total, err := s.repo.CountSubscriptions(ctx)
+	if err != nil {
+		logger.Errorf("Failed to count webhook subscriptions: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count webhooks", 500)
+	}
+
+	return subs, total, nil
+}
+
+// UpdateSubscription changes a subscription. 비활성화하면 새 이벤트는 쌓이지 않지만 이미 쌓인 전송은 계속 보낸다.
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id string, update WebhookUpdate) (*repository.WebhookSubscription, error) {
+	sub, err := s.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.URL != nil {
+		if err := validateWebhookURL(*update.URL); err != nil {
+			return nil, err
+		}
+		sub.URL = *update.URL
+	}
+	if update.EventTypes != nil {
+		if sub.EventTypes, err = normalizeEventTypes(*update.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if update.Secret != nil {
+		if err := validateWebhookSecret(*update.Secret); err != nil {
+			return nil, err
+		}
+		sub.Secret = *update.Secret
+	}
+	if update.Description != nil {
+		sub.Description = *update.Description
+	}
+	if update.Active != nil {
+		sub.Active = *update.Active
+	}
+
+	err = s.repo.UpdateSubscription(ctx, sub)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Webhook not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to update webhook subscription: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to update webhook", 500)
+	}
+
+	logger.Infof("Webhook subscription updated: %s", id)
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription and its delivery log
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return apperrors.New(apperrors.ErrCodeNotFound, "Webhook not found", 404)
+	}
+
+	err := s.repo.DeleteSubscription(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Webhook not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to delete webhook subscription: %v", err)
+		return apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to delete webhook", 500)
+	}
+
+	logger.Infof("Webhook subscription deleted: %s", id)
+	return nil
+}
+
+// ListDeliveries lists the delivery log of a subscription, newest first. An empty status lists every delivery.
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID, status string, limit, offset int) ([]*repository.WebhookDelivery, int64, error) {
+	switch status {
+	case "", repository.DeliveryPending, repository.DeliverySucceeded, repository.DeliveryDead:
+	default:
+		return nil, 0, apperrors.New(apperrors.ErrCodeValidation, "status must be pending, succeeded or dead", 400)
+	}
+
+	if _, err := s.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, 0, err
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, subscriptionID, status, limit, offset)
+	if err != nil {
+		logger.Errorf("Failed to list webhook deliveries: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to list deliveries", 500)
+	}
+
+	total, err := s.repo.CountDeliveries(ctx, subscriptionID, status)
+	if err != nil {
+		logger.Errorf("Failed to count webhook deliveries: %v", err)
+		return nil, 0, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to count deliveries", 500)
+	}
+
+	return deliveries, total, nil
+}
+
+// Redeliver queues a dead delivery again with a fresh set of attempts
+func (s *WebhookService) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*repository.WebhookDelivery, error) {
+	if _, err := uuid.Parse(subscriptionID); err != nil {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "Delivery not found", 404)
+	}
+	if _, err := uuid.Parse(deliveryID); err != nil {
+		return nil, apperrors.New(apperrors.ErrCodeNotFound, "Delivery not found", 404)
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, subscriptionID, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeNotFound, "Delivery not found", 404)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get webhook delivery: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to get delivery", 500)
+	}
+	if delivery.Status != repository.DeliveryDead {
+		return nil, apperrors.New(apperrors.ErrCodeConflict, "Only dead deliveries can be redelivered", 409)
+	}
+
+	delivery, err = s.repo.Redeliver(ctx, subscriptionID, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeConflict, "Only dead deliveries can be redelivered", 409)
+	}
+	if err != nil {
+		logger.Errorf("Failed to redeliver webhook delivery: %v", err)
+		return nil, apperrors.Wrap(err, apperrors.ErrCodeDatabaseError, "Failed to redeliver", 500)
+	}
+
+	logger.Infof("Webhook delivery queued again: %s (%s)", deliveryID, subscriptionID)
+	s.notify()
+	return delivery, nil
+}
+
+// Notify queues an event for every subscription that wants it.
+// 이벤트를 일으킨 작업은 이미 끝났으므로 기록에 실패해도 로그만 남긴다.
+func (s *WebhookService) Notify(ctx context.Context, eventType, userID string, data interface{}) {
+	event := WebhookEvent{ID: uuid.New().String(), Type: eventType, CreatedAt: time.Now().UTC(), Data: data}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logger.Warnf("Failed to encode %s webhook event: %v", eventType, err)
+		return
+	}
+
+	queued, err := s.repo.Enqueue(ctx, event.ID, eventType, userID, payload)
+	if err != nil {
+		logger.Warnf("Failed to queue %s webhook event (%s): %v", eventType, event.ID, err)
+		return
+	}
+	if queued > 0 {
+		s.notify()
+	}
+}
+
+// RunDue sends every delivery that is due until none is left to claim and returns how many succeeded
+func (s *WebhookService) RunDue(ctx context.Context) (int, error) {
+	succeeded := 0
+	for {
+		deliveries, err := s.repo.ClaimDue(ctx, s.opts.BatchSize, s.opts.Lease)
+		if err != nil || len(deliveries) == 0 {
+			return succeeded, err
+		}
+
+		// 느린 수신측 하나가 배치 전체를 붙잡지 않도록 동시에 보낸다. 임대는 제한 시간보다 길다.
+		var wg sync.WaitGroup
+		results := make([]bool, len(deliveries))
+		for i, delivery := range deliveries {
+			wg.Add(1)
+			go func(i int, delivery *repository.WebhookDelivery) {
+				defer wg.Done()
+				results[i] = s.deliver(ctx, delivery)
+			}(i, delivery)
+		}
+		wg.Wait()
+
+		for _, ok := range results {
+			if ok {
+				succeeded++
+			}
+		}
+		if ctx.Err() != nil {
+			return succeeded, ctx.Err()
+		}
+	}
+}
+
+// SweepDelivered removes succeeded deliveries past the retention period
+func (s *WebhookService) SweepDelivered(ctx context.Context) (int64, error) {
+	return s.repo.DeleteSucceeded(ctx, s.opts.Retention)
+}
+
+// Start sends due deliveries every interval, and right after each new event, until Close is called.
+// 보존 기간이 지난 성공 기록은 주기마다 정리한다.
+func (s *WebhookService) Start(interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		sweep := true
+		for {
+			if n, err := s.RunDue(ctx); err != nil && ctx.Err() == nil {
+				logger.Warnf("Webhook worker failed: %v", err)
+			} else if n > 0 {
+				logger.Debugf("Webhook worker: %d delivery(ies) succeeded", n)
+			}
+
+			if sweep {
+				if n, err := s.SweepDelivered(ctx); err != nil && ctx.Err() == nil {
+					logger.Warnf("Webhook sweep failed: %v", err)
+				} else if n > 0 {
+					logger.Infof("Webhook sweep: %d old delivery(ies) removed", n)
+				}
+			}
+
+			select {
+			case <-ticker.C:
+				sweep = true
+			case <-s.wake:
+				sweep = false
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the worker; deliveries being sent are released and sent again on the next start
+func (s *WebhookService) Close() {
+	if s.cancel != nil {
+		s.cancel()
+		<-s.done
+		s.cancel = nil
+	}
+}
+
+// notify wakes the worker without blocking
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends one delivery and records the outcome; it reports whether the receiver accepted it
+func (s *WebhookService) deliver(ctx context.Context, delivery *repository.WebhookDelivery) bool {
+	statusCode, sendErr := s.send(ctx, delivery)
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 3*time.Second)
+	defer cancel()
+
+	if sendErr == nil {
+		if err := s.repo.MarkSucceeded(recordCtx, delivery.ID, statusCode); err != nil {
+			logger.Warnf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+		}
+		middleware.RecordWebhookDelivery(repository.DeliverySucceeded)
+		return true
+	}
+
+	// 종료 중이라 끊긴 시도는 실패로 세지 않고 돌려놓는다.
+	if ctx.Err() != nil {
+		if err := s.repo.Release(recordCtx, delivery.ID); err != nil {
+			logger.Warnf("Failed to release webhook delivery %s: %v", delivery.ID, err)
+		}
+		return false
+	}
+
+	final := delivery.Attempts >= s.opts.MaxAttempts
+	retryAfter := webhook.Backoff(delivery.Attempts, s.opts.BackoffBase, s.opts.BackoffMax)
+	if err := s.repo.MarkFailed(recordCtx, delivery.ID, statusCode, sendErr.Error(), retryAfter, final); err != nil {
+		logger.Warnf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+	}
+
+	if final {
+		middleware.RecordWebhookDelivery(repository.DeliveryDead)
+		logger.Warnf("Webhook delivery %s (%s to %s) is dead after %d attempts: %v",
+			delivery.ID, delivery.EventType, delivery.URL, delivery.Attempts, sendErr)
+	} else {
+		middleware.RecordWebhookDelivery("retry")
+		logger.Infof("Webhook delivery %s (%s to %s) failed, attempt %d, retrying in %s: %v",
+			delivery.ID, delivery.EventType, delivery.URL, delivery.Attempts, retryAfter, sendErr)
+	}
+	return false
+}
+
+// send posts the signed payload; any 2xx response is a success.
+// 응답을 받지 못하면 상태 코드 0 을 돌려준다.
+func (s *WebhookService) send(ctx context.Context, delivery *repository.WebhookDelivery) (int, error) {
+	var body []byte
+	if delivery.Payload != nil {
+		body = *delivery.Payload
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RealTimeMessageChat-Webhooks")
+	req.Header.Set(webhook.HeaderEvent, delivery.EventType)
+	req.Header.Set(webhook.HeaderDelivery, delivery.ID)
+	req.Header.Set(webhook.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(webhook.HeaderSignature, webhook.Sign(delivery.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return resp.StatusCode, nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, webhookErrorBodyLimit))
+	message := fmt.Sprintf("HTTP %d", resp.StatusCode)
+	if text := strings.TrimSpace(string(snippet)); text != "" {
+		message += ": " + text
+	}
+	return resp.StatusCode, errors.New(message)
+}
+
+// validateWebhookURL accepts absolute http and https URLs
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return apperrors.New(apperrors.ErrCodeValidation, "url must be an absolute http or https URL", 400).
+			WithFields(map[string]string{"url": "must be an absolute http or https URL"})
+	}
+	if parsed.User != nil {
+		return apperrors.New(apperrors.ErrCodeValidation, "url must not contain credentials", 400).
+			WithFields(map[string]string{"url": "must not contain credentials"})
+	}
+	return nil
+}
+
+// validateWebhookSecret checks a secret chosen by the caller
+func validateWebhookSecret(secret string) error {
+	if len(secret) < minWebhookSecretLength {
+		return apperrors.New(apperrors.ErrCodeValidation, fmt.Sprintf("secret must be at least %d characters", minWebhookSecretLength), 400).
+			WithFields(map[string]string{"secret": "is too short"})
+	}
+	return nil
+}
+
+// normalizeEventTypes checks event types and drops duplicates, keeping their order
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	normalized := make([]string, 0, len(eventTypes))
+	seen := make(map[string]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		if !isWebhookEventType(eventType) {
+			return nil, apperrors.New(apperrors.ErrCodeValidation, "Unknown event type: "+eventType, 400).
+				WithFields(map[string]string{"event_types": "must be one of " + strings.Join(WebhookEventTypes, ", ")})
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized, nil
+}
+
+// isWebhookEventType reports whether eventType is a known event type
+func isWebhookEventType(eventType string) bool {
+	for _, known := range WebhookEventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
@@ -1,13 +1,18 @@
 package services
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/config"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq" // PostgreSQL driver
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // DatabaseService handles PostgreSQL database operations
@@ -22,7 +27,7 @@ func NewDatabaseService(cfg *config.DatabaseConfig) (*DatabaseService, error) {
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
 	)
 
-	db, err := sqlx.Connect("postgres", dsn)
+	db, err := openDB("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -45,6 +50,28 @@ func NewDatabaseService(cfg *config.DatabaseConfig) (*DatabaseService, error) {
 	}, nil
 }
 
+// openDB opens a connection pool whose queries are traced.
+// 요청 span 아래의 쿼리만 span 을 만들어 백그라운드 워커의 폴링 쿼리가 trace 를 쏟아내지 않게 한다.
+// 쿼리 문장만 기록하고 인자 값은 남기지 않는다.
+func openDB(driverName, dsn string) (*sqlx.DB, error) {
+	db, err := otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return trace.SpanContextFromContext(ctx).IsValid()
+			},
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	// 바인드 변수 형식($1)은 드라이버 이름으로 정해지므로 감싼 드라이버와 관계없이 postgres 로 둔다.
+	return sqlx.NewDb(db, "postgres"), nil
+}
+
 // GetDB returns the underlying database connection
 func (d *DatabaseService) GetDB() *sqlx.DB {
 	return d.db
@@ -70,11 +97,58 @@ func (d *DatabaseService) IsHealthy() bool {
 // initdb 가 먼저 만든 정의와 어긋난 채 조용히 no-op 이 되고 이후 모든 쿼리가 실패했다.
 var requiredSchema = map[string][]string{
 	"users": {
-		"id", "user_id", "username", "email", "status", "last_seen", "created_at", "updated_at",
+		"id", "user_id", "username", "email", "password_hash", "role", "status", "last_seen", "created_at", "updated_at",
+		"deleted_at",
 	},
 	"messages": {
 		"id", "message_id", "user_id", "sub_id", "command", "publisher_info",
-		"server_name", "content", "is_encrypted", "status", "created_at", "processed_at",
+		"server_name", "content", "is_encrypted", "status", "created_at", "processed_at", "room_id", "recipient_id",
+		"edited_at", "recalled_at", "recalled_by", "parent_message_id", "thread_root_id",
+	},
+	"rooms": {
+		"id", "room_id", "name", "description", "is_private", "created_by", "created_at", "updated_at",
+	},
+	"room_members": {
+		"room_id", "user_id", "role", "joined_at",
+	},
+	"message_revisions": {
+		"id", "message_id", "content", "is_encrypted", "replaced_at",
+	},
+	"message_receipts": {
+		"message_id", "user_id", "delivered_at", "read_at",
+	},
+	"message_reactions": {
+		"message_id", "user_id", "emoji", "created_at",
+	},
+	"attachments": {
+		"id", "uploader_id", "message_id", "file_name", "content_type", "size_bytes", "sha256", "created_at",
+	},
+	"moderation_flags": {
+		"id", "message_id", "user_id", "content", "is_edit", "verdicts", "status", "reviewed_by", "reviewed_at",
+		"created_at",
+	},
+	"api_keys": {
+		"id", "key_id", "name", "secret_hash", "owner_user_id", "scopes", "expires_at",
+		"revoked_at", "last_used_at", "usage_count", "created_by", "created_at",
+	},
+	"audit_log": {
+		"id", "occurred_at", "actor_id", "actor_type", "api_key_id", "action", "target_type",
+		"target_id", "before_value", "after_value", "request_id", "client_ip", "outcome", "status_code",
+	},
+	"user_erasures": {
+		"id", "user_id", "message_mode", "status", "requested_by", "messages_processed", "cache_purged",
+		"attempts", "last_error", "lease_until", "requested_at", "started_at", "completed_at",
+	},
+	"user_exports": {
+		"id", "user_id", "status", "requested_by", "file_name", "size_bytes", "attempts", "last_error",
+		"lease_until", "requested_at", "started_at", "completed_at", "expires_at",
+	},
+	"webhook_subscriptions": {
+		"id", "url", "secret", "event_types", "description", "active", "created_by", "created_at", "updated_at",
+	},
+	"webhook_deliveries": {
+		"id", "subscription_id", "event_id", "event_type", "user_id", "payload", "status", "attempts",
+		"next_attempt_at", "last_status_code", "last_error", "lease_until", "created_at", "delivered_at",
 	},
 }
 
@@ -107,7 +181,7 @@ func (d *DatabaseService) VerifySchema() error {
 		}
 
 		if len(missing) > 0 {
-			return fmt.Errorf("table %q is missing column(s) %v - apply database/migrations/001_unify_messages.sql", table, missing)
+			return fmt.Errorf("table %q is missing column(s) %v - apply database/migrations", table, missing)
 		}
 	}
 
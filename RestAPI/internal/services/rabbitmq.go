@@ -9,7 +9,13 @@ import (
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/config"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RabbitMQService handles RabbitMQ operations
@@ -157,11 +163,43 @@ func (s *RabbitMQService) handleReconnect() {
 
 // Publish publishes a message to the queue
 func (s *RabbitMQService) Publish(ctx context.Context, message []byte) error {
-	return s.PublishWithPriority(ctx, message, 0)
+	return s.publish(ctx, "", message, 0)
+}
+
+// PublishMessage publishes a queue message under its publisher_information.message_id.
+// message_id 는 AMQP message-id 속성으로, 요청 컨텍스트의 request ID 는 correlation-id 속성으로 실린다.
+func (s *RabbitMQService) PublishMessage(ctx context.Context, messageID string, message []byte) error {
+	return s.publish(ctx, messageID, message, 0)
 }
 
 // PublishWithPriority publishes a message to the queue with specified priority
 func (s *RabbitMQService) PublishWithPriority(ctx context.Context, message []byte, priority uint8) error {
+	return s.publish(ctx, "", message, priority)
+}
+
+// publish sends a message and waits for the broker to confirm it.
+// 발행 span 의 trace context 를 traceparent 헤더로 실어 보내, 소비측(DBWorker)이 같은 trace 를 이어 갈 수 있게 한다.
+// 성공이든 실패든 발행마다 message_id·request_id 를 한 줄 남겨, 로그만으로 요청과 큐 메시지를 이을 수 있게 한다.
+func (s *RabbitMQService) publish(ctx context.Context, messageID string, message []byte, priority uint8) (err error) {
+	requestID := middleware.RequestIDFromContext(ctx)
+	ctx, span := startPublishSpan(ctx, s.config.QueueName, messageID, requestID, message, priority)
+	defer func() {
+		entry := logger.WithFields(logrus.Fields{
+			"queue":      s.config.QueueName,
+			"message_id": messageID,
+			"request_id": requestID,
+			"priority":   priority,
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			entry.WithError(err).Warn("Message publish failed")
+		} else {
+			entry.Info("Message published and confirmed")
+		}
+		span.End()
+	}()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -173,13 +211,7 @@ func (s *RabbitMQService) PublishWithPriority(ctx context.Context, message []byt
 		return fmt.Errorf("rabbitmq channel is not initialized")
 	}
 
-	publishing := amqp.Publishing{
-		DeliveryMode: amqp.Persistent,
-		ContentType:  "application/json",
-		Body:         message,
-		Timestamp:    time.Now(),
-		Priority:     priority,
-	}
+	publishing := newPublishing(ctx, messageID, requestID, message, priority)
 
 	// amqp091-go 의 PublishWithContext 는 컨텍스트를 무시한다(channel.go 주석 명시).
 	// DeferredConfirmation.WaitContext 로 브로커 ack 을 기다려야 호출측 타임아웃이 실제로 적용된다.
@@ -208,10 +240,48 @@ func (s *RabbitMQService) PublishWithPriority(ctx context.Context, message []byt
 	}
 
 	middleware.RecordRabbitMQPublish(s.config.QueueName, true)
-	logger.WithField("queue", s.config.QueueName).Debug("Message published and confirmed")
 	return nil
 }
 
+// newPublishing builds the AMQP message of a publish. 비어 있는 message_id·request_id 는 속성을 비워 둔다.
+func newPublishing(ctx context.Context, messageID, requestID string, message []byte, priority uint8) amqp.Publishing {
+	publishing := amqp.Publishing{
+		DeliveryMode:  amqp.Persistent,
+		ContentType:   "application/json",
+		Body:          message,
+		Timestamp:     time.Now(),
+		Priority:      priority,
+		MessageId:     messageID,
+		CorrelationId: requestID,
+		Headers:       amqp.Table{},
+	}
+	tracing.InjectAMQP(ctx, publishing.Headers)
+	return publishing
+}
+
+// startPublishSpan starts a producer span for a message sent to queue
+func startPublishSpan(ctx context.Context, queue, messageID, requestID string, message []byte, priority uint8) (context.Context, trace.Span) {
+	attributes := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingOperationTypePublish,
+		semconv.MessagingDestinationName(queue),
+		semconv.MessagingRabbitmqDestinationRoutingKey(queue),
+		semconv.MessagingMessageBodySize(len(message)),
+		attribute.Int("messaging.rabbitmq.message.priority", int(priority)),
+	}
+	if messageID != "" {
+		attributes = append(attributes, semconv.MessagingMessageID(messageID))
+	}
+	if requestID != "" {
+		attributes = append(attributes, semconv.MessagingMessageConversationID(requestID))
+	}
+
+	return tracing.Tracer().Start(ctx, queue+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(attributes...),
+	)
+}
+
 // Close closes the RabbitMQ connection
 func (s *RabbitMQService) Close() error {
 	s.mu.Lock()
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/config"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestNewPublishing(t *testing.T) {
+	tracingtest.Setup(t)
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "POST /api/v1/messages/send")
+	defer span.End()
+
+	publishing := newPublishing(ctx, "mid", "req-1", []byte(`{}`), 2)
+
+	assert.Equal(t, "mid", publishing.MessageId)
+	assert.Equal(t, "req-1", publishing.CorrelationId)
+	assert.Equal(t, uint8(2), publishing.Priority)
+	assert.Contains(t, publishing.Headers, "traceparent")
+
+	anonymous := newPublishing(context.Background(), "", "", []byte(`{}`), 0)
+	assert.Empty(t, anonymous.MessageId)
+	assert.Empty(t, anonymous.CorrelationId)
+}
+
+func TestPublishMessage_Identifiers(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	rabbitMQ := &RabbitMQService{config: &config.RabbitMQConfig{QueueName: "messages"}, closed: true}
+	ctx := middleware.ContextWithRequestID(context.Background(), "req-1")
+
+	err := rabbitMQ.PublishMessage(ctx, "mid", []byte(`{}`))
+	assert.ErrorContains(t, err, "closed")
+
+	// 실패한 발행도 span 에 식별자가 남아 어느 요청의 어느 메시지였는지 알 수 있다.
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "messages publish", spans[0].Name)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.Contains(t, spans[0].Attributes, attribute.String("messaging.message.id", "mid"))
+	assert.Contains(t, spans[0].Attributes, attribute.String("messaging.message.conversation_id", "req-1"))
+}
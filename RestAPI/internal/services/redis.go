@@ -9,7 +9,12 @@ import (
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/config"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/middleware"
 	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/logger"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // metricsHook 은 모든 Redis 명령의 지연·성공 여부를 Prometheus 에 적립한다.
@@ -36,6 +41,60 @@ func (metricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.Pro
 	}
 }
 
+// tracingHook 은 Redis 명령마다 client span 을 남긴다. metricsHook 과 같은 방식으로 훅 한 곳에서 처리한다.
+// 요청 span 아래에서만 만들어, presence sweeper 같은 백그라운드 작업이 trace 를 쏟아내지 않게 한다.
+// 명령 인자는 캐시된 사용자 정보 같은 값이 담기므로 기록하지 않는다.
+type tracingHook struct{}
+
+func (tracingHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmd)
+		}
+
+		ctx, span := startRedisSpan(ctx, cmd.Name())
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+func (tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			return next(ctx, cmds)
+		}
+
+		ctx, span := startRedisSpan(ctx, "pipeline")
+		defer span.End()
+		span.SetAttributes(attribute.Int("db.redis.pipeline_length", len(cmds)))
+
+		err := next(ctx, cmds)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+// startRedisSpan starts a client span named after the command
+func startRedisSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, "redis "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(operation)),
+	)
+}
+
+// recordRedisError marks the span failed unless the error is a cache miss
+func recordRedisError(span trace.Span, err error) {
+	if err = operationError(err); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
 // operationError 는 키 부재(redis.Nil)를 오류로 집계하지 않는다 — 정상적인 캐시 미스다.
 func operationError(err error) error {
 	if errors.Is(err, redis.Nil) {
@@ -44,6 +103,10 @@ func operationError(err error) error {
 	return err
 }
 
+// ErrCacheMiss is returned by the getters when the key or field does not exist.
+// 호출측이 Redis 장애와 단순 미스를 구분해야 할 때 errors.Is 로 판별한다.
+var ErrCacheMiss = errors.New("key not found")
+
 // RedisService handles Redis operations
 type RedisService struct {
 	client *redis.Client
@@ -64,6 +127,7 @@ func NewRedisService(cfg *config.RedisConfig) (*RedisService, error) {
 	})
 
 	client.AddHook(metricsHook{})
+	client.AddHook(tracingHook{})
 
 	// Test connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -90,11 +154,25 @@ func (r *RedisService) Set(ctx context.Context, key string, value interface{}, e
 func (r *RedisService) Get(ctx context.Context, key string) (string, error) {
 	val, err := r.client.Get(ctx, key).Result()
 	if err == redis.Nil {
-		return "", fmt.Errorf("key not found: %s", key)
+		return "", fmt.Errorf("%w: %s", ErrCacheMiss, key)
+	}
+	return val, err
+}
+
+// GetDel retrieves a value and deletes the key atomically
+func (r *RedisService) GetDel(ctx context.Context, key string) (string, error) {
+	val, err := r.client.GetDel(ctx, key).Result()
+	if err == redis.Nil {
+		return "", fmt.Errorf("%w: %s", ErrCacheMiss, key)
 	}
 	return val, err
 }
 
+// MGet retrieves several keys in one round trip; missing keys are returned as nil
+func (r *RedisService) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return r.client.MGet(ctx, keys...).Result()
+}
+
 // Delete deletes one or more keys
 func (r *RedisService) Delete(ctx context.Context, keys ...string) error {
 	return r.client.Del(ctx, keys...).Err()
@@ -149,9 +227,29 @@ func (r *RedisService) Decr(ctx context.Context, key string) (int64, error) {
 	return r.client.Decr(ctx, key).Result()
 }
 
-// ZAdd adds members to a sorted set
-func (r *RedisService) ZAdd(ctx context.Context, key string, members ...redis.Z) error {
-	return r.client.ZAdd(ctx, key, members...).Err()
+// ZAdd adds or updates members of a sorted set and returns how many were newly added
+func (r *RedisService) ZAdd(ctx context.Context, key string, members ...redis.Z) (int64, error) {
+	return r.client.ZAdd(ctx, key, members...).Result()
+}
+
+// ZRem removes members from a sorted set and returns how many were present
+func (r *RedisService) ZRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return r.client.ZRem(ctx, key, members...).Result()
+}
+
+// ZCount counts members of a sorted set within a score range
+func (r *RedisService) ZCount(ctx context.Context, key string, min, max string) (int64, error) {
+	return r.client.ZCount(ctx, key, min, max).Result()
+}
+
+// ZRevRangeByScore retrieves a page of members by score range, highest score first
+func (r *RedisService) ZRevRangeByScore(ctx context.Context, key string, min, max string, offset, count int64) ([]string, error) {
+	return r.client.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min:    min,
+		Max:    max,
+		Offset: offset,
+		Count:  count,
+	}).Result()
 }
 
 // ZRangeByScore retrieves members from sorted set by score range
@@ -167,6 +265,11 @@ func (r *RedisService) ZRemRangeByScore(ctx context.Context, key string, min, ma
 	return r.client.ZRemRangeByScore(ctx, key, min, max).Err()
 }
 
+// RunScript runs a Lua script, loading it on first use
+func (r *RedisService) RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	return script.Run(ctx, r.client, keys, args...).Result()
+}
+
 // Publish publishes a message to a channel
 func (r *RedisService) Publish(ctx context.Context, channel string, message interface{}) error {
 	return r.client.Publish(ctx, channel, message).Err()
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/internal/config"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing/tracingtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedisTracing(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	server := miniredis.RunT(t)
+	port, err := strconv.Atoi(server.Port())
+	require.NoError(t, err)
+	redisService, err := NewRedisService(&config.RedisConfig{Host: server.Host(), Port: port})
+	require.NoError(t, err)
+	t.Cleanup(func() { redisService.Close() })
+	exporter.Reset()
+
+	t.Run("without a request span", func(t *testing.T) {
+		require.NoError(t, redisService.Set(context.Background(), "k", "v", 0))
+		assert.Empty(t, exporter.GetSpans())
+	})
+
+	t.Run("under a request span", func(t *testing.T) {
+		exporter.Reset()
+		ctx, parent := tracing.Tracer().Start(context.Background(), "GET /users/:userID")
+
+		require.NoError(t, redisService.Set(ctx, "k", "v", 0))
+		_, err := redisService.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrCacheMiss)
+		parent.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 3)
+		assert.Equal(t, "redis set", spans[0].Name)
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+		assert.Contains(t, spans[0].Attributes, attribute.String("db.system", "redis"))
+
+		assert.Equal(t, "redis get", spans[1].Name)
+		assert.Equal(t, codes.Unset, spans[1].Status.Code, "캐시 미스는 오류가 아니다")
+	})
+
+	t.Run("records failures", func(t *testing.T) {
+		exporter.Reset()
+		ctx, parent := tracing.Tracer().Start(context.Background(), "request")
+
+		server.SetError("LOADING")
+		defer server.SetError("")
+		assert.Error(t, redisService.Set(ctx, "k", "v", 0))
+		parent.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	})
+}
+
+func TestDatabaseTracing(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	_, mock, err := sqlmock.NewWithDSN("tracing_test", sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	db, err := openDB("sqlmock", "tracing_test")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	mock.ExpectExec("UPDATE users SET last_seen = NOW() WHERE user_id = $1").
+		WithArgs("alice").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM sessions").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "PATCH /users/:userID")
+	_, err = db.ExecContext(ctx, "UPDATE users SET last_seen = NOW() WHERE user_id = $1", "alice")
+	require.NoError(t, err)
+	parent.End()
+
+	_, err = db.ExecContext(context.Background(), "DELETE FROM sessions")
+	require.NoError(t, err)
+
+	var execSpans []string
+	for _, span := range exporter.GetSpans() {
+		if span.Parent.SpanID() == parent.SpanContext().SpanID() {
+			execSpans = append(execSpans, span.Name)
+			assert.Contains(t, span.Attributes, attribute.String("db.system", "postgresql"))
+			for _, attr := range span.Attributes {
+				assert.NotEqual(t, "alice", attr.Value.Emit(), "인자 값은 남기지 않는다")
+			}
+		}
+	}
+	assert.Equal(t, []string{"sql.conn.exec"}, execSpans)
+	assert.Len(t, exporter.GetSpans(), 2, "요청 span 밖의 쿼리는 span 을 만들지 않는다")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
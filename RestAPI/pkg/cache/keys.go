@@ -8,8 +8,15 @@ const (
 	PrefixUserStatus    = "user:status"
 	PrefixMessage       = "message"
 	PrefixMessageStatus = "message:status"
+	PrefixReactions     = "message:reactions"
 	PrefixSession       = "session"
+	PrefixRefreshFamily = "session:family"
+	PrefixRotatedToken  = "session:rotated"
+	PrefixRevokedToken  = "revoked:token"
+	PrefixRevokedUser   = "revoked:user"
 	PrefixRateLimit     = "ratelimit"
+	PrefixPresence      = "presence"
+	PrefixFlood         = "moderation:flood"
 )
 
 // UserKey generates a cache key for user data
@@ -32,11 +39,46 @@ func MessageStatusKey(messageID string) string {
 	return fmt.Sprintf("%s:%s", PrefixMessageStatus, messageID)
 }
 
+// MessageReactionsKey is the hash of a message's reaction counts by emoji
+func MessageReactionsKey(messageID string) string {
+	return fmt.Sprintf("%s:%s", PrefixReactions, messageID)
+}
+
 // SessionKey generates a cache key for session data
 func SessionKey(sessionID string) string {
 	return fmt.Sprintf("%s:%s", PrefixSession, sessionID)
 }
 
+// RefreshFamilyKey generates a cache key pointing at the live refresh token of a rotation family
+func RefreshFamilyKey(familyID string) string {
+	return fmt.Sprintf("%s:%s", PrefixRefreshFamily, familyID)
+}
+
+// RotatedTokenKey generates a cache key remembering a refresh token that was already rotated
+func RotatedTokenKey(tokenHash string) string {
+	return fmt.Sprintf("%s:%s", PrefixRotatedToken, tokenHash)
+}
+
+// RevokedTokenKey generates a cache key for a revoked access token ID (jti)
+func RevokedTokenKey(tokenID string) string {
+	return fmt.Sprintf("%s:%s", PrefixRevokedToken, tokenID)
+}
+
+// RevokedUserKey generates a cache key holding the time before which a user's tokens are revoked
+func RevokedUserKey(userID string) string {
+	return fmt.Sprintf("%s:%s", PrefixRevokedUser, userID)
+}
+
+// PresenceOnlineKey is the sorted set of present users scored by their last heartbeat (unix ms)
+func PresenceOnlineKey() string {
+	return fmt.Sprintf("%s:online", PrefixPresence)
+}
+
+// ModerationFloodKey counts how often a user sent content with the given digest
+func ModerationFloodKey(userID, digest string) string {
+	return fmt.Sprintf("%s:%s:%s", PrefixFlood, userID, digest)
+}
+
 // RateLimitKey generates a cache key for rate limiting
 func RateLimitKey(identifier string) string {
 	return fmt.Sprintf("%s:%s", PrefixRateLimit, identifier)
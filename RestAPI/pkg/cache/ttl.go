@@ -11,6 +11,7 @@ const (
 	// Message-related TTLs
 	TTLMessageData   = 30 * time.Minute
 	TTLMessageStatus = 1 * time.Hour
+	TTLReactions     = 10 * time.Minute
 
 	// Session-related TTLs
 	TTLSession      = 24 * time.Hour
@@ -0,0 +1,77 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCiphertext is returned when stored content cannot be decrypted with the configured key
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// MessageCipher decrypts message content stored by CommonModule/DBWorker.
+// DBWorker 는 AES-256-CBC(PKCS#7 패딩) 로 암호화한 뒤 base64 로 저장한다. 키와 IV 도 base64 이며
+// MainServerConsumer 설정의 database_encryption_key / database_encryption_iv 와 같은 값이어야 한다.
+type MessageCipher struct {
+	block cipher.Block
+	iv    []byte
+}
+
+// NewMessageCipher creates a cipher from a base64 encoded 32-byte key and 16-byte IV
+func NewMessageCipher(keyBase64, ivBase64 string) (*MessageCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(keyBase64)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key is not valid base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+
+	iv, err := base64.StdEncoding.DecodeString(ivBase64)
+	if err != nil {
+		return nil, fmt.Errorf("encryption iv is not valid base64: %w", err)
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("encryption iv must be %d bytes, got %d", aes.BlockSize, len(iv))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageCipher{block: block, iv: iv}, nil
+}
+
+// Decrypt decodes and decrypts base64 content written by DBWorker
+func (m *MessageCipher) Decrypt(content string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	if len(data) == 0 || len(data)%aes.BlockSize != 0 {
+		return "", fmt.Errorf("%w: length %d is not a multiple of the block size", ErrInvalidCiphertext, len(data))
+	}
+
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(m.block, m.iv).CryptBlocks(plain, data)
+
+	// 패딩이 맞지 않으면 키가 다르다는 뜻이다 — 깨진 평문을 돌려주지 않는다.
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > aes.BlockSize || !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return "", fmt.Errorf("%w: bad padding", ErrInvalidCiphertext)
+	}
+	return string(plain[:len(plain)-pad]), nil
+}
+
+// Encrypt encrypts plaintext the way DBWorker does and returns it base64 encoded
+func (m *MessageCipher) Encrypt(plaintext string) string {
+	pad := aes.BlockSize - len(plaintext)%aes.BlockSize
+	data := append([]byte(plaintext), bytes.Repeat([]byte{byte(pad)}, pad)...)
+
+	out := make([]byte, len(data))
+	cipher.NewCBCEncrypter(m.block, m.iv).CryptBlocks(out, data)
+	return base64.StdEncoding.EncodeToString(out)
+}
@@ -0,0 +1,71 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	testIV  = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("v", 16)))
+)
+
+func TestNewMessageCipher(t *testing.T) {
+	tests := []struct {
+		name    string
+		key, iv string
+		wantErr string
+	}{
+		{"valid", testKey, testIV, ""},
+		{"key not base64", "not base64!", testIV, "not valid base64"},
+		{"short key", base64.StdEncoding.EncodeToString([]byte("short")), testIV, "must be 32 bytes"},
+		{"short iv", testKey, base64.StdEncoding.EncodeToString([]byte("short")), "must be 16 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMessageCipher(tt.key, tt.iv)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestMessageCipher_Decrypt(t *testing.T) {
+	c, err := NewMessageCipher(testKey, testIV)
+	require.NoError(t, err)
+
+	t.Run("round trip", func(t *testing.T) {
+		for _, plain := range []string{"", "hello", strings.Repeat("x", 16), `{"message":"안녕하세요"}`} {
+			decrypted, err := c.Decrypt(c.Encrypt(plain))
+
+			require.NoError(t, err)
+			assert.Equal(t, plain, decrypted)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		other, err := NewMessageCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", 32))), testIV)
+		require.NoError(t, err)
+
+		_, err = other.Decrypt(c.Encrypt("hello"))
+
+		assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	})
+
+	t.Run("not ciphertext", func(t *testing.T) {
+		for _, content := range []string{"plain text", base64.StdEncoding.EncodeToString([]byte("short")), ""} {
+			_, err := c.Decrypt(content)
+
+			assert.ErrorIs(t, err, ErrInvalidCiphertext)
+		}
+	})
+}
@@ -8,10 +8,11 @@ import (
 
 // AppError represents an application error with code and HTTP status
 type AppError struct {
-	Code       string `json:"code"`
-	Message    string `json:"message"`
-	StatusCode int    `json:"-"`
-	Err        error  `json:"-"`
+	Code       string            `json:"code"`
+	Message    string            `json:"message"`
+	Fields     map[string]string `json:"fields,omitempty"`
+	StatusCode int               `json:"-"`
+	Err        error             `json:"-"`
 }
 
 // Error implements the error interface
@@ -46,6 +47,12 @@ func Wrap(err error, code, message string, statusCode int) *AppError {
 	}
 }
 
+// WithFields attaches per-field messages, keyed by JSON field name, and returns the error
+func (e *AppError) WithFields(fields map[string]string) *AppError {
+	e.Fields = fields
+	return e
+}
+
 // IsAppError checks if an error is an AppError
 func IsAppError(err error) bool {
 	var appErr *AppError
@@ -78,6 +85,7 @@ const (
 	ErrCodeInvalidPayload   = "INVALID_PAYLOAD"
 	ErrCodeDuplicateKey     = "DUPLICATE_KEY"
 	ErrCodeInvalidOperation = "INVALID_OPERATION"
+	ErrCodeContentRejected  = "CONTENT_REJECTED"
 )
 
 // Pre-defined errors
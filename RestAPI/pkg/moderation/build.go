@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config describes the built-in filters of a pipeline. Filters with nothing to check are left out.
+type Config struct {
+	MaxLength int
+
+	BlockedWords []string
+	// BlockedWordsFile has one word or phrase per line; empty lines and lines starting with # are skipped
+	BlockedWordsFile   string
+	BlockedWordsAction Action
+
+	Rules []RuleConfig
+
+	AllowDomains []string
+	DenyDomains  []string
+	LinksAction  Action
+
+	FloodMaxRepeats int
+	FloodWindow     time.Duration
+	FloodAction     Action
+}
+
+// RuleConfig describes a regular expression rule
+type RuleConfig struct {
+	Name      string
+	Pattern   string
+	Action    Action
+	Reason    string
+	Normalize bool
+}
+
+// Build creates the pipeline described by cfg: max length, blocked words, rules, links, then flood detection.
+// 반복 감지를 맨 뒤에 둬서 다른 필터가 거절한 메시지는 세지 않는다.
+func Build(cfg Config, counter Counter) (*Pipeline, error) {
+	var filters []Filter
+
+	if cfg.MaxLength > 0 {
+		filters = append(filters, NewMaxLength(cfg.MaxLength))
+	}
+
+	words := cfg.BlockedWords
+	if cfg.BlockedWordsFile != "" {
+		fromFile, err := readWordList(cfg.BlockedWordsFile)
+		if err != nil {
+			return nil, err
+		}
+		words = append(append([]string(nil), words...), fromFile...)
+	}
+	if len(words) > 0 {
+		filters = append(filters, NewBlockedWords(words, cfg.BlockedWordsAction))
+	}
+
+	for _, rule := range cfg.Rules {
+		filter, err := NewRegexRule(rule.Name, rule.Pattern, rule.Action, rule.Reason, rule.Normalize)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(cfg.AllowDomains) > 0 || len(cfg.DenyDomains) > 0 {
+		filters = append(filters, NewLinks(cfg.AllowDomains, cfg.DenyDomains, cfg.LinksAction))
+	}
+
+	if cfg.FloodMaxRepeats > 0 {
+		if counter == nil {
+			return nil, fmt.Errorf("flood detection needs a counter")
+		}
+		filters = append(filters, NewFlood(counter, cfg.FloodMaxRepeats, cfg.FloodWindow, cfg.FloodAction))
+	}
+
+	return NewPipeline(filters...), nil
+}
+
+// readWordList reads a word list file
+func readWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("blocked words file: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("blocked words file: %w", err)
+	}
+	return words, nil
+}
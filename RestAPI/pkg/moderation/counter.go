@@ -0,0 +1,76 @@
+package moderation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryCounter counts in process memory. 레플리카마다 따로 세므로 한 대로 돌릴 때나 Redis 가 없을 때 쓴다.
+type MemoryCounter struct {
+	mu      sync.Mutex
+	entries map[string]*counterEntry
+	now     func() time.Time
+	// sweepAt is when expired entries are next removed
+	sweepAt time.Time
+}
+
+type counterEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// NewMemoryCounter creates an in-memory counter
+func NewMemoryCounter() *MemoryCounter {
+	return &MemoryCounter{entries: make(map[string]*counterEntry), now: time.Now}
+}
+
+// Incr counts an event for key and returns the count within the current window
+func (c *MemoryCounter) Incr(_ context.Context, key string, window time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.After(c.sweepAt) {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.sweepAt = now.Add(window)
+	}
+
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		entry = &counterEntry{expiresAt: now.Add(window)}
+		c.entries[key] = entry
+	}
+	entry.count++
+	return entry.count, nil
+}
+
+// incrScript increments a counter and starts its window on the first event, atomically
+var incrScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+  redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+// RedisCounter counts in Redis so every replica shares the counts
+type RedisCounter struct {
+	client *redis.Client
+}
+
+// NewRedisCounter creates a Redis-backed counter
+func NewRedisCounter(client *redis.Client) *RedisCounter {
+	return &RedisCounter{client: client}
+}
+
+// Incr counts an event for key and returns the count within the current window
+func (c *RedisCounter) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return incrScript.Run(ctx, c.client, []string{key}, window.Milliseconds()).Int64()
+}
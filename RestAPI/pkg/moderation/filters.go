@@ -0,0 +1,271 @@
+package moderation
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/cache"
+)
+
+// MaxLength rejects content longer than a number of characters
+type MaxLength struct {
+	max int
+}
+
+// NewMaxLength creates a filter rejecting content over max characters
+func NewMaxLength(max int) *MaxLength {
+	return &MaxLength{max: max}
+}
+
+// Name returns the filter name
+func (f *MaxLength) Name() string {
+	return "max_length"
+}
+
+// Check rejects the message when its content is too long
+func (f *MaxLength) Check(_ context.Context, msg *Message) (Verdict, error) {
+	if utf8.RuneCountInString(msg.Content) > f.max {
+		return Verdict{Action: ActionReject, Reason: fmt.Sprintf("content exceeds %d characters", f.max)}, nil
+	}
+	return Verdict{}, nil
+}
+
+// BlockedWords matches words and phrases from a list against normalized content
+type BlockedWords struct {
+	action Action
+	// phrases are matched as whole words; fragments anywhere in the text
+	phrases   [][]string
+	fragments []string
+}
+
+// NewBlockedWords creates a filter for the given words and phrases. Entries are normalized like content,
+// so one entry covers case, accent, fullwidth and leetspeak variants.
+func NewBlockedWords(words []string, action Action) *BlockedWords {
+	f := &BlockedWords{action: action}
+	for _, word := range words {
+		normalized := Normalize(word)
+		if normalized == "" {
+			continue
+		}
+		if spaceSeparated(normalized) {
+			if phrase := tokens(normalized); len(phrase) > 0 {
+				f.phrases = append(f.phrases, phrase)
+			}
+		} else {
+			f.fragments = append(f.fragments, normalized)
+		}
+	}
+	return f
+}
+
+// Name returns the filter name
+func (f *BlockedWords) Name() string {
+	return "blocked_words"
+}
+
+// Check flags or rejects the message when it contains a blocked word
+func (f *BlockedWords) Check(_ context.Context, msg *Message) (Verdict, error) {
+	normalized := Normalize(msg.Content)
+
+	for _, fragment := range f.fragments {
+		if strings.Contains(normalized, fragment) {
+			return f.verdict(fragment), nil
+		}
+	}
+
+	if len(f.phrases) == 0 {
+		return Verdict{}, nil
+	}
+	words := tokens(normalized)
+	for _, phrase := range f.phrases {
+		if containsPhrase(words, phrase) {
+			return f.verdict(strings.Join(phrase, " ")), nil
+		}
+	}
+	return Verdict{}, nil
+}
+
+func (f *BlockedWords) verdict(word string) Verdict {
+	return Verdict{Action: f.action, Reason: fmt.Sprintf("contains blocked word %q", word)}
+}
+
+// containsPhrase reports whether words contains phrase as consecutive words
+func containsPhrase(words, phrase []string) bool {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// RegexRule flags or rejects content matching a regular expression
+type RegexRule struct {
+	name      string
+	pattern   *regexp.Regexp
+	action    Action
+	reason    string
+	normalize bool
+}
+
+// NewRegexRule compiles a rule. With normalize the pattern is matched against normalized content.
+func NewRegexRule(name, pattern string, action Action, reason string, normalize bool) (*RegexRule, error) {
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", name, err)
+	}
+	if reason == "" {
+		reason = "matches rule " + name
+	}
+	return &RegexRule{name: name, pattern: compiled, action: action, reason: reason, normalize: normalize}, nil
+}
+
+// Name returns the filter name
+func (f *RegexRule) Name() string {
+	return "rule:" + f.name
+}
+
+// Check flags or rejects the message when its content matches the rule
+func (f *RegexRule) Check(_ context.Context, msg *Message) (Verdict, error) {
+	content := msg.Content
+	if f.normalize {
+		content = Normalize(content)
+	}
+	if f.pattern.MatchString(content) {
+		return Verdict{Action: f.action, Reason: f.reason}, nil
+	}
+	return Verdict{}, nil
+}
+
+// linkPattern finds links written with a scheme or starting with "www."
+var linkPattern = regexp.MustCompile(`(?i)\b(?:[a-z][a-z0-9+.-]*://|www\.)[^\s<>"']+`)
+
+// Links checks the domains of links in content against allow and deny lists
+type Links struct {
+	allow  []string
+	deny   []string
+	action Action
+}
+
+// NewLinks creates a link filter. Links to denied domains are rejected. When allow is not empty,
+// links to any other domain get action. A domain covers its subdomains.
+func NewLinks(allow, deny []string, action Action) *Links {
+	return &Links{allow: normalizeDomains(allow), deny: normalizeDomains(deny), action: action}
+}
+
+// Name returns the filter name
+func (f *Links) Name() string {
+	return "links"
+}
+
+// Check rejects links to denied domains and flags or rejects links outside the allow list
+func (f *Links) Check(_ context.Context, msg *Message) (Verdict, error) {
+	var outside string
+	// 전각 점·슬래시로 쓴 링크도 찾도록 정규화한 내용에서 찾는다.
+	for _, link := range linkPattern.FindAllString(Normalize(msg.Content), -1) {
+		host := linkHost(link)
+		if host == "" {
+			continue
+		}
+		if matchDomain(host, f.deny) {
+			return Verdict{Action: ActionReject, Reason: fmt.Sprintf("links to blocked domain %s", host)}, nil
+		}
+		if outside == "" && len(f.allow) > 0 && !matchDomain(host, f.allow) {
+			outside = host
+		}
+	}
+
+	if outside != "" {
+		return Verdict{Action: f.action, Reason: fmt.Sprintf("links to domain %s outside the allow list", outside)}, nil
+	}
+	return Verdict{}, nil
+}
+
+// linkHost returns the lowercase host of a link without a trailing dot
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(strings.TrimRight(link, ".,;:!?)"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+}
+
+// matchDomain reports whether host is one of the domains or a subdomain of one
+func matchDomain(host string, domains []string) bool {
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
+
+// Counter counts events per key within a window that starts at the first event
+type Counter interface {
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+}
+
+// Flood catches the same content sent repeatedly by one user
+type Flood struct {
+	counter    Counter
+	maxRepeats int64
+	window     time.Duration
+	action     Action
+}
+
+// NewFlood creates a filter that acts on the content a user sends more than maxRepeats times within window
+func NewFlood(counter Counter, maxRepeats int, window time.Duration, action Action) *Flood {
+	return &Flood{counter: counter, maxRepeats: int64(maxRepeats), window: window, action: action}
+}
+
+// Name returns the filter name
+func (f *Flood) Name() string {
+	return "flood"
+}
+
+// Check counts the message and flags or rejects it once the same content repeats too often.
+// 정규화한 내용의 해시로 세므로 대소문자·공백만 바꾼 반복도 같은 내용으로 본다.
+func (f *Flood) Check(ctx context.Context, msg *Message) (Verdict, error) {
+	if msg.Edit || msg.UserID == "" {
+		return Verdict{}, nil
+	}
+
+	digest := sha256.Sum256([]byte(Normalize(msg.Content)))
+	count, err := f.counter.Incr(ctx, cache.ModerationFloodKey(msg.UserID, hex.EncodeToString(digest[:16])), f.window)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	if count > f.maxRepeats {
+		return Verdict{Action: f.action, Reason: fmt.Sprintf("the same content was sent %d times within %s", count, f.window)}, nil
+	}
+	return Verdict{}, nil
+}
@@ -0,0 +1,114 @@
+// Package moderation checks message content with a chain of filters before it is published.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Action is what a filter decides for a message
+type Action string
+
+// Filter actions, from least to most severe
+const (
+	ActionAllow  Action = "allow"
+	ActionFlag   Action = "flag"
+	ActionReject Action = "reject"
+)
+
+// ParseAction validates a configured action. 설정에서 허용하지 않는 "allow" 는 규칙을 끄는 것과 같으므로 받지 않는다.
+func ParseAction(value string) (Action, error) {
+	switch Action(value) {
+	case ActionFlag, ActionReject:
+		return Action(value), nil
+	default:
+		return "", fmt.Errorf("action must be %q or %q, got %q", ActionFlag, ActionReject, value)
+	}
+}
+
+// Message is the content being checked
+type Message struct {
+	UserID  string
+	Content string
+	// Edit is set when an already sent message is being edited; flood detection skips edits
+	Edit bool
+}
+
+// Verdict is one filter's finding about a message
+type Verdict struct {
+	Filter string `json:"filter"`
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// Filter checks one aspect of a message. A Verdict with ActionAllow (or the zero Verdict) lets the message through.
+type Filter interface {
+	Name() string
+	Check(ctx context.Context, msg *Message) (Verdict, error)
+}
+
+// Decision is the outcome of a pipeline run
+type Decision struct {
+	Action Action `json:"action"`
+	// Verdicts are the flags and the rejection, in filter order
+	Verdicts []Verdict `json:"verdicts,omitempty"`
+}
+
+// Rejection returns the verdict that rejected the message, or nil
+func (d *Decision) Rejection() *Verdict {
+	for i := range d.Verdicts {
+		if d.Verdicts[i].Action == ActionReject {
+			return &d.Verdicts[i]
+		}
+	}
+	return nil
+}
+
+// Pipeline runs filters in order
+type Pipeline struct {
+	filters []Filter
+}
+
+// NewPipeline creates a pipeline of the given filters
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Filters returns the names of the pipeline's filters in order
+func (p *Pipeline) Filters() []string {
+	names := make([]string, 0, len(p.filters))
+	for _, filter := range p.filters {
+		names = append(names, filter.Name())
+	}
+	return names
+}
+
+// Run checks a message with every filter until one rejects it.
+// 필터가 실패하면(예: Redis 장애) 그 필터만 건너뛰고 나머지를 계속 돌린 뒤, 결정과 함께 오류를 돌려준다 —
+// 판정 수단이 잠깐 없다고 메시지를 막지는 않는다.
+func (p *Pipeline) Run(ctx context.Context, msg *Message) (*Decision, error) {
+	decision := &Decision{Action: ActionAllow}
+	var errs []error
+
+	for _, filter := range p.filters {
+		verdict, err := filter.Check(ctx, msg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filter.Name(), err))
+			continue
+		}
+		if verdict.Action == "" || verdict.Action == ActionAllow {
+			continue
+		}
+
+		verdict.Filter = filter.Name()
+		decision.Verdicts = append(decision.Verdicts, verdict)
+		if verdict.Action == ActionReject {
+			decision.Action = ActionReject
+			break
+		}
+		decision.Action = ActionFlag
+	}
+
+	return decision, errors.Join(errs...)
+}
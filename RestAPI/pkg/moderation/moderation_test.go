@@ -0,0 +1,271 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func check(t *testing.T, filter Filter, content string) Verdict {
+	t.Helper()
+
+	verdict, err := filter.Check(context.Background(), &Message{UserID: "alice", Content: content})
+	require.NoError(t, err)
+	return verdict
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"Ｆｒｅｅ  Money":           "free money",
+		"FR\u00c9E\u200b money": "free money",
+		"\ufb01ne":              "fine",
+		"𝐁𝐎𝐋𝐃":                  "bold",
+		"  안녕하세요\t세계  ":         "안녕하세요 세계",
+		"Straße":                "strasse",
+		"e\u0301clair":          "eclair",
+		"\u00adsoft\u00adhyp":   "softhyp",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, Normalize(input), input)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	assert.Equal(t, []string{"free", "money", "2024"}, tokens(Normalize("FR33 m0ney, 2024!")))
+	assert.Equal(t, []string{"spam", "@"}, tokens("$pam @"))
+}
+
+func TestMaxLength(t *testing.T) {
+	filter := NewMaxLength(5)
+
+	assert.Equal(t, ActionAllow, check(t, filter, "안녕하세요").Action.orAllow())
+	assert.Equal(t, ActionReject, check(t, filter, "안녕하세요!").Action)
+}
+
+func TestBlockedWords(t *testing.T) {
+	filter := NewBlockedWords([]string{"Spam", "buy now", "바보", ""}, ActionReject)
+
+	tests := map[string]Action{
+		"this is SPAM":            ActionReject,
+		"this is ＳＰＡＭ":            ActionReject,
+		"this is $p4m":            ActionReject,
+		"s p a m":                 ActionAllow,
+		"spammer":                 ActionAllow,
+		"Buy   NOW!":              ActionReject,
+		"buy it now":              ActionAllow,
+		"너 바보야":                   ActionReject,
+		"hello":                   ActionAllow,
+		"the year 4 spam reports": ActionReject,
+	}
+	for content, want := range tests {
+		assert.Equal(t, want, check(t, filter, content).Action.orAllow(), content)
+	}
+
+	verdict := check(t, filter, "buy now")
+	assert.Equal(t, `contains blocked word "buy now"`, verdict.Reason)
+}
+
+func TestRegexRule(t *testing.T) {
+	raw, err := NewRegexRule("card", `\b\d{4}-\d{4}-\d{4}-\d{4}\b`, ActionFlag, "", false)
+	require.NoError(t, err)
+	normalized, err := NewRegexRule("crypto", `free (btc|bitcoin)`, ActionReject, "crypto scam", true)
+	require.NoError(t, err)
+
+	verdict := check(t, raw, "my card is 1234-5678-9012-3456")
+	assert.Equal(t, ActionFlag, verdict.Action)
+	assert.Equal(t, "matches rule card", verdict.Reason)
+	assert.Equal(t, "rule:card", raw.Name())
+
+	assert.Equal(t, ActionReject, check(t, normalized, "FREE   Bitcoin here").Action)
+	assert.Equal(t, ActionAllow, check(t, normalized, "bitcoin price").Action.orAllow())
+
+	_, err = NewRegexRule("broken", `(`, ActionFlag, "", false)
+	assert.Error(t, err)
+}
+
+func TestLinks(t *testing.T) {
+	t.Run("deny list", func(t *testing.T) {
+		filter := NewLinks(nil, []string{"Evil.example", "."}, ActionFlag)
+
+		assert.Equal(t, ActionReject, check(t, filter, "see https://www.evil.example/x").Action)
+		assert.Equal(t, ActionReject, check(t, filter, "see ｗｗｗ．evil．example").Action)
+		assert.Equal(t, ActionAllow, check(t, filter, "see https://notevil.example").Action.orAllow())
+		assert.Equal(t, ActionAllow, check(t, filter, "see https://good.example").Action.orAllow())
+	})
+
+	t.Run("allow list", func(t *testing.T) {
+		filter := NewLinks([]string{"example.com"}, []string{"bad.example.com"}, ActionFlag)
+
+		assert.Equal(t, ActionAllow, check(t, filter, "docs at https://docs.example.com/page.").Action.orAllow())
+		assert.Equal(t, ActionAllow, check(t, filter, "no links, just example.org").Action.orAllow())
+
+		verdict := check(t, filter, "https://example.com and http://other.net:8080/x")
+		assert.Equal(t, ActionFlag, verdict.Action)
+		assert.Equal(t, "links to domain other.net outside the allow list", verdict.Reason)
+
+		assert.Equal(t, ActionReject, check(t, filter, "http://other.net http://bad.example.com").Action)
+	})
+}
+
+func TestFlood(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	for name, counter := range map[string]Counter{"memory": NewMemoryCounter(), "redis": NewRedisCounter(client)} {
+		t.Run(name, func(t *testing.T) {
+			filter := NewFlood(counter, 2, time.Minute, ActionReject)
+
+			for i := 0; i < 2; i++ {
+				assert.Equal(t, ActionAllow, check(t, filter, "hello").Action.orAllow())
+			}
+			verdict := check(t, filter, "  HELLO ")
+			assert.Equal(t, ActionReject, verdict.Action)
+			assert.Equal(t, "the same content was sent 3 times within 1m0s", verdict.Reason)
+
+			// 다른 내용, 다른 사용자, 수정은 따로 센다.
+			assert.Equal(t, ActionAllow, check(t, filter, "hello again").Action.orAllow())
+			verdict, err := filter.Check(ctx, &Message{UserID: "bob", Content: "hello"})
+			require.NoError(t, err)
+			assert.Equal(t, ActionAllow, verdict.Action.orAllow())
+			verdict, err = filter.Check(ctx, &Message{UserID: "alice", Content: "hello", Edit: true})
+			require.NoError(t, err)
+			assert.Equal(t, ActionAllow, verdict.Action.orAllow())
+		})
+	}
+
+	t.Run("window expires", func(t *testing.T) {
+		now := time.Now()
+		counter := NewMemoryCounter()
+		counter.now = func() time.Time { return now }
+		filter := NewFlood(counter, 1, time.Minute, ActionFlag)
+
+		check(t, filter, "hi")
+		assert.Equal(t, ActionFlag, check(t, filter, "hi").Action)
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, ActionAllow, check(t, filter, "hi").Action.orAllow())
+		assert.Len(t, counter.entries, 1)
+	})
+
+	t.Run("redis window", func(t *testing.T) {
+		filter := NewFlood(NewRedisCounter(client), 1, time.Minute, ActionFlag)
+		check(t, filter, "window")
+		assert.Equal(t, ActionFlag, check(t, filter, "window").Action)
+
+		server.FastForward(time.Minute)
+		assert.Equal(t, ActionAllow, check(t, filter, "window").Action.orAllow())
+	})
+}
+
+// failingFilter always fails, like a flood filter without Redis
+type failingFilter struct{}
+
+func (failingFilter) Name() string { return "failing" }
+
+func (failingFilter) Check(context.Context, *Message) (Verdict, error) {
+	return Verdict{}, errors.New("connection refused")
+}
+
+func TestPipeline(t *testing.T) {
+	ctx := context.Background()
+	flag, err := NewRegexRule("question", `\?`, ActionFlag, "asks a question", false)
+	require.NoError(t, err)
+	pipeline := NewPipeline(NewMaxLength(20), failingFilter{}, flag, NewBlockedWords([]string{"spam"}, ActionReject))
+
+	assert.Equal(t, []string{"max_length", "failing", "rule:question", "blocked_words"}, pipeline.Filters())
+
+	t.Run("allow", func(t *testing.T) {
+		decision, err := pipeline.Run(ctx, &Message{Content: "hello"})
+		assert.ErrorContains(t, err, "failing: connection refused")
+		assert.Equal(t, ActionAllow, decision.Action)
+		assert.Empty(t, decision.Verdicts)
+		assert.Nil(t, decision.Rejection())
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		decision, _ := pipeline.Run(ctx, &Message{Content: "hello?"})
+		assert.Equal(t, ActionFlag, decision.Action)
+		assert.Equal(t, []Verdict{{Filter: "rule:question", Action: ActionFlag, Reason: "asks a question"}}, decision.Verdicts)
+	})
+
+	t.Run("reject keeps earlier flags", func(t *testing.T) {
+		decision, _ := pipeline.Run(ctx, &Message{Content: "spam?"})
+		assert.Equal(t, ActionReject, decision.Action)
+		require.Len(t, decision.Verdicts, 2)
+		assert.Equal(t, "blocked_words", decision.Rejection().Filter)
+	})
+
+	t.Run("reject stops the chain", func(t *testing.T) {
+		decision, _ := pipeline.Run(ctx, &Message{Content: strings.Repeat("spam? ", 10)})
+		assert.Equal(t, ActionReject, decision.Action)
+		assert.Equal(t, []Verdict{{Filter: "max_length", Action: ActionReject, Reason: "content exceeds 20 characters"}}, decision.Verdicts)
+	})
+}
+
+func TestBuild(t *testing.T) {
+	words := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(words, []byte("# comment\n\nscam\n  phish  \n"), 0o600))
+
+	pipeline, err := Build(Config{
+		MaxLength:          100,
+		BlockedWords:       []string{"spam"},
+		BlockedWordsFile:   words,
+		BlockedWordsAction: ActionReject,
+		Rules:              []RuleConfig{{Name: "question", Pattern: `\?`, Action: ActionFlag}},
+		DenyDomains:        []string{"evil.example"},
+		LinksAction:        ActionFlag,
+		FloodMaxRepeats:    3,
+		FloodWindow:        time.Minute,
+		FloodAction:        ActionReject,
+	}, NewMemoryCounter())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"max_length", "blocked_words", "rule:question", "links", "flood"}, pipeline.Filters())
+
+	decision, _ := pipeline.Run(context.Background(), &Message{UserID: "alice", Content: "PHISH"})
+	assert.Equal(t, ActionReject, decision.Action)
+
+	t.Run("empty", func(t *testing.T) {
+		pipeline, err := Build(Config{}, nil)
+		require.NoError(t, err)
+		assert.Empty(t, pipeline.Filters())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Build(Config{FloodMaxRepeats: 1}, nil)
+		assert.Error(t, err)
+		_, err = Build(Config{BlockedWordsFile: filepath.Join(t.TempDir(), "missing.txt")}, nil)
+		assert.Error(t, err)
+		_, err = Build(Config{Rules: []RuleConfig{{Name: "broken", Pattern: `(`}}}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseAction(t *testing.T) {
+	action, err := ParseAction("flag")
+	require.NoError(t, err)
+	assert.Equal(t, ActionFlag, action)
+
+	for _, value := range []string{"", "allow", "block"} {
+		_, err := ParseAction(value)
+		assert.Error(t, err, value)
+	}
+}
+
+// orAllow reads the zero action as allow
+func (a Action) orAllow() Action {
+	if a == "" {
+		return ActionAllow
+	}
+	return a
+}
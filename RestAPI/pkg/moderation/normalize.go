@@ -0,0 +1,92 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// leetLetters maps the digits and symbols commonly typed in place of letters
+var leetLetters = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's',
+}
+
+// Normalize folds text for matching: compatibility forms (fullwidth, ligatures, styled letters) become plain
+// letters, case is folded, accents and invisible format characters are dropped, and whitespace is collapsed.
+// 예) "Ｆｒｅｅ"·"ｆｒｅｅ"·"FRÉE" 는 모두 "free" 가 된다. 한글 음절은 그대로 유지한다.
+func Normalize(text string) string {
+	folded := cases.Fold().String(norm.NFKC.String(text))
+
+	var b strings.Builder
+	b.Grow(len(folded))
+	space := false
+	for _, r := range norm.NFD.String(folded) {
+		switch {
+		case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Cf, r):
+			continue
+		case unicode.IsSpace(r):
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+
+	// 한글은 NFD 에서 자모로 나뉘므로 다시 합친다.
+	return norm.NFC.String(b.String())
+}
+
+// tokens splits normalized text into words of letters and digits. Letters typed as digits or symbols
+// are read as letters inside words that have at least one letter, so "fr33" is "free" but "2024" stays.
+func tokens(normalized string) []string {
+	var words []string
+	var word []rune
+	letters := false
+
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if letters {
+			for i, r := range word {
+				if l, ok := leetLetters[r]; ok {
+					word[i] = l
+				}
+			}
+		}
+		words = append(words, string(word))
+		word, letters = word[:0], false
+	}
+
+	for _, r := range normalized {
+		_, leet := leetLetters[r]
+		switch {
+		case unicode.IsLetter(r):
+			letters = true
+			word = append(word, r)
+		case unicode.IsDigit(r) || leet:
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return words
+}
+
+// spaceSeparated reports whether every letter of a word belongs to a script that separates words with spaces
+// and does not attach particles to them. 한글·한자·가나처럼 조사나 띄어쓰기 없이 붙여 쓰는 문자는 단어 경계로 찾을 수 없다.
+func spaceSeparated(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) && !unicode.In(r, unicode.Latin, unicode.Greek, unicode.Cyrillic) {
+			return false
+		}
+	}
+	return true
+}
@@ -8,14 +8,16 @@ import (
 	apperrors "github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/errors"
 )
 
-// Response represents a standard API response
+// Response represents a standard API response.
+// Fields 는 요청 본문의 어떤 필드가 왜 거부됐는지 담는다(JSON 필드 이름 → 사유).
 type Response struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message,omitempty"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     string      `json:"error,omitempty"`
-	Code      string      `json:"code,omitempty"`
-	Timestamp int64       `json:"timestamp"`
+	Success   bool              `json:"success"`
+	Message   string            `json:"message,omitempty"`
+	Data      interface{}       `json:"data,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	Code      string            `json:"code,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+	Timestamp int64             `json:"timestamp"`
 }
 
 // PaginatedData represents paginated response data
@@ -55,6 +57,16 @@ func Created(c *gin.Context, message string, data interface{}) {
 	})
 }
 
+// Accepted sends a 202 Accepted response for work that continues in the background
+func Accepted(c *gin.Context, message string, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Timestamp: time.Now().Unix(),
+	})
+}
+
 // NoContent sends a 204 No Content response
 func NoContent(c *gin.Context) {
 	c.Status(http.StatusNoContent)
@@ -81,6 +93,7 @@ func Error(c *gin.Context, err error) {
 			Success:   false,
 			Error:     appErr.Message,
 			Code:      appErr.Code,
+			Fields:    appErr.Fields,
 			Timestamp: time.Now().Unix(),
 		})
 		return
@@ -0,0 +1,128 @@
+// Package tracing sets up OpenTelemetry tracing and carries trace context through RabbitMQ messages.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName names the tracer of every span this module creates
+const InstrumentationName = "github.com/hyunkyulee/RealTimeMessageChat/RestAPI"
+
+// Exporters
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config holds the exporter and sampling settings
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// OTLPEndpoint is the collector URL, e.g. http://otel-collector:4318; empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost
+	OTLPEndpoint string
+	// SampleRatio is the share of new traces that are recorded; traces continued from a caller follow the caller's decision
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+// 반환된 shutdown 은 종료 시 남은 span 을 내보낸다.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(cfg.ServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(NewSampler(cfg.SampleRatio)),
+	)
+	Install(provider)
+	return provider.Shutdown, nil
+}
+
+// Install makes provider the global tracer provider and propagates W3C trace context and baggage
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// NewSampler samples ratio of new traces and follows the parent's decision otherwise.
+// 부모를 따르지 않으면 한 요청의 span 이 서비스마다 따로 버려져 trace 가 중간에 끊긴다.
+func NewSampler(ratio float64) sdktrace.Sampler {
+	return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+}
+
+// Tracer returns the tracer of this module from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// newExporter builds the configured span exporter
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case ExporterStdout:
+		return stdouttrace.New()
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter: %q", cfg.Exporter)
+	}
+}
+
+// AMQPCarrier reads and writes trace context in AMQP message headers
+type AMQPCarrier amqp.Table
+
+// Get returns the header value for key, or "" when it is missing or not a string
+func (c AMQPCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+// Set stores a header
+func (c AMQPCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys lists the header names
+func (c AMQPCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// InjectAMQP writes the trace context of ctx (traceparent, tracestate) into headers
+func InjectAMQP(ctx context.Context, headers amqp.Table) {
+	otel.GetTextMapPropagator().Inject(ctx, AMQPCarrier(headers))
+}
+
+// ExtractAMQP returns ctx carrying the trace context found in headers, for consumers continuing the trace
+func ExtractAMQP(ctx context.Context, headers amqp.Table) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, AMQPCarrier(headers))
+}
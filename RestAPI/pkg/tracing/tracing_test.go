@@ -0,0 +1,65 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing"
+	"github.com/hyunkyulee/RealTimeMessageChat/RestAPI/pkg/tracing/tracingtest"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAMQPPropagation(t *testing.T) {
+	exporter := tracingtest.Setup(t)
+
+	ctx, span := tracing.Tracer().Start(context.Background(), "publish")
+	headers := amqp.Table{"x-existing": int32(1)}
+	tracing.InjectAMQP(ctx, headers)
+	span.End()
+
+	traceparent, ok := headers["traceparent"].(string)
+	require.True(t, ok, "traceparent 는 문자열 헤더로 들어가야 다른 언어의 소비자도 읽는다")
+	assert.Contains(t, traceparent, span.SpanContext().TraceID().String())
+	assert.Equal(t, int32(1), headers["x-existing"])
+
+	consumed := trace.SpanContextFromContext(tracing.ExtractAMQP(context.Background(), headers))
+	assert.True(t, consumed.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), consumed.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), consumed.SpanID())
+	assert.Len(t, exporter.GetSpans(), 1)
+}
+
+func TestExtractAMQP_NoTraceContext(t *testing.T) {
+	tracingtest.Setup(t)
+
+	ctx := tracing.ExtractAMQP(context.Background(), amqp.Table{"traceparent": int64(1)})
+	assert.False(t, trace.SpanContextFromContext(ctx).IsValid())
+}
+
+func TestNewSampler(t *testing.T) {
+	traceID := trace.TraceID{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	sample := func(sampler sdktrace.Sampler, parent trace.SpanContext) sdktrace.SamplingDecision {
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), parent)
+		return sampler.ShouldSample(sdktrace.SamplingParameters{ParentContext: ctx, TraceID: traceID, Name: "span"}).Decision
+	}
+
+	never := tracing.NewSampler(0)
+	assert.Equal(t, sdktrace.Drop, sample(never, trace.SpanContext{}))
+
+	sampledParent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: trace.SpanID{1}, TraceFlags: trace.FlagsSampled, Remote: true,
+	})
+	assert.Equal(t, sdktrace.RecordAndSample, sample(never, sampledParent), "호출측이 샘플링한 trace 는 비율과 관계없이 잇는다")
+
+	unsampledParent := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: trace.SpanID{1}, Remote: true})
+	assert.Equal(t, sdktrace.Drop, sample(tracing.NewSampler(1), unsampledParent))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	_, err := tracing.Setup(context.Background(), tracing.Config{ServiceName: "test", Exporter: "jaeger", SampleRatio: 1})
+	assert.ErrorContains(t, err, "unknown tracing exporter")
+}
@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"type":"user.created"}`)
+	signature := Sign("secret", 1700000000, body)
+
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	assert.Len(t, signature, len("sha256=")+64)
+	assert.True(t, Verify("secret", 1700000000, body, signature))
+
+	assert.False(t, Verify("other", 1700000000, body, signature))
+	assert.False(t, Verify("secret", 1700000001, body, signature), "타임스탬프도 서명된다")
+	assert.False(t, Verify("secret", 1700000000, []byte(`{"type":"user.deleted"}`), signature))
+	assert.False(t, Verify("secret", 1700000000, body, strings.TrimPrefix(signature, "sha256=")))
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	require.NoError(t, err)
+	b, err := NewSecret()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(a, "whsec_"))
+	assert.NotEqual(t, a, b)
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 10*time.Second, 5*time.Minute
+
+	assert.Equal(t, 10*time.Second, Backoff(1, base, max))
+	assert.Equal(t, 20*time.Second, Backoff(2, base, max))
+	assert.Equal(t, 160*time.Second, Backoff(5, base, max))
+	assert.Equal(t, max, Backoff(6, base, max))
+	assert.Equal(t, max, Backoff(100, base, max))
+	assert.Equal(t, max, Backoff(1, time.Hour, max))
+}
@@ -34,6 +34,8 @@ UserClient::UserClient(std::shared_ptr<Configurations> configurations)
 
 	messages_.insert({ "update_user_clinet_status", std::bind(&UserClient::update_user_clinet_status, this, std::placeholders::_1) });
 	messages_.insert({ "send_broadcast_message", std::bind(&UserClient::send_broadcast_message, this, std::placeholders::_1) });
+	messages_.insert({ "edit_message", std::bind(&UserClient::edit_message, this, std::placeholders::_1) });
+	messages_.insert({ "recall", std::bind(&UserClient::recall, this, std::placeholders::_1) });
 }
 
 UserClient::~UserClient(void)
@@ -322,3 +324,17 @@ auto UserClient::send_broadcast_message(const std::string message) -> std::expec
 
 	return {};
 }
+
+auto UserClient::edit_message(const std::string message) -> std::expected<void, std::string>
+{
+	Logger::handle().write(LogTypes::Information, std::format("Received message edit: {}", message));
+
+	return {};
+}
+
+auto UserClient::recall(const std::string message) -> std::expected<void, std::string>
+{
+	Logger::handle().write(LogTypes::Information, std::format("Received message recall: {}", message));
+
+	return {};
+}
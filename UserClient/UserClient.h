@@ -36,6 +36,8 @@ protected:
 	auto parsing_message(const std::string& command, const std::string& message) -> std::expected<void, std::string>;
 	auto update_user_clinet_status(const std::string message) -> std::expected<void, std::string>;
 	auto send_broadcast_message(const std::string message) -> std::expected<void, std::string>;
+	auto edit_message(const std::string message) -> std::expected<void, std::string>;
+	auto recall(const std::string message) -> std::expected<void, std::string>;
 
 private:
 	std::mutex mutex_;
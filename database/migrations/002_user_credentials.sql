@@ -0,0 +1,17 @@
+-- /api/v1/auth/token 로그인을 위해 사용자 비밀번호 해시 컬럼을 추가한다.
+-- 기존 사용자는 NULL 로 남으며 비밀번호가 설정되기 전까지 로그인할 수 없다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255);
+END $$;
+
+COMMIT;
@@ -0,0 +1,22 @@
+-- 역할 기반 권한 검사를 위해 users 에 role 컬럼을 추가한다.
+-- 기존 사용자는 모두 'user' 가 되며, 첫 관리자는 직접 지정해야 한다:
+--   UPDATE users SET role = 'admin' WHERE user_id = '<admin user_id>';
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'users_role_check') THEN
+        ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
+    END IF;
+END $$;
+
+COMMIT;
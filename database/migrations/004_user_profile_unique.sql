@@ -0,0 +1,30 @@
+-- username 과 email 을 대소문자 구분 없이 유일하게 만든다 (PATCH /api/v1/users/:userID 의 409 근거).
+-- 이미 중복된 값이 있으면 인덱스를 만들 수 없으므로, 아래 조회로 먼저 정리한 뒤 다시 실행한다:
+--   SELECT LOWER(email), array_agg(user_id) FROM users WHERE email IS NOT NULL
+--   GROUP BY LOWER(email) HAVING COUNT(*) > 1;
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM users WHERE username IS NOT NULL
+               GROUP BY LOWER(username) HAVING COUNT(*) > 1) THEN
+        RAISE EXCEPTION 'users has usernames that differ only in case - resolve them before applying this migration';
+    END IF;
+
+    IF EXISTS (SELECT 1 FROM users WHERE email IS NOT NULL
+               GROUP BY LOWER(email) HAVING COUNT(*) > 1) THEN
+        RAISE EXCEPTION 'users has emails that differ only in case - resolve them before applying this migration';
+    END IF;
+
+    CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (LOWER(username));
+    CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
+END $$;
+
+COMMIT;
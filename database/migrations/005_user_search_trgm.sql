@@ -0,0 +1,23 @@
+-- GET /api/v1/users 의 부분 일치 검색(q, username, email)과 기간 필터·정렬을 위한 인덱스.
+-- '%term%' 형태의 ILIKE 는 btree 를 쓰지 못하므로 pg_trgm GIN 인덱스를 둔다.
+-- pg_trgm 확장을 만들려면 데이터베이스 소유자 권한이 필요하다.
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (username gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (email gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+    CREATE INDEX IF NOT EXISTS idx_users_last_seen ON users(last_seen);
+END $$;
+
+COMMIT;
@@ -0,0 +1,36 @@
+-- DELETE /api/v1/users/:userID 를 soft delete 로 바꾸고, 메시지까지 지우는 삭제(erasure) 작업 테이블을 추가한다.
+-- 기존 users 행은 모두 deleted_at = NULL(활성) 로 남는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+    COMMENT ON COLUMN users.deleted_at IS 'Soft delete time - deleted users are hidden from every query until their erasure completes';
+END $$;
+
+CREATE TABLE IF NOT EXISTS user_erasures (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             VARCHAR(255) UNIQUE NOT NULL,
+    message_mode        VARCHAR(20) NOT NULL CHECK (message_mode IN ('anonymize', 'delete')),
+    status              VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed')),
+    requested_by        VARCHAR(255) NOT NULL DEFAULT '',
+    messages_processed  BIGINT NOT NULL DEFAULT 0,
+    cache_purged        BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    last_error          TEXT NOT NULL DEFAULT '',
+    lease_until         TIMESTAMP WITH TIME ZONE,
+    requested_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at          TIMESTAMP WITH TIME ZONE,
+    completed_at        TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_erasures_open ON user_erasures(requested_at) WHERE status <> 'completed';
+
+COMMIT;
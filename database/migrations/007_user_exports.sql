@@ -0,0 +1,41 @@
+-- 사용자 데이터 내보내기(POST /api/v1/users/:userID/export) 작업 테이블을 추가한다.
+-- 사용자당 진행 중인 작업은 하나로 제한한다(idx_user_exports_open).
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS user_exports (
+        id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        user_id         VARCHAR(255) NOT NULL,
+        status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+        requested_by    VARCHAR(255) NOT NULL DEFAULT '',
+        file_name       VARCHAR(255) NOT NULL DEFAULT '',
+        size_bytes      BIGINT NOT NULL DEFAULT 0,
+        attempts        INTEGER NOT NULL DEFAULT 0,
+        last_error      TEXT NOT NULL DEFAULT '',
+        lease_until     TIMESTAMP WITH TIME ZONE,
+        requested_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        started_at      TIMESTAMP WITH TIME ZONE,
+        completed_at    TIMESTAMP WITH TIME ZONE,
+        expires_at      TIMESTAMP WITH TIME ZONE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_user_exports_user_id ON user_exports(user_id, requested_at DESC);
+    CREATE UNIQUE INDEX IF NOT EXISTS idx_user_exports_open ON user_exports(user_id) WHERE status IN ('pending', 'running');
+    CREATE INDEX IF NOT EXISTS idx_user_exports_expires_at ON user_exports(expires_at) WHERE status = 'completed';
+
+    COMMENT ON TABLE user_exports IS 'Per-user data export jobs (subject access requests) and their archives';
+    COMMENT ON COLUMN user_exports.id IS 'Export ID - also the archive file name, so it is random rather than sequential';
+    COMMENT ON COLUMN user_exports.file_name IS 'Archive file name inside export.directory; the file is removed when the export expires';
+    COMMENT ON COLUMN user_exports.expires_at IS 'The archive can be downloaded until this time';
+    COMMENT ON COLUMN user_exports.lease_until IS 'The worker holding the job renews this; an expired lease lets another worker retry it';
+END $$;
+
+COMMIT;
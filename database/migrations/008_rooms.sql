@@ -0,0 +1,42 @@
+-- 대화방(rooms)과 멤버십(room_members)을 추가하고 messages 에 room_id 를 단다.
+-- 기존 메시지는 room_id = NULL(전체 브로드캐스트) 로 남는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS room_id UUID;
+    CREATE INDEX IF NOT EXISTS idx_messages_room_id_created_at ON messages(room_id, created_at DESC) WHERE room_id IS NOT NULL;
+    COMMENT ON COLUMN messages.room_id IS 'Room from the message JSON "room_id" field - NULL for broadcast messages. No foreign key: history outlives a deleted room';
+END $$;
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id              BIGSERIAL PRIMARY KEY,
+    room_id         UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    name            VARCHAR(100) NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    is_private      BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rooms_created_at ON rooms(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS room_members (
+    room_id         UUID NOT NULL REFERENCES rooms(room_id) ON DELETE CASCADE,
+    user_id         VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role            VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'moderator', 'member')),
+    joined_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_members_user_id ON room_members(user_id);
+
+COMMIT;
@@ -0,0 +1,25 @@
+-- messages 에 1:1 메시지 수신자(recipient_id)를 단다.
+-- 기존 메시지는 recipient_id = NULL(DM 아님) 로 남는다. 방 메시지와 DM 은 동시에 될 수 없다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS recipient_id VARCHAR(255);
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_messages_room_or_recipient') THEN
+        ALTER TABLE messages ADD CONSTRAINT chk_messages_room_or_recipient CHECK (room_id IS NULL OR recipient_id IS NULL);
+    END IF;
+
+    CREATE INDEX IF NOT EXISTS idx_messages_dm_sender ON messages(user_id, recipient_id, created_at DESC) WHERE recipient_id IS NOT NULL;
+    CREATE INDEX IF NOT EXISTS idx_messages_dm_recipient ON messages(recipient_id, created_at DESC) WHERE recipient_id IS NOT NULL;
+    COMMENT ON COLUMN messages.recipient_id IS 'Direct message recipient from the message JSON "recipient_id" field - NULL unless the message is a DM';
+END $$;
+
+COMMIT;
@@ -0,0 +1,29 @@
+-- 수신자별 전달·읽음 시각(message_receipts)을 추가한다.
+-- messages.status 는 메시지당 하나라 방·브로드캐스트 메시지를 누가 받고 읽었는지 담을 수 없다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS message_receipts (
+        message_id      UUID NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+        user_id         VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        delivered_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        read_at         TIMESTAMP WITH TIME ZONE,
+        PRIMARY KEY (message_id, user_id)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_message_receipts_user_id ON message_receipts(user_id);
+
+    COMMENT ON TABLE message_receipts IS 'Per-recipient delivery and read state; a row exists once the recipient acknowledged delivery or read';
+    COMMENT ON COLUMN message_receipts.delivered_at IS 'First delivery acknowledgement - set together with read_at when a message is read without one';
+    COMMENT ON COLUMN message_receipts.read_at IS 'First read - NULL while delivered but unread';
+END $$;
+
+COMMIT;
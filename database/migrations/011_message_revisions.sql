@@ -0,0 +1,32 @@
+-- 메시지 수정(messages.edited_at)과 이전 버전(message_revisions)을 추가한다.
+-- 기존 메시지는 edited_at = NULL(수정된 적 없음) 으로 남는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP WITH TIME ZONE;
+    COMMENT ON COLUMN messages.edited_at IS 'Last content edit by the author - NULL if never edited; prior versions are in message_revisions';
+
+    CREATE TABLE IF NOT EXISTS message_revisions (
+        id              BIGSERIAL PRIMARY KEY,
+        message_id      UUID NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+        content         TEXT NOT NULL,
+        is_encrypted    BOOLEAN NOT NULL,
+        replaced_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_message_revisions_message_id ON message_revisions(message_id, id);
+
+    COMMENT ON TABLE message_revisions IS 'Prior versions of edited messages, one row per edit';
+    COMMENT ON COLUMN message_revisions.content IS 'Content before the edit - encrypted (base64) when is_encrypted, like messages.content';
+    COMMENT ON COLUMN message_revisions.replaced_at IS 'When this version was replaced by the next one';
+END $$;
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- 메시지 회수(messages.recalled_at, recalled_by)를 추가한다.
+-- 회수된 메시지의 내용은 감사용으로 남고 API 와 recent_messages 뷰에서는 가려진다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS recalled_at TIMESTAMP WITH TIME ZONE;
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS recalled_by VARCHAR(255);
+    COMMENT ON COLUMN messages.recalled_at IS 'When the message was recalled (unsent) - the content is kept for audit but never returned by the API';
+    COMMENT ON COLUMN messages.recalled_by IS 'User who recalled the message - the author or an admin';
+
+    CREATE OR REPLACE VIEW recent_messages AS
+    SELECT
+        id,
+        message_id,
+        user_id,
+        sub_id,
+        command,
+        server_name,
+        is_encrypted,
+        status,
+        created_at,
+        CASE
+            WHEN recalled_at IS NOT NULL THEN '[RECALLED]'
+            WHEN is_encrypted THEN '[ENCRYPTED]'
+            ELSE LEFT(content, 100)
+        END AS content_preview
+    FROM messages
+    WHERE created_at >= NOW() - INTERVAL '24 hours'
+    ORDER BY created_at DESC;
+END $$;
+
+COMMIT;
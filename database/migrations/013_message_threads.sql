@@ -0,0 +1,26 @@
+-- 메시지 답글(messages.parent_message_id, thread_root_id)을 추가한다.
+-- 답글의 답글도 같은 스레드(thread_root_id = 첫 메시지)에 속하며, 첫 메시지가 지워지면 답글도 함께 지워진다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS parent_message_id UUID;
+    ALTER TABLE messages ADD COLUMN IF NOT EXISTS thread_root_id UUID REFERENCES messages(message_id) ON DELETE CASCADE;
+
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'chk_messages_thread') THEN
+        ALTER TABLE messages ADD CONSTRAINT chk_messages_thread CHECK ((parent_message_id IS NULL) = (thread_root_id IS NULL));
+    END IF;
+
+    CREATE INDEX IF NOT EXISTS idx_messages_thread_root ON messages(thread_root_id, created_at) WHERE thread_root_id IS NOT NULL;
+    COMMENT ON COLUMN messages.parent_message_id IS 'Message this one replies to (MessageRequest.reply_to) - NULL unless the message is a reply';
+    COMMENT ON COLUMN messages.thread_root_id IS 'First message of the thread; replies are deleted with it';
+END $$;
+
+COMMIT;
@@ -0,0 +1,28 @@
+-- 메시지 반응(message_reactions)을 추가한다.
+-- 반응은 새 메시지를 만들지 않고, 사용자는 같은 메시지에 같은 이모지를 한 번만 달 수 있다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS message_reactions (
+        message_id      UUID NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+        user_id         VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        emoji           VARCHAR(64) NOT NULL,
+        created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        PRIMARY KEY (message_id, user_id, emoji)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_message_reactions_user_id ON message_reactions(user_id);
+
+    COMMENT ON TABLE message_reactions IS 'Emoji reactions, at most one row per message, user and emoji';
+    COMMENT ON COLUMN message_reactions.emoji IS 'Emoji character or shortcode as sent by the client, e.g. "👍" or ":tada:"';
+END $$;
+
+COMMIT;
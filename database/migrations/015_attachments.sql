@@ -0,0 +1,34 @@
+-- 첨부 파일(attachments)을 추가한다.
+-- 내용은 blob 저장소에 SHA-256 키로 한 번만 저장되고, 이 테이블은 업로드마다 한 행씩 메타데이터를 둔다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS attachments (
+        id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        uploader_id     VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        message_id      UUID,
+        file_name       VARCHAR(255) NOT NULL,
+        content_type    VARCHAR(255) NOT NULL,
+        size_bytes      BIGINT NOT NULL CHECK (size_bytes > 0),
+        sha256          CHAR(64) NOT NULL,
+        created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_attachments_uploader_id ON attachments(uploader_id, created_at DESC);
+    CREATE INDEX IF NOT EXISTS idx_attachments_message_id ON attachments(message_id) WHERE message_id IS NOT NULL;
+
+    COMMENT ON TABLE attachments IS 'Uploaded files; the content lives in the blob store under sha256/<first two hex digits>/<sha256>, shared by identical uploads';
+    COMMENT ON COLUMN attachments.message_id IS 'Message the attachment was sent with - NULL until sent; not a foreign key because DBWorker stores the message after it is published';
+    COMMENT ON COLUMN attachments.content_type IS 'Sniffed from the content, not taken from the client';
+    COMMENT ON COLUMN attachments.sha256 IS 'Hex SHA-256 of the content - also its blob store key';
+END $$;
+
+COMMIT;
@@ -0,0 +1,38 @@
+-- 검토 대기열(moderation_flags)을 추가한다.
+-- 모더레이션 필터가 flag 로 판정한 메시지는 발행되고, 관리자가 검토하도록 여기에 한 행씩 남는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS moderation_flags (
+        id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        message_id      UUID NOT NULL,
+        user_id         VARCHAR(255) NOT NULL,
+        content         TEXT NOT NULL,
+        is_edit         BOOLEAN NOT NULL DEFAULT FALSE,
+        verdicts        JSONB NOT NULL,
+        status          VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'removed')),
+        reviewed_by     VARCHAR(255),
+        reviewed_at     TIMESTAMP WITH TIME ZONE,
+        created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_moderation_flags_status ON moderation_flags(status, created_at);
+    CREATE INDEX IF NOT EXISTS idx_moderation_flags_user_id ON moderation_flags(user_id);
+
+    COMMENT ON TABLE moderation_flags IS 'Review queue of messages the moderation filters flagged; flagged messages are still published';
+    COMMENT ON COLUMN moderation_flags.message_id IS 'Flagged message - not a foreign key because DBWorker stores the message after it is published';
+    COMMENT ON COLUMN moderation_flags.content IS 'Plain content as sent, so reviewers can read encrypted messages';
+    COMMENT ON COLUMN moderation_flags.is_edit IS 'TRUE when the flagged content is an edit of an already sent message';
+    COMMENT ON COLUMN moderation_flags.verdicts IS 'Filter findings: [{"filter", "action", "reason"}]';
+    COMMENT ON COLUMN moderation_flags.status IS 'pending, approved (left as is) or removed (the message was recalled)';
+END $$;
+
+COMMIT;
@@ -0,0 +1,57 @@
+-- 외부 웹훅(webhook_subscriptions)과 전송 기록(webhook_deliveries)을 추가한다.
+-- 이벤트마다 구독별로 한 행씩 쌓이고, 전송 워커가 여기서 꺼내 보내며 재시도와 dead 상태도 여기에 남는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+        id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        url             TEXT NOT NULL,
+        secret          VARCHAR(255) NOT NULL,
+        event_types     TEXT[] NOT NULL DEFAULT '{}',
+        description     VARCHAR(255) NOT NULL DEFAULT '',
+        active          BOOLEAN NOT NULL DEFAULT TRUE,
+        created_by      VARCHAR(255) NOT NULL DEFAULT '',
+        created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    );
+
+    CREATE TABLE IF NOT EXISTS webhook_deliveries (
+        id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        subscription_id   UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+        event_id          UUID NOT NULL,
+        event_type        VARCHAR(64) NOT NULL,
+        user_id           VARCHAR(255) NOT NULL DEFAULT '',
+        payload           JSONB NOT NULL,
+        status            VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+        attempts          INTEGER NOT NULL DEFAULT 0,
+        next_attempt_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        last_status_code  INTEGER,
+        last_error        TEXT NOT NULL DEFAULT '',
+        lease_until       TIMESTAMP WITH TIME ZONE,
+        created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        delivered_at      TIMESTAMP WITH TIME ZONE
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+    CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_user_id ON webhook_deliveries(user_id);
+
+    COMMENT ON TABLE webhook_subscriptions IS 'Outbound webhook endpoints and the event types each receives';
+    COMMENT ON COLUMN webhook_subscriptions.secret IS 'HMAC-SHA256 signing key - kept in plain text because every delivery is signed with it';
+    COMMENT ON COLUMN webhook_subscriptions.event_types IS 'Event types delivered to the endpoint; empty means every type';
+    COMMENT ON TABLE webhook_deliveries IS 'One row per event and subscription: the outbox the delivery worker sends from, and the delivery log';
+    COMMENT ON COLUMN webhook_deliveries.event_id IS 'Shared by the deliveries of one event, so receivers can drop duplicates';
+    COMMENT ON COLUMN webhook_deliveries.user_id IS 'User the event is about, so erasing the user removes the payloads';
+    COMMENT ON COLUMN webhook_deliveries.status IS 'pending (waiting for an attempt), succeeded, or dead (out of attempts, redeliver by hand)';
+    COMMENT ON COLUMN webhook_deliveries.lease_until IS 'Set while a worker is sending; an expired lease lets another worker retry it';
+END $$;
+
+COMMIT;
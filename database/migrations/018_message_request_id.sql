@@ -0,0 +1,21 @@
+-- publisher_info 안의 request_id 로 메시지를 찾는 식 인덱스를 추가한다.
+-- RestAPI 가 발행 요청의 X-Request-ID 를 publisher_information.request_id 로 싣고, DBWorker 는 이를 그대로 저장한다.
+-- 식은 MessageRepository.ListByRequestID 의 WHERE 절과 같아야 인덱스를 탄다 (IS JSON 은 PostgreSQL 16 이상).
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'messages') THEN
+        RAISE NOTICE 'messages table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE INDEX IF NOT EXISTS idx_messages_request_id
+        ON messages ((CASE WHEN publisher_info IS JSON OBJECT THEN publisher_info::jsonb ->> 'request_id' END));
+
+    COMMENT ON COLUMN messages.publisher_info IS 'JSON string of producer metadata - request_id links the row to the HTTP request that sent it';
+END $$;
+
+COMMIT;
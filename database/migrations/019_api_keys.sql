@@ -0,0 +1,37 @@
+-- API 키(api_keys)를 추가한다.
+-- 데몬이 X-API-Key 헤더로 보내는 장기 자격 증명이며, 키 자체는 저장하지 않고 SHA-256 해시만 남긴다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS api_keys (
+        id              BIGSERIAL PRIMARY KEY,
+        key_id          VARCHAR(32) UNIQUE NOT NULL,
+        name            VARCHAR(100) NOT NULL,
+        secret_hash     CHAR(64) NOT NULL,
+        owner_user_id   VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+        scopes          TEXT[] NOT NULL DEFAULT '{}',
+        expires_at      TIMESTAMP WITH TIME ZONE,
+        revoked_at      TIMESTAMP WITH TIME ZONE,
+        last_used_at    TIMESTAMP WITH TIME ZONE,
+        usage_count     BIGINT NOT NULL DEFAULT 0,
+        created_by      VARCHAR(255) NOT NULL DEFAULT '',
+        created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_api_keys_owner_user_id ON api_keys(owner_user_id);
+
+    COMMENT ON TABLE api_keys IS 'Long-lived credentials for service-to-service callers, sent in the X-API-Key header';
+    COMMENT ON COLUMN api_keys.key_id IS 'Public identifier embedded in the key - used for lookup';
+    COMMENT ON COLUMN api_keys.secret_hash IS 'SHA-256 hex of the full key. The key itself is shown only once at issuance';
+    COMMENT ON COLUMN api_keys.usage_count IS 'Authenticated requests, flushed from the API in batches';
+END $$;
+
+COMMIT;
@@ -0,0 +1,41 @@
+-- 감사 로그(audit_log)를 추가한다.
+-- 상태를 바꾸는 API 호출마다 한 행씩 쌓이며, 권한 검사에서 거부된 시도도 남는다. 행은 고치거나 지우지 않는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'users') THEN
+        RAISE NOTICE 'users table does not exist - run database/schema.sql instead';
+        RETURN;
+    END IF;
+
+    CREATE TABLE IF NOT EXISTS audit_log (
+        id              BIGSERIAL PRIMARY KEY,
+        occurred_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+        actor_id        VARCHAR(255) NOT NULL DEFAULT '',
+        actor_type      VARCHAR(20) NOT NULL CHECK (actor_type IN ('user', 'api_key', 'anonymous')),
+        api_key_id      VARCHAR(32) NOT NULL DEFAULT '',
+        action          VARCHAR(100) NOT NULL,
+        target_type     VARCHAR(50) NOT NULL,
+        target_id       VARCHAR(255) NOT NULL DEFAULT '',
+        before_value    JSONB,
+        after_value     JSONB,
+        request_id      VARCHAR(64) NOT NULL DEFAULT '',
+        client_ip       VARCHAR(45) NOT NULL DEFAULT '',
+        outcome         VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'denied', 'failure')),
+        status_code     INTEGER NOT NULL
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, occurred_at DESC);
+    CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, occurred_at DESC);
+    CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log(occurred_at DESC);
+
+    COMMENT ON TABLE audit_log IS 'Append-only record of mutating API operations, including denied attempts';
+    COMMENT ON COLUMN audit_log.actor_id IS 'Authenticated user, or the owner of the API key; empty for anonymous callers';
+    COMMENT ON COLUMN audit_log.before_value IS 'State before the change, recorded only when it is cheap to read';
+    COMMENT ON COLUMN audit_log.request_id IS 'X-Request-ID of the request, for joining with access logs';
+END $$;
+
+COMMIT;
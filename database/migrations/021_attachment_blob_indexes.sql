@@ -0,0 +1,20 @@
+-- 첨부 blob 을 지울 때 쓰는 인덱스를 추가한다.
+-- AttachmentRepository.DeleteBlob 은 같은 sha256 을 쓰는 다른 첨부가 있는지 보고, 정리 워커는 오래된 미전송 첨부를 찾는다.
+
+BEGIN;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.tables
+                   WHERE table_schema = 'public' AND table_name = 'attachments') THEN
+        RAISE NOTICE 'attachments table does not exist - run database/schema.sql or 015_attachments.sql first';
+        RETURN;
+    END IF;
+
+    CREATE INDEX IF NOT EXISTS idx_attachments_sha256 ON attachments(sha256);
+    CREATE INDEX IF NOT EXISTS idx_attachments_unclaimed ON attachments(created_at) WHERE message_id IS NULL;
+
+    COMMENT ON COLUMN attachments.message_id IS 'Message the attachment was sent with - NULL until sent; unsent uploads are removed after attachments.unclaimed_ttl_hours; not a foreign key because DBWorker stores the message after it is published';
+END $$;
+
+COMMIT;
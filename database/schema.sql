@@ -1,22 +1,40 @@
 -- RealTimeMessageChat Database Schema
 
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
 
 CREATE TABLE IF NOT EXISTS users (
     id          BIGSERIAL PRIMARY KEY,
     user_id     VARCHAR(255) UNIQUE NOT NULL,
     username    VARCHAR(255),
     email       VARCHAR(255),
+    password_hash VARCHAR(255),
+    role        VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin')),
     status      VARCHAR(20) NOT NULL DEFAULT 'offline',
     last_seen   TIMESTAMP WITH TIME ZONE,
     created_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMP WITH TIME ZONE
 );
 
 CREATE INDEX IF NOT EXISTS idx_users_user_id ON users(user_id);
 CREATE INDEX IF NOT EXISTS idx_users_status ON users(status);
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_lower_key ON users (LOWER(username));
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_lower_key ON users (LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING gin (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
+CREATE INDEX IF NOT EXISTS idx_users_last_seen ON users(last_seen);
+
+COMMENT ON INDEX users_username_lower_key IS 'Usernames are unique regardless of case';
+COMMENT ON INDEX users_email_lower_key IS 'Emails are unique regardless of case';
+COMMENT ON INDEX idx_users_username_trgm IS 'Substring search (ILIKE) on GET /api/v1/users';
+COMMENT ON INDEX idx_users_email_trgm IS 'Substring search (ILIKE) on GET /api/v1/users';
 
 COMMENT ON TABLE users IS 'API users managed through /api/v1/users';
+COMMENT ON COLUMN users.password_hash IS 'bcrypt hash used by /api/v1/auth/token - NULL means the user cannot log in';
+COMMENT ON COLUMN users.role IS 'Authorization role copied into issued access tokens';
+COMMENT ON COLUMN users.deleted_at IS 'Soft delete time - deleted users are hidden from every query until their erasure completes';
 
 CREATE TABLE IF NOT EXISTS messages (
     id              BIGSERIAL PRIMARY KEY,
@@ -35,8 +53,20 @@ CREATE TABLE IF NOT EXISTS messages (
 
     status          VARCHAR(20) NOT NULL DEFAULT 'pending',
 
+    room_id         UUID,
+    recipient_id    VARCHAR(255),
+
     created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
-    processed_at    TIMESTAMP WITH TIME ZONE
+    processed_at    TIMESTAMP WITH TIME ZONE,
+    edited_at       TIMESTAMP WITH TIME ZONE,
+    recalled_at     TIMESTAMP WITH TIME ZONE,
+    recalled_by     VARCHAR(255),
+
+    parent_message_id UUID,
+    thread_root_id  UUID REFERENCES messages(message_id) ON DELETE CASCADE,
+
+    CONSTRAINT chk_messages_room_or_recipient CHECK (room_id IS NULL OR recipient_id IS NULL),
+    CONSTRAINT chk_messages_thread CHECK ((parent_message_id IS NULL) = (thread_root_id IS NULL))
 );
 
 CREATE INDEX IF NOT EXISTS idx_messages_message_id ON messages(message_id);
@@ -46,16 +76,142 @@ CREATE INDEX IF NOT EXISTS idx_messages_status ON messages(status);
 CREATE INDEX IF NOT EXISTS idx_messages_server_name ON messages(server_name);
 CREATE INDEX IF NOT EXISTS idx_messages_created_at ON messages(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_messages_user_id_created_at ON messages(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_messages_room_id_created_at ON messages(room_id, created_at DESC) WHERE room_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_dm_sender ON messages(user_id, recipient_id, created_at DESC) WHERE recipient_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_dm_recipient ON messages(recipient_id, created_at DESC) WHERE recipient_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_thread_root ON messages(thread_root_id, created_at) WHERE thread_root_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_messages_request_id ON messages ((CASE WHEN publisher_info IS JSON OBJECT THEN publisher_info::jsonb ->> 'request_id' END));
 
 COMMENT ON TABLE messages IS 'Broadcast messages consumed from RabbitMQ, optionally encrypted';
 COMMENT ON COLUMN messages.message_id IS 'Producer-supplied tracking UUID (publisher_information.message_id)';
 COMMENT ON COLUMN messages.user_id IS 'User identifier from the message JSON "id" field';
 COMMENT ON COLUMN messages.sub_id IS 'Session identifier from the message JSON "sub_id" field';
 COMMENT ON COLUMN messages.command IS 'Command from the message JSON "message.command" field';
-COMMENT ON COLUMN messages.publisher_info IS 'JSON string of producer metadata';
+COMMENT ON COLUMN messages.publisher_info IS 'JSON string of producer metadata - request_id links the row to the HTTP request that sent it';
 COMMENT ON COLUMN messages.content IS 'Message body - encrypted (base64) or plain text';
 COMMENT ON COLUMN messages.is_encrypted IS 'TRUE if content is encrypted';
 COMMENT ON COLUMN messages.status IS 'pending, sent, processed, or failed';
+COMMENT ON COLUMN messages.room_id IS 'Room from the message JSON "room_id" field - NULL for broadcast messages. No foreign key: history outlives a deleted room';
+COMMENT ON COLUMN messages.recipient_id IS 'Direct message recipient from the message JSON "recipient_id" field - NULL unless the message is a DM';
+COMMENT ON COLUMN messages.edited_at IS 'Last content edit by the author - NULL if never edited; prior versions are in message_revisions';
+COMMENT ON COLUMN messages.recalled_at IS 'When the message was recalled (unsent) - the content is kept for audit but never returned by the API';
+COMMENT ON COLUMN messages.recalled_by IS 'User who recalled the message - the author or an admin';
+COMMENT ON COLUMN messages.parent_message_id IS 'Message this one replies to (MessageRequest.reply_to) - NULL unless the message is a reply';
+COMMENT ON COLUMN messages.thread_root_id IS 'First message of the thread; replies are deleted with it';
+
+CREATE TABLE IF NOT EXISTS rooms (
+    id              BIGSERIAL PRIMARY KEY,
+    room_id         UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    name            VARCHAR(100) NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    is_private      BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rooms_created_at ON rooms(created_at DESC);
+
+COMMENT ON TABLE rooms IS 'Conversations managed through /api/v1/rooms';
+COMMENT ON COLUMN rooms.is_private IS 'Private rooms are hidden from non-members and can only be joined when added by an owner or moderator';
+
+CREATE TABLE IF NOT EXISTS room_members (
+    room_id         UUID NOT NULL REFERENCES rooms(room_id) ON DELETE CASCADE,
+    user_id         VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role            VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'moderator', 'member')),
+    joined_at       TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (room_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_members_user_id ON room_members(user_id);
+
+COMMENT ON TABLE room_members IS 'Room membership; only members can post to a room';
+COMMENT ON COLUMN room_members.role IS 'owner manages the room and its roles, moderator adds and removes members. A room always keeps at least one owner';
+
+CREATE TABLE IF NOT EXISTS message_receipts (
+    message_id      UUID NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+    user_id         VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    delivered_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    read_at         TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (message_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_receipts_user_id ON message_receipts(user_id);
+
+COMMENT ON TABLE message_receipts IS 'Per-recipient delivery and read state; a row exists once the recipient acknowledged delivery or read';
+COMMENT ON COLUMN message_receipts.delivered_at IS 'First delivery acknowledgement - set together with read_at when a message is read without one';
+COMMENT ON COLUMN message_receipts.read_at IS 'First read - NULL while delivered but unread';
+
+CREATE TABLE IF NOT EXISTS message_reactions (
+    message_id      UUID NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+    user_id         VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    emoji           VARCHAR(64) NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id, emoji)
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_reactions_user_id ON message_reactions(user_id);
+
+COMMENT ON TABLE message_reactions IS 'Emoji reactions, at most one row per message, user and emoji';
+COMMENT ON COLUMN message_reactions.emoji IS 'Emoji character or shortcode as sent by the client, e.g. "👍" or ":tada:"';
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    uploader_id     VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    message_id      UUID,
+    file_name       VARCHAR(255) NOT NULL,
+    content_type    VARCHAR(255) NOT NULL,
+    size_bytes      BIGINT NOT NULL CHECK (size_bytes > 0),
+    sha256          CHAR(64) NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachments_uploader_id ON attachments(uploader_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_attachments_message_id ON attachments(message_id) WHERE message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_attachments_sha256 ON attachments(sha256);
+CREATE INDEX IF NOT EXISTS idx_attachments_unclaimed ON attachments(created_at) WHERE message_id IS NULL;
+
+COMMENT ON TABLE attachments IS 'Uploaded files; the content lives in the blob store under sha256/<first two hex digits>/<sha256>, shared by identical uploads';
+COMMENT ON COLUMN attachments.message_id IS 'Message the attachment was sent with - NULL until sent; unsent uploads are removed after attachments.unclaimed_ttl_hours; not a foreign key because DBWorker stores the message after it is published';
+COMMENT ON COLUMN attachments.content_type IS 'Sniffed from the content, not taken from the client';
+COMMENT ON COLUMN attachments.sha256 IS 'Hex SHA-256 of the content - also its blob store key';
+
+CREATE TABLE IF NOT EXISTS message_revisions (
+    id              BIGSERIAL PRIMARY KEY,
+    message_id      UUID NOT NULL REFERENCES messages(message_id) ON DELETE CASCADE,
+    content         TEXT NOT NULL,
+    is_encrypted    BOOLEAN NOT NULL,
+    replaced_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_revisions_message_id ON message_revisions(message_id, id);
+
+COMMENT ON TABLE message_revisions IS 'Prior versions of edited messages, one row per edit';
+COMMENT ON COLUMN message_revisions.content IS 'Content before the edit - encrypted (base64) when is_encrypted, like messages.content';
+COMMENT ON COLUMN message_revisions.replaced_at IS 'When this version was replaced by the next one';
+
+CREATE TABLE IF NOT EXISTS moderation_flags (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message_id      UUID NOT NULL,
+    user_id         VARCHAR(255) NOT NULL,
+    content         TEXT NOT NULL,
+    is_edit         BOOLEAN NOT NULL DEFAULT FALSE,
+    verdicts        JSONB NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'removed')),
+    reviewed_by     VARCHAR(255),
+    reviewed_at     TIMESTAMP WITH TIME ZONE,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_flags_status ON moderation_flags(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_moderation_flags_user_id ON moderation_flags(user_id);
+
+COMMENT ON TABLE moderation_flags IS 'Review queue of messages the moderation filters flagged; flagged messages are still published';
+COMMENT ON COLUMN moderation_flags.message_id IS 'Flagged message - not a foreign key because DBWorker stores the message after it is published';
+COMMENT ON COLUMN moderation_flags.content IS 'Plain content as sent, so reviewers can read encrypted messages';
+COMMENT ON COLUMN moderation_flags.is_edit IS 'TRUE when the flagged content is an edit of an already sent message';
+COMMENT ON COLUMN moderation_flags.verdicts IS 'Filter findings: [{"filter", "action", "reason"}]';
+COMMENT ON COLUMN moderation_flags.status IS 'pending, approved (left as is) or removed (the message was recalled)';
 
 CREATE OR REPLACE VIEW recent_messages AS
 SELECT
@@ -69,6 +225,7 @@ SELECT
     status,
     created_at,
     CASE
+        WHEN recalled_at IS NOT NULL THEN '[RECALLED]'
         WHEN is_encrypted THEN '[ENCRYPTED]'
         ELSE LEFT(content, 100)
     END AS content_preview
@@ -136,3 +293,143 @@ CREATE TABLE IF NOT EXISTS encryption_keys (
 CREATE INDEX IF NOT EXISTS idx_encryption_keys_active ON encryption_keys(is_active) WHERE is_active = TRUE;
 
 COMMENT ON TABLE encryption_keys IS 'AES-256-CBC key storage. NOT YET USED - keys come from the consumer config file.';
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id              BIGSERIAL PRIMARY KEY,
+    key_id          VARCHAR(32) UNIQUE NOT NULL,
+    name            VARCHAR(100) NOT NULL,
+    secret_hash     CHAR(64) NOT NULL,
+    owner_user_id   VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    scopes          TEXT[] NOT NULL DEFAULT '{}',
+    expires_at      TIMESTAMP WITH TIME ZONE,
+    revoked_at      TIMESTAMP WITH TIME ZONE,
+    last_used_at    TIMESTAMP WITH TIME ZONE,
+    usage_count     BIGINT NOT NULL DEFAULT 0,
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_owner_user_id ON api_keys(owner_user_id);
+
+COMMENT ON TABLE api_keys IS 'Long-lived credentials for service-to-service callers, sent in the X-API-Key header';
+COMMENT ON COLUMN api_keys.key_id IS 'Public identifier embedded in the key - used for lookup';
+COMMENT ON COLUMN api_keys.secret_hash IS 'SHA-256 hex of the full key. The key itself is shown only once at issuance';
+COMMENT ON COLUMN api_keys.usage_count IS 'Authenticated requests, flushed from the API in batches';
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id              BIGSERIAL PRIMARY KEY,
+    occurred_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    actor_id        VARCHAR(255) NOT NULL DEFAULT '',
+    actor_type      VARCHAR(20) NOT NULL CHECK (actor_type IN ('user', 'api_key', 'anonymous')),
+    api_key_id      VARCHAR(32) NOT NULL DEFAULT '',
+    action          VARCHAR(100) NOT NULL,
+    target_type     VARCHAR(50) NOT NULL,
+    target_id       VARCHAR(255) NOT NULL DEFAULT '',
+    before_value    JSONB,
+    after_value     JSONB,
+    request_id      VARCHAR(64) NOT NULL DEFAULT '',
+    client_ip       VARCHAR(45) NOT NULL DEFAULT '',
+    outcome         VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'denied', 'failure')),
+    status_code     INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log(target_type, target_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_occurred_at ON audit_log(occurred_at DESC);
+
+COMMENT ON TABLE audit_log IS 'Append-only record of mutating API operations, including denied attempts';
+COMMENT ON COLUMN audit_log.actor_id IS 'Authenticated user, or the owner of the API key; empty for anonymous callers';
+COMMENT ON COLUMN audit_log.before_value IS 'State before the change, recorded only when it is cheap to read';
+COMMENT ON COLUMN audit_log.request_id IS 'X-Request-ID of the request, for joining with access logs';
+
+CREATE TABLE IF NOT EXISTS user_erasures (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             VARCHAR(255) UNIQUE NOT NULL,
+    message_mode        VARCHAR(20) NOT NULL CHECK (message_mode IN ('anonymize', 'delete')),
+    status              VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed')),
+    requested_by        VARCHAR(255) NOT NULL DEFAULT '',
+    messages_processed  BIGINT NOT NULL DEFAULT 0,
+    cache_purged        BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    last_error          TEXT NOT NULL DEFAULT '',
+    lease_until         TIMESTAMP WITH TIME ZONE,
+    requested_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at          TIMESTAMP WITH TIME ZONE,
+    completed_at        TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_erasures_open ON user_erasures(requested_at) WHERE status <> 'completed';
+
+COMMENT ON TABLE user_erasures IS 'Erasure requests for users; a completed row is the record that the erasure finished';
+COMMENT ON COLUMN user_erasures.user_id IS 'Erased user - no foreign key, the users row is removed when the erasure completes';
+COMMENT ON COLUMN user_erasures.message_mode IS 'anonymize detaches messages from the user, delete removes them';
+COMMENT ON COLUMN user_erasures.messages_processed IS 'Messages anonymized or deleted so far, committed with each batch';
+COMMENT ON COLUMN user_erasures.lease_until IS 'The worker holding the job renews this; an expired lease lets another worker resume it';
+
+CREATE TABLE IF NOT EXISTS user_exports (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id         VARCHAR(255) NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed', 'expired')),
+    requested_by    VARCHAR(255) NOT NULL DEFAULT '',
+    file_name       VARCHAR(255) NOT NULL DEFAULT '',
+    size_bytes      BIGINT NOT NULL DEFAULT 0,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    lease_until     TIMESTAMP WITH TIME ZONE,
+    requested_at    TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at      TIMESTAMP WITH TIME ZONE,
+    completed_at    TIMESTAMP WITH TIME ZONE,
+    expires_at      TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_exports_user_id ON user_exports(user_id, requested_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_exports_open ON user_exports(user_id) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_user_exports_expires_at ON user_exports(expires_at) WHERE status = 'completed';
+
+COMMENT ON TABLE user_exports IS 'Per-user data export jobs (subject access requests) and their archives';
+COMMENT ON COLUMN user_exports.id IS 'Export ID - also the archive file name, so it is random rather than sequential';
+COMMENT ON COLUMN user_exports.file_name IS 'Archive file name inside export.directory; the file is removed when the export expires';
+COMMENT ON COLUMN user_exports.expires_at IS 'The archive can be downloaded until this time';
+COMMENT ON COLUMN user_exports.lease_until IS 'The worker holding the job renews this; an expired lease lets another worker retry it';
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    url             TEXT NOT NULL,
+    secret          VARCHAR(255) NOT NULL,
+    event_types     TEXT[] NOT NULL DEFAULT '{}',
+    description     VARCHAR(255) NOT NULL DEFAULT '',
+    active          BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by      VARCHAR(255) NOT NULL DEFAULT '',
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id   UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id          UUID NOT NULL,
+    event_type        VARCHAR(64) NOT NULL,
+    user_id           VARCHAR(255) NOT NULL DEFAULT '',
+    payload           JSONB NOT NULL,
+    status            VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts          INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at   TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_status_code  INTEGER,
+    last_error        TEXT NOT NULL DEFAULT '',
+    lease_until       TIMESTAMP WITH TIME ZONE,
+    created_at        TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    delivered_at      TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_user_id ON webhook_deliveries(user_id);
+
+COMMENT ON TABLE webhook_subscriptions IS 'Outbound webhook endpoints and the event types each receives';
+COMMENT ON COLUMN webhook_subscriptions.secret IS 'HMAC-SHA256 signing key - kept in plain text because every delivery is signed with it';
+COMMENT ON COLUMN webhook_subscriptions.event_types IS 'Event types delivered to the endpoint; empty means every type';
+COMMENT ON TABLE webhook_deliveries IS 'One row per event and subscription: the outbox the delivery worker sends from, and the delivery log';
+COMMENT ON COLUMN webhook_deliveries.event_id IS 'Shared by the deliveries of one event, so receivers can drop duplicates';
+COMMENT ON COLUMN webhook_deliveries.user_id IS 'User the event is about, so erasing the user removes the payloads';
+COMMENT ON COLUMN webhook_deliveries.status IS 'pending (waiting for an attempt), succeeded, or dead (out of attempts, redeliver by hand)';
+COMMENT ON COLUMN webhook_deliveries.lease_until IS 'Set while a worker is sending; an expired lease lets another worker retry it';